@@ -0,0 +1,251 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"go.uber.org/zap"
+)
+
+// State is a state of a circuit breaker
+type State string
+
+const (
+	// StateClosed is the normal state: every fetch goes through to the API
+	StateClosed State = "closed"
+	// StateOpen means API is considered down: fetches fail immediately, without any network I/O
+	StateOpen State = "open"
+	// StateHalfOpen means cooldown is over, and a single trial fetch is let through
+	// to find out whether API is back
+	StateHalfOpen State = "half_open"
+)
+
+// Metrics is the part of service.Metrics that circuit breaker reports to
+type Metrics interface {
+	CircuitStateChanged(apiName service.APIName, from string, to string)
+}
+
+// New wraps PostalAPI with a circuit breaker:
+// after failureThreshold consecutive fetches ending with service.StatusUnknownError
+// the circuit opens, and for the next cooldown fetches return service.StatusCircuitOpen right away,
+// so that service keeps serving what it has stored instead.
+// Optional interfaces of the wrapped API (service.FormatDeclarer, service.BatchFetcher, etc.) are preserved.
+func New(
+	api service.PostalAPI,
+	apiName service.APIName,
+	metrics Metrics,
+	failureThreshold int,
+	cooldown time.Duration,
+	logger *zap.Logger,
+	now func() time.Time,
+) service.PostalAPI {
+	b := &Breaker{
+		api:              api,
+		apiName:          apiName,
+		metrics:          metrics,
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		log:              logger,
+		now:              now,
+		state:            StateClosed,
+	}
+	if batchFetcher, ok := api.(service.BatchFetcher); ok {
+		return &batchBreaker{Breaker: b, batchFetcher: batchFetcher}
+	}
+	return b
+}
+
+type Breaker struct {
+	api              service.PostalAPI
+	apiName          service.APIName
+	metrics          Metrics
+	failureThreshold int
+	cooldown         time.Duration
+	log              *zap.Logger
+	now              func() time.Time
+
+	mu                  sync.Mutex
+	state               State
+	consecutiveFailures int
+	openedAt            time.Time
+	trialInFlight       bool
+}
+
+func (b *Breaker) Fetch(ctx context.Context, trackingNumber string) service.PostalApiResponse {
+	if !b.allow() {
+		return service.PostalApiResponse{
+			TrackingNumber: trackingNumber,
+			APIName:        b.apiName,
+			Status:         service.StatusCircuitOpen,
+		}
+	}
+	resp := b.api.Fetch(ctx, trackingNumber)
+	b.record(resp.Status == service.StatusUnknownError)
+	return resp
+}
+
+func (b *Breaker) Parse(rawResponse service.PostalApiResponse) (*service.TrackingInfo, error) {
+	return b.api.Parse(rawResponse)
+}
+
+// SupportedFormats delegates to the wrapped API, empty result means it declares nothing, same as not implementing it
+func (b *Breaker) SupportedFormats() []service.TrackingNumberFormat {
+	if declarer, ok := b.api.(service.FormatDeclarer); ok {
+		return declarer.SupportedFormats()
+	}
+	return nil
+}
+
+// SupportedCountries delegates to the wrapped API, empty result means it declares nothing, same as not implementing it
+func (b *Breaker) SupportedCountries() []string {
+	if declarer, ok := b.api.(service.CountryDeclarer); ok {
+		return declarer.SupportedCountries()
+	}
+	return nil
+}
+
+// SupportedLanguages delegates to the wrapped API, empty result means it can't localize, same as not implementing it
+func (b *Breaker) SupportedLanguages() []string {
+	if localizer, ok := b.api.(service.Localizer); ok {
+		return localizer.SupportedLanguages()
+	}
+	return nil
+}
+
+// FetchLocalized goes through the circuit same as Fetch, since it hits the same API
+func (b *Breaker) FetchLocalized(ctx context.Context, trackingNumber string, language string) service.PostalApiResponse {
+	localizer, ok := b.api.(service.Localizer)
+	if !ok {
+		return service.PostalApiResponse{
+			TrackingNumber: trackingNumber,
+			APIName:        b.apiName,
+			Status:         service.StatusUnknownError,
+		}
+	}
+	if !b.allow() {
+		return service.PostalApiResponse{
+			TrackingNumber: trackingNumber,
+			APIName:        b.apiName,
+			Status:         service.StatusCircuitOpen,
+		}
+	}
+	resp := localizer.FetchLocalized(ctx, trackingNumber, language)
+	b.record(resp.Status == service.StatusUnknownError)
+	return resp
+}
+
+// State returns current state of the circuit
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow decides whether a fetch can go through, possibly moving open circuit to half-open
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Before(b.openedAt.Add(b.cooldown)) {
+			return false
+		}
+		b.transition(StateHalfOpen)
+		b.trialInFlight = true
+		return true
+	case StateHalfOpen:
+		// only a single trial fetch at a time, the rest are rejected until we know its outcome
+		if b.trialInFlight {
+			return false
+		}
+		b.trialInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record accounts for the outcome of a fetch that was allowed to go through
+func (b *Breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen {
+		b.trialInFlight = false
+		if failed {
+			b.open()
+		} else {
+			b.consecutiveFailures = 0
+			b.transition(StateClosed)
+		}
+		return
+	}
+
+	if !failed {
+		b.consecutiveFailures = 0
+		return
+	}
+	b.consecutiveFailures++
+	if b.state == StateClosed && b.consecutiveFailures >= b.failureThreshold {
+		b.open()
+	}
+}
+
+func (b *Breaker) open() {
+	b.openedAt = b.now()
+	b.transition(StateOpen)
+}
+
+func (b *Breaker) transition(to State) {
+	from := b.state
+	if from == to {
+		return
+	}
+	b.state = to
+	b.log.Info(
+		"circuit state changed",
+		zap.String("apiName", string(b.apiName)),
+		zap.String("from", string(from)),
+		zap.String("to", string(to)),
+	)
+	b.metrics.CircuitStateChanged(b.apiName, string(from), string(to))
+}
+
+// batchBreaker is a Breaker around an API that is also a service.BatchFetcher.
+// A batch request counts as a single fetch.
+type batchBreaker struct {
+	*Breaker
+	batchFetcher service.BatchFetcher
+}
+
+func (b *batchBreaker) FetchBatch(ctx context.Context, trackingNumbers []string) []service.PostalApiResponse {
+	if !b.allow() {
+		responses := make([]service.PostalApiResponse, len(trackingNumbers))
+		for i, trackingNumber := range trackingNumbers {
+			responses[i] = service.PostalApiResponse{
+				TrackingNumber: trackingNumber,
+				APIName:        b.apiName,
+				Status:         service.StatusCircuitOpen,
+			}
+		}
+		return responses
+	}
+
+	responses := b.batchFetcher.FetchBatch(ctx, trackingNumbers)
+	failed := len(responses) > 0
+	for _, resp := range responses {
+		if resp.Status != service.StatusUnknownError {
+			failed = false
+			break
+		}
+	}
+	b.record(failed)
+	return responses
+}
+
+func (b *batchBreaker) MaxBatchSize() int {
+	return b.batchFetcher.MaxBatchSize()
+}
@@ -0,0 +1,179 @@
+package circuitbreaker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/circuitbreaker"
+	"github.com/dir01/parcels/service"
+	"go.uber.org/zap"
+)
+
+type fakeAPI struct {
+	status  service.ApiResponseStatus
+	fetches int
+}
+
+func (a *fakeAPI) Fetch(ctx context.Context, trackingNumber string) service.PostalApiResponse {
+	a.fetches++
+	return service.PostalApiResponse{TrackingNumber: trackingNumber, APIName: "api1", Status: a.status}
+}
+
+func (a *fakeAPI) Parse(rawResponse service.PostalApiResponse) (*service.TrackingInfo, error) {
+	return &service.TrackingInfo{TrackingNumber: rawResponse.TrackingNumber}, nil
+}
+
+type fakeBatchAPI struct {
+	*fakeAPI
+}
+
+func (a fakeBatchAPI) FetchBatch(ctx context.Context, trackingNumbers []string) []service.PostalApiResponse {
+	var responses []service.PostalApiResponse
+	for _, tn := range trackingNumbers {
+		responses = append(responses, a.Fetch(ctx, tn))
+	}
+	return responses
+}
+
+func (a fakeBatchAPI) MaxBatchSize() int {
+	return 10
+}
+
+func (a fakeBatchAPI) SupportedCountries() []string {
+	return []string{"CN"}
+}
+
+func (a fakeBatchAPI) SupportedLanguages() []string {
+	return []string{"ru"}
+}
+
+func (a fakeBatchAPI) FetchLocalized(ctx context.Context, trackingNumber string, language string) service.PostalApiResponse {
+	return a.Fetch(ctx, trackingNumber)
+}
+
+type fakeMetrics struct {
+	transitions []string
+}
+
+func (m *fakeMetrics) CircuitStateChanged(apiName service.APIName, from string, to string) {
+	m.transitions = append(m.transitions, from+"->"+to)
+}
+
+func TestBreaker(t *testing.T) {
+	ctx := context.Background()
+
+	prepareTestSubjects := func() (*circuitbreaker.Breaker, *fakeAPI, *fakeMetrics, func(time.Duration)) {
+		api := &fakeAPI{status: service.StatusUnknownError}
+		metrics := &fakeMetrics{}
+		now := time.Unix(10000, 0)
+		breaker := circuitbreaker.New(api, "api1", metrics, 3, time.Minute, zap.NewNop(), func() time.Time {
+			return now
+		}).(*circuitbreaker.Breaker)
+		return breaker, api, metrics, func(d time.Duration) { now = now.Add(d) }
+	}
+
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		breaker, api, metrics, _ := prepareTestSubjects()
+
+		for i := 0; i < 3; i++ {
+			breaker.Fetch(ctx, "123")
+		}
+		if breaker.State() != circuitbreaker.StateOpen {
+			t.Fatalf("expected circuit to be open, got %s", breaker.State())
+		}
+
+		resp := breaker.Fetch(ctx, "123")
+		if resp.Status != service.StatusCircuitOpen || resp.TrackingNumber != "123" || resp.APIName != "api1" {
+			t.Fatalf("unexpected response of open circuit: %+v", resp)
+		}
+		if api.fetches != 3 {
+			t.Fatalf("expected open circuit not to hit API, got %d fetches", api.fetches)
+		}
+		if len(metrics.transitions) != 1 || metrics.transitions[0] != "closed->open" {
+			t.Fatalf("unexpected transitions: %v", metrics.transitions)
+		}
+	})
+
+	t.Run("success resets failure count", func(t *testing.T) {
+		breaker, api, _, _ := prepareTestSubjects()
+
+		breaker.Fetch(ctx, "123")
+		breaker.Fetch(ctx, "123")
+		api.status = service.StatusNotFound
+		breaker.Fetch(ctx, "123")
+		api.status = service.StatusUnknownError
+		breaker.Fetch(ctx, "123")
+		breaker.Fetch(ctx, "123")
+
+		if breaker.State() != circuitbreaker.StateClosed {
+			t.Fatalf("expected circuit to stay closed, got %s", breaker.State())
+		}
+	})
+
+	t.Run("half-opens after cooldown", func(t *testing.T) {
+		breaker, api, metrics, advance := prepareTestSubjects()
+		for i := 0; i < 3; i++ {
+			breaker.Fetch(ctx, "123")
+		}
+
+		// trial fetch fails, so circuit opens again for another cooldown
+		advance(time.Minute)
+		breaker.Fetch(ctx, "123")
+		if api.fetches != 4 || breaker.State() != circuitbreaker.StateOpen {
+			t.Fatalf("expected failed trial to reopen circuit, got %d fetches, %s", api.fetches, breaker.State())
+		}
+		advance(59 * time.Second)
+		breaker.Fetch(ctx, "123")
+		if api.fetches != 4 {
+			t.Fatalf("expected reopened circuit not to hit API, got %d fetches", api.fetches)
+		}
+
+		// API is back
+		advance(time.Second)
+		api.status = service.StatusSuccess
+		breaker.Fetch(ctx, "123")
+		if breaker.State() != circuitbreaker.StateClosed {
+			t.Fatalf("expected successful trial to close circuit, got %s", breaker.State())
+		}
+
+		expected := []string{"closed->open", "open->half_open", "half_open->open", "open->half_open", "half_open->closed"}
+		if len(metrics.transitions) != len(expected) {
+			t.Fatalf("expected transitions %v, got %v", expected, metrics.transitions)
+		}
+		for i := range expected {
+			if metrics.transitions[i] != expected[i] {
+				t.Fatalf("expected transitions %v, got %v", expected, metrics.transitions)
+			}
+		}
+	})
+
+	t.Run("preserves optional interfaces", func(t *testing.T) {
+		api := fakeBatchAPI{&fakeAPI{status: service.StatusUnknownError}}
+		wrapped := circuitbreaker.New(api, "api1", &fakeMetrics{}, 1, time.Minute, zap.NewNop(), time.Now)
+
+		batchFetcher, ok := wrapped.(service.BatchFetcher)
+		if !ok {
+			t.Fatalf("expected wrapped API to be a batch fetcher")
+		}
+		if _, ok := circuitbreaker.New(&fakeAPI{}, "api1", &fakeMetrics{}, 1, time.Minute, zap.NewNop(), time.Now).(service.BatchFetcher); ok {
+			t.Fatalf("expected wrapped API not to become a batch fetcher")
+		}
+
+		declarer, ok := wrapped.(service.CountryDeclarer)
+		if !ok || len(declarer.SupportedCountries()) != 1 || declarer.SupportedCountries()[0] != "CN" {
+			t.Fatalf("expected wrapped API to declare countries of the original one")
+		}
+
+		localizer, ok := wrapped.(service.Localizer)
+		if !ok || len(localizer.SupportedLanguages()) != 1 || localizer.SupportedLanguages()[0] != "ru" {
+			t.Fatalf("expected wrapped API to support languages of the original one")
+		}
+
+		batchFetcher.FetchBatch(ctx, []string{"1", "2"})
+		responses := batchFetcher.FetchBatch(ctx, []string{"1", "2"})
+		if len(responses) != 2 || api.fetches != 2 {
+			t.Fatalf("expected failed batch to open circuit, got %d fetches", api.fetches)
+		}
+	})
+}
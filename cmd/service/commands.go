@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/dir01/parcels/retention"
+	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/sqlite_storage"
+	"github.com/jmoiron/sqlx"
+)
+
+func runCommand(
+	ctx context.Context,
+	name string,
+	args []string,
+	db *sqlx.DB,
+	dbDriver string,
+	svc *service.Impl,
+	pruner *retention.Pruner,
+) error {
+	switch name {
+	case "prune":
+		return runPrune(ctx, args, pruner)
+	case "reparse":
+		return runReparse(ctx, args, svc)
+	case "backfill":
+		return runBackfill(ctx, args, db, dbDriver)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// runPrune deletes responses that are not worth keeping anymore, same as it's done periodically
+func runPrune(ctx context.Context, args []string, pruner *retention.Pruner) error {
+	flags := flag.NewFlagSet("prune", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only report how many responses would be deleted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := pruner.Prune(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "deleted"
+	if *dryRun {
+		verb = "would delete"
+	}
+	fmt.Printf(
+		"%s %d responses: %d expired, %d of delivered parcels, %d of history\n",
+		verb, report.Total(), report.Expired, report.Delivered, report.History,
+	)
+	return nil
+}
+
+// runReparse parses stored responses again, e.g. after parser was fixed
+func runReparse(ctx context.Context, args []string, svc *service.Impl) error {
+	flags := flag.NewFlagSet("reparse", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only report what would change")
+	batchSize := flags.Int("batch-size", 500, "how many responses to load at once")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("-batch-size should be positive, got %d", *batchSize)
+	}
+
+	reports, err := svc.Reparse(ctx, *batchSize, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		fmt.Printf("%s: %d responses reparsed\n", report.APIName, report.Responses)
+		for change, count := range report.StatusChanges {
+			fmt.Printf("  %s -> %s: %d\n", change.From, change.To, count)
+		}
+		fmt.Printf("  final status changed: %d\n", report.FinalChanges)
+		for code, count := range report.UnknownCodes {
+			fmt.Printf("  unknown code %q: %d\n", code, count)
+		}
+	}
+	if *dryRun {
+		fmt.Println("dry run, nothing was updated")
+	}
+	return nil
+}
+
+// runBackfill hashes and compresses sqlite responses stored before response_hash was introduced.
+// It is meant to be run once, after applying migrations; postgres migration backfills hashes by itself
+func runBackfill(ctx context.Context, args []string, db *sqlx.DB, dbDriver string) error {
+	flags := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	batchSize := flags.Int("batch-size", 500, "how many responses to update in a single transaction")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("-batch-size should be positive, got %d", *batchSize)
+	}
+	if dbDriver != "sqlite3" {
+		return fmt.Errorf("backfill is only needed for sqlite, got %s", dbDriver)
+	}
+
+	updated, err := sqlite_storage.BackfillResponseHashes(ctx, db, *batchSize)
+	if err != nil {
+		return fmt.Errorf("backfilled %d responses before failing: %w", updated, err)
+	}
+	// space freed by compression is only returned to OS after VACUUM
+	fmt.Printf("backfilled %d responses, consider running VACUUM\n", updated)
+	return nil
+}
@@ -1,27 +1,56 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"github.com/dir01/parcels/metrics"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/dir01/parcels/circuitbreaker"
 	"github.com/dir01/parcels/externalapis/cainiao"
+	"github.com/dir01/parcels/externalapis/usps"
 	"github.com/dir01/parcels/parcels_api"
+	"github.com/dir01/parcels/postgres_storage"
+	"github.com/dir01/parcels/retention"
+	"github.com/dir01/parcels/scheduler"
 	"github.com/dir01/parcels/service"
 	"github.com/dir01/parcels/sqlite_storage"
+	"github.com/dir01/parcels/subscriptions"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
 
 func main() {
 	// region parameters
-	dbPath := os.Getenv("DB_PATH")
+	dbPath := os.Getenv("DB_PATH") // path to sqlite file, or DSN in case of postgres
 	if dbPath == "" {
 		panic("DB_PATH is not set")
 	}
 
+	dbDriver := os.Getenv("DB_DRIVER") // "sqlite3" or "postgres"
+	if dbDriver == "" {
+		dbDriver = "sqlite3"
+	}
+
+	// credentials of an app registered at https://developers.usps.com, USPS is not asked if they're not set
+	uspsClientID := os.Getenv("USPS_CLIENT_ID")
+	uspsClientSecret := os.Getenv("USPS_CLIENT_SECRET")
+
+	// token admins put in "Authorization: Bearer <token>" header to use /admin/ routes, which are disabled if it's not set
+	adminToken := os.Getenv("ADMIN_TOKEN")
+
+	// With several replicas sharing postgres, refreshes and pruning should only run on one of them:
+	// running them everywhere is safe, but every replica would hit carriers for the same parcels.
+	// Set SINGLETON_JOBS=false on the rest. Webhooks are claimed by whichever replica polls first,
+	// so dispatcher runs everywhere, and so do transit times and unknown codes refreshes, since they are kept in memory
+	runSingletonJobs := os.Getenv("SINGLETON_JOBS") != "false"
+
 	bindAddr := "0.0.0.0:0"
 	if bindAddrEnv := os.Getenv("BIND_ADDR"); bindAddrEnv != "" {
 		bindAddr = bindAddrEnv
@@ -34,20 +63,85 @@ func main() {
 	// expiryTimeout is the time after which a parcel is treated as if we never heard of it
 	// this is due to the fact that sometimes tracking numbers can be reused
 	expiryTimeout := 6 * 30 * 24 * time.Hour
+	// rateLimitCooldown is how long to leave an API alone after it rate limited us without saying for how long
+	rateLimitCooldown := 15 * time.Minute
+	circuitFailureThreshold := 5       // how many consecutive API failures make us consider it down
+	circuitCooldown := 1 * time.Minute // how long to consider API down before trying it again
+
+	refreshTickInterval := 5 * time.Minute // how often to look for parcels due for a background refresh
+	refreshBatchSize := 50                 // how many parcels to refresh at once
+	refreshMaxPerTick := 1000              // how many parcels to refresh at most per tick
+
+	webhookPollInterval := 10 * time.Second // how often to look for webhook deliveries to send
+	webhookBaseBackoff := 1 * time.Minute   // how long to wait before retrying a failed delivery, doubles every attempt
+	webhookMaxAttempts := 10                // after this many failed attempts delivery is considered dead
+	webhookTimeout := 10 * time.Second      // how long to wait for subscriber to respond
+
+	pruneInterval := 24 * time.Hour // how often to delete responses that are not worth keeping anymore
+	retentionPolicy := retention.Policy{
+		KeepHistoryFor:        90 * 24 * time.Hour, // every distinct response is kept for that long
+		CompactDeliveredAfter: 30 * 24 * time.Hour, // then only first and latest responses of delivered parcels are kept
+		ExpireAfter:           expiryTimeout,       // then parcel is forgotten completely
+	}
+
+	// how often to learn from delivered parcels how long transit takes, to estimate ETA when carrier doesn't tell one
+	transitTimesRefreshInterval := 24 * time.Hour
+	// how often to look through stored responses for codes parsers don't know, for /admin/unknownCodes to list
+	unknownCodesRefreshInterval := 6 * time.Hour
+
+	shutdownTimeout := 30 * time.Second // how long to wait for in-flight requests on shutdown
 	// endregion
 
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	logger, err := zap.NewProduction()
 	if err != nil {
 		panic(err)
 	}
 
-	db := sqlx.MustOpen("sqlite3", dbPath)
-	storage := sqlite_storage.NewStorage(db)
+	db := sqlx.MustOpen(dbDriver, dbPath)
+	var storage service.Storage
+	var subscriptionsStorage subscriptions.Storage
+	var retentionStorage retention.Storage
+	switch dbDriver {
+	case "sqlite3":
+		storage = sqlite_storage.NewStorage(db)
+		subscriptionsStorage = sqlite_storage.NewSubscriptionsStorage(db)
+		retentionStorage = sqlite_storage.NewRetentionStorage(db)
+	case "postgres":
+		storage = postgres_storage.NewStorage(db)
+		subscriptionsStorage = postgres_storage.NewSubscriptionsStorage(db)
+		retentionStorage = postgres_storage.NewRetentionStorage(db)
+	default:
+		panic("unsupported DB_DRIVER: " + dbDriver)
+	}
 
 	promMetrics := metrics.NewPrometheus()
 
 	apiMap := map[service.APIName]service.PostalAPI{
-		cainiao.APIName: cainiao.New(),
+		cainiao.APIName: circuitbreaker.New(
+			cainiao.New(),
+			cainiao.APIName,
+			promMetrics,
+			circuitFailureThreshold,
+			circuitCooldown,
+			logger,
+			time.Now,
+		),
+	}
+	if uspsClientID != "" && uspsClientSecret != "" {
+		apiMap[usps.APIName] = circuitbreaker.New(
+			usps.New(uspsClientID, uspsClientSecret),
+			usps.APIName,
+			promMetrics,
+			circuitFailureThreshold,
+			circuitCooldown,
+			logger,
+			time.Now,
+		)
+	} else {
+		logger.Info("USPS credentials are not set, USPS won't be asked")
 	}
 
 	svc := service.NewService(
@@ -59,16 +153,109 @@ func main() {
 		unknownErrorCheckInterval,
 		apiFetchTimeout,
 		expiryTimeout,
+		rateLimitCooldown,
+		logger,
+		time.Now,
+	)
+
+	subscriptionsSvc := subscriptions.NewService(
+		subscriptionsStorage,
+		promMetrics,
+		subscriptions.NewWebhookClient(webhookTimeout),
+		webhookPollInterval,
+		webhookBaseBackoff,
+		webhookMaxAttempts,
+		logger,
+		time.Now,
+	)
+	svc.AddChangeListener(subscriptionsSvc)
+
+	refreshScheduler := scheduler.New(
+		svc,
+		promMetrics,
+		refreshTickInterval,
+		refreshBatchSize,
+		refreshMaxPerTick,
+		logger,
+		time.Now,
+	)
+
+	pruner := retention.New(
+		retentionStorage,
+		promMetrics,
+		retentionPolicy,
+		pruneInterval,
 		logger,
 		time.Now,
 	)
 
-	httpServer := parcels_api.NewServer(svc, logger)
+	// one-off commands, e.g. `service prune -dry-run`, `service reparse` or `service backfill`, do their job and exit instead of serving
+	if len(os.Args) > 1 {
+		if err := runCommand(ctx, os.Args[1], os.Args[2:], db, dbDriver, svc, pruner); err != nil {
+			logger.Fatal("command failed", zap.String("command", os.Args[1]), zap.Error(err))
+		}
+		return
+	}
+
+	wg := sync.WaitGroup{}
+	if runSingletonJobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			refreshScheduler.Run(ctx)
+		}()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pruner.Run(ctx)
+		}()
+	} else {
+		logger.Info("SINGLETON_JOBS is false, refreshes and pruning are left to another replica")
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		subscriptionsSvc.RunDispatcher(ctx)
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		svc.RunTransitTimesRefresh(ctx, transitTimesRefreshInterval)
+	}()
+	if adminToken != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			svc.RunUnknownCodesRefresh(ctx, unknownCodesRefreshInterval)
+		}()
+	} else {
+		logger.Info("ADMIN_TOKEN is not set, admin routes are disabled")
+	}
+
+	httpServer := parcels_api.NewServer(svc, subscriptionsSvc, adminToken, logger)
 	listener, err := net.Listen("tcp", bindAddr)
 	if err != nil {
 		panic(err)
 	}
+	server := &http.Server{Handler: httpServer.GetMux()}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		logger.Info("shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("failed to shutdown http server", zap.Error(err))
+		}
+	}()
+
 	logger.Info("listening", zap.String("addr", listener.Addr().String()))
-	err = http.Serve(listener, httpServer.GetMux())
-	logger.Info("svc terminated", zap.Error(err))
+	err = server.Serve(listener)
+	if !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("http server failed", zap.Error(err))
+		stop()
+	}
+	wg.Wait()
+	logger.Info("svc terminated")
 }
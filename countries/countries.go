@@ -0,0 +1,140 @@
+// Package countries turns whatever carriers call a country into ISO 3166-1 alpha-2 code:
+// carriers use names, codes and localized strings, each carrier its own way.
+package countries
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// aliases are names carriers and people use that are not in ISO database, since it prefers formal names
+var aliases = map[string]string{
+	"Mainland China":         "CN",
+	"PRC":                    "CN",
+	"中国大陆":                   "CN",
+	"Hong Kong SAR":          "HK",
+	"香港":                     "HK",
+	"Macau":                  "MO",
+	"Taiwan":                 "TW",
+	"台湾":                     "TW",
+	"臺灣":                     "TW",
+	"USA":                    "US",
+	"America":                "US",
+	"США":                    "US",
+	"Америка":                "US",
+	"ארה\"ב":                 "US",
+	"美國":                     "US",
+	"UK":                     "GB",
+	"Great Britain":          "GB",
+	"Britain":                "GB",
+	"England":                "GB",
+	"Великобритания":         "GB",
+	"Англия":                 "GB",
+	"בריטניה":                "GB",
+	"אנגליה":                 "GB",
+	"英國":                     "GB",
+	"Russia":                 "RU",
+	"Россия":                 "RU",
+	"РФ":                     "RU",
+	"רוסיה":                  "RU",
+	"俄羅斯":                    "RU",
+	"Korea":                  "KR",
+	"Корея":                  "KR",
+	"Belarus":                "BY",
+	"Белоруссия":             "BY",
+	"Беларусь":               "BY",
+	"Moldova":                "MD",
+	"Молдавия":               "MD",
+	"Киргизия":               "KG",
+	"Czech Republic":         "CZ",
+	"Czechia":                "CZ",
+	"Чехия":                  "CZ",
+	"Turkey":                 "TR",
+	"Турция":                 "TR",
+	"Holland":                "NL",
+	"Netherlands":            "NL",
+	"UAE":                    "AE",
+	"Emirates":               "AE",
+	"ОАЭ":                    "AE",
+	"Vietnam":                "VN",
+	"Вьетнам":                "VN",
+	"Iran":                   "IR",
+	"Syria":                  "SY",
+	"Laos":                   "LA",
+	"Bolivia":                "BO",
+	"Venezuela":              "VE",
+	"Tanzania":               "TZ",
+	"Ivory Coast":            "CI",
+	"Cape Verde":             "CV",
+	"Swaziland":              "SZ",
+	"Macedonia":              "MK",
+	"Burma":                  "MM",
+	"Vatican":                "VA",
+	"Palestine":              "PS",
+	"North Korea":            "KP",
+	"Северная Корея":         "KP",
+	"Republic of Ireland":    "IE",
+	"Brunei":                 "BN",
+	"Micronesia":             "FM",
+	"Saint Martin":           "MF",
+	"Sint Maarten":           "SX",
+	"Falkland Islands":       "FK",
+	"Virgin Islands":         "VI",
+	"British Virgin Islands": "VG",
+}
+
+// index maps normalized names, aliases and codes to alpha-2 codes
+var index = buildIndex()
+
+func buildIndex() map[string]string {
+	result := map[string]string{}
+
+	// names of different countries may collide once normalized, in which case name tells nothing
+	ambiguous := map[string]bool{}
+	codes := make([]string, 0, len(isoNames))
+	for code := range isoNames {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		for _, name := range isoNames[code] {
+			key := normalizeKey(name)
+			if existing, exists := result[key]; exists && existing != code {
+				ambiguous[key] = true
+			}
+			result[key] = code
+		}
+	}
+	for key := range ambiguous {
+		delete(result, key)
+	}
+
+	for alias, code := range aliases {
+		result[normalizeKey(alias)] = code
+	}
+	for _, code := range codes {
+		result[normalizeKey(code)] = code
+	}
+	return result
+}
+
+// Normalize returns ISO 3166-1 alpha-2 code of the country, given its code (alpha-2 or alpha-3),
+// name or a common alias, in English, Russian, Hebrew or Chinese.
+// Returns false if country is not familiar.
+func Normalize(raw string) (string, bool) {
+	code, ok := index[normalizeKey(raw)]
+	return code, ok
+}
+
+// normalizeKey makes spelling variations of the same name equal:
+// case, punctuation and extra whitespace don't matter, neither does leading "the"
+func normalizeKey(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > 1 && words[0] == "the" {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,46 @@
+package countries_test
+
+import (
+	"testing"
+
+	"github.com/dir01/parcels/countries"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"IL":                       "IL",
+		"il":                       "IL",
+		"ISR":                      "IL",
+		"Israel":                   "IL",
+		"  israel ":                "IL",
+		"Израиль":                  "IL",
+		"ישראל":                    "IL",
+		"以色列":                      "IL",
+		"Mainland China":           "CN",
+		"China":                    "CN",
+		"中国":                       "CN",
+		"Russian Federation":       "RU",
+		"Russia":                   "RU",
+		"Россия":                   "RU",
+		"United States of America": "US",
+		"USA":                      "US",
+		"ארה\"ב":                   "US",
+		"UK":                       "GB",
+		"Korea, Republic of":       "KR",
+		"South Korea":              "KR",
+		"The Netherlands":          "NL",
+		"Viet Nam":                 "VN",
+		"Vietnam":                  "VN",
+	}
+	for raw, expected := range cases {
+		if code, ok := countries.Normalize(raw); !ok || code != expected {
+			t.Fatalf("expected %q to be %s, got %q, %v", raw, expected, code, ok)
+		}
+	}
+
+	for _, raw := range []string{"", "Atlantis", "XX"} {
+		if code, ok := countries.Normalize(raw); ok {
+			t.Fatalf("expected %q to be unfamiliar, got %s", raw, code)
+		}
+	}
+}
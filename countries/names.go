@@ -0,0 +1,255 @@
+package countries
+
+// isoNames are ISO 3166-1 alpha-3 codes, English names and their Russian, Hebrew and Chinese translations
+// of every country, keyed by alpha-2 code. They are taken from iso-codes database (https://salsa.debian.org/iso-codes-team/iso-codes)
+var isoNames = map[string][]string{
+	"AD": {"AND", "Andorra", "Principality of Andorra", "Андорра", "אנדורה", "安道尔", "Княжество Андорра", "נסיכות אנדורה", "安道尔公国"},
+	"AE": {"ARE", "United Arab Emirates", "Объединённые Арабские Эмираты", "איחוד האמירויות הערביות", "阿联酋"},
+	"AF": {"AFG", "Afghanistan", "Islamic Republic of Afghanistan", "Афганистан", "אפגניסטן", "阿富汗", "Исламская Республика Афганистан", "הרפובליקה האיסלמית של אפגניסטן", "阿富汗伊斯兰共和国"},
+	"AG": {"ATG", "Antigua and Barbuda", "Антигуа и Барбуда", "אנטיגואה וברבודה", "安提瓜和巴布达"},
+	"AI": {"AIA", "Anguilla", "Ангвилла", "אנגווילה", "安圭拉"},
+	"AL": {"ALB", "Albania", "Republic of Albania", "Албания", "אלבניה", "阿尔巴尼亚", "Республика Албания", "הרפובליקה של אלבניה", "阿尔巴尼亚共和国"},
+	"AM": {"ARM", "Armenia", "Republic of Armenia", "Армения", "ארמניה", "亚美尼亚", "Республика Армения", "הרפובליקה של ארמניה", "亚美尼亚共和国"},
+	"AO": {"AGO", "Angola", "Republic of Angola", "Ангола", "אנגולה", "安哥拉", "Республика Ангола", "הרפובליקה של אנגולה", "安哥拉共和国"},
+	"AQ": {"ATA", "Antarctica", "Антарктика", "אנטרקטיקה", "南极洲"},
+	"AR": {"ARG", "Argentina", "Argentine Republic", "Аргентина", "ארגנטינה", "阿根廷", "Аргентинская Республика", "הרפובליקה הארגנטינאית", "阿根廷共和国"},
+	"AS": {"ASM", "American Samoa", "Американские Самоа", "סמואה האמריקנית", "美属萨摩亚"},
+	"AT": {"AUT", "Austria", "Republic of Austria", "Австрия", "אוסטריה", "奥地利", "Австрийская Республика", "הרפובליקה של אוסטריה", "奥地利共和国"},
+	"AU": {"AUS", "Australia", "Австралия", "אוסטרליה", "澳大利亚"},
+	"AW": {"ABW", "Aruba", "Аруба", "ארובה", "阿鲁巴"},
+	"AX": {"ALA", "Åland Islands", "Аландские острова", "אולנד", "奥兰群岛"},
+	"AZ": {"AZE", "Azerbaijan", "Republic of Azerbaijan", "Азербайджан", "אזרבייג׳ן", "阿塞拜疆", "Республика Азербайджан", "הרפובליקה של אזרביג'ן", "阿塞拜疆共和国"},
+	"BA": {"BIH", "Bosnia and Herzegovina", "Republic of Bosnia and Herzegovina", "Босния и Герцеговина", "בוסניה והרצגובינה", "波斯尼亚和黑塞哥维那", "Республика Босния и Герцеговина", "הרפובליקה של בוסניה והרצגובינה", "波斯尼亚和黑塞哥维那共和国"},
+	"BB": {"BRB", "Barbados", "Барбадос", "ברבדוס", "巴巴多斯"},
+	"BD": {"BGD", "Bangladesh", "People's Republic of Bangladesh", "Бангладеш", "בנגלדש", "孟加拉", "Народная Республика Бангладеш", "רפובליקת העם של בנגלדש", "孟加拉人民共和国"},
+	"BE": {"BEL", "Belgium", "Kingdom of Belgium", "Бельгия", "בלגיה", "比利时", "Королевство Бельгия", "ממלכת בלגיה", "比利时王国"},
+	"BF": {"BFA", "Burkina Faso", "Буркина-Фасо", "בורקינה פאסו", "布基纳法索"},
+	"BG": {"BGR", "Bulgaria", "Republic of Bulgaria", "Болгария", "בולגריה", "保加利亚", "Республика Болгария", "הרפובליקה של בוגלריה", "保加利亚共和国"},
+	"BH": {"BHR", "Bahrain", "Kingdom of Bahrain", "Бахрейн", "בחריין", "巴林", "Королевство Бахрейн", "ממלכת בחריין", "巴林王国"},
+	"BI": {"BDI", "Burundi", "Republic of Burundi", "Бурунди", "בורונדי", "布隆迪", "Республика Бурунди", "הרפובליקה של בורונדי", "布隆迪共和国"},
+	"BJ": {"BEN", "Benin", "Republic of Benin", "Бенин", "בנין", "贝宁", "Республика Бенин", "הרפובליקה של בנין", "贝宁共和国"},
+	"BL": {"BLM", "Saint Barthélemy", "Сен-Бартельми", "סנט ברתלמי", "圣巴泰勒米岛"},
+	"BM": {"BMU", "Bermuda", "Бермуды", "ברמודה", "百慕大"},
+	"BN": {"BRN", "Brunei Darussalam", "Бруней Даруссалам", "ברונאי דרוסלאלם", "文莱"},
+	"BO": {"BOL", "Bolivia, Plurinational State of", "Plurinational State of Bolivia", "Bolivia", "Боливия", "בוליביה, המדינה הרב לאומית של", "玻利维亚共和国", "Многонациональное Государство Боливия", "המדינה הרב לאומית של בוליביה", "בוליביה", "波利维亚"},
+	"BQ": {"BES", "Bonaire, Sint Eustatius and Saba", "Бонайре, Синт-Эстатиус и Саба", "בונייר, סנט אוסטתיוס וסאבא", "博奈尔、圣尤斯特歇斯岛和萨巴"},
+	"BR": {"BRA", "Brazil", "Federative Republic of Brazil", "Бразилия", "ברזיל", "巴西", "Федеративная Республика Бразилия", "הרפובליקה הפדרטיבית של ברזיל", "巴西联邦共和国"},
+	"BS": {"BHS", "Bahamas", "Commonwealth of the Bahamas", "Багамы", "בהמאס", "巴哈马", "Содружество Багамских Островов", "בהאמס", "巴哈马国"},
+	"BT": {"BTN", "Bhutan", "Kingdom of Bhutan", "Бутан", "בהוטן", "不丹", "Королевство Бутан", "ממכלת בהוטן", "不丹王国"},
+	"BV": {"BVT", "Bouvet Island", "Остров Буве", "בובה", "布维群岛"},
+	"BW": {"BWA", "Botswana", "Republic of Botswana", "Ботсвана", "בוטסואנה", "博兹瓦那", "Республика Ботсвана", "הרפובליקה של בוטסוואנה", "博兹瓦那共和国"},
+	"BY": {"BLR", "Belarus", "Republic of Belarus", "Беларусь", "בלארוס", "白俄罗斯", "Республика Беларусь", "הרפובליקה של בלרוס", "白俄罗斯共和国"},
+	"BZ": {"BLZ", "Belize", "Белиз", "בליז", "伯利兹"},
+	"CA": {"CAN", "Canada", "Канада", "קנדה", "加拿大"},
+	"CC": {"CCK", "Cocos (Keeling) Islands", "Кокосовые острова", "איי קוקוס", "科科斯群岛"},
+	"CD": {"COD", "Congo, The Democratic Republic of the", "Демократическая Республика Конго", "קונגו, הרפובליקה הדמוקרטית של", "刚果民主共和国"},
+	"CF": {"CAF", "Central African Republic", "Центрально-африканская республика", "הרפובליקה המרכז־אפריקאית", "中非"},
+	"CG": {"COG", "Congo", "Republic of the Congo", "Конго", "קונגו", "刚果", "Республика Конго", "הרפובליקה של קונגו", "刚果共和国"},
+	"CH": {"CHE", "Switzerland", "Swiss Confederation", "Швейцария", "שווייץ", "瑞士", "Швейцарская Конфедерация", "הקונפדרציה השווצרית", "瑞士联邦"},
+	"CI": {"CIV", "Côte d'Ivoire", "Republic of Côte d'Ivoire", "Кот-д'Ивуар", "חוף השנהב", "科特迪瓦", "Республика Кот-д'Ивуар", "רפובליקת חוף השנהב", "科特迪瓦共和国"},
+	"CK": {"COK", "Cook Islands", "Острова Кука", "איי קוק", "库克群岛"},
+	"CL": {"CHL", "Chile", "Republic of Chile", "Чили", "צ'ילה", "智利", "Республика Чили", "הרפובליקה של צ'ילה", "智利共和国"},
+	"CM": {"CMR", "Cameroon", "Republic of Cameroon", "Камерун", "קמרון", "喀麦隆", "Республика Камерун", "הרפובליקה של קמרון", "喀麦隆共和国"},
+	"CN": {"CHN", "China", "People's Republic of China", "Китай", "סין", "中国", "Китайская Народная Республика", "הרפובליקה העממית של סין", "中华人民共和国"},
+	"CO": {"COL", "Colombia", "Republic of Colombia", "Колумбия", "קולומביה", "哥伦比亚", "Республика Колумбия", "הרפובליקה של קולומביה", "哥伦比亚共和国"},
+	"CR": {"CRI", "Costa Rica", "Republic of Costa Rica", "Коста-Рика", "קוסטה ריקה", "哥斯达黎加", "Республика Коста-Рика", "הרפובליקה של קוסטה ריקה", "哥斯达黎加共和国"},
+	"CU": {"CUB", "Cuba", "Republic of Cuba", "Куба", "קובה", "古巴", "Республика Куба", "הרפובליקה של קובה", "古巴共和国"},
+	"CV": {"CPV", "Cabo Verde", "Republic of Cabo Verde", "Кабо-Верде", "קאבו ורדה", "佛得角", "Республика Кабо-Верде", "הרפובליקה של קאבו ורדה", "佛得角共和国"},
+	"CW": {"CUW", "Curaçao", "Кюрасао", "קוראסאו", "库拉索"},
+	"CX": {"CXR", "Christmas Island", "Остров Рождества", "איי חג המולד", "圣诞岛"},
+	"CY": {"CYP", "Cyprus", "Republic of Cyprus", "Кипр", "קפריסין", "塞浦路斯", "Республика Кипр", "הרפובליקה של קפריסין", "塞浦路斯共和国"},
+	"CZ": {"CZE", "Czechia", "Czech Republic", "Чехия", "צ'כיה", "捷克", "Чешская Республика", "צ׳כיה"},
+	"DE": {"DEU", "Germany", "Federal Republic of Germany", "Германия", "גרמניה", "德国", "Федеративная Республика Германия", "德意志联邦共和国"},
+	"DJ": {"DJI", "Djibouti", "Republic of Djibouti", "Джибути", "ג׳יבוטי", "吉布提", "Республика Джибути", "הרפובליקה של ג׳יבוטי", "吉布提共和国"},
+	"DK": {"DNK", "Denmark", "Kingdom of Denmark", "Дания", "דנמרק", "丹麦", "Королевство Дания", "הממלכה של דנמרק", "丹麦王国"},
+	"DM": {"DMA", "Dominica", "Commonwealth of Dominica", "Доминика", "דומיניקה", "多米尼克", "Содружество Доминики", "米尼克共和国"},
+	"DO": {"DOM", "Dominican Republic", "Доминиканская республика", "הרפובליקה הדומיניקנית", "多米尼加共和国"},
+	"DZ": {"DZA", "Algeria", "People's Democratic Republic of Algeria", "Алжир", "אלג'יריה", "阿尔及利亚", "Алжирская Народная Демократическая Республика", "הרפובליקה האלג'יראית הדמוקרטית העממית", "阿尔及利亚人民民主共和国"},
+	"EC": {"ECU", "Ecuador", "Republic of Ecuador", "Эквадор", "אקוודור", "厄瓜多尔", "Республика Эквадор", "הרפובליקה של אקוודור", "厄瓜多尔共和国"},
+	"EE": {"EST", "Estonia", "Republic of Estonia", "Эстония", "אסטוניה", "爱沙尼亚", "Эстонская Республика", "הרפובליקה של אסטוניה", "爱沙尼亚共和国"},
+	"EG": {"EGY", "Egypt", "Arab Republic of Egypt", "Египет", "מצרים", "埃及", "Арабская Республика Египет", "阿拉伯埃及共和国"},
+	"EH": {"ESH", "Western Sahara", "Западная Сахара", "סהרה המערבית", "西撒哈拉"},
+	"ER": {"ERI", "Eritrea", "the State of Eritrea", "Эритрея", "אריתריאה", "厄立特里亚", "Государство Эритрея", "מדינת אריתריאה", "厄立特里亚国"},
+	"ES": {"ESP", "Spain", "Kingdom of Spain", "Испания", "ספרד", "西班牙", "Королевство Испания", "ממלכת ספרד", "西班牙王国"},
+	"ET": {"ETH", "Ethiopia", "Federal Democratic Republic of Ethiopia", "Эфиопия", "אתיופיה", "埃塞俄比亚", "Федеративная Демократическая Республика Эфиопия", "הרפובליקה הפדרלית הדמוקרטית של אתיופיה", "埃塞俄比亚联邦民主共和国"},
+	"FI": {"FIN", "Finland", "Republic of Finland", "Финляндия", "פינלנד", "芬兰", "Финляндская Республика", "הרפובליקה של פינלנד", "芬兰共和国"},
+	"FJ": {"FJI", "Fiji", "Republic of Fiji", "Фиджи", "פיג'י", "斐济", "Республика Фиджи", "הרפובליקה של פיג'י", "斐济共和国"},
+	"FK": {"FLK", "Falkland Islands (Malvinas)", "Фолклендские (Мальвинские) острова", "איי פוקלנד", "福克兰群岛(马尔维纳斯)"},
+	"FM": {"FSM", "Micronesia, Federated States of", "Federated States of Micronesia", "Федеративные Штаты Микронезии", "מיקרונזיה", "密克罗尼西亚", "密克罗尼西亚联邦"},
+	"FO": {"FRO", "Faroe Islands", "Фарерские острова", "איי פארו", "法罗群岛"},
+	"FR": {"FRA", "France", "French Republic", "Франция", "צרפת", "法国", "Французская Республика", "法兰西共和国"},
+	"GA": {"GAB", "Gabon", "Gabonese Republic", "Габон", "גבון", "加蓬", "Габонская Республика", "הרפובליקה הגבונית", "加蓬共和国"},
+	"GB": {"GBR", "United Kingdom", "United Kingdom of Great Britain and Northern Ireland", "Соединённое Королевство", "הממלכה המאוחדת", "英国", "Соединённое Королевство Великобритании и Северной Ирландии", "הממלכה המאוחדת של בריטניה הגדולה וצפון אירנלד", "大不列颠及北爱尔兰联合王国"},
+	"GD": {"GRD", "Grenada", "Гренада", "גרנדה", "格林纳达"},
+	"GE": {"GEO", "Georgia", "Грузия", "גאורגיה", "格鲁吉亚"},
+	"GF": {"GUF", "French Guiana", "Французская Гвиана", "גיאנה הצרפתית", "法属圭亚那"},
+	"GG": {"GGY", "Guernsey", "Гернси", "גרנזי", "根西岛"},
+	"GH": {"GHA", "Ghana", "Republic of Ghana", "Гана", "גאנה", "加纳", "Республика Гана", "הרפובליקה של גאנה", "加纳共和国"},
+	"GI": {"GIB", "Gibraltar", "Гибралтар", "גיברלטר", "直布罗陀"},
+	"GL": {"GRL", "Greenland", "Гренландия", "גרינלנד", "格陵兰"},
+	"GM": {"GMB", "Gambia", "Republic of the Gambia", "Гамбия", "גמביה", "冈比亚", "Республика Гамбия", "הרפובליקה של גמביה", "冈比亚共和国"},
+	"GN": {"GIN", "Guinea", "Republic of Guinea", "Гвинея", "גינאה", "几内亚", "Гвинейская Республика", "הרפובליקה של גינאה", "几内亚共和国"},
+	"GP": {"GLP", "Guadeloupe", "Гваделупа", "גוואדלופ", "瓜德罗普"},
+	"GQ": {"GNQ", "Equatorial Guinea", "Republic of Equatorial Guinea", "Экваториальная Гвинея", "גינאה המשוונית", "赤道几内亚", "Республика Экваториальная Гвинея", "הרפובליקה של גינאה המשוונית", "赤道几内亚共和国"},
+	"GR": {"GRC", "Greece", "Hellenic Republic", "Греция", "יוון", "希腊", "Греческая Республика", "希腊共和国"},
+	"GS": {"SGS", "South Georgia and the South Sandwich Islands", "Южная Джорджия и Южные Сандвичевы острова", "איי ג׳ורג׳יה הדרומית ואיי סנדוויץ׳ הדרומיים", "南乔治亚岛和南桑德韦奇岛"},
+	"GT": {"GTM", "Guatemala", "Republic of Guatemala", "Гватемала", "גואטמלה", "瓜地马拉", "Республика Гватемала", "הרפובליקה של גואטמלה", "瓜地马拉共和国"},
+	"GU": {"GUM", "Guam", "Гуам", "גואם", "关岛"},
+	"GW": {"GNB", "Guinea-Bissau", "Republic of Guinea-Bissau", "Гвинея-Бисау", "גינאה ביסאו", "几内亚比绍", "Республика Гвинея-Бисау", "הרפובליקה של גינאה בסאו", "几内亚比绍共和国"},
+	"GY": {"GUY", "Guyana", "Republic of Guyana", "Гайана", "גיאנה", "圭亚那", "Республика Гайана", "הרפובליקה של גויאנה", "圭亚那共和国"},
+	"HK": {"HKG", "Hong Kong", "Hong Kong Special Administrative Region of China", "Гонконг", "הונג קונג", "香港", "Осо́бый административный район Гонконг", "האיזור המנהלי הונג קונג של סין", "中国香港特别行政区"},
+	"HM": {"HMD", "Heard Island and McDonald Islands", "Остров Херд и острова МакДональд", "האי הרד ואיי מקדונלד", "赫德岛与麦克唐纳群岛"},
+	"HN": {"HND", "Honduras", "Republic of Honduras", "Гондурас", "הונדורס", "洪都拉斯", "Республика Гондурас", "הרפובליקה של הונדורס", "洪都拉斯共和国"},
+	"HR": {"HRV", "Croatia", "Republic of Croatia", "Хорватия", "קרואטיה", "克罗地亚", "Республика Хорватия", "הרפובליקה של קרואטיה", "克罗地亚共和国"},
+	"HT": {"HTI", "Haiti", "Republic of Haiti", "Гаити", "האיטי", "海地", "Республика Гаити", "הרפובליקה של האיטי", "海地共和国"},
+	"HU": {"HUN", "Hungary", "Венгрия", "הונגריה", "匈牙利"},
+	"ID": {"IDN", "Indonesia", "Republic of Indonesia", "Индонезия", "אינדונזיה", "印度尼西亚", "Республика Индонезия", "הרפובליקה של אינדונזיה", "印度尼西亚共和国"},
+	"IE": {"IRL", "Ireland", "Ирландия", "אירלנד", "爱尔兰"},
+	"IL": {"ISR", "Israel", "State of Israel", "Израиль", "ישראל", "以色列", "Государство Израиль", "מדינת ישראל", "以色列国"},
+	"IM": {"IMN", "Isle of Man", "Остров Мэн", "האי מאן", "曼岛"},
+	"IN": {"IND", "India", "Republic of India", "Индия", "הודו", "印度", "Республика Индия", "הרפובליקה של הודו", "印度共和国"},
+	"IO": {"IOT", "British Indian Ocean Territory", "Британская территория Индийского океана", "הטריטוריה הבריטית באוקיינוס ההודי", "英属印度洋领地"},
+	"IQ": {"IRQ", "Iraq", "Republic of Iraq", "Ирак", "עיראק", "伊拉克", "Иракская Республика", "הרפובליקה של עירק", "伊拉克共和国"},
+	"IR": {"IRN", "Iran, Islamic Republic of", "Islamic Republic of Iran", "Iran", "Иран", "אירן, הרפובליקה האיסלמית של", "伊朗伊斯兰共和国", "Исламская Респу́блика Иран", "הרפובליקה האיסלמית של אירן", "איראן", "伊朗"},
+	"IS": {"ISL", "Iceland", "Republic of Iceland", "Исландия", "איסלנד", "冰岛", "Республика Исландия", "הרפובליקה של איסלנד", "冰岛共和国"},
+	"IT": {"ITA", "Italy", "Italian Republic", "Италия", "איטליה", "意大利", "Итальянская Республика", "הרפובליקה האיטלקית", "意大利共和国"},
+	"JE": {"JEY", "Jersey", "Джерси", "ג'רזי", "泽西岛"},
+	"JM": {"JAM", "Jamaica", "Ямайка", "ג'מייקה", "牙买加"},
+	"JO": {"JOR", "Jordan", "Hashemite Kingdom of Jordan", "Иордания", "ירדן", "约旦", "Иорданское Хашимитское Королевство", "הממלכה ההאשמית של ירדן", "约旦哈希姆王国"},
+	"JP": {"JPN", "Japan", "Япония", "יפן", "日本"},
+	"KE": {"KEN", "Kenya", "Republic of Kenya", "Кения", "קניה", "肯尼亚", "Республика Кения", "הרפובליקה של קניה", "肯尼亚共和国"},
+	"KG": {"KGZ", "Kyrgyzstan", "Kyrgyz Republic", "Киргизия", "קירגיזסטן", "吉尔吉斯坦", "Республика Кыргызстан", "הרפובליקה הקירג'יזית", "吉尔吉斯共和国"},
+	"KH": {"KHM", "Cambodia", "Kingdom of Cambodia", "Камбоджа", "קמבודיה", "柬埔塞", "Королевство Камбоджа", "הממלכה של קמבודיה", "柬埔塞王国"},
+	"KI": {"KIR", "Kiribati", "Republic of Kiribati", "Кирибати", "קיריבטי", "基里巴斯", "Республика Кирибати", "הרפובליקה של קיריבטי", "基里巴斯共和国"},
+	"KM": {"COM", "Comoros", "Union of the Comoros", "Коморы", "קומורו", "科摩罗", "Союз Коморских Островов", "האיחוד של קומורוס", "科摩罗联邦"},
+	"KN": {"KNA", "Saint Kitts and Nevis", "Сент-Китс и Невис", "סנט קיטס ונוויס", "圣基茨和尼维斯"},
+	"KP": {"PRK", "Korea, Democratic People's Republic of", "Democratic People's Republic of Korea", "North Korea", "Корейская Народно-Демократическая Республика", "קוריאה, דמוקרטיית העם של", "朝鲜民主主义人民共和国", "הרפובליקה הדמוקרטית של העם של קוריאה", "Северная Корея", "קוריאה הצפונית", "朝鲜"},
+	"KR": {"KOR", "Korea, Republic of", "South Korea", "Республика Корея", "קוריאה, הרפובליקה של", "大韩民国", "Южная Корея", "קוריאה הדרומית", "韩国"},
+	"KW": {"KWT", "Kuwait", "State of Kuwait", "Кувейт", "כווית", "科威特", "Государство Кувейт", "מדינת כווית", "科威特国"},
+	"KY": {"CYM", "Cayman Islands", "Каймановы острова", "איי קיימן", "开曼群岛"},
+	"KZ": {"KAZ", "Kazakhstan", "Republic of Kazakhstan", "Казахстан", "קזחסטן", "哈萨克斯坦", "Республика Казахстан", "הרפובליקה של קזחסטאן", "哈萨克斯坦共和国"},
+	"LA": {"LAO", "Lao People's Democratic Republic", "Laos", "Лаосская Народно-Демократическая Республика", "לאוס", "老挝人民民主共和国", "老挝"},
+	"LB": {"LBN", "Lebanon", "Lebanese Republic", "Ливан", "לבנון", "黎巴嫩", "Ливанская Республика", "הרפובליקה הלבנונית", "黎巴嫩共和国"},
+	"LC": {"LCA", "Saint Lucia", "Сент-Люсия", "סנט לוסיה", "圣路西亚"},
+	"LI": {"LIE", "Liechtenstein", "Principality of Liechtenstein", "Лихтенштейн", "ליכטנשטיין", "列支敦士登", "Княжество Лихтенштейн", "נסיכות ליכטנשטיין", "列支敦士登公国"},
+	"LK": {"LKA", "Sri Lanka", "Democratic Socialist Republic of Sri Lanka", "Шри-Ланка", "סרי לנקה", "斯里兰卡", "Демократическая Социалистическая Республика Шри-Ланка", "הרפובליקה הסוציאל דמוקרטית של סרי לאנקה", "斯里兰卡民主社会主义共和国"},
+	"LR": {"LBR", "Liberia", "Republic of Liberia", "Либерия", "ליבריה", "利比里亚", "Республика Либерия", "הרפובליקה של ליבריה", "利比里亚共和国"},
+	"LS": {"LSO", "Lesotho", "Kingdom of Lesotho", "Лесото", "לסוטו", "莱索托", "Королевство Лесото", "ממלכת לסותו", "莱索托王国"},
+	"LT": {"LTU", "Lithuania", "Republic of Lithuania", "Литва", "ליטא", "立陶宛", "Литовская Республика", "הרפובליקה של ליטואניה", "立陶宛共和国"},
+	"LU": {"LUX", "Luxembourg", "Grand Duchy of Luxembourg", "Люксембург", "לוקסמבורג", "卢森堡", "Великое Герцогство Люксембург", "הדוקסות של לוקסנבורג", "卢森堡大公国"},
+	"LV": {"LVA", "Latvia", "Republic of Latvia", "Латвия", "לטביה", "拉脱维亚", "Латвийская Республика", "הרפובליקה של לטביה", "拉脱维亚共和国"},
+	"LY": {"LBY", "Libya", "Ливия", "לוב", "利比亚"},
+	"MA": {"MAR", "Morocco", "Kingdom of Morocco", "Марокко", "מרוקו", "摩洛哥", "Королевство Марокко", "ממלכת מרוקו", "摩洛哥王国"},
+	"MC": {"MCO", "Monaco", "Principality of Monaco", "Монако", "מונקו", "摩纳哥", "Княжество Монако", "הנסיכות של מונקו", "摩纳哥公国"},
+	"MD": {"MDA", "Moldova, Republic of", "Republic of Moldova", "Moldova", "Республика Молдова", "מולדובה, הרפובליקה של", "摩尔多瓦共和国", "הרפובליקה של מולדובה", "Молдавия", "מולדובה", "摩尔多瓦"},
+	"ME": {"MNE", "Montenegro", "Черногория", "מונטנגרו", "黑山"},
+	"MF": {"MAF", "Saint Martin (French part)", "Сен-Мартен (Франция)", "סן מרטן", "法属圣马丁"},
+	"MG": {"MDG", "Madagascar", "Republic of Madagascar", "Мадагаскар", "מדגסקר", "马达加斯加", "Республика Мадагаскар", "הרפובליקה של מדגסקר", "马达加斯加共和国"},
+	"MH": {"MHL", "Marshall Islands", "Republic of the Marshall Islands", "Маршалловы острова", "איי מרשל", "马绍尔群岛", "Респу́блика Маршалловы Острова", "הרפובליקה של איי מרשל", "马绍尔群岛共和国"},
+	"MK": {"MKD", "North Macedonia", "Republic of North Macedonia", "Северная Македония", "צפון קלדוניה", "北马其顿", "Республика Северная Македония", "הרפובליקה של צפון מקדוניה", "北马其顿共和国"},
+	"ML": {"MLI", "Mali", "Republic of Mali", "Мали", "מאלי", "马里", "Республика Мали", "הרפובליקה של מלי", "马里共和国"},
+	"MM": {"MMR", "Myanmar", "Republic of Myanmar", "Мьянма", "מיאנמר", "缅甸", "Республика Мьянма", "הרפובליקה של מיאנמר", "缅甸联邦共和国"},
+	"MN": {"MNG", "Mongolia", "Монголия", "מונגוליה", "蒙古"},
+	"MO": {"MAC", "Macao", "Macao Special Administrative Region of China", "Макао", "מאקאו", "澳门", "Специальный Административный район Макао", "האיזור הניהולי המיוחד מאקאו של סין", "中国澳门特别行政区"},
+	"MP": {"MNP", "Northern Mariana Islands", "Commonwealth of the Northern Mariana Islands", "Острова северной Марианы", "איי מריאנה הצפוניים", "北马里亚纳群岛", "Содружество Северных Марианских островов", "איי מרינה הצפוניים", "北马里亚纳群岛自由联邦"},
+	"MQ": {"MTQ", "Martinique", "Мартиника", "מרטיניק", "马提尼克"},
+	"MR": {"MRT", "Mauritania", "Islamic Republic of Mauritania", "Мавритания", "מאוריטניה", "毛里塔尼亚", "Исламская Республика Мавритания", "הרפובליקה האיסלמית של מאוריטניה", "毛里塔尼亚伊斯兰共和国"},
+	"MS": {"MSR", "Montserrat", "Монтсеррат", "מונטסראט", "蒙塞拉特岛"},
+	"MT": {"MLT", "Malta", "Republic of Malta", "Мальта", "מלטה", "马尔他", "Республика Мальта", "הרפובליקה של מלטה", "马尔他共和国"},
+	"MU": {"MUS", "Mauritius", "Republic of Mauritius", "Маврикий", "מאוריציוס", "毛里求斯", "Республика Маврикий", "הרפובליקה של מאוריציוס", "毛里求斯共和国"},
+	"MV": {"MDV", "Maldives", "Republic of Maldives", "Мальдивы", "האיים המלדיביים", "马尔代夫", "Мальдивская Республика", "הרפובליקה של מלדויס", "马尔代夫共和国"},
+	"MW": {"MWI", "Malawi", "Republic of Malawi", "Малави", "מלאווי", "马拉维", "Республика Малави", "הרפובליקה של מלאוי", "马拉维共和国"},
+	"MX": {"MEX", "Mexico", "United Mexican States", "Мексика", "מקסיקו", "墨西哥", "Мексиканские Соединённые Штаты", "מדינות מקסיקניות המאוחדות", "墨西哥合众国"},
+	"MY": {"MYS", "Malaysia", "Малайзия", "מלזיה", "马来西亚"},
+	"MZ": {"MOZ", "Mozambique", "Republic of Mozambique", "Мозамбик", "מוזמביק", "莫桑比克", "Республика Мозамбик", "הרפובליקה של מוזמביק", "莫桑比克共和国"},
+	"NA": {"NAM", "Namibia", "Republic of Namibia", "Намибия", "נמיביה", "纳米比亚", "Республика Намибия", "הרפובליקה של נמיביה", "纳米比亚共和国"},
+	"NC": {"NCL", "New Caledonia", "Новая Каледония", "קלדוניה החדשה", "新喀里多尼亚"},
+	"NE": {"NER", "Niger", "Republic of the Niger", "Нигер", "ניז׳ר", "尼日尔", "Республика Нигер", "הרפובליקה של ניז'ר", "尼日尔共和国"},
+	"NF": {"NFK", "Norfolk Island", "Остров Норфолк", "נורפוק", "诺福克岛"},
+	"NG": {"NGA", "Nigeria", "Federal Republic of Nigeria", "Нигерия", "ניגריה", "尼日利亚", "Федеративная Республика Нигерия", "הרפובליקה הפדרלית של ניגריה", "尼日利亚联邦共和国"},
+	"NI": {"NIC", "Nicaragua", "Republic of Nicaragua", "Никарагуа", "ניקרגואה", "尼加拉瓜", "Республика Никарагуа", "הרפובליקה של ניקרגואה", "尼加拉瓜共和国"},
+	"NL": {"NLD", "Netherlands", "Kingdom of the Netherlands", "Нидерланды", "הולנד", "荷兰", "Королевство Нидерландов", "הממלכה של ההולנדים", "荷兰王国"},
+	"NO": {"NOR", "Norway", "Kingdom of Norway", "Норвегия", "נורווגיה", "挪威", "Королевство Норвегия", "הממלכה של נורבגיה", "挪威王国"},
+	"NP": {"NPL", "Nepal", "Federal Democratic Republic of Nepal", "Непал", "נפאל", "尼泊尔", "Федеративная Демократическая Республика Непал", "הרפובליקה הפדרלית דמוקרטית של נפאל", "尼泊尔联邦民主共和国"},
+	"NR": {"NRU", "Nauru", "Republic of Nauru", "Науру", "נאורו", "瑙鲁", "Республика Науру", "הרפובליקה של נאורו", "瑙鲁共和国"},
+	"NU": {"NIU", "Niue", "Ниуэ", "ניואה", "纽埃"},
+	"NZ": {"NZL", "New Zealand", "Новая Зеландия", "ניו זילנד", "新西兰"},
+	"OM": {"OMN", "Oman", "Sultanate of Oman", "Оман", "עומאן", "阿曼", "Султанат Оман", "הסולטנות של עומן", "阿曼苏丹国"},
+	"PA": {"PAN", "Panama", "Republic of Panama", "Панама", "פנמה", "巴拿马", "Республика Панама", "הרפובליקה של פנמה", "巴拿马共和国"},
+	"PE": {"PER", "Peru", "Republic of Peru", "Перу", "פרו", "秘鲁", "Республика Перу", "הרפובליקה של פרו", "秘鲁共和国"},
+	"PF": {"PYF", "French Polynesia", "Французская Полинезия", "פולינזיה הצרפתית", "法属玻利尼西亚"},
+	"PG": {"PNG", "Papua New Guinea", "Independent State of Papua New Guinea", "Папуа — Новая Гвинея", "פפואה גינאה החדשה", "巴布亚新几内亚", "Независимое Государство Папуа — Новая Гвинея", "המדינה העצמאית של פפואה גינאה החדשה", "巴布亚新几内亚独立国"},
+	"PH": {"PHL", "Philippines", "Republic of the Philippines", "Филиппины", "הפיליפינים", "菲律宾", "Республика Филиппины", "הרפובליקה של הפיליפינים", "菲律宾共和国"},
+	"PK": {"PAK", "Pakistan", "Islamic Republic of Pakistan", "Пакистан", "פקיסטן", "巴基斯坦", "Исламская Республика Пакистан", "הרפובליקה האיסלמית של פקיסטן", "巴基斯坦伊斯兰共和国"},
+	"PL": {"POL", "Poland", "Republic of Poland", "Польша", "פולין", "波兰", "Республика Польша", "הרפובליקה של פולין", "波兰共和国"},
+	"PM": {"SPM", "Saint Pierre and Miquelon", "Сен-Пьер и Микелон", "סן פייר ומיקלון", "圣皮埃尔和密克隆"},
+	"PN": {"PCN", "Pitcairn", "Питкэрн", "פיטקרן", "皮特克恩"},
+	"PR": {"PRI", "Puerto Rico", "Пуэрто-Рико", "פוארטו ריקו", "波多黎各"},
+	"PS": {"PSE", "Palestine, State of", "the State of Palestine", "Палестина", "פסלטין, מדינת", "巴勒斯坦", "Государство Палестина", "מדינת פלסטין", "巴勒斯坦国"},
+	"PT": {"PRT", "Portugal", "Portuguese Republic", "Португалия", "פורטוגל", "葡萄牙", "Португальская Республика", "הרפובליקה הפורטוגזית", "葡萄牙共和国"},
+	"PW": {"PLW", "Palau", "Republic of Palau", "Палау", "פלאו", "帕劳", "Республика Палау", "הרפובליקה של פלאו", "帕劳共和国"},
+	"PY": {"PRY", "Paraguay", "Republic of Paraguay", "Парагвай", "פרגוואי", "巴拉圭", "Республика Парагвай", "הרפובליקה של פרגוואי", "巴拉圭共和国"},
+	"QA": {"QAT", "Qatar", "State of Qatar", "Катар", "קטר", "卡塔尔", "Государство Катар", "卡塔尔国"},
+	"RE": {"REU", "Réunion", "Реюньон", "ראוניון", "留尼汪"},
+	"RO": {"ROU", "Romania", "Румыния", "רומניה", "罗马尼亚"},
+	"RS": {"SRB", "Serbia", "Republic of Serbia", "Сербия", "סרביה", "塞尔维亚", "Республика Сербия", "הרפובליקה של סרביה", "塞尔维亚共和国"},
+	"RU": {"RUS", "Russian Federation", "Российская Федерация", "הפדרציה הרוסית", "俄罗斯"},
+	"RW": {"RWA", "Rwanda", "Rwandese Republic", "Руанда", "רואנדה", "卢旺达", "Руандийская Республика", "הרפובליקה הרואנדית", "卢旺达共和国"},
+	"SA": {"SAU", "Saudi Arabia", "Kingdom of Saudi Arabia", "Саудовская Аравия", "ערב הסעודית", "沙特阿拉伯", "Королевство Саудовская Аравия", "הממלכה של ערב הסעודית", "沙特阿拉伯王国"},
+	"SB": {"SLB", "Solomon Islands", "Соломоновы Острова", "איי שלמה", "所罗门群岛"},
+	"SC": {"SYC", "Seychelles", "Republic of Seychelles", "Сейшелы", "סיישל", "塞舌尔", "Республика Сейшельские Острова", "הרפובליקה של איי סיישל", "塞舌尔共和国"},
+	"SD": {"SDN", "Sudan", "Republic of the Sudan", "Судан", "סודאן", "苏丹", "Республика Судан", "הרפובליקה של סודן", "苏丹共和国"},
+	"SE": {"SWE", "Sweden", "Kingdom of Sweden", "Швеция", "שוודיה", "瑞典", "Королевство Швеция", "הממלכה של שבדיה", "瑞典王国"},
+	"SG": {"SGP", "Singapore", "Republic of Singapore", "Сингапур", "סינגפור", "新加坡", "Республика Сингапур", "הרפובליקה של סינגפור", "新加坡共和国"},
+	"SH": {"SHN", "Saint Helena, Ascension and Tristan da Cunha", "Остров Святой Елены, Остров Вознесения и Тристан-да-Кунья", "סנט הלנה, אסנשן וטריסטן דה קונה", "圣赫勒拿-阿森松-特里斯坦达库尼亚"},
+	"SI": {"SVN", "Slovenia", "Republic of Slovenia", "Словения", "סלובניה", "斯洛文尼亚", "Республика Словения", "הרפובליקה של סלובניה", "斯洛文尼亚共和国"},
+	"SJ": {"SJM", "Svalbard and Jan Mayen", "Шпицберген и Ян-Майен", "סוולברד ויאן מאין", "斯瓦尔巴特和扬马延岛"},
+	"SK": {"SVK", "Slovakia", "Slovak Republic", "Словакия", "סלובקיה", "斯洛伐克", "Словацкая Республика", "הרפובליקה הסלובקית", "斯洛伐克共和国"},
+	"SL": {"SLE", "Sierra Leone", "Republic of Sierra Leone", "Сьерра-Леоне", "סיירה לאון", "塞拉利昂", "Республика Сьерра-Леоне", "הרפובליקה של סיירה ליאון", "塞拉利昂共和国"},
+	"SM": {"SMR", "San Marino", "Republic of San Marino", "Сан-Марино", "סן מרינו", "圣马力诺市", "Республика Сан-Марино", "הרפובליקה של סאן מרינו", "圣马力诺共和国"},
+	"SN": {"SEN", "Senegal", "Republic of Senegal", "Сенегал", "סנגל", "塞内加尔", "Республика Сенегал", "הרפובליקה של סנגל", "塞内加尔共和国"},
+	"SO": {"SOM", "Somalia", "Federal Republic of Somalia", "Сомали", "סומליה", "索马里", "Федеративная Республика Сомали", "הרפובליקה הפדרלית של סומליה", "索马里联邦共和国"},
+	"SR": {"SUR", "Suriname", "Republic of Suriname", "Суринам", "סורינאם", "苏里南", "Республика Суринам", "הרפובליקה של סורינאם", "苏里南共和国"},
+	"SS": {"SSD", "South Sudan", "Republic of South Sudan", "Южный Судан", "דרום סודאן", "南苏丹", "Республика Южный Судан", "הרפובליקה של דרום סודן", "南苏丹共和国"},
+	"ST": {"STP", "Sao Tome and Principe", "Democratic Republic of Sao Tome and Principe", "Сан-Томе и Принсипи", "סאו טומה ופרינסיפה", "圣多美和普林西比", "Демократическая Республика Сан-Томе и Принсипи", "הרפובליקה הדמוקרטית של סאו טומה ופרינסיפה", "圣多美和普林西比民主共和国"},
+	"SV": {"SLV", "El Salvador", "Republic of El Salvador", "Сальвадор", "אל סלוודור", "萨尔瓦多", "Республика Эль-Сальвадор", "אל סלבדור", "萨尔瓦多共和国"},
+	"SX": {"SXM", "Sint Maarten (Dutch part)", "Синт-Мартен (голландская часть)", "סנט מארטן (החלק ההולנדי)", "荷属圣马丁"},
+	"SY": {"SYR", "Syrian Arab Republic", "Syria", "Сирийская Арабская Республика", "הרפובליקה הערבית הסורית", "阿拉伯叙利亚共和国", "סוריה", "叙利亚"},
+	"SZ": {"SWZ", "Eswatini", "Kingdom of Eswatini", "Эсватини", "אסווטני", "斯威士兰", "Королевство Эсватини", "ממלכת אסווטיני", "斯威士兰王国"},
+	"TC": {"TCA", "Turks and Caicos Islands", "Острова Туркс и Каикос", "איי טרקס וקייקוס", "特克斯和凯科斯群岛"},
+	"TD": {"TCD", "Chad", "Republic of Chad", "Чад", "צ׳אד", "乍得", "Республика Чад", "הרפובליקה של צ'ד", "乍得共和国"},
+	"TF": {"ATF", "French Southern Territories", "Французские южные территории", "הטריטוריות הדרומיות של צרפת", "法属南半球领地"},
+	"TG": {"TGO", "Togo", "Togolese Republic", "Того", "טוגו", "多哥", "Тоголезская Республика", "הרפובליקה הטוגוליזית", "多哥共和国"},
+	"TH": {"THA", "Thailand", "Kingdom of Thailand", "Таиланд", "תאילנד", "泰国", "Королевство Таиланд", "ממלכת תאילנד", "泰王国"},
+	"TJ": {"TJK", "Tajikistan", "Republic of Tajikistan", "Таджикистан", "טג׳יקיסטן", "塔吉克斯坦", "Республика Таджикистан", "הרפובליקה של טג'יקיסטן", "塔吉克斯坦共和国"},
+	"TK": {"TKL", "Tokelau", "Токелау", "טוקלאו", "托克劳"},
+	"TL": {"TLS", "Timor-Leste", "Democratic Republic of Timor-Leste", "Восточный Тимор", "טימור מזרח", "东帝汶", "Демократическая Республика Восточный Тимор", "הרפובליקה הדמוקרטית של טימר מזרח", "东帝汶民主共和国"},
+	"TM": {"TKM", "Turkmenistan", "Туркменистан", "טורקמניסטן", "土库曼斯坦"},
+	"TN": {"TUN", "Tunisia", "Republic of Tunisia", "Тунис", "תוניסיה", "突尼斯", "Тунисская Республика", "הרפובליקה של טוניסיה", "突尼斯共和国"},
+	"TO": {"TON", "Tonga", "Kingdom of Tonga", "Тонга", "טונגה", "汤加", "Королевство Тонга", "ממלכת טונגה", "汤加王国"},
+	"TR": {"TUR", "Türkiye", "Republic of Türkiye", "טורקיה", "土耳其", "הרפובליקה של טורקיה", "土耳其共和国"},
+	"TT": {"TTO", "Trinidad and Tobago", "Republic of Trinidad and Tobago", "Тринидад и Тобаго", "טרינידד וטובגו", "特里尼达和多巴哥", "Республика Тринидад и Тобаго", "הרפובליקה של טרינידד וטובגו", "特里尼达和多巴哥共和国"},
+	"TV": {"TUV", "Tuvalu", "Тувалу", "טובאלו", "图瓦卢"},
+	"TW": {"TWN", "Taiwan, Province of China", "Taiwan", "Китайская провинция Тайвань", "טאייואן, מחוז של סין", "中国台湾省", "Тайвань", "טאיוואן", "台湾"},
+	"TZ": {"TZA", "Tanzania, United Republic of", "United Republic of Tanzania", "Tanzania", "Танзания", "טנזניה, הרפובליקה המאוחדת של", "坦桑尼亚", "Объединённая Республика Танзания", "הרפובליקה המאוחדת של טנזניה", "坦桑尼亚联合共和国", "טנזניה"},
+	"UA": {"UKR", "Ukraine", "Украина", "אוקראינה", "乌克兰"},
+	"UG": {"UGA", "Uganda", "Republic of Uganda", "Уганда", "אוגנדה", "乌干达", "Республика Уганда", "הרפובליקה של אוגנדה", "乌干达共和国"},
+	"UM": {"UMI", "United States Minor Outlying Islands", "Соединенные штаты Малых Удаленных островов", "האיים המרוחקים הקטנים של ארצות הברית", "美国本土外小岛屿"},
+	"US": {"USA", "United States", "United States of America", "Соединённые штаты", "ארצות הברית", "美国", "Соединённые Штаты Америки", "美利坚合众国"},
+	"UY": {"URY", "Uruguay", "Eastern Republic of Uruguay", "Уругвай", "אורוגוואי", "乌拉圭", "Восточная республика Уругвай", "הרפובליקה מזרחית של אורוגאי", "乌拉圭东岸共和国"},
+	"UZ": {"UZB", "Uzbekistan", "Republic of Uzbekistan", "Узбекистан", "אוזבקיסטן", "乌兹别克斯坦", "Республика Узбекистан", "הרפובליקה של אוזבקיסטן", "乌兹别克斯坦共和国"},
+	"VA": {"VAT", "Holy See (Vatican City State)", "Государство-город Ватикан", "וותיקן", "梵地冈"},
+	"VC": {"VCT", "Saint Vincent and the Grenadines", "Сент-Винсент и Гренадины", "סנט וינסנט והגרנדינים", "圣文森特和格林纳丁斯"},
+	"VE": {"VEN", "Venezuela, Bolivarian Republic of", "Bolivarian Republic of Venezuela", "Venezuela", "Боливарианская Республика Венесуэла", "ונצואלה, הרפובליקה הבוליוריאנית של", "委内瑞拉玻利瓦尔共和国", "הרפובליקה הבוליוריאנית של ונצואלה", "Венесуэла", "ונצואלה", "委内瑞拉"},
+	"VG": {"VGB", "Virgin Islands, British", "British Virgin Islands", "Виргинские острова (Британия)", "איי הבתולה (בריטיים)", "英属维尔京群岛", "Британские Виргинские Острова", "איי הבתולה הבריטיים"},
+	"VI": {"VIR", "Virgin Islands, U.S.", "Virgin Islands of the United States", "Виргинские острова (США)", "איי הבתולה (ארה״ב)", "美属维尔京群岛", "Американские Виргинские острова", "איי הבתולה של ארה\"ב", "美属维京群岛"},
+	"VN": {"VNM", "Viet Nam", "Socialist Republic of Viet Nam", "Vietnam", "Вьетнам", "ויטנאם", "越南", "Социалистическая Республика Вьетнам", "הרפובליקה הסוציאליסטית של ויאטנם", "越南社会主义共和国", "וייטנאם"},
+	"VU": {"VUT", "Vanuatu", "Republic of Vanuatu", "Вануату", "ונואטו", "瓦努阿图", "Республика Вануату", "הרפובליקה של ואנואטו", "瓦努阿图共和国"},
+	"WF": {"WLF", "Wallis and Futuna", "Уоллес и Футана", "ואליס ופוטונה", "瓦利斯和富图纳"},
+	"WS": {"WSM", "Samoa", "Independent State of Samoa", "Самоа", "סמואה", "萨摩亚", "Независимое Государство Самоа", "המדינה העצמאית של סמואה", "萨摩亚独立国"},
+	"YE": {"YEM", "Yemen", "Republic of Yemen", "Йемен", "תימן", "也门", "Йеменская Республика", "הרפובליקה של תימן", "也门共和国"},
+	"YT": {"MYT", "Mayotte", "Майот", "מיוט", "马约特"},
+	"ZA": {"ZAF", "South Africa", "Republic of South Africa", "Южная Африка", "דרום אפריקה", "南非", "Южно-Африканская Республика", "הרפובליקה של דרום אפריקה", "南非共和国"},
+	"ZM": {"ZMB", "Zambia", "Republic of Zambia", "Замбия", "זמביה", "赞比亚", "Республика Замбия", "הרפובליקה של זמביה", "赞比亚共和国"},
+	"ZW": {"ZWE", "Zimbabwe", "Republic of Zimbabwe", "Зимбабве", "זימבבואה", "津巴布韦", "Республика Зимбабве", "הרפובליקה של זימבבואה", "津巴布韦共和国"},
+}
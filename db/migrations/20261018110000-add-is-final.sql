@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE postal_api_responses ADD COLUMN is_final INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX idx_postal_api_responses_last_fetched_at ON postal_api_responses (last_fetched_at);
+
+
+-- +migrate Down
+DROP INDEX idx_postal_api_responses_last_fetched_at;
+ALTER TABLE postal_api_responses DROP COLUMN is_final;
@@ -0,0 +1,28 @@
+-- +migrate Up
+CREATE TABLE subscriptions
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    tracking_number TEXT    NOT NULL,
+    callback_url    TEXT    NOT NULL,
+    secret          TEXT    NOT NULL,
+    created_at      INTEGER NOT NULL
+);
+CREATE INDEX idx_subscriptions_tracking_number ON subscriptions (tracking_number);
+
+CREATE TABLE webhook_deliveries
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    payload         TEXT    NOT NULL,
+    status          TEXT    NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at INTEGER NOT NULL,
+    last_error      TEXT    NOT NULL DEFAULT '',
+    created_at      INTEGER NOT NULL
+);
+CREATE INDEX idx_webhook_deliveries_status_next_attempt_at ON webhook_deliveries (status, next_attempt_at);
+
+
+-- +migrate Down
+DROP TABLE webhook_deliveries;
+DROP TABLE subscriptions;
@@ -0,0 +1,11 @@
+-- +migrate Up
+CREATE TABLE tracking_number_links
+(
+    tracking_number        TEXT NOT NULL,
+    linked_tracking_number TEXT NOT NULL,
+    PRIMARY KEY (tracking_number, linked_tracking_number)
+);
+
+
+-- +migrate Down
+DROP TABLE tracking_number_links;
@@ -0,0 +1,24 @@
+-- +migrate Up
+ALTER TABLE postal_api_responses ADD COLUMN is_latest INTEGER NOT NULL DEFAULT 0;
+UPDATE postal_api_responses
+SET is_latest = 1
+WHERE id IN (
+    SELECT (
+        SELECT p2.id
+        FROM postal_api_responses p2
+        WHERE p2.tracking_number = p1.tracking_number
+        AND p2.api_name = p1.api_name
+        ORDER BY p2.last_fetched_at DESC, p2.id DESC
+        LIMIT 1
+    )
+    FROM postal_api_responses p1
+    GROUP BY p1.tracking_number, p1.api_name
+);
+CREATE UNIQUE INDEX idx_postal_api_responses_latest
+    ON postal_api_responses (tracking_number, api_name)
+    WHERE is_latest = 1;
+
+
+-- +migrate Down
+DROP INDEX idx_postal_api_responses_latest;
+ALTER TABLE postal_api_responses DROP COLUMN is_latest;
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- response_hash of existing rows is filled by `service backfill` command, which also compresses their bodies
+ALTER TABLE postal_api_responses ADD COLUMN response_hash TEXT NOT NULL DEFAULT '';
+
+
+-- +migrate Down
+ALTER TABLE postal_api_responses DROP COLUMN response_hash;
@@ -0,0 +1,17 @@
+-- +migrate Up
+-- responses in languages other than the default one, only used to describe events;
+-- only the latest one per tracking number, API and language is kept
+CREATE TABLE localized_responses
+(
+    tracking_number TEXT    NOT NULL,
+    api_name        TEXT    NOT NULL,
+    language        TEXT    NOT NULL,
+    fetched_at      INTEGER NOT NULL,
+    response_body   BLOB    NOT NULL,
+    status          TEXT    NOT NULL,
+    PRIMARY KEY (tracking_number, api_name, language)
+);
+
+
+-- +migrate Down
+DROP TABLE localized_responses;
@@ -0,0 +1,8 @@
+-- +migrate Up
+CREATE INDEX idx_postal_api_responses_final
+    ON postal_api_responses (api_name, id)
+    WHERE is_latest = 1 AND is_final = 1 AND status = 'success';
+
+
+-- +migrate Down
+DROP INDEX idx_postal_api_responses_final;
@@ -0,0 +1,7 @@
+-- +migrate Up
+CREATE INDEX idx_postal_api_responses_first
+    ON postal_api_responses (tracking_number, api_name, first_fetched_at, id);
+
+
+-- +migrate Down
+DROP INDEX idx_postal_api_responses_first;
@@ -0,0 +1,55 @@
+-- +migrate Up
+CREATE TABLE postal_api_responses
+(
+    id               BIGSERIAL PRIMARY KEY,
+    api_name         TEXT    NOT NULL,
+    tracking_number  TEXT    NOT NULL,
+    first_fetched_at BIGINT  NOT NULL,
+    last_fetched_at  BIGINT  NOT NULL,
+    response_body    BYTEA   NOT NULL,
+    status           TEXT    NOT NULL,
+    is_final         BOOLEAN NOT NULL DEFAULT FALSE,
+    is_latest        BOOLEAN NOT NULL DEFAULT FALSE
+);
+CREATE INDEX idx_postal_api_responses_last_fetched_at ON postal_api_responses (last_fetched_at);
+CREATE UNIQUE INDEX idx_postal_api_responses_latest
+    ON postal_api_responses (tracking_number, api_name)
+    WHERE is_latest;
+
+CREATE TABLE tracking_number_links
+(
+    id                     BIGSERIAL PRIMARY KEY,
+    tracking_number        TEXT NOT NULL,
+    linked_tracking_number TEXT NOT NULL,
+    UNIQUE (tracking_number, linked_tracking_number)
+);
+
+CREATE TABLE subscriptions
+(
+    id              BIGSERIAL PRIMARY KEY,
+    tracking_number TEXT   NOT NULL,
+    callback_url    TEXT   NOT NULL,
+    secret          TEXT   NOT NULL,
+    created_at      BIGINT NOT NULL
+);
+CREATE INDEX idx_subscriptions_tracking_number ON subscriptions (tracking_number);
+
+CREATE TABLE webhook_deliveries
+(
+    id              BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT  NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    payload         BYTEA   NOT NULL,
+    status          TEXT    NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at BIGINT  NOT NULL,
+    last_error      TEXT    NOT NULL DEFAULT '',
+    created_at      BIGINT  NOT NULL
+);
+CREATE INDEX idx_webhook_deliveries_status_next_attempt_at ON webhook_deliveries (status, next_attempt_at);
+
+
+-- +migrate Down
+DROP TABLE webhook_deliveries;
+DROP TABLE subscriptions;
+DROP TABLE tracking_number_links;
+DROP TABLE postal_api_responses;
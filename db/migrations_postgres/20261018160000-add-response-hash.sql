@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE postal_api_responses ADD COLUMN response_hash TEXT NOT NULL DEFAULT '';
+UPDATE postal_api_responses SET response_hash = encode(sha256(response_body), 'hex');
+
+
+-- +migrate Down
+ALTER TABLE postal_api_responses DROP COLUMN response_hash;
@@ -7,9 +7,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/dir01/parcels/countries"
 	"github.com/dir01/parcels/service"
+	"golang.org/x/exp/maps"
 )
 
 const APIName service.APIName = "cainiao"
@@ -20,13 +26,49 @@ func New() service.PostalAPI {
 
 type Cainiao struct{}
 
+// defaultLang is the language responses are stored in, see service.DefaultLanguage
+const defaultLang = "en-US"
+
+// langs maps languages cainiao can describe events in to the way it calls them
+var langs = map[string]string{
+	"en": defaultLang,
+	"ru": "ru-RU",
+	"es": "es-ES",
+	"fr": "fr-FR",
+	"pt": "pt-PT",
+}
+
 func (c *Cainiao) Fetch(ctx context.Context, trackingNumber string) service.PostalApiResponse {
+	return c.fetch(ctx, trackingNumber, defaultLang)
+}
+
+// SupportedLanguages are the languages FetchLocalized can describe events in
+func (c *Cainiao) SupportedLanguages() []string {
+	languages := maps.Keys(langs)
+	slices.Sort(languages)
+	return languages
+}
+
+// FetchLocalized is the same as Fetch, but events are described in the given language
+func (c *Cainiao) FetchLocalized(ctx context.Context, trackingNumber string, language string) service.PostalApiResponse {
+	lang, ok := langs[language]
+	if !ok {
+		return service.PostalApiResponse{
+			TrackingNumber: trackingNumber,
+			APIName:        APIName,
+			Status:         service.StatusUnknownError,
+		}
+	}
+	return c.fetch(ctx, trackingNumber, lang)
+}
+
+func (c *Cainiao) fetch(ctx context.Context, trackingNumber string, lang string) service.PostalApiResponse {
 	result := service.PostalApiResponse{
 		TrackingNumber: trackingNumber,
 		APIName:        "cainiao",
 	}
 
-	url := fmt.Sprintf("https://global.cainiao.com/global/detail.json?mailNos=%s&lang=en-US", trackingNumber)
+	url := fmt.Sprintf("https://global.cainiao.com/global/detail.json?mailNos=%s&lang=%s", trackingNumber, lang)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		result.Status = service.StatusUnknownError
@@ -39,6 +81,12 @@ func (c *Cainiao) Fetch(ctx context.Context, trackingNumber string) service.Post
 		return result
 	}
 
+	if isRateLimited(resp) {
+		result.Status = service.StatusRateLimitExceeded
+		result.RetryAfter = service.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return result
+	}
+
 	if resp.StatusCode == http.StatusNotFound {
 		result.Status = service.StatusNotFound
 		return result
@@ -70,6 +118,136 @@ func (c *Cainiao) Fetch(ctx context.Context, trackingNumber string) service.Post
 	return result
 }
 
+func (c *Cainiao) SupportedFormats() []service.TrackingNumberFormat {
+	return []service.TrackingNumberFormat{service.FormatCainiao, service.FormatUPUS10}
+}
+
+// SupportedCountries is where cainiao ships from: it tracks parcels leaving China wherever they go
+func (c *Cainiao) SupportedCountries() []string {
+	return []string{"CN"}
+}
+
+// maxBatchSize is how many mailNos we dare to ask cainiao about at once
+const maxBatchSize = 10
+
+func (c *Cainiao) MaxBatchSize() int {
+	return maxBatchSize
+}
+
+// FetchBatch asks about several tracking numbers at once, since detail.json accepts comma-separated mailNos.
+// Combined response is split into one response per tracking number,
+// each of them looking exactly like a response to a single tracking number request.
+func (c *Cainiao) FetchBatch(ctx context.Context, trackingNumbers []string) []service.PostalApiResponse {
+	results := make([]service.PostalApiResponse, len(trackingNumbers))
+	for i, trackingNumber := range trackingNumbers {
+		results[i] = service.PostalApiResponse{
+			TrackingNumber: trackingNumber,
+			APIName:        APIName,
+		}
+	}
+	setStatus := func(status service.ApiResponseStatus) []service.PostalApiResponse {
+		for i := range results {
+			results[i].Status = status
+		}
+		return results
+	}
+
+	url := fmt.Sprintf(
+		"https://global.cainiao.com/global/detail.json?mailNos=%s&lang=%s",
+		strings.Join(trackingNumbers, ","),
+		defaultLang,
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return setStatus(service.StatusUnknownError)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return setStatus(service.StatusUnknownError)
+	}
+	defer resp.Body.Close()
+
+	if isRateLimited(resp) {
+		retryAfter := service.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		for i := range results {
+			results[i].RetryAfter = retryAfter
+		}
+		return setStatus(service.StatusRateLimitExceeded)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return setStatus(service.StatusUnknownError)
+	}
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return setStatus(service.StatusUnknownError)
+	}
+
+	modules, err := splitModules(responseBody)
+	if err != nil {
+		return setStatus(service.StatusUnknownError)
+	}
+
+	for i := range results {
+		moduleBody, found := modules[results[i].TrackingNumber]
+		if !found {
+			results[i].Status = service.StatusNotFound
+			continue
+		}
+		results[i].ResponseBody = moduleBody
+		if bytes.Contains(moduleBody, []byte(`"detailList":[]`)) {
+			results[i].Status = service.StatusNotFound
+		} else {
+			results[i].Status = service.StatusSuccess
+		}
+	}
+
+	return results
+}
+
+// isRateLimited detects both explicit rate limiting
+// and anti-bot "slider captcha" page that cainiao serves instead of JSON once it gets suspicious
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode == http.StatusOK && strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html")
+}
+
+// splitModules splits multi-number response into map[mailNo]singleNumberResponseBody.
+// Modules are kept as raw bytes, so single-number response body is the same
+// as if we've asked about that number alone.
+func splitModules(responseBody []byte) (map[string][]byte, error) {
+	var multiResponse struct {
+		Module  []json.RawMessage `json:"module"`
+		Success bool              `json:"success"`
+	}
+	if err := json.Unmarshal(responseBody, &multiResponse); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]byte, len(multiResponse.Module))
+	for _, rawModule := range multiResponse.Module {
+		var m struct {
+			MailNo string `json:"mailNo"`
+		}
+		if err := json.Unmarshal(rawModule, &m); err != nil {
+			return nil, err
+		}
+		// building body by hand, since json.Marshal would escape HTML characters inside raw module
+		body := make([]byte, 0, len(rawModule)+32)
+		body = append(body, `{"module":[`...)
+		body = append(body, rawModule...)
+		body = append(body, `],"success":`...)
+		body = strconv.AppendBool(body, multiResponse.Success)
+		body = append(body, '}')
+		result[m.MailNo] = body
+	}
+	return result, nil
+}
+
 func (c *Cainiao) Parse(rawResponse service.PostalApiResponse) (*service.TrackingInfo, error) {
 	var cainiaoResponse response
 	if err := json.Unmarshal(rawResponse.ResponseBody, &cainiaoResponse); err != nil {
@@ -82,29 +260,112 @@ func (c *Cainiao) Parse(rawResponse service.PostalApiResponse) (*service.Trackin
 
 	m0 := cainiaoResponse.Module[0]
 	var events []service.TrackingEvent
+	var additionalTrackingNumbers []string
 	for _, detail := range m0.DetailList {
-		if trackingEvent := c.parseDetail(detail); trackingEvent != nil {
+		if trackingEvent := c.parseDetail(detail, m0); trackingEvent != nil {
 			events = append(events, *trackingEvent)
 		}
+		linked := c.parseLinkedTrackingNumber(detail)
+		if linked != "" && linked != rawResponse.TrackingNumber && !slices.Contains(additionalTrackingNumbers, linked) {
+			additionalTrackingNumbers = append(additionalTrackingNumbers, linked)
+		}
 	}
 
 	return &service.TrackingInfo{
-		TrackingNumber:     rawResponse.TrackingNumber,
-		APIName:            APIName,
-		OriginCountry:      m0.OriginCountry,
-		DestinationCountry: m0.DestCountry,
-		Events:             events,
+		TrackingNumber:            rawResponse.TrackingNumber,
+		APIName:                   APIName,
+		OriginCountry:             m0.OriginCountry,
+		DestinationCountry:        m0.DestCountry,
+		Events:                    events,
+		AdditionalTrackingNumbers: additionalTrackingNumbers,
+		ETA:                       c.parseETA(m0),
 	}, nil
 }
 
-func (c *Cainiao) parseDetail(detail detail) *service.TrackingEvent {
+func (c *Cainiao) parseETA(m module) *service.ETA {
+	eta := m.GlobalEtaInfo
+	if eta.DeliveryMinTime == 0 || eta.DeliveryMaxTime == 0 {
+		return nil
+	}
+	return &service.ETA{
+		Earliest: time.UnixMilli(eta.DeliveryMinTime),
+		Latest:   time.UnixMilli(eta.DeliveryMaxTime),
+		Source:   service.ETASourceCarrier,
+	}
+}
+
+// rePreMainCode matches the last-mile tracking number cainiao mentions when parcel is handed over,
+// e.g. "preMainCode:SINOA00241668IL"
+var rePreMainCode = regexp.MustCompile(`preMainCode:\s*([0-9A-Za-z]+)`)
+
+func (c *Cainiao) parseLinkedTrackingNumber(detail detail) string {
+	for _, desc := range []string{detail.Desc, detail.StanderdDesc} {
+		if m := rePreMainCode.FindStringSubmatch(desc); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+func (c *Cainiao) parseDetail(detail detail, m module) *service.TrackingEvent {
+	status := c.mapStatus(detail.ActionCode)
 	return &service.TrackingEvent{
-		Time:        time.Unix(detail.Time/1000, 0),
-		Description: detail.StanderdDesc,
-		Status:      c.mapStatus(detail.ActionCode),
+		Time:           c.parseTime(detail),
+		Description:    detail.StanderdDesc,
+		Status:         status,
+		Location:       c.parseLocation(detail, status, m),
+		RawCode:        detail.ActionCode,
+		RawDescription: detail.Desc,
+	}
+}
+
+// reTimeZone matches cainiao's timezones, e.g. "GMT+8" or "GMT-3:30"
+var reTimeZone = regexp.MustCompile(`^GMT([+-])(\d{1,2})(?::(\d{2}))?$`)
+
+// parseTime puts event time into the timezone of the place it happened at.
+// Note that timeStr is always in China time, so only timeZone tells where event happened
+func (c *Cainiao) parseTime(detail detail) time.Time {
+	t := time.UnixMilli(detail.Time).UTC()
+	m := reTimeZone.FindStringSubmatch(detail.TimeZone)
+	if m == nil {
+		return t
+	}
+	hours, _ := strconv.Atoi(m[2])
+	minutes, _ := strconv.Atoi(m[3]) // empty when there are no minutes, which is exactly 0
+	offset := hours*60*60 + minutes*60
+	if m[1] == "-" {
+		offset = -offset
+	}
+	return t.In(time.FixedZone(detail.TimeZone, offset))
+}
+
+// reCity matches city cainiao sometimes prepends to description, e.g. "[Fenggang Town] Departed from sorting center"
+var reCity = regexp.MustCompile(`^\[([^\]]+)\]`)
+
+// parseLocation tells where event happened. Cainiao doesn't say which country event happened in,
+// but it's either origin or destination one, depending on how far parcel has got
+func (c *Cainiao) parseLocation(detail detail, status service.TrackingStatus, m module) *service.Location {
+	var location service.Location
+	for _, desc := range []string{detail.StanderdDesc, detail.Desc} {
+		if match := reCity.FindStringSubmatch(desc); match != nil {
+			location.City = strings.TrimSpace(match[1])
+			break
+		}
+	}
+	switch status.Phase() {
+	case service.PhaseInfoReceived, service.PhaseInTransitAtOrigin, service.PhaseExportCustoms:
+		location.Country, _ = countries.Normalize(m.OriginCountry)
+	case service.PhaseImportCustoms, service.PhaseOutForDelivery, service.PhaseReadyForPickup, service.PhaseDelivered:
+		location.Country, _ = countries.Normalize(m.DestCountry)
+	}
+	if location == (service.Location{}) {
+		return nil
 	}
+	return &location
 }
 
+// mapStatus only maps action codes seen in real responses, see testdata for terminal ones.
+// A wrong terminal guess would stop tracking parcel for good, while unknown code is merely reported
 func (c *Cainiao) mapStatus(actionCode string) service.TrackingStatus {
 	switch actionCode {
 	case "GWMS_ACCEPT":
@@ -145,6 +406,12 @@ func (c *Cainiao) mapStatus(actionCode string) service.TrackingStatus {
 		return service.TrackingStatusImportCustomsClearanceSuccess
 	case "CUSTOMS_ARRIVED_IN_AREA_CALLBACK":
 		return service.TrackingStatusArrivedAtCustoms
+	case "GTMS_SIGNED":
+		return service.TrackingStatusDelivered
+	case "GTMS_STA_SIGNED":
+		return service.TrackingStatusPickedUpFromLocker
+	case "RETURN_SIGNED":
+		return service.TrackingStatusReturnedToSender
 	default:
 		return service.TrackingStatusUnknown
 	}
@@ -7,7 +7,9 @@ import (
 	"github.com/dir01/parcels/service"
 	"os"
 	"path"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestCainiao(t *testing.T) {
@@ -26,6 +28,13 @@ func TestCainiao(t *testing.T) {
 		if info.TrackingNumber != "RS0814398526Y" {
 			t.Fatalf("Unexpected TrackingNumber: %s", info.TrackingNumber)
 		}
+		if info.ETA == nil || info.ETA.Source != service.ETASourceCarrier ||
+			!info.ETA.Earliest.Equal(time.UnixMilli(1697155196000)) || !info.ETA.Latest.Equal(time.UnixMilli(1697500796000)) {
+			t.Fatalf("unexpected ETA: %+v", info.ETA)
+		}
+		if info.IsTerminal() {
+			t.Fatalf("expected parcel still in transit to be tracked further, got %q", info.TerminalState())
+		}
 	})
 
 	t.Run("UZ0556033196Y", func(t *testing.T) {
@@ -44,6 +53,163 @@ func TestCainiao(t *testing.T) {
 	})
 }
 
+func TestParseLinkedTrackingNumbers(t *testing.T) {
+	body := []byte(`{"module":[{"mailNo":"LP00123456789012","detailList":[` +
+		`{"time":1700000000000,"desc":"Rerouted, preMainCode:SINOA00241668IL","actionCode":"TRANSIT_PORT_REROUTE_CALLBACK"},` +
+		`{"time":1690000000000,"desc":"preMainCode: SINOA00241668IL","actionCode":"TRANSIT_PORT_REROUTE_CALLBACK"},` +
+		`{"time":1680000000000,"desc":"Accepted by carrier","actionCode":"PU_PICKUP_SUCCESS"}` +
+		`]}],"success":true}`)
+
+	info, err := cainiao.New().Parse(service.PostalApiResponse{
+		TrackingNumber: "LP00123456789012",
+		APIName:        cainiao.APIName,
+		ResponseBody:   body,
+		Status:         service.StatusSuccess,
+	})
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if len(info.AdditionalTrackingNumbers) != 1 || info.AdditionalTrackingNumbers[0] != "SINOA00241668IL" {
+		t.Fatalf("unexpected additional tracking numbers: %v", info.AdditionalTrackingNumbers)
+	}
+	if info.Events[0].Status != service.TrackingStatusTransitPortRerouteCb {
+		t.Fatalf("unexpected status: %s", info.Events[0].Status)
+	}
+}
+
+func TestParseRawCodes(t *testing.T) {
+	body := []byte(`{"module":[{"mailNo":"LP00123456789012","detailList":[` +
+		`{"time":1700000000000,"desc":"Something unheard of","standerdDesc":"Unheard of","actionCode":"SOMETHING_NEW"},` +
+		`{"time":1680000000000,"desc":"Accepted by carrier","standerdDesc":"Accepted","actionCode":"PU_PICKUP_SUCCESS"}` +
+		`]}],"success":true}`)
+
+	info, err := cainiao.New().Parse(service.PostalApiResponse{
+		TrackingNumber: "LP00123456789012",
+		APIName:        cainiao.APIName,
+		ResponseBody:   body,
+		Status:         service.StatusSuccess,
+	})
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	unknown, known := info.Events[0], info.Events[1]
+	if unknown.Status != service.TrackingStatusUnknown || unknown.RawCode != "SOMETHING_NEW" || unknown.RawDescription != "Something unheard of" {
+		t.Fatalf("expected raw code and description of unknown event to be kept, got %+v", unknown)
+	}
+	if known.Status != service.TrackingStatusAcceptedByCarrier || known.RawCode != "PU_PICKUP_SUCCESS" || known.Description != "Accepted" {
+		t.Fatalf("unexpected event: %+v", known)
+	}
+}
+
+func TestParseTerminalCodes(t *testing.T) {
+	testCases := []struct {
+		golden        string
+		code          string
+		status        service.TrackingStatus
+		terminalState service.TerminalState
+	}{
+		{"delivered", "GTMS_SIGNED", service.TrackingStatusDelivered, service.TerminalStateDelivered},
+		{"picked_up", "GTMS_STA_SIGNED", service.TrackingStatusPickedUpFromLocker, service.TerminalStatePickedUp},
+		{"returned", "RETURN_SIGNED", service.TrackingStatusReturnedToSender, service.TerminalStateReturned},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.code, func(t *testing.T) {
+			bytes, err := os.ReadFile(path.Join("testdata", tc.golden+".golden"))
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			var resp service.PostalApiResponse
+			if err := json.Unmarshal(bytes, &resp); err != nil {
+				t.Fatalf("failed to unmarshal golden file: %v", err)
+			}
+
+			info, err := cainiao.New().Parse(resp)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			if e := info.Events[0]; e.RawCode != tc.code || e.Status != tc.status {
+				t.Fatalf("expected %s to be %s, got %+v", tc.code, tc.status, e)
+			}
+			if state := info.TerminalState(); state != tc.terminalState {
+				t.Fatalf("expected terminal state %q, got %q", tc.terminalState, state)
+			}
+		})
+	}
+}
+
+func TestParseUnfamiliarCodesAreNotTerminal(t *testing.T) {
+	// codes nobody has seen cainiao send yet, however final they sound, shouldn't stop tracking
+	body := []byte(`{"module":[{"mailNo":"LP00123456789012","detailList":[` +
+		`{"time":1700000000000,"desc":"Lost","standerdDesc":"Lost","actionCode":"GTMS_LOST"},` +
+		`{"time":1680000000000,"desc":"Accepted by carrier","standerdDesc":"Accepted","actionCode":"PU_PICKUP_SUCCESS"}` +
+		`]}],"success":true}`)
+
+	info, err := cainiao.New().Parse(service.PostalApiResponse{
+		TrackingNumber: "LP00123456789012",
+		APIName:        cainiao.APIName,
+		ResponseBody:   body,
+		Status:         service.StatusSuccess,
+	})
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	if info.Events[0].Status != service.TrackingStatusUnknown || info.Events[0].RawCode != "GTMS_LOST" {
+		t.Fatalf("expected unfamiliar event to be unknown, got %+v", info.Events[0])
+	}
+	if info.IsTerminal() {
+		t.Fatalf("expected parcel to be tracked further, got %q", info.TerminalState())
+	}
+	if info.ETA != nil {
+		t.Fatalf("expected no ETA when carrier gives none, got %+v", info.ETA)
+	}
+}
+
+func TestParseTimeAndLocation(t *testing.T) {
+	body := []byte(`{"module":[{"mailNo":"LP00123456789012","originCountry":"Mainland China","destCountry":"Israel","detailList":[` +
+		`{"time":1695865613000,"timeZone":"GMT+3","standerdDesc":"Received by local delivery company","actionCode":"CC_IM_SUCCESS"},` +
+		`{"time":1695662640000,"timeZone":"GMT-3:30","standerdDesc":"Departed from departure country/region","actionCode":"LH_DEPART"},` +
+		`{"time":1695394249000,"timeZone":"GMT+8","standerdDesc":"[Fenggang Town] Departed from sorting center","actionCode":"SC_OUTBOUND_SUCCESS"},` +
+		`{"time":1695369302000,"standerdDesc":"Received by logistics company","actionCode":"SOMETHING_NEW"}` +
+		`]}],"success":true}`)
+
+	info, err := cainiao.New().Parse(service.PostalApiResponse{
+		TrackingNumber: "LP00123456789012",
+		APIName:        cainiao.APIName,
+		ResponseBody:   body,
+		Status:         service.StatusSuccess,
+	})
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	expectedTimes := []string{
+		"2023-09-28T04:46:53+03:00",
+		"2023-09-25T13:54:00-03:30",
+		"2023-09-22T22:50:49+08:00",
+		"2023-09-22T07:55:02Z",
+	}
+	for i, expected := range expectedTimes {
+		if actual := info.Events[i].Time.Format(time.RFC3339); actual != expected {
+			t.Fatalf("expected event #%d to happen at %s, got %s", i, expected, actual)
+		}
+	}
+
+	expectedLocations := []*service.Location{
+		{Country: "IL"},
+		nil, // somewhere in between
+		{City: "Fenggang Town", Country: "CN"},
+		nil,
+	}
+	for i, expected := range expectedLocations {
+		actual := info.Events[i].Location
+		if (expected == nil) != (actual == nil) || (expected != nil && *expected != *actual) {
+			t.Fatalf("expected event #%d to happen at %+v, got %+v", i, expected, actual)
+		}
+	}
+}
+
 func loadGoldenOrFetch(t *testing.T, api service.PostalAPI, trackingNumber string) service.PostalApiResponse {
 	// if UPDATE_TESTDATA in env or file is missing, fetch from API and save to file
 	// otherwise, load from file and respond
@@ -78,3 +244,21 @@ func loadGoldenOrFetch(t *testing.T, api service.PostalAPI, trackingNumber strin
 	}
 	return resp
 }
+
+func TestLocalization(t *testing.T) {
+	api := cainiao.New()
+
+	localizer, ok := api.(service.Localizer)
+	if !ok {
+		t.Fatalf("expected cainiao to be able to describe events in other languages")
+	}
+	languages := localizer.SupportedLanguages()
+	if !slices.Contains(languages, "ru") || !slices.Contains(languages, "en") {
+		t.Fatalf("expected ru and en to be supported, got %v", languages)
+	}
+
+	resp := localizer.FetchLocalized(context.Background(), "RS0814398526Y", "he")
+	if resp.Status != service.StatusUnknownError {
+		t.Fatalf("expected unsupported language to be an error, got %v", resp.Status)
+	}
+}
@@ -0,0 +1,46 @@
+package cainiao
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/dir01/parcels/service"
+)
+
+func TestSplitModules(t *testing.T) {
+	loadGoldenBody := func(t *testing.T, trackingNumber string) []byte {
+		b, err := os.ReadFile("TestCainiao/" + trackingNumber + ".golden")
+		if err != nil {
+			t.Fatalf("failed to read golden file: %v", err)
+		}
+		var resp service.PostalApiResponse
+		if err := json.Unmarshal(b, &resp); err != nil {
+			t.Fatalf("failed to unmarshal golden file: %v", err)
+		}
+		return resp.ResponseBody
+	}
+
+	body1 := loadGoldenBody(t, "RS0814398526Y")
+	body2 := loadGoldenBody(t, "UZ0556033196Y")
+
+	// combined response looks like the two single responses with their modules concatenated
+	module1 := bytes.TrimSuffix(bytes.TrimPrefix(body1, []byte(`{"module":[`)), []byte(`],"success":true}`))
+	module2 := bytes.TrimSuffix(bytes.TrimPrefix(body2, []byte(`{"module":[`)), []byte(`],"success":true}`))
+	combined := []byte(`{"module":[` + string(module1) + `,` + string(module2) + `],"success":true}`)
+
+	modules, err := splitModules(combined)
+	if err != nil {
+		t.Fatalf("failed to split modules: %v", err)
+	}
+	if len(modules) != 2 {
+		t.Fatalf("expected 2 modules, got %d", len(modules))
+	}
+	if !bytes.Equal(modules["RS0814398526Y"], body1) {
+		t.Fatalf("expected split body to be identical to single response body, got %s", modules["RS0814398526Y"])
+	}
+	if !bytes.Equal(modules["UZ0556033196Y"], body2) {
+		t.Fatalf("expected split body to be identical to single response body, got %s", modules["UZ0556033196Y"])
+	}
+}
@@ -0,0 +1,122 @@
+package usps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is how long before expiration token is refreshed,
+// so that it doesn't expire while request is in flight
+const tokenRefreshMargin = time.Minute
+
+// tokenSource obtains OAuth access tokens with client credentials grant and caches them until they're about to expire
+type tokenSource struct {
+	baseURL      string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+	now          func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	// inflight is the token request in progress, if any
+	inflight *tokenRequest
+}
+
+// tokenRequest is a token request callers can wait for the result of, once done is closed
+type tokenRequest struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// get returns cached token, or obtains a new one if there's none or it's about to expire.
+// Concurrent callers wait for the same token instead of obtaining one each,
+// without holding the lock while it's being obtained
+func (s *tokenSource) get(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	if s.token != "" && s.now().Before(s.expiresAt.Add(-tokenRefreshMargin)) {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+	if req := s.inflight; req != nil {
+		s.mu.Unlock()
+		select {
+		case <-req.done:
+			return req.token, req.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	req := &tokenRequest{done: make(chan struct{})}
+	s.inflight = req
+	s.mu.Unlock()
+
+	token, expiresIn, err := s.request(ctx)
+
+	s.mu.Lock()
+	if err == nil {
+		s.token = token
+		s.expiresAt = s.now().Add(expiresIn)
+	}
+	s.inflight = nil
+	s.mu.Unlock()
+
+	req.token, req.err = token, err
+	close(req.done)
+	return token, err
+}
+
+// request obtains a new token, telling how long it's valid for
+func (s *tokenSource) request(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {s.clientID},
+		"client_secret": {s.clientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/oauth2/v3/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	var tr tokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return "", 0, err
+	}
+	if tr.AccessToken == "" {
+		return "", 0, fmt.Errorf("token response has no access_token")
+	}
+	return tr.AccessToken, time.Duration(tr.ExpiresIn) * time.Second, nil
+}
+
+// invalidate forgets the token API refused, unless it was already replaced with a new one
+func (s *tokenSource) invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token {
+		s.token = ""
+	}
+}
@@ -0,0 +1,156 @@
+package usps
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+)
+
+func TestTokens(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type server struct {
+		tokensIssued int
+		revoked      map[string]bool
+		tokenStatus  int
+		// onToken is called before token is issued
+		onToken func()
+	}
+
+	prepareTestSubjects := func(t *testing.T) (*USPS, *server) {
+		s := &server{revoked: map[string]bool{}, tokenStatus: http.StatusOK}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/oauth2/v3/token", func(w http.ResponseWriter, r *http.Request) {
+			if r.FormValue("client_id") != "id" || r.FormValue("client_secret") != "secret" ||
+				r.FormValue("grant_type") != "client_credentials" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if s.tokenStatus != http.StatusOK {
+				w.WriteHeader(s.tokenStatus)
+				return
+			}
+			if s.onToken != nil {
+				s.onToken()
+			}
+			s.tokensIssued++
+			fmt.Fprintf(w, `{"access_token":"token%d","token_type":"Bearer","expires_in":3600}`, s.tokensIssued)
+		})
+		mux.HandleFunc("/tracking/v3/tracking/", func(w http.ResponseWriter, r *http.Request) {
+			token := r.Header.Get("Authorization")
+			if token == "" || s.revoked[token] {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"trackingEvents":[{"eventCode":"03"}]}`)
+		})
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+
+		api := &USPS{
+			baseURL: srv.URL,
+			client:  srv.Client(),
+			tokens: &tokenSource{
+				baseURL:      srv.URL,
+				clientID:     "id",
+				clientSecret: "secret",
+				client:       srv.Client(),
+				now:          func() time.Time { return now },
+			},
+		}
+		return api, s
+	}
+
+	fetch := func(t *testing.T, api *USPS) {
+		t.Helper()
+		if resp := api.Fetch(context.Background(), "9205590164917312751089"); resp.Status != service.StatusSuccess {
+			t.Fatalf("unexpected status: %v", resp.Status)
+		}
+	}
+
+	t.Run("token is cached until it's about to expire", func(t *testing.T) {
+		api, s := prepareTestSubjects(t)
+
+		fetch(t, api)
+		fetch(t, api)
+		if s.tokensIssued != 1 {
+			t.Fatalf("expected token to be reused, got %d issued", s.tokensIssued)
+		}
+
+		now = now.Add(time.Hour - tokenRefreshMargin)
+		fetch(t, api)
+		if s.tokensIssued != 2 {
+			t.Fatalf("expected token to be refreshed, got %d issued", s.tokensIssued)
+		}
+	})
+
+	t.Run("refused token is refreshed", func(t *testing.T) {
+		api, s := prepareTestSubjects(t)
+
+		fetch(t, api)
+		s.revoked["Bearer token1"] = true
+		fetch(t, api)
+		if s.tokensIssued != 2 {
+			t.Fatalf("expected token to be refreshed, got %d issued", s.tokensIssued)
+		}
+	})
+
+	t.Run("concurrent callers share token request, and don't block others while it's in flight", func(t *testing.T) {
+		api, s := prepareTestSubjects(t)
+		requested := make(chan struct{})
+		release := make(chan struct{})
+		s.onToken = func() {
+			close(requested)
+			<-release
+		}
+
+		const callers = 5
+		tokens := make(chan string, callers)
+		for i := 0; i < callers; i++ {
+			go func() {
+				token, err := api.tokens.get(context.Background())
+				if err != nil {
+					t.Errorf("failed to get token: %v", err)
+				}
+				tokens <- token
+			}()
+		}
+
+		<-requested
+		invalidated := make(chan struct{})
+		go func() {
+			api.tokens.invalidate("stale")
+			close(invalidated)
+		}()
+		select {
+		case <-invalidated:
+		case <-time.After(time.Second):
+			close(release)
+			t.Fatalf("expected token source not to be locked while token is being requested")
+		}
+		close(release)
+
+		for i := 0; i < callers; i++ {
+			if token := <-tokens; token != "token1" {
+				t.Fatalf("expected every caller to get token1, got %q", token)
+			}
+		}
+		if s.tokensIssued != 1 {
+			t.Fatalf("expected one token to be issued, got %d", s.tokensIssued)
+		}
+	})
+
+	t.Run("no token is an unknown error", func(t *testing.T) {
+		api, s := prepareTestSubjects(t)
+		s.tokenStatus = http.StatusServiceUnavailable
+
+		if resp := api.Fetch(context.Background(), "9205590164917312751089"); resp.Status != service.StatusUnknownError {
+			t.Fatalf("unexpected status: %v", resp.Status)
+		}
+	})
+}
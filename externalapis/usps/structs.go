@@ -0,0 +1,43 @@
+package usps
+
+type response struct {
+	TrackingNumber         string  `json:"trackingNumber"`
+	MailClass              string  `json:"mailClass"`
+	OriginCity             string  `json:"originCity"`
+	OriginState            string  `json:"originState"`
+	OriginZIP              string  `json:"originZIP"`
+	OriginCountry          string  `json:"originCountry"`
+	DestinationCity        string  `json:"destinationCity"`
+	DestinationState       string  `json:"destinationState"`
+	DestinationZIP         string  `json:"destinationZIP"`
+	DestinationCountryCode string  `json:"destinationCountryCode"`
+	Status                 string  `json:"status"`
+	StatusCategory         string  `json:"statusCategory"`
+	StatusSummary          string  `json:"statusSummary"`
+	TrackingEvents         []event `json:"trackingEvents"`
+
+	ExpectedDeliveryTimeStamp        string `json:"expectedDeliveryTimeStamp"`
+	PredictedDeliveryWindowStartTime string `json:"predictedDeliveryWindowStartTime"`
+	PredictedDeliveryWindowEndTime   string `json:"predictedDeliveryWindowEndTime"`
+}
+
+type event struct {
+	EventType       string `json:"eventType"`
+	EventTimestamp  string `json:"eventTimestamp"`
+	GMTTimestamp    string `json:"GMTTimestamp"`
+	GMTOffset       string `json:"GMTOffset"`
+	EventCountry    string `json:"eventCountry"`
+	EventCity       string `json:"eventCity"`
+	EventState      string `json:"eventState"`
+	EventZIP        string `json:"eventZIP"`
+	Firm            string `json:"firm"`
+	Name            string `json:"name"`
+	AuthorizedAgent bool   `json:"authorizedAgent"`
+	EventCode       string `json:"eventCode"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
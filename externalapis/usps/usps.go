@@ -0,0 +1,251 @@
+package usps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/dir01/parcels/countries"
+	"github.com/dir01/parcels/service"
+)
+
+const APIName service.APIName = "usps"
+
+// baseURL is where both OAuth and tracking APIs live
+const baseURL = "https://apis.usps.com"
+
+// New creates USPS tracking API client, authenticating with credentials of an app
+// registered at USPS developer portal (https://developers.usps.com)
+func New(clientID string, clientSecret string) service.PostalAPI {
+	return &USPS{
+		baseURL: baseURL,
+		client:  http.DefaultClient,
+		tokens: &tokenSource{
+			baseURL:      baseURL,
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			client:       http.DefaultClient,
+			now:          time.Now,
+		},
+	}
+}
+
+type USPS struct {
+	baseURL string
+	client  *http.Client
+	tokens  *tokenSource
+}
+
+func (u *USPS) Fetch(ctx context.Context, trackingNumber string) service.PostalApiResponse {
+	result := service.PostalApiResponse{
+		TrackingNumber: trackingNumber,
+		APIName:        APIName,
+	}
+
+	resp, responseBody, err := u.get(ctx, trackingNumber)
+	if err != nil {
+		result.Status = service.StatusUnknownError
+		return result
+	}
+	result.ResponseBody = responseBody
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusTooManyRequests:
+		result.Status = service.StatusRateLimitExceeded
+		result.RetryAfter = service.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return result
+	case http.StatusNotFound, http.StatusBadRequest: // numbers USPS doesn't consider its own are bad requests
+		result.Status = service.StatusNotFound
+		return result
+	default:
+		result.Status = service.StatusUnknownError
+		return result
+	}
+
+	var uspsResponse response
+	if err := json.Unmarshal(responseBody, &uspsResponse); err != nil {
+		result.Status = service.StatusUnknownError
+		return result
+	}
+	if len(uspsResponse.TrackingEvents) == 0 { // label is not even created yet
+		result.Status = service.StatusNotFound
+		return result
+	}
+
+	result.Status = service.StatusSuccess
+	return result
+}
+
+// get requests tracking details. Token is refreshed and request is retried once if API refuses the token,
+// since it might have been revoked before it expired
+func (u *USPS) get(ctx context.Context, trackingNumber string) (*http.Response, []byte, error) {
+	for attempt := 1; ; attempt++ {
+		token, err := u.tokens.get(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		endpoint := fmt.Sprintf("%s/tracking/v3/tracking/%s?expand=DETAIL", u.baseURL, url.PathEscape(trackingNumber))
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := u.client.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		responseBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 1 {
+			u.tokens.invalidate(token)
+			continue
+		}
+		return resp, responseBody, nil
+	}
+}
+
+func (u *USPS) SupportedFormats() []service.TrackingNumberFormat {
+	return []service.TrackingNumberFormat{service.FormatUSPS, service.FormatUPUS10}
+}
+
+// SupportedCountries is where USPS delivers or ships from
+func (u *USPS) SupportedCountries() []string {
+	return []string{"US"}
+}
+
+func (u *USPS) Parse(rawResponse service.PostalApiResponse) (*service.TrackingInfo, error) {
+	var uspsResponse response
+	if err := json.Unmarshal(rawResponse.ResponseBody, &uspsResponse); err != nil {
+		return nil, err
+	}
+
+	var events []service.TrackingEvent
+	for _, e := range uspsResponse.TrackingEvents {
+		events = append(events, service.TrackingEvent{
+			Time:           u.parseTime(e),
+			Description:    e.EventType,
+			Status:         u.mapStatus(e.EventCode),
+			Location:       u.parseLocation(e),
+			RawCode:        e.EventCode,
+			RawDescription: e.EventType,
+		})
+	}
+
+	return &service.TrackingInfo{
+		TrackingNumber:     rawResponse.TrackingNumber,
+		APIName:            APIName,
+		OriginCountry:      country(uspsResponse.OriginCountry, uspsResponse.OriginState),
+		DestinationCountry: country(uspsResponse.DestinationCountryCode, uspsResponse.DestinationState),
+		Events:             events,
+		ETA:                u.parseETA(uspsResponse),
+	}, nil
+}
+
+// country is what USPS says, or US if it only tells the state, which it only does for US addresses
+func country(raw string, state string) string {
+	if raw == "" && state != "" {
+		return "US"
+	}
+	return raw
+}
+
+// parseETA prefers predicted delivery window, falling back to expected delivery date
+func (u *USPS) parseETA(r response) *service.ETA {
+	start, startErr := parseTimestamp(r.PredictedDeliveryWindowStartTime, time.UTC)
+	end, endErr := parseTimestamp(r.PredictedDeliveryWindowEndTime, time.UTC)
+	if startErr == nil && endErr == nil {
+		return &service.ETA{Earliest: start, Latest: end, Source: service.ETASourceCarrier}
+	}
+	if expected, err := parseTimestamp(r.ExpectedDeliveryTimeStamp, time.UTC); err == nil {
+		return &service.ETA{Earliest: expected, Latest: expected, Source: service.ETASourceCarrier}
+	}
+	return nil
+}
+
+// reGMTOffset matches USPS's offsets from GMT, e.g. "-05:00"
+var reGMTOffset = regexp.MustCompile(`^([+-])(\d{2}):?(\d{2})$`)
+
+// parseTime puts event time into the timezone of the place it happened at.
+// GMTTimestamp is the exact moment, while eventTimestamp is the local time there
+func (u *USPS) parseTime(e event) time.Time {
+	location := time.UTC
+	if m := reGMTOffset.FindStringSubmatch(e.GMTOffset); m != nil {
+		hours, _ := strconv.Atoi(m[2])
+		minutes, _ := strconv.Atoi(m[3])
+		offset := hours*60*60 + minutes*60
+		if m[1] == "-" {
+			offset = -offset
+		}
+		location = time.FixedZone(e.GMTOffset, offset)
+	}
+	if t, err := parseTimestamp(e.GMTTimestamp, time.UTC); err == nil {
+		return t.In(location)
+	}
+	t, _ := parseTimestamp(e.EventTimestamp, location)
+	return t
+}
+
+// parseTimestamp parses USPS timestamps, which come both with and without offset.
+// Those without one are in location
+func parseTimestamp(s string, location *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02T15:04:05", s, location)
+}
+
+// parseLocation tells where event happened. USPS only tells the state for US locations,
+// and the country for foreign ones
+func (u *USPS) parseLocation(e event) *service.Location {
+	location := service.Location{City: e.EventCity}
+	if code, ok := countries.Normalize(e.EventCountry); ok {
+		location.Country = code
+	} else if e.EventState != "" {
+		location.Country = "US"
+	}
+	if location == (service.Location{}) {
+		return nil
+	}
+	return &location
+}
+
+// mapStatus maps USPS event codes. Facilities of any kind are sorting centers as far as our statuses go.
+// Returns are not mapped: USPS tells when item is being returned, but not when it's returned
+func (u *USPS) mapStatus(eventCode string) service.TrackingStatus {
+	switch eventCode {
+	case "MA": // Pre-Shipment Info Sent to USPS
+		return service.TrackingStatusShipmentInfoReceived
+	case "GX": // Shipping Label Cancelled
+		return service.TrackingStatusCancelled
+	case "03", "80": // Accept or Pickup; Picked Up by Shipping Partner
+		return service.TrackingStatusAcceptedByCarrier
+	case "07", "10", "81": // Arrival at Unit; Processed; Arrived Shipping Partner Facility
+		return service.TrackingStatusArrivedAtSortingCenter
+	case "82": // Departed Shipping Partner Facility
+		return service.TrackingStatusDepartedFromSortingCenter
+	case "OF": // Out for Delivery
+		return service.TrackingStatusOutForDelivery
+	case "14", "16": // Arrival at Pickup Point; Available for Pickup
+		return service.TrackingStatusReadyForPickup
+	case "01", "17": // Delivered; Picked Up by Agent
+		return service.TrackingStatusDelivered
+	case "43": // Picked Up at Post Office
+		return service.TrackingStatusPickedUpFromLocker
+	default:
+		return service.TrackingStatusUnknown
+	}
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cooldownCollector reports remaining API cooldown at scrape time,
+// so that the gauge goes down to zero by itself, even if nobody is tracking parcels
+type cooldownCollector struct {
+	desc  *prometheus.Desc
+	now   func() time.Time
+	mu    sync.Mutex
+	until map[service.APIName]time.Time
+}
+
+func newCooldownCollector(now func() time.Time) *cooldownCollector {
+	return &cooldownCollector{
+		desc: prometheus.NewDesc(
+			"parcels_api_rate_limit_cooldown_seconds",
+			"How long we are not going to fetch API, since it told us to back off",
+			[]string{"api_name"},
+			nil,
+		),
+		now:   now,
+		until: make(map[service.APIName]time.Time),
+	}
+}
+
+func (c *cooldownCollector) set(apiName service.APIName, until time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.until[apiName] = until
+}
+
+func (c *cooldownCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *cooldownCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for apiName, until := range c.until {
+		remaining := max(until.Sub(now), 0)
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, remaining.Seconds(), string(apiName))
+	}
+}
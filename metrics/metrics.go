@@ -1,11 +1,14 @@
 package metrics
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/dir01/parcels/service"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-func NewPrometheus() service.Metrics {
+func NewPrometheus() *PrometheusMetrics {
 	apiLabels := []string{"api_name"}
 
 	parcelDeliveredCounter := prometheus.NewCounter(prometheus.CounterOpts{
@@ -14,6 +17,12 @@ func NewPrometheus() service.Metrics {
 	})
 	prometheus.MustRegister(parcelDeliveredCounter)
 
+	parcelFinal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "parcels_parcel_final_total",
+		Help: "Parcel have reached terminal state (e.g. delivered or returned), no requests will hit APIs",
+	}, []string{"state"})
+	prometheus.MustRegister(parcelFinal)
+
 	fetchedChanged := prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "parcels_fetched_changed_total",
 		Help: "API have been fetched and API response does not match cached one",
@@ -80,8 +89,78 @@ func NewPrometheus() service.Metrics {
 	}, apiLabels)
 	prometheus.MustRegister(cacheHitAfterNotFoundError)
 
+	refreshQueueDepth := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "parcels_refresh_queue_depth",
+		Help: "How many parcels are due for a background refresh",
+	})
+	prometheus.MustRegister(refreshQueueDepth)
+
+	refreshLag := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "parcels_refresh_lag_seconds",
+		Help: "How long the most overdue parcel is waiting for a background refresh",
+	})
+	prometheus.MustRegister(refreshLag)
+
+	parcelsRefreshed := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "parcels_refreshed_total",
+		Help: "Parcels refreshed in background",
+	})
+	prometheus.MustRegister(parcelsRefreshed)
+
+	webhookDelivered := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "parcels_webhook_delivered_total",
+		Help: "Webhook notifications successfully delivered to subscribers",
+	})
+	prometheus.MustRegister(webhookDelivered)
+
+	webhookFailed := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "parcels_webhook_failed_total",
+		Help: "Webhook notification attempts that failed and will be retried",
+	})
+	prometheus.MustRegister(webhookFailed)
+
+	webhookDead := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "parcels_webhook_dead_total",
+		Help: "Webhook notifications we gave up on after too many attempts",
+	})
+	prometheus.MustRegister(webhookDead)
+
+	rateLimitCooldown := newCooldownCollector(time.Now)
+	prometheus.MustRegister(rateLimitCooldown)
+
+	circuitTransitions := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "parcels_circuit_state_transitions_total",
+		Help: "Circuit breaker around API changed its state",
+	}, []string{"api_name", "from", "to"})
+	prometheus.MustRegister(circuitTransitions)
+
+	circuitOpen := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "parcels_circuit_open",
+		Help: "Circuit breaker around API is open (1) or half-open (0.5), so API is not fetched",
+	}, apiLabels)
+	prometheus.MustRegister(circuitOpen)
+
+	unknownEventCode := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "parcels_unknown_event_code_total",
+		Help: "Freshly fetched API response has events with a code we don't know how to map to a status, see /admin/unknownCodes for which ones",
+	}, []string{"api_name"})
+	prometheus.MustRegister(unknownEventCode)
+
+	relevanceChangedByCountries := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "parcels_relevance_changed_by_countries_total",
+		Help: "Countries parcel goes between made us ask API we wouldn't ask otherwise (relevant=true), or not ask API we would (relevant=false)",
+	}, []string{"api_name", "relevant"})
+	prometheus.MustRegister(relevanceChangedByCountries)
+
+	responsesPruned := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "parcels_responses_pruned_total",
+		Help: "Stored API responses have been deleted according to retention policy",
+	}, []string{"policy"})
+	prometheus.MustRegister(responsesPruned)
+
 	return &PrometheusMetrics{
 		parcelDeliveredCounter:      parcelDeliveredCounter,
+		parcelFinal:                 parcelFinal,
 		fetchedChangedCounter:       fetchedChanged,
 		fetchedFirstCounter:         fetchedFirst,
 		fetchedUnchanged:            fetchedUnchanged,
@@ -93,11 +172,24 @@ func NewPrometheus() service.Metrics {
 		cacheHitAfterUnknownError:   cacheHitAfterUnknownError,
 		cacheBustAfterNotFoundError: cacheBustAfterNotFoundError,
 		cacheHitAfterNotFoundError:  cacheHitAfterNotFoundError,
+		refreshQueueDepth:           refreshQueueDepth,
+		refreshLag:                  refreshLag,
+		parcelsRefreshed:            parcelsRefreshed,
+		webhookDelivered:            webhookDelivered,
+		webhookFailed:               webhookFailed,
+		webhookDead:                 webhookDead,
+		rateLimitCooldown:           rateLimitCooldown,
+		circuitTransitions:          circuitTransitions,
+		circuitOpen:                 circuitOpen,
+		unknownEventCode:            unknownEventCode,
+		responsesPruned:             responsesPruned,
+		relevanceChangedByCountries: relevanceChangedByCountries,
 	}
 }
 
 type PrometheusMetrics struct {
 	parcelDeliveredCounter      prometheus.Counter
+	parcelFinal                 *prometheus.CounterVec
 	fetchedChangedCounter       *prometheus.CounterVec
 	fetchedFirstCounter         *prometheus.CounterVec
 	fetchedUnchanged            *prometheus.CounterVec
@@ -109,10 +201,25 @@ type PrometheusMetrics struct {
 	cacheHitAfterUnknownError   *prometheus.CounterVec
 	cacheBustAfterNotFoundError *prometheus.CounterVec
 	cacheHitAfterNotFoundError  *prometheus.CounterVec
+	refreshQueueDepth           prometheus.Gauge
+	refreshLag                  prometheus.Gauge
+	parcelsRefreshed            prometheus.Counter
+	webhookDelivered            prometheus.Counter
+	webhookFailed               prometheus.Counter
+	webhookDead                 prometheus.Counter
+	rateLimitCooldown           *cooldownCollector
+	circuitTransitions          *prometheus.CounterVec
+	circuitOpen                 *prometheus.GaugeVec
+	unknownEventCode            *prometheus.CounterVec
+	responsesPruned             *prometheus.CounterVec
+	relevanceChangedByCountries *prometheus.CounterVec
 }
 
-func (p *PrometheusMetrics) ParcelDelivered() {
-	p.parcelDeliveredCounter.Inc()
+func (p *PrometheusMetrics) ParcelFinal(state service.TerminalState) {
+	p.parcelFinal.WithLabelValues(string(state)).Inc()
+	if state == service.TerminalStateDelivered {
+		p.parcelDeliveredCounter.Inc()
+	}
 }
 
 func (p *PrometheusMetrics) FetchedChanged(apiName service.APIName) {
@@ -158,3 +265,55 @@ func (p *PrometheusMetrics) CacheBustAfterNotFoundError(apiName service.APIName,
 		p.cacheHitAfterNotFoundError.WithLabelValues(string(apiName)).Inc()
 	}
 }
+
+func (p *PrometheusMetrics) RefreshQueueDepth(depth int) {
+	p.refreshQueueDepth.Set(float64(depth))
+}
+
+func (p *PrometheusMetrics) RefreshLag(lag time.Duration) {
+	p.refreshLag.Set(lag.Seconds())
+}
+
+func (p *PrometheusMetrics) ParcelsRefreshed(count int) {
+	p.parcelsRefreshed.Add(float64(count))
+}
+
+func (p *PrometheusMetrics) WebhookDelivered() {
+	p.webhookDelivered.Inc()
+}
+
+func (p *PrometheusMetrics) WebhookFailed() {
+	p.webhookFailed.Inc()
+}
+
+func (p *PrometheusMetrics) WebhookDead() {
+	p.webhookDead.Inc()
+}
+
+func (p *PrometheusMetrics) RateLimitedUntil(apiName service.APIName, until time.Time) {
+	p.rateLimitCooldown.set(apiName, until)
+}
+
+func (p *PrometheusMetrics) CircuitStateChanged(apiName service.APIName, from string, to string) {
+	p.circuitTransitions.WithLabelValues(string(apiName), from, to).Inc()
+	switch to {
+	case "open":
+		p.circuitOpen.WithLabelValues(string(apiName)).Set(1)
+	case "half_open":
+		p.circuitOpen.WithLabelValues(string(apiName)).Set(0.5)
+	default:
+		p.circuitOpen.WithLabelValues(string(apiName)).Set(0)
+	}
+}
+
+func (p *PrometheusMetrics) UnknownEventCode(apiName service.APIName) {
+	p.unknownEventCode.WithLabelValues(string(apiName)).Inc()
+}
+
+func (p *PrometheusMetrics) RelevanceChangedByCountries(apiName service.APIName, relevant bool) {
+	p.relevanceChangedByCountries.WithLabelValues(string(apiName), strconv.FormatBool(relevant)).Inc()
+}
+
+func (p *PrometheusMetrics) ResponsesPruned(policy string, count int) {
+	p.responsesPruned.WithLabelValues(policy).Add(float64(count))
+}
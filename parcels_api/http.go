@@ -1,35 +1,72 @@
 package parcels_api
 
 import (
+	"cmp"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/subscriptions"
 	"github.com/hori-ryota/zaperr"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
-func NewServer(parcelsService service.Service, logger *zap.Logger) *HttpServer {
+// NewServer creates HTTP server. Routes under /admin/ require "Authorization: Bearer <adminToken>",
+// and are not served at all if adminToken is empty
+func NewServer(
+	parcelsService service.Service,
+	subscriptionsService subscriptions.Service,
+	adminToken string,
+	logger *zap.Logger,
+) *HttpServer {
 	return &HttpServer{
-		parcelsService: parcelsService,
-		logger:         logger,
+		parcelsService:       parcelsService,
+		subscriptionsService: subscriptionsService,
+		adminToken:           adminToken,
+		logger:               logger,
 	}
 }
 
 type HttpServer struct {
-	parcelsService service.Service
-	logger         *zap.Logger
+	parcelsService       service.Service
+	subscriptionsService subscriptions.Service
+	adminToken           string
+	logger               *zap.Logger
 }
 
 func (s *HttpServer) GetMux() *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/trackingInfo/", s.handleGetTrackingInfo)
+	mux.HandleFunc("/v2/trackingInfo/", s.handleGetTrackingInfoV2)
+	mux.HandleFunc("/trackingInfo/batch", s.handleGetTrackingInfoBatch)
+	mux.HandleFunc("/trackingInfo/history", s.handleGetHistory)
+	mux.HandleFunc("/subscriptions", s.handleCreateSubscription)
+	if s.adminToken != "" {
+		mux.HandleFunc("/admin/unknownCodes", s.requireAdmin(s.handleGetUnknownCodes))
+	}
 	mux.HandleFunc("/metrics", promhttp.Handler().ServeHTTP)
 	return mux
 }
 
+// handleGetTrackingInfo responds with a bare array of tracks from all the carriers, as it always has
 func (s *HttpServer) handleGetTrackingInfo(w http.ResponseWriter, r *http.Request) {
+	s.serveTrackingInfo(w, r, func(resp *TrackingInfoResponse) any { return resp.TrackingInfos })
+}
+
+// handleGetTrackingInfoV2 responds with TrackingInfoResponse: tracks along with carrier detection and merged timeline
+func (s *HttpServer) handleGetTrackingInfoV2(w http.ResponseWriter, r *http.Request) {
+	s.serveTrackingInfo(w, r, func(resp *TrackingInfoResponse) any { return resp })
+}
+
+// serveTrackingInfo looks up tracking number from query, and responds with whatever body picks from TrackingInfoResponse
+func (s *HttpServer) serveTrackingInfo(w http.ResponseWriter, r *http.Request, body func(*TrackingInfoResponse) any) {
 	trackingNumber := r.URL.Query().Get("trackingNumber")
 	if trackingNumber == "" {
 		w.WriteHeader(http.StatusBadRequest)
@@ -37,7 +74,8 @@ func (s *HttpServer) handleGetTrackingInfo(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	trackingInfos, err := s.parcelsService.GetTrackingInfo(r.Context(), trackingNumber)
+	language := requestLanguage(r)
+	trackingInfos, err := s.parcelsService.GetTrackingInfo(r.Context(), trackingNumber, language)
 	if err != nil {
 		s.logger.Error("failed to get tracking info", zaperr.ToField(err))
 		w.WriteHeader(http.StatusInternalServerError)
@@ -50,18 +88,241 @@ func (s *HttpServer) handleGetTrackingInfo(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	var httpTrackingInfos []*TrackingInfo
-	for _, t := range trackingInfos {
-		httpTrackingInfos = append(httpTrackingInfos, TrackingInfo{}.fromBusinessStruct(t))
+	detection := s.parcelsService.DetectCarriers(trackingNumber)
+	httpResp := TrackingInfoResponse{}.fromBusinessStructs(trackingNumber, trackingInfos, detection)
+
+	if respBytes, err := json.Marshal(body(httpResp)); err != nil {
+		s.logger.Error("failed to marshal response", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error", "message":"internal server error"}`))
+		return
+	} else {
+		w.Header().Set("Content-Language", language)
+		w.WriteHeader(http.StatusOK)
+		w.Write(respBytes)
+	}
+}
+
+// requestLanguage picks the language events are described in: lang query param if it's supported,
+// otherwise the most preferred supported language from Accept-Language header, otherwise the default one
+func requestLanguage(r *http.Request) string {
+	if language := service.NormalizeLanguage(r.URL.Query().Get("lang")); slices.Contains(service.Languages, language) {
+		return language
 	}
 
-	if respBytes, err := json.Marshal(httpTrackingInfos); err != nil {
+	type preference struct {
+		language string
+		quality  float64
+	}
+	var preferences []preference
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		p := preference{language: service.NormalizeLanguage(tag), quality: 1}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if quality, err := strconv.ParseFloat(q, 64); err == nil {
+				p.quality = quality
+			}
+		}
+		if p.quality > 0 && slices.Contains(service.Languages, p.language) {
+			preferences = append(preferences, p)
+		}
+	}
+	slices.SortStableFunc(preferences, func(a, b preference) int {
+		return cmp.Compare(b.quality, a.quality)
+	})
+	if len(preferences) > 0 {
+		return preferences[0].language
+	}
+	return service.DefaultLanguage
+}
+
+// maxBatchSize limits how many tracking numbers can be requested at once
+const maxBatchSize = 500
+
+func (s *HttpServer) handleGetTrackingInfoBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(`{"status":"error", "message":"only POST is allowed"}`))
+		return
+	}
+
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error", "message":"request body should be a JSON object with tracking_numbers list"}`))
+		return
+	}
+	if len(req.TrackingNumbers) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error", "message":"tracking_numbers is required"}`))
+		return
+	}
+	if len(req.TrackingNumbers) > maxBatchSize {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error", "message":"too many tracking_numbers"}`))
+		return
+	}
+
+	language := requestLanguage(r)
+	results, err := s.parcelsService.GetTrackingInfoBatch(r.Context(), req.TrackingNumbers, language)
+	if err != nil {
+		s.logger.Error("failed to get tracking info batch", zaperr.ToField(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error", "message":"internal server error"}`))
+		return
+	}
+
+	httpResp := BatchResponse{Results: make([]*BatchResult, 0, len(results))}
+	for _, result := range results {
+		httpResult := &BatchResult{
+			TrackingInfoResponse: TrackingInfoResponse{}.fromBusinessStructs(
+				result.TrackingNumber,
+				result.TrackingInfos,
+				s.parcelsService.DetectCarriers(result.TrackingNumber),
+			),
+		}
+		switch {
+		case result.Err != nil:
+			s.logger.Error(
+				"failed to get tracking info within a batch",
+				zap.String("trackingNumber", result.TrackingNumber),
+				zaperr.ToField(result.Err),
+			)
+			httpResult.Status = "error"
+			httpResult.Error = "failed to get tracking info"
+		case len(result.TrackingInfos) == 0:
+			httpResult.Status = "not_found"
+		default:
+			httpResult.Status = "ok"
+		}
+		httpResp.Results = append(httpResp.Results, httpResult)
+	}
+
+	if respBytes, err := json.Marshal(httpResp); err != nil {
 		s.logger.Error("failed to marshal response", zap.Error(err))
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"status":"error", "message":"internal server error"}`))
 		return
 	} else {
+		w.Header().Set("Content-Language", language)
 		w.WriteHeader(http.StatusOK)
 		w.Write(respBytes)
 	}
 }
+
+func (s *HttpServer) handleGetHistory(w http.ResponseWriter, r *http.Request) {
+	trackingNumber := r.URL.Query().Get("trackingNumber")
+	if trackingNumber == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error", "message":"trackingNumber query param is required"}`))
+		return
+	}
+
+	histories, err := s.parcelsService.GetHistory(r.Context(), trackingNumber)
+	if err != nil {
+		s.logger.Error("failed to get history", zaperr.ToField(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error", "message":"internal server error"}`))
+		return
+	}
+	if len(histories) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status":"error", "message":"history not found"}`))
+		return
+	}
+
+	if respBytes, err := json.Marshal(HistoryResponse{}.fromBusinessStructs(trackingNumber, histories)); err != nil {
+		s.logger.Error("failed to marshal response", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error", "message":"internal server error"}`))
+		return
+	} else {
+		w.WriteHeader(http.StatusOK)
+		w.Write(respBytes)
+	}
+}
+
+// requireAdmin only lets requests bearing admin token through to handler
+func (s *HttpServer) requireAdmin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"error", "message":"admin token required"}`))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func (s *HttpServer) handleGetUnknownCodes(w http.ResponseWriter, r *http.Request) {
+	report, err := s.parcelsService.GetUnknownCodes(r.Context())
+	if err != nil {
+		s.logger.Error("failed to get unknown codes", zaperr.ToField(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error", "message":"internal server error"}`))
+		return
+	}
+
+	if respBytes, err := json.Marshal(UnknownCodesResponse{}.fromBusinessStructs(report)); err != nil {
+		s.logger.Error("failed to marshal response", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error", "message":"internal server error"}`))
+		return
+	} else {
+		w.WriteHeader(http.StatusOK)
+		w.Write(respBytes)
+	}
+}
+
+func (s *HttpServer) handleCreateSubscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(`{"status":"error", "message":"only POST is allowed"}`))
+		return
+	}
+
+	var req SubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error", "message":"request body should be a JSON object with tracking_number and callback_url"}`))
+		return
+	}
+	if req.TrackingNumber == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error", "message":"tracking_number is required"}`))
+		return
+	}
+	if u, err := url.Parse(req.CallbackURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error", "message":"callback_url should be an absolute http(s) URL"}`))
+		return
+	}
+	if err := subscriptions.CheckCallbackURL(r.Context(), req.CallbackURL); errors.Is(err, subscriptions.ErrForbiddenAddress) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error", "message":"callback_url should point to a public address"}`))
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error", "message":"callback_url host could not be resolved"}`))
+		return
+	}
+
+	subscription, err := s.subscriptionsService.Subscribe(r.Context(), req.TrackingNumber, req.CallbackURL)
+	if err != nil {
+		s.logger.Error("failed to subscribe", zaperr.ToField(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error", "message":"internal server error"}`))
+		return
+	}
+
+	if respBytes, err := json.Marshal(Subscription{}.fromBusinessStruct(subscription)); err != nil {
+		s.logger.Error("failed to marshal response", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error", "message":"internal server error"}`))
+		return
+	} else {
+		w.WriteHeader(http.StatusCreated)
+		w.Write(respBytes)
+	}
+}
@@ -4,29 +4,144 @@ import (
 	"time"
 
 	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/subscriptions"
 )
 
+// TrackingInfoResponse is a response to a tracking info request:
+// tracks from all the carriers, and how we figured out which carriers to ask
+type TrackingInfoResponse struct {
+	TrackingNumber string           `json:"tracking_number"`
+	Detection      CarrierDetection `json:"detection"`
+	TrackingInfos  []*TrackingInfo  `json:"tracking_infos"`
+	Merged         *MergedTimeline  `json:"merged"`
+}
+
+// BatchRequest is a request for tracking info of many parcels at once
+type BatchRequest struct {
+	TrackingNumbers []string `json:"tracking_numbers"`
+}
+
+// BatchResponse contains a result for each of the requested tracking numbers
+type BatchResponse struct {
+	Results []*BatchResult `json:"results"`
+}
+
+// BatchResult is a result of tracking a single parcel within a batch.
+// Status is one of "ok", "not_found" or "error"
+type BatchResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	*TrackingInfoResponse
+}
+
+// CarrierDetection represents tracking number classification
+type CarrierDetection struct {
+	Formats    []FormatMatch      `json:"formats"`
+	Candidates []CarrierCandidate `json:"candidates"`
+}
+
+// FormatMatch represents a single tracking number format the tracking number looks like
+type FormatMatch struct {
+	Format             string `json:"format"`
+	CheckDigitVerified bool   `json:"check_digit_verified"`
+	CountryCode        string `json:"country_code,omitempty"`
+}
+
+// CarrierCandidate represents a carrier that is likely to know about the tracking number
+type CarrierCandidate struct {
+	ApiName service.APIName `json:"api_name"`
+	Score   int             `json:"score"`
+}
+
 // TrackingInfo represents a single track of a parcel according to one carrier in an API response
 type TrackingInfo struct {
 	TrackingNumber string          `json:"tracking_number"`
 	ApiName        service.APIName `json:"api_name"`
-	IsDelivered    bool            `json:"is_delivered"`
-	LastCheckedAt  string          `json:"last_checked_at"`
-	LastUpdatedAt  string          `json:"last_updated_at"`
-	Events         []TrackingEvent `json:"events"`
+	// OriginCountry and DestinationCountry are ISO 3166-1 alpha-2 codes, raw ones are what carrier said
+	OriginCountry         string          `json:"origin_country,omitempty"`
+	OriginCountryRaw      string          `json:"origin_country_raw,omitempty"`
+	DestinationCountry    string          `json:"destination_country,omitempty"`
+	DestinationCountryRaw string          `json:"destination_country_raw,omitempty"`
+	IsDelivered           bool            `json:"is_delivered"` // picked up parcels as well, see terminal_state
+	TerminalState         string          `json:"terminal_state,omitempty"`
+	Phase                 string          `json:"phase,omitempty"`
+	Progress              int             `json:"progress"`
+	LastCheckedAt         string          `json:"last_checked_at"`
+	LastUpdatedAt         string          `json:"last_updated_at"`
+	Events                []TrackingEvent `json:"events"`
+	ETA                   *ETA            `json:"eta,omitempty"`
+}
+
+// ETA is a time window parcel is expected to be delivered within.
+// Source is "carrier" if carrier told us, or "history" if it's estimated from parcels delivered before
+type ETA struct {
+	Earliest string `json:"earliest"`
+	Latest   string `json:"latest"`
+	Source   string `json:"source"`
 }
 
 // TrackingEvent represents a single event in a parcel's track
 type TrackingEvent struct {
-	Time        string `json:"time"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
+	Time           string    `json:"time"`
+	Description    string    `json:"description"`
+	Status         string    `json:"status"`
+	Location       *Location `json:"location,omitempty"`
+	RawCode        string    `json:"raw_code,omitempty"`
+	RawDescription string    `json:"raw_description,omitempty"`
+}
+
+// Location is where event happened, as precise as carrier tells it. Country is ISO 3166-1 alpha-2 code
+type Location struct {
+	City     string `json:"city,omitempty"`
+	Country  string `json:"country,omitempty"`
+	Facility string `json:"facility,omitempty"`
+}
+
+// MergedTimeline is a single timeline built from tracks of all the carriers
+type MergedTimeline struct {
+	CurrentStatus string        `json:"current_status"`
+	IsDelivered   bool          `json:"is_delivered"`
+	TerminalState string        `json:"terminal_state,omitempty"`
+	Events        []MergedEvent `json:"events"`
+}
+
+// MergedEvent is an event of a merged timeline, along with carriers that reported it
+type MergedEvent struct {
+	Time        string            `json:"time"`
+	Description string            `json:"description"`
+	Status      string            `json:"status"`
+	Location    *Location         `json:"location,omitempty"`
+	ApiNames    []service.APIName `json:"api_names"`
+}
+
+func (hmt MergedTimeline) fromBusinessStruct(t *service.MergedTimeline) *MergedTimeline {
+	hmt.CurrentStatus = string(t.CurrentStatus)
+	hmt.IsDelivered = t.IsDelivered
+	hmt.TerminalState = string(t.TerminalState)
+	hmt.Events = []MergedEvent{}
+	for _, e := range t.Events {
+		hmt.Events = append(hmt.Events, MergedEvent{
+			Time:        e.Time.Format(time.RFC3339),
+			Description: e.Description,
+			Status:      string(e.Status),
+			Location:    fromBusinessLocation(e.Location),
+			ApiNames:    e.APINames,
+		})
+	}
+	return &hmt
 }
 
 func (hti TrackingInfo) fromBusinessStruct(t *service.TrackingInfo) *TrackingInfo {
 	hti.TrackingNumber = t.TrackingNumber
 	hti.ApiName = t.APIName
+	hti.OriginCountry = t.OriginCountry
+	hti.OriginCountryRaw = t.OriginCountryRaw
+	hti.DestinationCountry = t.DestinationCountry
+	hti.DestinationCountryRaw = t.DestinationCountryRaw
 	hti.IsDelivered = t.IsDelivered()
+	hti.TerminalState = string(t.TerminalState())
+	hti.Phase = string(t.CurrentPhase())
+	hti.Progress = t.Progress()
 	hti.LastCheckedAt = t.LastFetchedAt.Format(time.RFC3339)
 	maxTime := time.Time{}
 	for _, e := range t.Events {
@@ -34,11 +149,181 @@ func (hti TrackingInfo) fromBusinessStruct(t *service.TrackingInfo) *TrackingInf
 			maxTime = e.Time
 		}
 		hti.Events = append(hti.Events, TrackingEvent{
-			Time:        e.Time.Format(time.RFC3339),
-			Description: e.Description,
-			Status:      string(e.Status),
+			Time:           e.Time.Format(time.RFC3339),
+			Description:    e.Description,
+			Status:         string(e.Status),
+			Location:       fromBusinessLocation(e.Location),
+			RawCode:        e.RawCode,
+			RawDescription: e.RawDescription,
 		})
 	}
 	hti.LastUpdatedAt = maxTime.Format(time.RFC3339)
+	if t.ETA != nil {
+		hti.ETA = &ETA{
+			Earliest: t.ETA.Earliest.Format(time.RFC3339),
+			Latest:   t.ETA.Latest.Format(time.RFC3339),
+			Source:   string(t.ETA.Source),
+		}
+	}
 	return &hti
 }
+
+func (r TrackingInfoResponse) fromBusinessStructs(
+	trackingNumber string,
+	trackingInfos []*service.TrackingInfo,
+	detection *service.CarrierDetection,
+) *TrackingInfoResponse {
+	r.TrackingNumber = trackingNumber
+	r.Detection.Formats = []FormatMatch{}
+	for _, f := range detection.Formats {
+		r.Detection.Formats = append(r.Detection.Formats, FormatMatch{
+			Format:             string(f.Format),
+			CheckDigitVerified: f.CheckDigitVerified,
+			CountryCode:        f.CountryCode,
+		})
+	}
+	r.Detection.Candidates = []CarrierCandidate{}
+	for _, c := range detection.Candidates {
+		r.Detection.Candidates = append(r.Detection.Candidates, CarrierCandidate{
+			ApiName: c.APIName,
+			Score:   c.Score,
+		})
+	}
+	for _, t := range trackingInfos {
+		r.TrackingInfos = append(r.TrackingInfos, TrackingInfo{}.fromBusinessStruct(t))
+	}
+	r.Merged = MergedTimeline{}.fromBusinessStruct(service.MergeTimeline(trackingInfos, service.DefaultMergeTolerance))
+	return &r
+}
+
+// HistoryResponse is every stored response for a parcel, grouped by API
+type HistoryResponse struct {
+	TrackingNumber string        `json:"tracking_number"`
+	APIs           []*APIHistory `json:"apis"`
+}
+
+// APIHistory is every stored response of a single API, oldest first
+type APIHistory struct {
+	ApiName   service.APIName    `json:"api_name"`
+	Snapshots []*HistorySnapshot `json:"snapshots"`
+}
+
+// HistorySnapshot is a stored response, parsed with the current parser,
+// and how its events differ from the previous successfully parsed response
+type HistorySnapshot struct {
+	FirstFetchedAt string          `json:"first_fetched_at"`
+	LastFetchedAt  string          `json:"last_fetched_at"`
+	Status         string          `json:"status"`
+	TrackingInfo   *TrackingInfo   `json:"tracking_info"`
+	ParseError     string          `json:"parse_error,omitempty"`
+	AddedEvents    []TrackingEvent `json:"added_events"`
+	RemovedEvents  []TrackingEvent `json:"removed_events"`
+}
+
+func (r HistoryResponse) fromBusinessStructs(trackingNumber string, histories []*service.APIHistory) *HistoryResponse {
+	r.TrackingNumber = trackingNumber
+	r.APIs = []*APIHistory{}
+	for _, h := range histories {
+		apiHistory := &APIHistory{ApiName: h.APIName, Snapshots: []*HistorySnapshot{}}
+		for _, snapshot := range h.Snapshots {
+			apiHistory.Snapshots = append(apiHistory.Snapshots, HistorySnapshot{}.fromBusinessStruct(snapshot))
+		}
+		r.APIs = append(r.APIs, apiHistory)
+	}
+	return &r
+}
+
+func (hs HistorySnapshot) fromBusinessStruct(s *service.HistorySnapshot) *HistorySnapshot {
+	hs.FirstFetchedAt = s.Response.FirstFetchedAt.Format(time.RFC3339)
+	hs.LastFetchedAt = s.Response.LastFetchedAt.Format(time.RFC3339)
+	hs.Status = string(s.Response.Status)
+	if s.TrackingInfo != nil {
+		hs.TrackingInfo = TrackingInfo{}.fromBusinessStruct(s.TrackingInfo)
+	}
+	if s.ParseError != nil {
+		hs.ParseError = s.ParseError.Error()
+	}
+	hs.AddedEvents = fromBusinessEvents(s.AddedEvents)
+	hs.RemovedEvents = fromBusinessEvents(s.RemovedEvents)
+	return &hs
+}
+
+func fromBusinessEvents(events []service.TrackingEvent) []TrackingEvent {
+	result := []TrackingEvent{}
+	for _, e := range events {
+		result = append(result, TrackingEvent{
+			Time:           e.Time.Format(time.RFC3339),
+			Description:    e.Description,
+			Status:         string(e.Status),
+			Location:       fromBusinessLocation(e.Location),
+			RawCode:        e.RawCode,
+			RawDescription: e.RawDescription,
+		})
+	}
+	return result
+}
+
+// UnknownCodesResponse lists carrier codes we don't know how to map to a status yet
+type UnknownCodesResponse struct {
+	UnknownCodes []*UnknownCode `json:"unknown_codes"`
+	// RefreshedAt is when stored responses were last looked through for unknown codes, null if not yet
+	RefreshedAt *time.Time `json:"refreshed_at"`
+}
+
+// UnknownCode is a carrier code we don't know how to map to a status yet, and parcels that had it
+type UnknownCode struct {
+	ApiName               service.APIName `json:"api_name"`
+	Code                  string          `json:"code"`
+	SampleDescription     string          `json:"sample_description"`
+	TrackingNumbers       int             `json:"tracking_numbers"`
+	SampleTrackingNumbers []string        `json:"sample_tracking_numbers"`
+}
+
+func (r UnknownCodesResponse) fromBusinessStructs(report *service.UnknownCodesReport) *UnknownCodesResponse {
+	r.UnknownCodes = []*UnknownCode{}
+	if !report.RefreshedAt.IsZero() {
+		r.RefreshedAt = &report.RefreshedAt
+	}
+	for _, c := range report.Codes {
+		r.UnknownCodes = append(r.UnknownCodes, &UnknownCode{
+			ApiName:               c.APIName,
+			Code:                  c.Code,
+			SampleDescription:     c.SampleDescription,
+			TrackingNumbers:       c.TrackingNumbers,
+			SampleTrackingNumbers: c.SampleTrackingNumbers,
+		})
+	}
+	return &r
+}
+
+// SubscriptionRequest is a request to be notified about parcel changes
+type SubscriptionRequest struct {
+	TrackingNumber string `json:"tracking_number"`
+	CallbackURL    string `json:"callback_url"`
+}
+
+// Subscription represents a created subscription.
+// Secret is only ever shown here, subscriber should use it to verify payload signatures
+type Subscription struct {
+	ID             int64  `json:"id"`
+	TrackingNumber string `json:"tracking_number"`
+	CallbackURL    string `json:"callback_url"`
+	Secret         string `json:"secret"`
+	CreatedAt      string `json:"created_at"`
+}
+
+func (hs Subscription) fromBusinessStruct(s *subscriptions.Subscription) *Subscription {
+	hs.ID = s.ID
+	hs.TrackingNumber = s.TrackingNumber
+	hs.CallbackURL = s.CallbackURL
+	hs.Secret = s.Secret
+	hs.CreatedAt = s.CreatedAt.Format(time.RFC3339)
+	return &hs
+}
+
+func fromBusinessLocation(l *service.Location) *Location {
+	if l == nil {
+		return nil
+	}
+	return &Location{City: l.City, Country: l.Country, Facility: l.Facility}
+}
@@ -0,0 +1,11 @@
+package postgres_storage
+
+import (
+	"github.com/dir01/parcels/retention"
+	"github.com/dir01/parcels/retention/sqlstorage"
+	"github.com/jmoiron/sqlx"
+)
+
+func NewRetentionStorage(db *sqlx.DB) retention.Storage {
+	return sqlstorage.New(db, sqlstorage.DefaultBatchSize)
+}
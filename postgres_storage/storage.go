@@ -0,0 +1,461 @@
+package postgres_storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dir01/parcels/service"
+	"github.com/hori-ryota/zaperr"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// uniqueViolation is the postgres error code for unique constraint violation
+const uniqueViolation = "23505"
+
+// maxUpsertAttempts is how many times Upsert is retried when it loses a race to another replica
+const maxUpsertAttempts = 3
+
+func NewStorage(db *sqlx.DB) service.Storage {
+	return &postgresStorage{db: db}
+}
+
+type postgresStorage struct {
+	db *sqlx.DB
+}
+
+func (s postgresStorage) GetLatest(
+	ctx context.Context,
+	trackingNumber string,
+	apiNames []service.APIName,
+) ([]*service.PostalApiResponse, error) {
+	zapFields := []zap.Field{
+		zap.String("trackingNumber", trackingNumber),
+		zap.Any("apiNames", apiNames),
+	}
+	var dbStructs []DBRawPostalApiResponse
+	err := s.db.SelectContext(ctx, &dbStructs, `
+		SELECT *
+		FROM postal_api_responses
+		WHERE tracking_number = $1
+		AND api_name = ANY($2)
+		AND is_latest
+	`, trackingNumber, apiNamesArray(apiNames))
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zapFields...)
+	}
+	return toBusinessModels(dbStructs), nil
+}
+
+func (s postgresStorage) GetLatestBatch(
+	ctx context.Context,
+	trackingNumbers []string,
+	apiNames []service.APIName,
+) ([]*service.PostalApiResponse, error) {
+	if len(trackingNumbers) == 0 || len(apiNames) == 0 {
+		return nil, nil
+	}
+	zapFields := []zap.Field{
+		zap.Strings("trackingNumbers", trackingNumbers),
+		zap.Any("apiNames", apiNames),
+	}
+	var dbStructs []DBRawPostalApiResponse
+	err := s.db.SelectContext(ctx, &dbStructs, `
+		SELECT *
+		FROM postal_api_responses
+		WHERE tracking_number = ANY($1)
+		AND api_name = ANY($2)
+		AND is_latest
+	`, pq.Array(trackingNumbers), apiNamesArray(apiNames))
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zapFields...)
+	}
+	return toBusinessModels(dbStructs), nil
+}
+
+// dueForRefreshCondition matches responses due for refresh, given dueForRefreshArgs as $1..$8
+const dueForRefreshCondition = `
+		api_name = ANY($1)
+		AND is_latest
+		AND last_fetched_at > $2
+		AND NOT is_final
+		AND (
+			(status = $3 AND last_fetched_at <= $4)
+			OR (status = $5 AND last_fetched_at <= $6)
+			OR (status = $7 AND last_fetched_at <= $8)
+		)
+`
+
+func dueForRefreshArgs(query service.RefreshQuery) []any {
+	return []any{
+		apiNamesArray(query.APINames),
+		toUnixTime(query.NotExpiredSince),
+		service.StatusSuccess, toUnixTime(query.SuccessFetchedBefore),
+		service.StatusNotFound, toUnixTime(query.NotFoundFetchedBefore),
+		service.StatusUnknownError, toUnixTime(query.UnknownErrorFetchedBefore),
+	}
+}
+
+func (s postgresStorage) GetDueForRefresh(
+	ctx context.Context,
+	query service.RefreshQuery,
+) ([]*service.PostalApiResponse, error) {
+	if len(query.APINames) == 0 {
+		return nil, nil
+	}
+	var dbStructs []DBDueResponse
+	// response is overdue by how long ago it passed the moment it should have been fetched before
+	err := s.db.SelectContext(ctx, &dbStructs, `
+		SELECT tracking_number, api_name, status, last_fetched_at
+		FROM postal_api_responses
+		WHERE `+dueForRefreshCondition+`
+		ORDER BY CASE status
+			WHEN $3 THEN last_fetched_at - $4
+			WHEN $5 THEN last_fetched_at - $6
+			ELSE last_fetched_at - $8
+		END
+		LIMIT $9
+	`, append(dueForRefreshArgs(query), query.Limit)...)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zap.Any("query", query))
+	}
+	var businessStructs []*service.PostalApiResponse
+	for _, dbStruct := range dbStructs {
+		businessStructs = append(businessStructs, dbStruct.ToBusinessModel())
+	}
+	return businessStructs, nil
+}
+
+func (s postgresStorage) CountDueForRefresh(ctx context.Context, query service.RefreshQuery) (int, error) {
+	if len(query.APINames) == 0 {
+		return 0, nil
+	}
+	var count int
+	err := s.db.GetContext(ctx, &count, `
+		SELECT COUNT(DISTINCT tracking_number)
+		FROM postal_api_responses
+		WHERE `+dueForRefreshCondition, dueForRefreshArgs(query)...)
+	if err != nil {
+		return 0, zaperr.Wrap(err, "failed to GetContext", zap.Any("query", query))
+	}
+	return count, nil
+}
+
+func (s postgresStorage) Insert(ctx context.Context, trackingNumber string, apiName service.APIName, response *service.PostalApiResponse) error {
+	dbStruct := DBRawPostalApiResponse{}.FromBusinessModel(response)
+	dbStruct.TrackingNumber = trackingNumber
+	dbStruct.APIName = apiName
+	zapFields := []zap.Field{zap.Any("dbStruct", dbStruct)}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to BeginTxx", zapFields...)
+	}
+	defer tx.Rollback()
+
+	if err := s.insert(ctx, tx, dbStruct); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return zaperr.Wrap(err, "failed to Commit", zapFields...)
+	}
+	response.ID = dbStruct.ID
+	return nil
+}
+
+func (s postgresStorage) Upsert(ctx context.Context, trackingNumber string, apiName service.APIName, response *service.PostalApiResponse) error {
+	dbStruct := DBRawPostalApiResponse{}.FromBusinessModel(response)
+	dbStruct.TrackingNumber = trackingNumber
+	dbStruct.APIName = apiName
+
+	for attempt := 1; ; attempt++ {
+		err := s.upsert(ctx, dbStruct)
+		// another replica has just stored the latest response, next attempt will see it
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && attempt < maxUpsertAttempts {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		response.ID = dbStruct.ID
+		response.FirstFetchedAt = fromUnixTime(dbStruct.FirstFetchedAt)
+		return nil
+	}
+}
+
+func (s postgresStorage) upsert(ctx context.Context, dbStruct *DBRawPostalApiResponse) error {
+	zapFields := []zap.Field{zap.Any("dbStruct", dbStruct)}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to BeginTxx", zapFields...)
+	}
+	defer tx.Rollback()
+
+	var latest DBRawPostalApiResponse
+	err = tx.GetContext(ctx, &latest, `
+		SELECT id, first_fetched_at, response_hash
+		FROM postal_api_responses
+		WHERE tracking_number = $1 AND api_name = $2 AND is_latest
+		FOR UPDATE
+	`, dbStruct.TrackingNumber, dbStruct.APIName)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) || (err == nil && latest.ResponseHash != dbStruct.ResponseHash):
+		if err := s.insert(ctx, tx, dbStruct); err != nil {
+			return err
+		}
+	case err == nil:
+		// someone has just stored the very same response, so we only need to refresh it
+		_, err := tx.ExecContext(ctx, `
+			UPDATE postal_api_responses
+			SET last_fetched_at = GREATEST(last_fetched_at, $1), status = $2, is_final = $3
+			WHERE id = $4
+		`, dbStruct.LastFetchedAt, dbStruct.Status, dbStruct.IsFinal, latest.ID)
+		if err != nil {
+			return zaperr.Wrap(err, "failed to update latest response", zapFields...)
+		}
+		dbStruct.ID = latest.ID
+		dbStruct.FirstFetchedAt = latest.FirstFetchedAt
+	default:
+		return zaperr.Wrap(err, "failed to get latest response", zapFields...)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return zaperr.Wrap(err, "failed to Commit", zapFields...)
+	}
+	return nil
+}
+
+// insert appends response to the history and makes the most recently fetched response the latest one
+func (s postgresStorage) insert(ctx context.Context, tx *sqlx.Tx, dbStruct *DBRawPostalApiResponse) error {
+	zapFields := []zap.Field{zap.Any("dbStruct", dbStruct)}
+	err := tx.GetContext(ctx, &dbStruct.ID, `
+		INSERT INTO postal_api_responses
+		    (api_name, tracking_number, first_fetched_at, last_fetched_at, response_body, response_hash, status, is_final)
+		VALUES
+		    ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`,
+		dbStruct.APIName, dbStruct.TrackingNumber, dbStruct.FirstFetchedAt, dbStruct.LastFetchedAt,
+		dbStruct.ResponseBody, dbStruct.ResponseHash, dbStruct.Status, dbStruct.IsFinal,
+	)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to insert response", zapFields...)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE postal_api_responses
+		SET is_latest = FALSE
+		WHERE tracking_number = $1 AND api_name = $2 AND is_latest
+	`, dbStruct.TrackingNumber, dbStruct.APIName)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to unmark latest response", zapFields...)
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE postal_api_responses
+		SET is_latest = TRUE
+		WHERE id = (
+			SELECT id
+			FROM postal_api_responses
+			WHERE tracking_number = $1 AND api_name = $2
+			ORDER BY last_fetched_at DESC, id DESC
+			LIMIT 1
+		)
+	`, dbStruct.TrackingNumber, dbStruct.APIName)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to mark latest response", zapFields...)
+	}
+	return nil
+}
+
+func (s postgresStorage) Update(ctx context.Context, response *service.PostalApiResponse) error {
+	dbStruct := DBRawPostalApiResponse{}.FromBusinessModel(response)
+	_, err := s.db.NamedExecContext(ctx, `
+		UPDATE postal_api_responses
+		SET api_name = :api_name,
+		    tracking_number = :tracking_number,
+		    first_fetched_at = :first_fetched_at,
+		    last_fetched_at = :last_fetched_at,
+		    response_body = :response_body,
+		    response_hash = :response_hash,
+		    status = :status,
+		    is_final = :is_final
+		WHERE id = :id
+	`, dbStruct)
+
+	if err != nil {
+		return zaperr.Wrap(err, "failed to NamedExecContext", zap.Any("dbStruct", dbStruct))
+	}
+	return nil
+}
+
+func (s postgresStorage) GetHistory(
+	ctx context.Context,
+	trackingNumber string,
+	apiName service.APIName,
+) ([]*service.PostalApiResponse, error) {
+	zapFields := []zap.Field{
+		zap.String("trackingNumber", trackingNumber),
+		zap.String("apiName", string(apiName)),
+	}
+	var dbStructs []DBRawPostalApiResponse
+	err := s.db.SelectContext(ctx, &dbStructs, `
+		SELECT *
+		FROM postal_api_responses
+		WHERE tracking_number = $1 AND api_name = $2
+		ORDER BY first_fetched_at, id
+	`, trackingNumber, apiName)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zapFields...)
+	}
+	return toBusinessModels(dbStructs), nil
+}
+
+func (s postgresStorage) GetResponsesAfter(
+	ctx context.Context,
+	apiName service.APIName,
+	afterID int64,
+	limit int,
+) ([]*service.PostalApiResponse, error) {
+	zapFields := []zap.Field{
+		zap.String("apiName", string(apiName)),
+		zap.Int64("afterID", afterID),
+		zap.Int("limit", limit),
+	}
+	var dbStructs []DBRawPostalApiResponse
+	err := s.db.SelectContext(ctx, &dbStructs, `
+		SELECT *
+		FROM postal_api_responses
+		WHERE api_name = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`, apiName, afterID, limit)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zapFields...)
+	}
+	return toBusinessModels(dbStructs), nil
+}
+
+func (s postgresStorage) GetFinalResponsesAfter(
+	ctx context.Context,
+	apiName service.APIName,
+	afterID int64,
+	limit int,
+) ([]*service.PostalApiResponse, error) {
+	zapFields := []zap.Field{
+		zap.String("apiName", string(apiName)),
+		zap.Int64("afterID", afterID),
+		zap.Int("limit", limit),
+	}
+	var dbStructs []DBRawPostalApiResponse
+	err := s.db.SelectContext(ctx, &dbStructs, `
+		SELECT *
+		FROM postal_api_responses
+		WHERE api_name = $1 AND id > $2
+		AND is_latest
+		AND is_final
+		AND status = 'success'
+		ORDER BY id
+		LIMIT $3
+	`, apiName, afterID, limit)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zapFields...)
+	}
+	return toBusinessModels(dbStructs), nil
+}
+
+func (s postgresStorage) GetLinks(ctx context.Context, trackingNumber string) ([]string, error) {
+	var linked []string
+	err := s.db.SelectContext(ctx, &linked, `
+		SELECT linked_tracking_number
+		FROM tracking_number_links
+		WHERE tracking_number = $1
+		ORDER BY id
+	`, trackingNumber)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zap.String("trackingNumber", trackingNumber))
+	}
+	return linked, nil
+}
+
+func (s postgresStorage) InsertLinks(ctx context.Context, trackingNumber string, linkedTrackingNumbers []string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO tracking_number_links (tracking_number, linked_tracking_number)
+		SELECT $1, linked
+		FROM unnest($2::text[]) WITH ORDINALITY AS l(linked, ord)
+		ORDER BY ord
+		ON CONFLICT DO NOTHING
+	`, trackingNumber, pq.Array(linkedTrackingNumbers))
+	if err != nil {
+		return zaperr.Wrap(
+			err, "failed to ExecContext",
+			zap.String("trackingNumber", trackingNumber),
+			zap.Strings("linkedTrackingNumbers", linkedTrackingNumbers),
+		)
+	}
+	return nil
+}
+
+// apiNamesArray binds api names as a postgres text[], to be used with `= ANY(...)`
+func apiNamesArray(apiNames []service.APIName) interface{} {
+	strs := make([]string, len(apiNames))
+	for i, apiName := range apiNames {
+		strs[i] = string(apiName)
+	}
+	return pq.Array(strs)
+}
+
+func toBusinessModels(dbStructs []DBRawPostalApiResponse) []*service.PostalApiResponse {
+	var businessStructs []*service.PostalApiResponse
+	for _, dbStruct := range dbStructs {
+		businessStructs = append(businessStructs, dbStruct.ToBusinessModel())
+	}
+	return businessStructs
+}
+
+func (s postgresStorage) GetLocalized(
+	ctx context.Context,
+	trackingNumber string,
+	apiName service.APIName,
+	language string,
+) (*service.PostalApiResponse, error) {
+	zapFields := []zap.Field{
+		zap.String("trackingNumber", trackingNumber),
+		zap.String("apiName", string(apiName)),
+		zap.String("language", language),
+	}
+	var dbStruct DBLocalizedResponse
+	err := s.db.GetContext(ctx, &dbStruct, `
+		SELECT *
+		FROM localized_responses
+		WHERE tracking_number = $1 AND api_name = $2 AND language = $3
+	`, trackingNumber, apiName, language)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to GetContext", zapFields...)
+	}
+	return dbStruct.ToBusinessModel(), nil
+}
+
+func (s postgresStorage) UpsertLocalized(ctx context.Context, response *service.PostalApiResponse) error {
+	dbStruct := DBLocalizedResponse{}.FromBusinessModel(response)
+	_, err := s.db.NamedExecContext(ctx, `
+		INSERT INTO localized_responses
+		    (tracking_number, api_name, language, fetched_at, response_body, status)
+		VALUES
+		    (:tracking_number, :api_name, :language, :fetched_at, :response_body, :status)
+		ON CONFLICT (tracking_number, api_name, language) DO UPDATE
+		SET fetched_at = excluded.fetched_at,
+		    response_body = excluded.response_body,
+		    status = excluded.status
+	`, dbStruct)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to NamedExecContext", zap.Any("dbStruct", dbStruct))
+	}
+	return nil
+}
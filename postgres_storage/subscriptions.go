@@ -0,0 +1,154 @@
+package postgres_storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/dir01/parcels/subscriptions"
+	"github.com/hori-ryota/zaperr"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+func NewSubscriptionsStorage(db *sqlx.DB) subscriptions.Storage {
+	return &postgresSubscriptionsStorage{db: db}
+}
+
+type postgresSubscriptionsStorage struct {
+	db *sqlx.DB
+}
+
+func (s postgresSubscriptionsStorage) CreateSubscription(ctx context.Context, subscription *subscriptions.Subscription) error {
+	dbStruct := DBSubscription{}.FromBusinessModel(subscription)
+	err := s.db.GetContext(ctx, &subscription.ID, `
+		INSERT INTO subscriptions
+		    (tracking_number, callback_url, secret, created_at)
+		VALUES
+		    ($1, $2, $3, $4)
+		RETURNING id
+	`, dbStruct.TrackingNumber, dbStruct.CallbackURL, dbStruct.Secret, dbStruct.CreatedAt)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to GetContext", zap.String("trackingNumber", subscription.TrackingNumber))
+	}
+	return nil
+}
+
+func (s postgresSubscriptionsStorage) GetSubscriptions(ctx context.Context, trackingNumber string) ([]*subscriptions.Subscription, error) {
+	var dbStructs []DBSubscription
+	err := s.db.SelectContext(ctx, &dbStructs, `
+		SELECT *
+		FROM subscriptions
+		WHERE tracking_number = $1
+		ORDER BY id
+	`, trackingNumber)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zap.String("trackingNumber", trackingNumber))
+	}
+
+	var businessStructs []*subscriptions.Subscription
+	for _, dbStruct := range dbStructs {
+		businessStructs = append(businessStructs, dbStruct.ToBusinessModel())
+	}
+	return businessStructs, nil
+}
+
+func (s postgresSubscriptionsStorage) InsertDeliveries(ctx context.Context, deliveries []*subscriptions.Delivery) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to BeginTxx")
+	}
+	defer tx.Rollback()
+
+	for _, delivery := range deliveries {
+		dbStruct := DBWebhookDelivery{}.FromBusinessModel(delivery)
+		err := tx.GetContext(ctx, &delivery.ID, `
+			INSERT INTO webhook_deliveries
+			    (subscription_id, payload, status, attempts, next_attempt_at, last_error, created_at)
+			VALUES
+			    ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id
+		`,
+			dbStruct.SubscriptionID, dbStruct.Payload, dbStruct.Status, dbStruct.Attempts,
+			dbStruct.NextAttemptAt, dbStruct.LastError, dbStruct.CreatedAt,
+		)
+		if err != nil {
+			return zaperr.Wrap(err, "failed to GetContext", zap.Int64("subscriptionID", delivery.SubscriptionID))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return zaperr.Wrap(err, "failed to Commit")
+	}
+	return nil
+}
+
+func (s postgresSubscriptionsStorage) ClaimDueDeliveries(
+	ctx context.Context,
+	now time.Time,
+	leaseUntil time.Time,
+	limit int,
+) ([]*subscriptions.Delivery, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to BeginTxx")
+	}
+	defer tx.Rollback()
+
+	// deliveries other replicas are claiming right now are skipped rather than waited for
+	var dbStructs []DBWebhookDelivery
+	err = tx.SelectContext(ctx, &dbStructs, `
+		SELECT d.*, s.callback_url, s.secret
+		FROM webhook_deliveries d
+		JOIN subscriptions s ON s.id = d.subscription_id
+		WHERE d.status = $1
+		AND d.next_attempt_at <= $2
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT $3
+		FOR UPDATE OF d SKIP LOCKED
+	`, subscriptions.DeliveryStatusPending, toUnixTime(now), limit)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext")
+	}
+	if len(dbStructs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, 0, len(dbStructs))
+	for _, dbStruct := range dbStructs {
+		ids = append(ids, dbStruct.ID)
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $1
+		WHERE id = ANY($2)
+	`, toUnixTime(leaseUntil), pq.Array(ids))
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to ExecContext")
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, zaperr.Wrap(err, "failed to Commit")
+	}
+
+	var businessStructs []*subscriptions.Delivery
+	for _, dbStruct := range dbStructs {
+		businessStructs = append(businessStructs, dbStruct.ToBusinessModel())
+	}
+	return businessStructs, nil
+}
+
+func (s postgresSubscriptionsStorage) UpdateDelivery(ctx context.Context, delivery *subscriptions.Delivery) error {
+	dbStruct := DBWebhookDelivery{}.FromBusinessModel(delivery)
+	_, err := s.db.NamedExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = :status,
+		    attempts = :attempts,
+		    next_attempt_at = :next_attempt_at,
+		    last_error = :last_error
+		WHERE id = :id
+	`, dbStruct)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to NamedExecContext", zap.Int64("deliveryID", delivery.ID))
+	}
+	return nil
+}
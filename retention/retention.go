@@ -0,0 +1,144 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/hori-ryota/zaperr"
+	"go.uber.org/zap"
+)
+
+// Storage is what pruner needs from storage
+type Storage interface {
+	// Prune deletes responses according to query in batches, so that database is not locked for the whole run,
+	// and reports how many responses each rule has deleted.
+	// In case of dryRun nothing is deleted, and report is what would have been deleted.
+	Prune(ctx context.Context, query Query, dryRun bool) (Report, error)
+}
+
+// Query tells which responses should be deleted. Rules are applied in order of declaration,
+// and a zero moment disables the respective rule.
+// The latest response for a tracking number and API is never deleted, unless it has expired.
+type Query struct {
+	// ExpiredFetchedBefore: delete all responses of tracking numbers not fetched since then
+	ExpiredFetchedBefore time.Time
+	// DeliveredFetchedBefore: delete all but the first and the latest responses
+	// of parcels that were delivered and last fetched before then
+	DeliveredFetchedBefore time.Time
+	// HistoryFetchedBefore: delete responses last fetched before then, except the first and the latest ones
+	HistoryFetchedBefore time.Time
+}
+
+// Metrics describes what custom metrics pruner should report on
+type Metrics interface {
+	// ResponsesPruned is how many responses were deleted by a policy, e.g. "history"
+	ResponsesPruned(policy string, count int)
+}
+
+// Policy describes how long responses are worth keeping. Zero duration disables the respective rule.
+type Policy struct {
+	// KeepHistoryFor is how long every distinct response is kept
+	KeepHistoryFor time.Duration
+	// CompactDeliveredAfter is how long after the last fetch of a delivered parcel
+	// only its first and latest responses are kept
+	CompactDeliveredAfter time.Duration
+	// ExpireAfter is how long after the last fetch tracking number is forgotten completely.
+	// It makes sense to keep it in line with service's expiryTimeout,
+	// since responses older than that are ignored anyway
+	ExpireAfter time.Duration
+}
+
+// Report is how many responses were pruned (or would be pruned, in case of dry run) by each rule
+type Report struct {
+	Expired   int
+	Delivered int
+	History   int
+}
+
+func (r Report) Total() int {
+	return r.Expired + r.Delivered + r.History
+}
+
+// New creates a pruner that keeps history of responses from growing unbounded.
+// When run periodically, it prunes responses every interval according to policy.
+func New(
+	storage Storage,
+	metrics Metrics,
+	policy Policy,
+	interval time.Duration,
+	logger *zap.Logger,
+	now func() time.Time,
+) *Pruner {
+	return &Pruner{
+		storage:  storage,
+		metrics:  metrics,
+		policy:   policy,
+		interval: interval,
+		log:      logger,
+		now:      now,
+	}
+}
+
+type Pruner struct {
+	storage  Storage
+	metrics  Metrics
+	policy   Policy
+	interval time.Duration
+	log      *zap.Logger
+	now      func() time.Time
+}
+
+// Run prunes responses every interval until ctx is done
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.Prune(ctx, false); err != nil {
+			p.log.Error("failed to prune responses", zaperr.ToField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			p.log.Info("pruner stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune applies policy once. In case of dryRun nothing is deleted,
+// and report tells how many responses would have been deleted.
+func (p *Pruner) Prune(ctx context.Context, dryRun bool) (Report, error) {
+	var query Query
+	now := p.now()
+	if p.policy.ExpireAfter > 0 {
+		query.ExpiredFetchedBefore = now.Add(-p.policy.ExpireAfter)
+	}
+	if p.policy.CompactDeliveredAfter > 0 {
+		query.DeliveredFetchedBefore = now.Add(-p.policy.CompactDeliveredAfter)
+	}
+	if p.policy.KeepHistoryFor > 0 {
+		query.HistoryFetchedBefore = now.Add(-p.policy.KeepHistoryFor)
+	}
+
+	report, err := p.storage.Prune(ctx, query, dryRun)
+	if err != nil {
+		return Report{}, zaperr.Wrap(err, "failed to prune responses", zap.Any("query", query), zap.Bool("dryRun", dryRun))
+	}
+
+	if !dryRun {
+		p.metrics.ResponsesPruned("expired", report.Expired)
+		p.metrics.ResponsesPruned("delivered", report.Delivered)
+		p.metrics.ResponsesPruned("history", report.History)
+	}
+	p.log.Info(
+		"pruned responses",
+		zap.Int("expired", report.Expired),
+		zap.Int("delivered", report.Delivered),
+		zap.Int("history", report.History),
+		zap.Bool("dryRun", dryRun),
+	)
+
+	return report, nil
+}
@@ -0,0 +1,103 @@
+package retention_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/retention"
+	"go.uber.org/zap"
+)
+
+type fakeStorage struct {
+	queries []retention.Query
+	dryRuns []bool
+	report  retention.Report
+}
+
+func (f *fakeStorage) Prune(_ context.Context, query retention.Query, dryRun bool) (retention.Report, error) {
+	f.queries = append(f.queries, query)
+	f.dryRuns = append(f.dryRuns, dryRun)
+	return f.report, nil
+}
+
+type fakeMetrics struct {
+	pruned map[string]int
+}
+
+func (f *fakeMetrics) ResponsesPruned(policy string, count int) {
+	if f.pruned == nil {
+		f.pruned = map[string]int{}
+	}
+	f.pruned[policy] += count
+}
+
+func TestPruner(t *testing.T) {
+	now := time.Unix(1000000, 0)
+
+	prepare := func(policy retention.Policy) (*retention.Pruner, *fakeStorage, *fakeMetrics) {
+		storage := &fakeStorage{report: retention.Report{Expired: 1, Delivered: 2, History: 3}}
+		metrics := &fakeMetrics{}
+		pruner := retention.New(storage, metrics, policy, time.Hour, zap.NewNop(), func() time.Time { return now })
+		return pruner, storage, metrics
+	}
+
+	t.Run("turns policy into query and reports metrics", func(t *testing.T) {
+		pruner, storage, metrics := prepare(retention.Policy{
+			KeepHistoryFor:        3 * time.Hour,
+			CompactDeliveredAfter: 2 * time.Hour,
+			ExpireAfter:           time.Hour,
+		})
+
+		report, err := pruner.Prune(context.Background(), false)
+		if err != nil {
+			t.Fatalf("failed to prune: %v", err)
+		}
+		if report.Total() != 6 {
+			t.Fatalf("expected report of storage to be returned, got %+v", report)
+		}
+
+		expected := retention.Query{
+			ExpiredFetchedBefore:   now.Add(-time.Hour),
+			DeliveredFetchedBefore: now.Add(-2 * time.Hour),
+			HistoryFetchedBefore:   now.Add(-3 * time.Hour),
+		}
+		if len(storage.queries) != 1 || storage.queries[0] != expected || storage.dryRuns[0] {
+			t.Fatalf("expected query %+v, got %+v (dry run: %v)", expected, storage.queries, storage.dryRuns)
+		}
+		if metrics.pruned["expired"] != 1 || metrics.pruned["delivered"] != 2 || metrics.pruned["history"] != 3 {
+			t.Fatalf("expected pruned responses to be reported, got %v", metrics.pruned)
+		}
+	})
+
+	t.Run("zero durations disable rules", func(t *testing.T) {
+		pruner, storage, _ := prepare(retention.Policy{ExpireAfter: time.Hour})
+
+		if _, err := pruner.Prune(context.Background(), false); err != nil {
+			t.Fatalf("failed to prune: %v", err)
+		}
+
+		expected := retention.Query{ExpiredFetchedBefore: now.Add(-time.Hour)}
+		if len(storage.queries) != 1 || storage.queries[0] != expected {
+			t.Fatalf("expected query %+v, got %+v", expected, storage.queries)
+		}
+	})
+
+	t.Run("dry run is not reported as pruned", func(t *testing.T) {
+		pruner, storage, metrics := prepare(retention.Policy{ExpireAfter: time.Hour})
+
+		report, err := pruner.Prune(context.Background(), true)
+		if err != nil {
+			t.Fatalf("failed to prune: %v", err)
+		}
+		if report.Total() != 6 {
+			t.Fatalf("expected what would be pruned to be reported, got %+v", report)
+		}
+		if len(storage.dryRuns) != 1 || !storage.dryRuns[0] {
+			t.Fatalf("expected storage to be asked for a dry run, got %v", storage.dryRuns)
+		}
+		if len(metrics.pruned) != 0 {
+			t.Fatalf("expected no metrics to be reported, got %v", metrics.pruned)
+		}
+	})
+}
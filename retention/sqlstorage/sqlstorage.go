@@ -0,0 +1,223 @@
+// Package sqlstorage implements retention.Storage on top of both sqlite and postgres:
+// queries are written with ? placeholders, rebound for the driver in use,
+// and with bare boolean columns, which sqlite treats as integers anyway.
+package sqlstorage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dir01/parcels/retention"
+	"github.com/hori-ryota/zaperr"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// DefaultBatchSize is how many responses (or expired tracking numbers) are deleted per statement,
+// so that database is never locked for long
+const DefaultBatchSize = 1000
+
+func New(db *sqlx.DB, batchSize int) retention.Storage {
+	return &sqlStorage{db: db, batchSize: batchSize}
+}
+
+type sqlStorage struct {
+	db        *sqlx.DB
+	batchSize int
+}
+
+// expiredTrackingNumbers selects tracking numbers not fetched by any API since cutoff
+const expiredTrackingNumbers = `
+	SELECT tracking_number
+	FROM postal_api_responses
+	WHERE is_latest
+	GROUP BY tracking_number
+	HAVING MAX(last_fetched_at) < ?
+`
+
+// isFirstResponse matches the first ever response for p's tracking number and API.
+// It's an index lookup thanks to idx_postal_api_responses_first
+const isFirstResponse = `p.id = (
+	SELECT f.id
+	FROM postal_api_responses f
+	WHERE f.tracking_number = p.tracking_number AND f.api_name = p.api_name
+	ORDER BY f.first_fetched_at, f.id
+	LIMIT 1
+)`
+
+// rule matches responses p to delete, given cutoff
+type rule struct {
+	condition string
+	cutoff    int64
+}
+
+var (
+	expiredRule = rule{condition: `p.tracking_number IN (` + expiredTrackingNumbers + `)`}
+	// deliveredRule keeps the first and the latest responses of parcels delivered before cutoff
+	deliveredRule = rule{condition: `NOT p.is_latest
+		AND NOT ` + isFirstResponse + `
+		AND EXISTS (
+			SELECT 1
+			FROM postal_api_responses l
+			WHERE l.tracking_number = p.tracking_number AND l.api_name = p.api_name
+			AND l.is_latest AND l.is_final AND l.last_fetched_at < ?
+		)`}
+	// historyRule keeps the first and the latest responses of every parcel
+	historyRule = rule{condition: `p.last_fetched_at < ?
+		AND NOT p.is_latest
+		AND NOT ` + isFirstResponse}
+)
+
+func (s sqlStorage) Prune(ctx context.Context, query retention.Query, dryRun bool) (retention.Report, error) {
+	var report retention.Report
+	zapFields := []zap.Field{zap.Any("query", query), zap.Bool("dryRun", dryRun)}
+
+	// rules applied before, so that dry run doesn't count what they would have deleted already
+	var applied []rule
+	var err error
+
+	if !query.ExpiredFetchedBefore.IsZero() {
+		r := expiredRule
+		r.cutoff = query.ExpiredFetchedBefore.Unix()
+		if dryRun {
+			report.Expired, err = s.count(ctx, r, applied)
+		} else {
+			report.Expired, err = s.deleteExpired(ctx, r.cutoff)
+		}
+		if err != nil {
+			return report, zaperr.Wrap(err, "failed to prune expired responses", zapFields...)
+		}
+		applied = append(applied, r)
+	}
+
+	if !query.DeliveredFetchedBefore.IsZero() {
+		r := deliveredRule
+		r.cutoff = query.DeliveredFetchedBefore.Unix()
+		if dryRun {
+			report.Delivered, err = s.count(ctx, r, applied)
+		} else {
+			report.Delivered, err = s.delete(ctx, r)
+		}
+		if err != nil {
+			return report, zaperr.Wrap(err, "failed to prune responses of delivered parcels", zapFields...)
+		}
+		applied = append(applied, r)
+	}
+
+	if !query.HistoryFetchedBefore.IsZero() {
+		r := historyRule
+		r.cutoff = query.HistoryFetchedBefore.Unix()
+		if dryRun {
+			report.History, err = s.count(ctx, r, applied)
+		} else {
+			report.History, err = s.delete(ctx, r)
+		}
+		if err != nil {
+			return report, zaperr.Wrap(err, "failed to prune old responses", zapFields...)
+		}
+	}
+
+	return report, nil
+}
+
+// count tells how many responses rule would delete, once the applied rules have deleted theirs
+func (s sqlStorage) count(ctx context.Context, r rule, applied []rule) (int, error) {
+	var sql strings.Builder
+	sql.WriteString(`SELECT COUNT(*) FROM postal_api_responses p WHERE (` + r.condition + `)`)
+	args := []any{r.cutoff}
+	for _, a := range applied {
+		sql.WriteString(` AND NOT (` + a.condition + `)`)
+		args = append(args, a.cutoff)
+	}
+
+	var count int
+	if err := s.db.GetContext(ctx, &count, s.db.Rebind(sql.String()), args...); err != nil {
+		return 0, zaperr.Wrap(err, "failed to GetContext")
+	}
+	return count, nil
+}
+
+// delete deletes responses matched by rule, batchSize at a time
+func (s sqlStorage) delete(ctx context.Context, r rule) (int, error) {
+	sql := s.db.Rebind(`
+		DELETE FROM postal_api_responses
+		WHERE id IN (
+			SELECT p.id
+			FROM postal_api_responses p
+			WHERE ` + r.condition + `
+			LIMIT ?
+		)
+	`)
+	deleted := 0
+	for {
+		result, err := s.db.ExecContext(ctx, sql, r.cutoff, s.batchSize)
+		if err != nil {
+			return deleted, zaperr.Wrap(err, "failed to ExecContext")
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return deleted, zaperr.Wrap(err, "failed to get RowsAffected")
+		}
+		deleted += int(affected)
+		if int(affected) < s.batchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// deleteExpired forgets tracking numbers not fetched since cutoff, batchSize tracking numbers at a time.
+// Everything known about a tracking number is deleted at once, so that nothing is left behind should pruning be interrupted
+func (s sqlStorage) deleteExpired(ctx context.Context, cutoff int64) (int, error) {
+	deleted := 0
+	for {
+		var trackingNumbers []string
+		err := s.db.SelectContext(ctx, &trackingNumbers, s.db.Rebind(expiredTrackingNumbers+` LIMIT ?`), cutoff, s.batchSize)
+		if err != nil {
+			return deleted, zaperr.Wrap(err, "failed to SelectContext")
+		}
+		if len(trackingNumbers) == 0 {
+			return deleted, nil
+		}
+
+		affected, err := s.forget(ctx, trackingNumbers)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += affected
+		if len(trackingNumbers) < s.batchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// forget deletes responses, localized responses and links of tracking numbers, and tells how many responses it deleted
+func (s sqlStorage) forget(ctx context.Context, trackingNumbers []string) (int, error) {
+	zapFields := []zap.Field{zap.Int("trackingNumbers", len(trackingNumbers))}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, zaperr.Wrap(err, "failed to BeginTxx", zapFields...)
+	}
+	defer tx.Rollback()
+
+	// responses are deleted last, so that's what affected tells about in the end
+	var affected int64
+	for _, table := range []string{"tracking_number_links", "localized_responses", "postal_api_responses"} {
+		sql, args, err := sqlx.In(`DELETE FROM `+table+` WHERE tracking_number IN (?)`, trackingNumbers)
+		if err != nil {
+			return 0, zaperr.Wrap(err, "failed to build IN query", zapFields...)
+		}
+		result, err := tx.ExecContext(ctx, tx.Rebind(sql), args...)
+		if err != nil {
+			return 0, zaperr.Wrap(err, "failed to delete from "+table, zapFields...)
+		}
+		if affected, err = result.RowsAffected(); err != nil {
+			return 0, zaperr.Wrap(err, "failed to get RowsAffected", zapFields...)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, zaperr.Wrap(err, "failed to Commit", zapFields...)
+	}
+	return int(affected), nil
+}
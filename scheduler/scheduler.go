@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"github.com/hori-ryota/zaperr"
+	"go.uber.org/zap"
+)
+
+// Service is the part of service.Service that scheduler relies on
+type Service interface {
+	CountDueForRefresh(ctx context.Context) (int, error)
+	ListDueForRefresh(ctx context.Context, limit int) ([]service.DueRefresh, error)
+	GetTrackingInfoBatch(ctx context.Context, trackingNumbers []string, language string) ([]*service.BatchResult, error)
+}
+
+// Metrics describes what custom metrics scheduler should report on
+type Metrics interface {
+	// RefreshQueueDepth is how many parcels are due for refresh
+	RefreshQueueDepth(int)
+	// RefreshLag is how long the most overdue parcel is waiting for refresh
+	RefreshLag(time.Duration)
+	// ParcelsRefreshed is how many parcels were refreshed
+	ParcelsRefreshed(int)
+}
+
+// New creates a scheduler that refreshes tracked parcels in the background,
+// so that data doesn't go stale for parcels nobody looks at,
+// and the first viewer doesn't have to wait for the carriers.
+// Every tickInterval it refreshes at most maxPerTick most overdue parcels, batchSize at a time.
+// Concurrency within a batch is bounded by service.GetTrackingInfoBatch.
+func New(
+	svc Service,
+	metrics Metrics,
+	tickInterval time.Duration,
+	batchSize int,
+	maxPerTick int,
+	logger *zap.Logger,
+	now func() time.Time,
+) *Scheduler {
+	return &Scheduler{
+		svc:          svc,
+		metrics:      metrics,
+		tickInterval: tickInterval,
+		batchSize:    batchSize,
+		maxPerTick:   maxPerTick,
+		log:          logger,
+		now:          now,
+	}
+}
+
+type Scheduler struct {
+	svc          Service
+	metrics      Metrics
+	tickInterval time.Duration
+	batchSize    int
+	maxPerTick   int
+	log          *zap.Logger
+	now          func() time.Time
+}
+
+// Run refreshes parcels every tickInterval until ctx is done.
+// Shutdown is graceful: batch that is already being refreshed is allowed to finish,
+// since cancelling it would make carriers' responses look like errors.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.tickInterval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.log.Info("scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) tick(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	queueDepth, err := s.svc.CountDueForRefresh(ctx)
+	if err != nil {
+		s.log.Error("failed to count parcels due for refresh", zaperr.ToField(err))
+		return
+	}
+	s.metrics.RefreshQueueDepth(queueDepth)
+	if queueDepth == 0 {
+		s.metrics.RefreshLag(0)
+		return
+	}
+
+	due, err := s.svc.ListDueForRefresh(ctx, s.maxPerTick)
+	if err != nil {
+		s.log.Error("failed to list parcels due for refresh", zaperr.ToField(err))
+		return
+	}
+	if len(due) == 0 { // refreshed by someone else in the meantime
+		s.metrics.RefreshLag(0)
+		return
+	}
+	s.metrics.RefreshLag(s.now().Sub(due[0].DueAt))
+	s.log.Info(
+		"refreshing parcels",
+		zap.Int("queueDepth", queueDepth),
+		zap.Time("oldestDueAt", due[0].DueAt),
+	)
+
+	// in-flight batch should finish even if we're shutting down
+	workCtx := context.WithoutCancel(ctx)
+
+	for len(due) > 0 {
+		if ctx.Err() != nil {
+			return
+		}
+
+		batch := due[:min(len(due), s.batchSize)]
+		due = due[len(batch):]
+
+		trackingNumbers := make([]string, len(batch))
+		for i, d := range batch {
+			trackingNumbers[i] = d.TrackingNumber
+		}
+
+		results, err := s.svc.GetTrackingInfoBatch(workCtx, trackingNumbers, service.DefaultLanguage)
+		if err != nil {
+			s.log.Error("failed to refresh parcels", zaperr.ToField(err))
+			continue
+		}
+
+		refreshed := 0
+		for _, result := range results {
+			if result.Err != nil {
+				s.log.Error(
+					"failed to refresh parcel",
+					zap.String("trackingNumber", result.TrackingNumber),
+					zaperr.ToField(result.Err),
+				)
+				continue
+			}
+			refreshed++
+		}
+		s.metrics.ParcelsRefreshed(refreshed)
+	}
+}
@@ -0,0 +1,116 @@
+package scheduler_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/scheduler"
+	"github.com/dir01/parcels/service"
+	"go.uber.org/zap"
+)
+
+type fakeService struct {
+	mu      sync.Mutex
+	due     []service.DueRefresh
+	batches [][]string
+	// onBatch is called before each batch is processed
+	onBatch func()
+}
+
+func (f *fakeService) CountDueForRefresh(context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.due), nil
+}
+
+func (f *fakeService) ListDueForRefresh(_ context.Context, limit int) ([]service.DueRefresh, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.due[:min(len(f.due), limit)], nil
+}
+
+func (f *fakeService) GetTrackingInfoBatch(ctx context.Context, trackingNumbers []string, language string) ([]*service.BatchResult, error) {
+	if f.onBatch != nil {
+		f.onBatch()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, trackingNumbers)
+	var results []*service.BatchResult
+	for _, tn := range trackingNumbers {
+		results = append(results, &service.BatchResult{TrackingNumber: tn, Err: ctx.Err()})
+	}
+	return results, nil
+}
+
+type fakeMetrics struct {
+	queueDepth int
+	lag        time.Duration
+	refreshed  int
+}
+
+func (f *fakeMetrics) RefreshQueueDepth(depth int)  { f.queueDepth = depth }
+func (f *fakeMetrics) RefreshLag(lag time.Duration) { f.lag = lag }
+func (f *fakeMetrics) ParcelsRefreshed(count int)   { f.refreshed += count }
+
+func TestScheduler(t *testing.T) {
+	now := time.Unix(10000, 0)
+
+	t.Run("refreshes due parcels in batches and reports metrics", func(t *testing.T) {
+		svc := &fakeService{due: []service.DueRefresh{
+			{TrackingNumber: "1", DueAt: now.Add(-time.Hour)},
+			{TrackingNumber: "2", DueAt: now.Add(-time.Minute)},
+			{TrackingNumber: "3", DueAt: now.Add(-time.Second)},
+			{TrackingNumber: "4", DueAt: now.Add(-time.Second)},
+		}}
+		metrics := &fakeMetrics{}
+		ctx, cancel := context.WithCancel(context.Background())
+		// the second batch is the last one allowed by maxPerTick, stop after it
+		svc.onBatch = func() {
+			if len(svc.batches) == 1 {
+				cancel()
+			}
+		}
+
+		s := scheduler.New(svc, metrics, time.Hour, 2, 3, zap.NewNop(), func() time.Time { return now })
+		s.Run(ctx)
+
+		if len(svc.batches) != 2 {
+			t.Fatalf("expected 2 batches, got %v", svc.batches)
+		}
+		if len(svc.batches[0]) != 2 || len(svc.batches[1]) != 1 {
+			t.Fatalf("expected batches of 2 and 1 parcels, got %v", svc.batches)
+		}
+		if metrics.queueDepth != 4 {
+			t.Fatalf("expected queue depth 4, got %d", metrics.queueDepth)
+		}
+		if metrics.lag != time.Hour {
+			t.Fatalf("expected lag 1h, got %s", metrics.lag)
+		}
+		if metrics.refreshed != 3 {
+			t.Fatalf("expected 3 parcels refreshed, got %d", metrics.refreshed)
+		}
+	})
+
+	t.Run("does not cancel in-flight batch on shutdown", func(t *testing.T) {
+		svc := &fakeService{due: []service.DueRefresh{
+			{TrackingNumber: "1", DueAt: now.Add(-time.Hour)},
+			{TrackingNumber: "2", DueAt: now.Add(-time.Hour)},
+		}}
+		metrics := &fakeMetrics{}
+		ctx, cancel := context.WithCancel(context.Background())
+		svc.onBatch = cancel
+
+		s := scheduler.New(svc, metrics, time.Hour, 1, 10, zap.NewNop(), func() time.Time { return now })
+		s.Run(ctx)
+
+		if len(svc.batches) != 1 {
+			t.Fatalf("expected no new batches to start after shutdown, got %v", svc.batches)
+		}
+		if metrics.refreshed != 1 {
+			t.Fatalf("expected in-flight batch to complete successfully, got %d refreshed", metrics.refreshed)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// fetchCall is a single in-flight fetch, possibly awaited by many callers
+type fetchCall struct {
+	done chan struct{}
+	resp PostalApiResponse
+}
+
+// fetchCoalescer makes concurrent fetches of the same tracking number from the same API in the same language
+// share a single request to the API
+type fetchCoalescer struct {
+	mu    sync.Mutex
+	calls map[fetchKey]*fetchCall
+}
+
+type fetchKey struct {
+	trackingNumber string
+	apiName        APIName
+	// language is empty for regular responses, and is set for localized ones (see Localizer)
+	language string
+}
+
+// do calls fetch, unless the same key is already being fetched,
+// in which case it waits for that fetch to finish and returns its response instead.
+// Fetch is shared, so it's not cancelled with ctx of whoever started it, only limited by timeout;
+// every caller stops waiting for it once its own ctx is done, and gets ctx error then
+func (c *fetchCoalescer) do(
+	ctx context.Context,
+	key fetchKey,
+	timeout time.Duration,
+	fetch func(ctx context.Context) PostalApiResponse,
+) (PostalApiResponse, error) {
+	c.mu.Lock()
+	if c.calls == nil {
+		c.calls = make(map[fetchKey]*fetchCall)
+	}
+	call, ok := c.calls[key]
+	if !ok {
+		call = &fetchCall{done: make(chan struct{})}
+		c.calls[key] = call
+		go c.run(ctx, key, call, timeout, fetch)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.resp, nil
+	case <-ctx.Done():
+		return PostalApiResponse{}, ctx.Err()
+	}
+}
+
+func (c *fetchCoalescer) run(
+	ctx context.Context,
+	key fetchKey,
+	call *fetchCall,
+	timeout time.Duration,
+	fetch func(ctx context.Context) PostalApiResponse,
+) {
+	defer func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		close(call.done)
+	}()
+	fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	call.resp = fetch(fetchCtx)
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// CountryDeclarer can optionally be implemented by PostalAPI
+// to declare which countries it covers, as ISO 3166-1 alpha-2 codes.
+// API is relevant for a parcel going from or to any of these countries.
+// APIs that don't implement it (or return an empty list) are considered to cover the whole world.
+type CountryDeclarer interface {
+	SupportedCountries() []string
+}
+
+// countryRelevance is what countries parcel goes between tell about relevance of an API
+type countryRelevance int
+
+const (
+	// countryRelevanceUnknown means either API covers the whole world, or we don't know where parcel goes yet
+	countryRelevanceUnknown countryRelevance = iota
+	countryRelevanceRelevant
+	countryRelevanceIrrelevant
+)
+
+// parcelCountries returns origin and destination countries, according to any of the APIs.
+// Expired responses are not trusted, since tracking number could have been reused since
+func (svc *Impl) parcelCountries(
+	lastRespMap map[APIName]*PostalApiResponse,
+	parsedResponsesMap map[APIName]*TrackingInfo,
+) []string {
+	var result []string
+	for apiName, parsed := range parsedResponsesMap {
+		if svc.now().After(lastRespMap[apiName].LastFetchedAt.Add(svc.expiryTimeout)) {
+			continue
+		}
+		for _, country := range []string{parsed.OriginCountry, parsed.DestinationCountry} {
+			if country != "" && !slices.Contains(result, country) {
+				result = append(result, country)
+			}
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+// countryRelevance tells whether API covers any of the countries parcel goes between
+func (svc *Impl) countryRelevance(apiName APIName, parcelCountries []string) countryRelevance {
+	declarer, ok := svc.apiMap[apiName].(CountryDeclarer)
+	if !ok || len(declarer.SupportedCountries()) == 0 || len(parcelCountries) == 0 {
+		return countryRelevanceUnknown
+	}
+	for _, country := range declarer.SupportedCountries() {
+		if slices.Contains(parcelCountries, country) {
+			return countryRelevanceRelevant
+		}
+	}
+	return countryRelevanceIrrelevant
+}
+
+// reconsiderRelevance adjusts decisions on which APIs to hit, made from tracking number format and stored responses,
+// once we know which countries parcel goes between:
+// APIs covering these countries are asked even if they were never considered (e.g. the destination country's
+// postal service, once parcel leaves China), and APIs not covering them are not bothered anymore,
+// unless they've already told us something about the parcel
+func (svc *Impl) reconsiderRelevance(
+	trackingNumber string,
+	lastRespMap map[APIName]*PostalApiResponse,
+	parsedResponsesMap map[APIName]*TrackingInfo,
+	apiHitDecisionMap map[APIName]bool,
+) {
+	countries := svc.parcelCountries(lastRespMap, parsedResponsesMap)
+	if len(countries) == 0 {
+		return
+	}
+
+	for _, apiName := range svc.apiNames {
+		resp := lastRespMap[apiName]
+		isNew := resp == nil || svc.now().After(resp.LastFetchedAt.Add(svc.expiryTimeout))
+		if !isNew && resp.Status == StatusSuccess {
+			// API knows about the parcel, whatever the countries are
+			continue
+		}
+
+		switch svc.countryRelevance(apiName, countries) {
+		case countryRelevanceRelevant:
+			if !isNew || apiHitDecisionMap[apiName] {
+				// API was asked before and is polled as usual, or is about to be asked anyway
+				continue
+			}
+			apiHitDecisionMap[apiName] = true
+			svc.log.Info(
+				"API covers parcel's countries, will ask it",
+				zap.String("trackingNumber", trackingNumber),
+				zap.String("apiName", string(apiName)),
+				zap.Strings("countries", countries),
+			)
+			svc.metrics.RelevanceChangedByCountries(apiName, true)
+		case countryRelevanceIrrelevant:
+			if !apiHitDecisionMap[apiName] {
+				continue
+			}
+			apiHitDecisionMap[apiName] = false
+			svc.log.Info(
+				"API does not cover parcel's countries, won't ask it",
+				zap.String("trackingNumber", trackingNumber),
+				zap.String("apiName", string(apiName)),
+				zap.Strings("countries", countries),
+			)
+			svc.metrics.RelevanceChangedByCountries(apiName, false)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/service/mocks"
+	"go.uber.org/zap"
+)
+
+// countryDeclaringAPI is a PostalAPI mock that declares both supported formats and countries it covers
+type countryDeclaringAPI struct {
+	*mocks.PostalAPIMock
+	formats   []service.TrackingNumberFormat
+	countries []string
+}
+
+func (a countryDeclaringAPI) SupportedFormats() []service.TrackingNumberFormat {
+	return a.formats
+}
+
+func (a countryDeclaringAPI) SupportedCountries() []string {
+	return a.countries
+}
+
+func TestServiceCountryRelevance(t *testing.T) {
+	now := time.Now()
+	chinese := countryDeclaringAPI{
+		PostalAPIMock: mocks.NewPostalAPIMock(t),
+		formats:       []service.TrackingNumberFormat{service.FormatCainiao},
+		countries:     []string{"CN"},
+	}
+	// doesn't know cainiao numbers, but that's where parcel is going
+	israeli := countryDeclaringAPI{
+		PostalAPIMock: mocks.NewPostalAPIMock(t),
+		formats:       []service.TrackingNumberFormat{service.FormatUPUS10},
+		countries:     []string{"IL"},
+	}
+	// claims to know any number, but parcel is not going there
+	american := countryDeclaringAPI{
+		PostalAPIMock: mocks.NewPostalAPIMock(t),
+		countries:     []string{"US"},
+	}
+	storage := mocks.NewStorageMock(t)
+
+	svc := service.NewService(
+		map[service.APIName]service.PostalAPI{"chinese": chinese, "israeli": israeli, "american": american},
+		storage,
+		promMetrics,
+		time.Hour,
+		time.Hour,
+		time.Hour,
+		time.Second,
+		30*24*time.Hour,
+		time.Minute,
+		zap.NewNop(),
+		func() time.Time { return now },
+	)
+
+	storage.GetLatestMock.Return([]*service.PostalApiResponse{{
+		TrackingNumber: "LP00123456789012",
+		APIName:        "chinese",
+		Status:         service.StatusSuccess,
+		ResponseBody:   []byte("foo"),
+		LastFetchedAt:  now,
+	}}, nil)
+	storage.GetLinksMock.Return(nil, nil)
+	chinese.ParseMock.Return(&service.TrackingInfo{
+		TrackingNumber:     "LP00123456789012",
+		APIName:            "chinese",
+		OriginCountry:      "Mainland China",
+		DestinationCountry: "Israel",
+	}, nil)
+
+	israeli.FetchMock.Return(service.PostalApiResponse{
+		TrackingNumber: "LP00123456789012",
+		APIName:        "israeli",
+		Status:         service.StatusNotFound,
+	})
+	storage.UpsertMock.Inspect(func(ctx context.Context, trackingNumber string, apiName service.APIName, response *service.PostalApiResponse) {
+		if apiName != "israeli" {
+			t.Fatalf("expected only israeli response to be stored, got %s", apiName)
+		}
+	}).Return(nil)
+
+	// american.FetchMock is not set, so asking it would fail the test
+	if _, err := svc.GetTrackingInfo(context.Background(), "LP00123456789012", service.DefaultLanguage); err != nil {
+		t.Fatalf("failed to get tracking info: %v", err)
+	}
+	if israeli.FetchAfterCounter() != 1 {
+		t.Fatalf("expected API of destination country to be asked")
+	}
+}
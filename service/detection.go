@@ -0,0 +1,252 @@
+package service
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TrackingNumberFormat is a well-known family of tracking numbers,
+// recognizable by its shape alone, without asking any API.
+type TrackingNumberFormat string
+
+const (
+	// FormatUPUS10 is the Universal Postal Union S10 standard:
+	// 2 letters, 8 digits serial, 1 mod-11 check digit, 2 letters ISO country code.
+	// E.g. RR123456785CN
+	FormatUPUS10 TrackingNumberFormat = "UPU_S10"
+	// FormatUPS is UPS's "1Z" format: 1Z, 6 chars shipper number, 2 digits service code, 8 digits with a check digit
+	FormatUPS TrackingNumberFormat = "UPS"
+	// FormatFedEx is FedEx Express (12 digits), Ground (15 digits) or SmartPost (20 digits)
+	FormatFedEx TrackingNumberFormat = "FEDEX"
+	// FormatCainiao covers numbers issued by Cainiao / AliExpress:
+	// LP00123456789012, AE012345678901, RS0123456789Y
+	FormatCainiao TrackingNumberFormat = "CAINIAO"
+	// FormatUSPS is USPS Intelligent Mail package barcode: 20 or 22 digits starting with 91-95, mod-10 check digit,
+	// optionally prefixed with 420 and destination ZIP code, as scanned from the label.
+	// E.g. 9205590164917312751089
+	FormatUSPS TrackingNumberFormat = "USPS"
+)
+
+// FormatMatch describes a single format that a tracking number looks like
+type FormatMatch struct {
+	Format TrackingNumberFormat
+	// CheckDigitVerified is true when the format has a check digit, and it is correct.
+	// Such a match is much more trustworthy than a mere regexp match.
+	CheckDigitVerified bool
+	// CountryCode is the ISO 3166-1 alpha-2 country code of the issuing postal operator,
+	// only known for some formats (e.g. UPU S10)
+	CountryCode string
+}
+
+// CarrierCandidate is an API that is likely to know about the tracking number
+type CarrierCandidate struct {
+	APIName APIName
+	// Score is higher for more likely candidates.
+	// APIs that do not declare supported formats get 0, since they can know about anything.
+	Score   int
+	Formats []TrackingNumberFormat
+}
+
+// CarrierDetection is the result of tracking number classification
+type CarrierDetection struct {
+	TrackingNumber string
+	Formats        []FormatMatch
+	Candidates     []CarrierCandidate
+}
+
+// IsCandidate reports whether the API is among the candidates
+func (cd *CarrierDetection) IsCandidate(apiName APIName) bool {
+	for _, c := range cd.Candidates {
+		if c.APIName == apiName {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatDeclarer can optionally be implemented by PostalAPI
+// to declare which tracking number formats it handles.
+// APIs that don't implement it (or return an empty list) are considered capable of tracking anything.
+type FormatDeclarer interface {
+	SupportedFormats() []TrackingNumberFormat
+}
+
+var (
+	reUPUS10        = regexp.MustCompile(`^([A-Z]{2})(\d{8})(\d)([A-Z]{2})$`)
+	reUPS           = regexp.MustCompile(`^1Z([0-9A-Z]{15})([0-9])$`)
+	reFedEx         = regexp.MustCompile(`^(\d{12}|\d{15}|\d{20})$`)
+	reCainiaoLP     = regexp.MustCompile(`^LP\d{14}$`)
+	reCainiaoAE     = regexp.MustCompile(`^AE\d{12,14}$`)
+	reCainiaoSuffix = regexp.MustCompile(`^[A-Z]{2}\d{10}Y$`)
+	reUSPS          = regexp.MustCompile(`^(?:420\d{5}(?:\d{4})?)?(9[1-5]\d{18}(?:\d{2})?)$`)
+)
+
+// NormalizeTrackingNumber removes whitespace and dashes people tend to copy along with tracking numbers
+func NormalizeTrackingNumber(trackingNumber string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '-':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(trackingNumber))
+}
+
+// ClassifyTrackingNumber returns all formats tracking number looks like.
+// Result is empty if format is not known.
+func ClassifyTrackingNumber(trackingNumber string) []FormatMatch {
+	tn := NormalizeTrackingNumber(trackingNumber)
+	var matches []FormatMatch
+
+	if m := reUPUS10.FindStringSubmatch(tn); m != nil {
+		matches = append(matches, FormatMatch{
+			Format:             FormatUPUS10,
+			CheckDigitVerified: s10CheckDigit(m[2]) == int(m[3][0]-'0'),
+			CountryCode:        m[4],
+		})
+	}
+
+	if m := reUPS.FindStringSubmatch(tn); m != nil {
+		matches = append(matches, FormatMatch{
+			Format:             FormatUPS,
+			CheckDigitVerified: upsCheckDigit(m[1]) == int(m[2][0]-'0'),
+		})
+	}
+
+	if reFedEx.MatchString(tn) {
+		matches = append(matches, FormatMatch{
+			Format:             FormatFedEx,
+			CheckDigitVerified: len(tn) == 12 && fedExCheckDigit(tn[:11]) == int(tn[11]-'0'),
+		})
+	}
+
+	if reCainiaoLP.MatchString(tn) || reCainiaoAE.MatchString(tn) || reCainiaoSuffix.MatchString(tn) {
+		matches = append(matches, FormatMatch{Format: FormatCainiao})
+	}
+
+	if m := reUSPS.FindStringSubmatch(tn); m != nil {
+		matches = append(matches, FormatMatch{
+			Format:             FormatUSPS,
+			CheckDigitVerified: uspsCheckDigit(m[1][:len(m[1])-1]) == int(m[1][len(m[1])-1]-'0'),
+		})
+	}
+
+	return matches
+}
+
+// DetectCarriers classifies the tracking number and ranks registered APIs by their relevance.
+// If the format is not recognized at all, or no API declares any of the formats it looks like, every API is a candidate.
+func (svc *Impl) DetectCarriers(trackingNumber string) *CarrierDetection {
+	detection := &CarrierDetection{
+		TrackingNumber: trackingNumber,
+		Formats:        ClassifyTrackingNumber(trackingNumber),
+	}
+
+	for _, apiName := range svc.apiNames {
+		declarer, ok := svc.apiMap[apiName].(FormatDeclarer)
+		if !ok || len(declarer.SupportedFormats()) == 0 || len(detection.Formats) == 0 {
+			detection.Candidates = append(detection.Candidates, CarrierCandidate{APIName: apiName})
+			continue
+		}
+
+		candidate := CarrierCandidate{APIName: apiName}
+		for _, supported := range declarer.SupportedFormats() {
+			for _, match := range detection.Formats {
+				if match.Format != supported {
+					continue
+				}
+				score := 1
+				if match.CheckDigitVerified {
+					score = 2
+				}
+				if score > candidate.Score {
+					candidate.Score = score
+				}
+				candidate.Formats = append(candidate.Formats, supported)
+			}
+		}
+		if candidate.Score > 0 {
+			detection.Candidates = append(detection.Candidates, candidate)
+		}
+	}
+
+	// format matching is a guess, and we'd rather ask everyone than no one
+	if len(detection.Candidates) == 0 {
+		for _, apiName := range svc.apiNames {
+			detection.Candidates = append(detection.Candidates, CarrierCandidate{APIName: apiName})
+		}
+	}
+
+	sort.SliceStable(detection.Candidates, func(i, j int) bool {
+		a, b := detection.Candidates[i], detection.Candidates[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.APIName < b.APIName
+	})
+
+	return detection
+}
+
+// s10CheckDigit calculates UPU S10 check digit for an 8-digit serial number
+func s10CheckDigit(serial string) int {
+	weights := [8]int{8, 6, 4, 2, 3, 5, 9, 7}
+	sum := 0
+	for i, w := range weights {
+		sum += int(serial[i]-'0') * w
+	}
+	check := 11 - sum%11
+	switch check {
+	case 10:
+		return 0
+	case 11:
+		return 5
+	default:
+		return check
+	}
+}
+
+// upsCheckDigit calculates check digit for the 15 chars following "1Z".
+// Letters are converted to digits as (ASCII - 63) % 10, odd positions are doubled.
+func upsCheckDigit(body string) int {
+	sum := 0
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		var v int
+		if c >= 'A' && c <= 'Z' {
+			v = (int(c) - 63) % 10
+		} else {
+			v = int(c - '0')
+		}
+		if i%2 == 1 {
+			v *= 2
+		}
+		sum += v
+	}
+	return (10 - sum%10) % 10
+}
+
+// fedExCheckDigit calculates check digit of a FedEx Express 12-digit number from its first 11 digits
+func fedExCheckDigit(digits string) int {
+	weights := [3]int{3, 1, 7}
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		sum += int(digits[i]-'0') * weights[i%3]
+	}
+	return sum % 11 % 10
+}
+
+// uspsCheckDigit calculates USPS mod-10 check digit from the digits preceding it:
+// digits are weighted 3 and 1 alternately, starting with 3 from the right
+func uspsCheckDigit(digits string) int {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		v := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 0 {
+			v *= 3
+		}
+		sum += v
+	}
+	return (10 - sum%10) % 10
+}
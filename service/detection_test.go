@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/service/mocks"
+	"go.uber.org/zap"
+)
+
+// formatDeclaringAPI is a PostalAPI mock that also declares supported formats
+type formatDeclaringAPI struct {
+	*mocks.PostalAPIMock
+	formats []service.TrackingNumberFormat
+}
+
+func (a formatDeclaringAPI) SupportedFormats() []service.TrackingNumberFormat {
+	return a.formats
+}
+
+func TestClassifyTrackingNumber(t *testing.T) {
+	testCases := []struct {
+		trackingNumber     string
+		expectedFormats    []service.TrackingNumberFormat
+		checkDigitVerified bool
+	}{
+		{"RR123456785CN", []service.TrackingNumberFormat{service.FormatUPUS10}, true},
+		{"rr 1234 5678 5 cn", []service.TrackingNumberFormat{service.FormatUPUS10}, true},
+		{"RR123456784CN", []service.TrackingNumberFormat{service.FormatUPUS10}, false},
+		{"1Z999AA10123456784", []service.TrackingNumberFormat{service.FormatUPS}, true},
+		{"1Z999AA10123456783", []service.TrackingNumberFormat{service.FormatUPS}, false},
+		{"986578788855", []service.TrackingNumberFormat{service.FormatFedEx}, true},
+		{"986578788856", []service.TrackingNumberFormat{service.FormatFedEx}, false},
+		{"LP00123456789012", []service.TrackingNumberFormat{service.FormatCainiao}, false},
+		{"RS0814398526Y", []service.TrackingNumberFormat{service.FormatCainiao}, false},
+		{"9205590164917312751089", []service.TrackingNumberFormat{service.FormatUSPS}, true},
+		{"9205590164917312751088", []service.TrackingNumberFormat{service.FormatUSPS}, false},
+		{"420100019205590164917312751089", []service.TrackingNumberFormat{service.FormatUSPS}, true},
+		{"92612999897543581074", []service.TrackingNumberFormat{service.FormatFedEx, service.FormatUSPS}, false},
+		{"hello", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.trackingNumber, func(t *testing.T) {
+			matches := service.ClassifyTrackingNumber(tc.trackingNumber)
+			if len(matches) != len(tc.expectedFormats) {
+				t.Fatalf("expected %d matches, got %v", len(tc.expectedFormats), matches)
+			}
+			for i, m := range matches {
+				if m.Format != tc.expectedFormats[i] {
+					t.Fatalf("expected format %s, got %s", tc.expectedFormats[i], m.Format)
+				}
+				if m.CheckDigitVerified != tc.checkDigitVerified {
+					t.Fatalf("expected check digit verified to be %v, got %v", tc.checkDigitVerified, m.CheckDigitVerified)
+				}
+			}
+		})
+	}
+
+	t.Run("S10 country code", func(t *testing.T) {
+		matches := service.ClassifyTrackingNumber("RR123456785CN")
+		if matches[0].CountryCode != "CN" {
+			t.Fatalf("expected country code CN, got %s", matches[0].CountryCode)
+		}
+	})
+}
+
+func TestDetectCarriers(t *testing.T) {
+	logger := zap.NewNop()
+
+	prepareTestSubjects := func() (*service.Impl, *mocks.StorageMock, *mocks.PostalAPIMock, *mocks.PostalAPIMock) {
+		storage := mocks.NewStorageMock(t)
+		upsAPI := mocks.NewPostalAPIMock(t)
+		universalAPI := mocks.NewPostalAPIMock(t)
+		apiMap := map[service.APIName]service.PostalAPI{
+			"ups":       formatDeclaringAPI{upsAPI, []service.TrackingNumberFormat{service.FormatUPS}},
+			"universal": universalAPI,
+		}
+		svc := service.NewService(
+			apiMap,
+			storage,
+			promMetrics,
+			time.Hour,
+			time.Hour,
+			time.Hour,
+			time.Millisecond,
+			time.Hour,
+			time.Hour,
+			logger,
+			time.Now,
+		)
+		return svc, storage, upsAPI, universalAPI
+	}
+
+	t.Run("ranks declaring APIs higher", func(t *testing.T) {
+		svc, _, _, _ := prepareTestSubjects()
+
+		detection := svc.DetectCarriers("1Z999AA10123456784")
+		if len(detection.Candidates) != 2 {
+			t.Fatalf("expected 2 candidates, got %v", detection.Candidates)
+		}
+		if detection.Candidates[0].APIName != "ups" || detection.Candidates[0].Score != 2 {
+			t.Fatalf("expected ups to be the top candidate, got %v", detection.Candidates)
+		}
+	})
+
+	t.Run("excludes declaring APIs for other formats", func(t *testing.T) {
+		svc, _, _, _ := prepareTestSubjects()
+
+		detection := svc.DetectCarriers("RR123456785CN")
+		if detection.IsCandidate("ups") {
+			t.Fatalf("expected ups not to be a candidate, got %v", detection.Candidates)
+		}
+		if !detection.IsCandidate("universal") {
+			t.Fatalf("expected universal to be a candidate, got %v", detection.Candidates)
+		}
+	})
+
+	t.Run("unknown format makes everyone a candidate", func(t *testing.T) {
+		svc, _, _, _ := prepareTestSubjects()
+
+		detection := svc.DetectCarriers("whatever")
+		if !detection.IsCandidate("ups") || !detection.IsCandidate("universal") {
+			t.Fatalf("expected both APIs to be candidates, got %v", detection.Candidates)
+		}
+	})
+
+	t.Run("format no API declares makes everyone a candidate", func(t *testing.T) {
+		svc := service.NewService(
+			map[service.APIName]service.PostalAPI{
+				"ups": formatDeclaringAPI{mocks.NewPostalAPIMock(t), []service.TrackingNumberFormat{service.FormatUPS}},
+			},
+			mocks.NewStorageMock(t),
+			promMetrics,
+			time.Hour,
+			time.Hour,
+			time.Hour,
+			time.Millisecond,
+			time.Hour,
+			time.Hour,
+			logger,
+			time.Now,
+		)
+
+		// looks like FedEx, which no registered API declares
+		detection := svc.DetectCarriers("123456789012")
+		if len(detection.Formats) == 0 {
+			t.Fatalf("expected tracking number to match some format")
+		}
+		if !detection.IsCandidate("ups") {
+			t.Fatalf("expected ups to be a candidate, got %v", detection.Candidates)
+		}
+	})
+
+	t.Run("irrelevant API is not fetched", func(t *testing.T) {
+		svc, storage, _, universalAPI := prepareTestSubjects()
+		ctx := context.Background()
+
+		storage.GetLatestMock.Return(nil, nil)
+		storage.GetLinksMock.Return(nil, nil)
+		universalAPI.FetchMock.Return(service.PostalApiResponse{
+			TrackingNumber: "RR123456785CN",
+			APIName:        "universal",
+			Status:         service.StatusNotFound,
+		})
+		storage.UpsertMock.Return(nil)
+
+		// upsAPI.Fetch is not expected, so minimock would fail the test if it was called
+		if _, err := svc.GetTrackingInfo(ctx, "RR123456785CN", service.DefaultLanguage); err != nil {
+			t.Fatalf("failed to get tracking info: %v", err)
+		}
+	})
+}
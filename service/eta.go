@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hori-ryota/zaperr"
+	"go.uber.org/zap"
+)
+
+// ETASource tells where estimated time of arrival came from
+type ETASource string
+
+const (
+	// ETASourceCarrier means carrier itself told us when to expect the parcel
+	ETASourceCarrier ETASource = "carrier"
+	// ETASourceHistory means ETA is estimated from how long parcels delivered before took
+	ETASourceHistory ETASource = "history"
+)
+
+// ETA is a time window parcel is expected to be delivered within
+type ETA struct {
+	Earliest time.Time
+	Latest   time.Time
+	Source   ETASource
+}
+
+const (
+	// transitTimesBatchSize is how many stored responses are loaded at once when collecting transit times
+	transitTimesBatchSize = 500
+	// minTransitSamples is how many delivered parcels it takes for an estimate to make sense
+	minTransitSamples = 5
+	// ETA window spans from etaEarliestPercentile to etaLatestPercentile of historical transit times,
+	// so that few parcels stuck for months don't make the window useless
+	etaEarliestPercentile = 0.2
+	etaLatestPercentile   = 0.8
+)
+
+// transitKey is what transit times are grouped by:
+// parcels going the same way take similar time from the same phase on
+type transitKey struct {
+	origin      string
+	destination string
+	phase       Phase
+}
+
+// transitTimes is how long delivered parcels took to reach recipient since they had entered a phase
+type transitTimes struct {
+	mu        sync.RWMutex
+	durations map[transitKey][]time.Duration // sorted
+}
+
+func (t *transitTimes) replace(durations map[transitKey][]time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.durations = durations
+}
+
+// window returns percentiles of transit times for key, and false if there are too few of them
+func (t *transitTimes) window(key transitKey) (earliest, latest time.Duration, ok bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	durations := t.durations[key]
+	if len(durations) < minTransitSamples {
+		return 0, 0, false
+	}
+	percentile := func(p float64) time.Duration {
+		return durations[int(p*float64(len(durations)-1))]
+	}
+	return percentile(etaEarliestPercentile), percentile(etaLatestPercentile), true
+}
+
+// RunTransitTimesRefresh refreshes transit times ETA is estimated from every interval, until context is done
+func (svc *Impl) RunTransitTimesRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := svc.RefreshTransitTimes(ctx); err != nil {
+			svc.log.Error("failed to refresh transit times", zaperr.ToField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			svc.log.Info("transit times refresh stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshTransitTimes parses stored responses of delivered parcels to learn how long it takes
+// to deliver a parcel going from one country to another, since it entered each phase.
+// It's expensive, so it's meant to be run periodically in background.
+func (svc *Impl) RefreshTransitTimes(ctx context.Context) error {
+	// several APIs may track the same parcel, it should only count once
+	samples := map[string]map[transitKey]time.Duration{}
+	for _, apiName := range svc.sortedAPINames() {
+		err := svc.walkFinalResponses(ctx, apiName, transitTimesBatchSize, func(resp *PostalApiResponse) error {
+			parsed, err := svc.parseApiResponse(*resp)
+			if err != nil || parsed == nil {
+				return nil
+			}
+			if parcelSamples := transitSamples(parsed); len(parcelSamples) > 0 {
+				samples[resp.TrackingNumber] = parcelSamples
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	durations := map[transitKey][]time.Duration{}
+	for _, parcelSamples := range samples {
+		for key, duration := range parcelSamples {
+			durations[key] = append(durations[key], duration)
+		}
+	}
+	for _, d := range durations {
+		slices.Sort(d)
+	}
+	svc.transitTimes.replace(durations)
+
+	svc.log.Info(
+		"refreshed transit times",
+		zap.Int("parcels", len(samples)),
+		zap.Int("routes", len(durations)),
+	)
+	return nil
+}
+
+// transitSamples tells how long delivered parcel took to reach recipient since it had entered each phase
+func transitSamples(info *TrackingInfo) map[transitKey]time.Duration {
+	deliveredAt, ok := deliveredAt(info)
+	if !ok {
+		return nil
+	}
+	samples := map[transitKey]time.Duration{}
+	for phase, enteredAt := range phasesEnteredAt(info) {
+		if phase == PhaseDelivered || phase == PhaseException || enteredAt.After(deliveredAt) {
+			continue
+		}
+		key := transitKey{origin: info.OriginCountry, destination: info.DestinationCountry, phase: phase}
+		samples[key] = deliveredAt.Sub(enteredAt)
+	}
+	return samples
+}
+
+// deliveredAt is the time of the latest delivery event, if parcel was delivered at all
+func deliveredAt(info *TrackingInfo) (time.Time, bool) {
+	var result time.Time
+	found := false
+	for _, e := range info.Events {
+		if e.Status.Phase() == PhaseDelivered && (!found || e.Time.After(result)) {
+			result = e.Time
+			found = true
+		}
+	}
+	return result, found
+}
+
+// phasesEnteredAt tells when parcel has entered each of the phases, i.e. the time of the earliest event of the phase
+func phasesEnteredAt(info *TrackingInfo) map[Phase]time.Time {
+	result := map[Phase]time.Time{}
+	for _, e := range info.Events {
+		phase := e.Status.Phase()
+		if phase == PhaseUnknown {
+			continue
+		}
+		if enteredAt, exists := result[phase]; !exists || e.Time.Before(enteredAt) {
+			result[phase] = e.Time
+		}
+	}
+	return result
+}
+
+// fillETA estimates ETA from historical transit times, unless carrier has already told us one.
+// Parcels that have reached terminal state are not expected anywhere anymore
+func (svc *Impl) fillETA(info *TrackingInfo) {
+	if info.ETA != nil || info.IsTerminal() {
+		return
+	}
+	phase := info.CurrentPhase()
+	if phase == PhaseUnknown {
+		return
+	}
+	enteredAt, ok := phasesEnteredAt(info)[phase]
+	if !ok {
+		return
+	}
+	key := transitKey{origin: info.OriginCountry, destination: info.DestinationCountry, phase: phase}
+	earliest, latest, ok := svc.transitTimes.window(key)
+	if !ok {
+		return
+	}
+	info.ETA = &ETA{
+		Earliest: enteredAt.Add(earliest),
+		Latest:   enteredAt.Add(latest),
+		Source:   ETASourceHistory,
+	}
+}
@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/service/mocks"
+	"go.uber.org/zap"
+)
+
+func TestServiceETA(t *testing.T) {
+	day := 24 * time.Hour
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(100 * day)
+
+	prepareTestSubjects := func() (*service.Impl, *mocks.StorageMock, *mocks.PostalAPIMock) {
+		storage := mocks.NewStorageMock(t)
+		api1 := mocks.NewPostalAPIMock(t)
+		svc := service.NewService(
+			map[service.APIName]service.PostalAPI{api1Name: api1},
+			storage,
+			promMetrics,
+			time.Hour,
+			time.Hour,
+			time.Hour,
+			time.Second,
+			30*24*time.Hour,
+			time.Minute,
+			zap.NewNop(),
+			func() time.Time { return now },
+		)
+
+		// delivered parcels from CN to IL took 10 to 14 days since import customs, another route is much slower
+		var responses []*service.PostalApiResponse
+		infos := map[string]*service.TrackingInfo{}
+		addDelivered := func(trackingNumber, destination string, sinceCustoms time.Duration) {
+			responses = append(responses, &service.PostalApiResponse{
+				ID:             int64(len(responses) + 1),
+				TrackingNumber: trackingNumber,
+				APIName:        api1Name,
+				Status:         service.StatusSuccess,
+				IsFinal:        true,
+				ResponseBody:   []byte(trackingNumber),
+			})
+			infos[trackingNumber] = &service.TrackingInfo{
+				TrackingNumber:     trackingNumber,
+				APIName:            api1Name,
+				OriginCountry:      "CN",
+				DestinationCountry: destination,
+				Events: []service.TrackingEvent{
+					{Time: start, Status: service.TrackingStatusAcceptedByCarrier},
+					{Time: start.Add(day), Status: service.TrackingStatusArrivedAtCustoms},
+					{Time: start.Add(day + sinceCustoms), Status: service.TrackingStatusDelivered},
+				},
+			}
+		}
+		for i, days := range []int{10, 11, 12, 13, 14} {
+			addDelivered(fmt.Sprintf("IL%d", i), "IL", time.Duration(days)*day)
+		}
+		addDelivered("US0", "US", 30*day)
+
+		storage.GetFinalResponsesAfterMock.Set(func(_ context.Context, _ service.APIName, afterID int64, limit int) ([]*service.PostalApiResponse, error) {
+			var page []*service.PostalApiResponse
+			for _, resp := range responses {
+				if resp.ID > afterID && len(page) < limit {
+					page = append(page, resp)
+				}
+			}
+			return page, nil
+		})
+		api1.ParseMock.Set(func(resp service.PostalApiResponse) (*service.TrackingInfo, error) {
+			if info, exists := infos[resp.TrackingNumber]; exists {
+				return info, nil
+			}
+			return &service.TrackingInfo{
+				TrackingNumber:     resp.TrackingNumber,
+				APIName:            api1Name,
+				OriginCountry:      "CN",
+				DestinationCountry: string(resp.ResponseBody),
+				Events: []service.TrackingEvent{
+					{Time: now.Add(-2 * day), Status: service.TrackingStatusArrivedAtCustoms},
+				},
+			}, nil
+		})
+
+		return svc, storage, api1
+	}
+
+	track := func(t *testing.T, svc *service.Impl, storage *mocks.StorageMock, destination string) *service.TrackingInfo {
+		storage.GetLatestMock.Return([]*service.PostalApiResponse{{
+			TrackingNumber: "123",
+			APIName:        api1Name,
+			Status:         service.StatusSuccess,
+			ResponseBody:   []byte(destination),
+			LastFetchedAt:  now,
+		}}, nil)
+		storage.GetLinksMock.Return(nil, nil)
+
+		infos, err := svc.GetTrackingInfo(context.Background(), "123", service.DefaultLanguage)
+		if err != nil {
+			t.Fatalf("failed to get tracking info: %v", err)
+		}
+		if len(infos) != 1 {
+			t.Fatalf("expected 1 tracking info, got %d", len(infos))
+		}
+		return infos[0]
+	}
+
+	t.Run("estimated from parcels delivered the same way", func(t *testing.T) {
+		svc, storage, _ := prepareTestSubjects()
+		if err := svc.RefreshTransitTimes(context.Background()); err != nil {
+			t.Fatalf("failed to refresh transit times: %v", err)
+		}
+
+		info := track(t, svc, storage, "IL")
+		enteredCustoms := now.Add(-2 * day)
+		expected := &service.ETA{
+			Earliest: enteredCustoms.Add(10 * day),
+			Latest:   enteredCustoms.Add(13 * day),
+			Source:   service.ETASourceHistory,
+		}
+		if info.ETA == nil || *info.ETA != *expected {
+			t.Fatalf("expected ETA %+v, got %+v", expected, info.ETA)
+		}
+	})
+
+	t.Run("not estimated from too few parcels", func(t *testing.T) {
+		svc, storage, _ := prepareTestSubjects()
+		if err := svc.RefreshTransitTimes(context.Background()); err != nil {
+			t.Fatalf("failed to refresh transit times: %v", err)
+		}
+
+		if info := track(t, svc, storage, "US"); info.ETA != nil {
+			t.Fatalf("expected no ETA, got %+v", info.ETA)
+		}
+	})
+
+	t.Run("not estimated before transit times are known", func(t *testing.T) {
+		svc, storage, _ := prepareTestSubjects()
+
+		if info := track(t, svc, storage, "IL"); info.ETA != nil {
+			t.Fatalf("expected no ETA, got %+v", info.ETA)
+		}
+	})
+}
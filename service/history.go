@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+
+	"github.com/hori-ryota/zaperr"
+	"go.uber.org/zap"
+)
+
+// APIHistory is everything a single API has ever told us about a parcel
+type APIHistory struct {
+	APIName   APIName
+	Snapshots []*HistorySnapshot
+}
+
+// HistorySnapshot is a stored response, as understood by the current parser.
+// Diff is relative to the previous snapshot that was parsed successfully,
+// so that e.g. a temporary "not found" doesn't look like all events disappeared and reappeared
+type HistorySnapshot struct {
+	Response *PostalApiResponse
+	// TrackingInfo is nil if response was not successful or can not be parsed anymore
+	TrackingInfo *TrackingInfo
+	// ParseError is why a successful response can not be parsed anymore
+	ParseError error
+	// AddedEvents are events that were not there in the previous snapshot
+	AddedEvents []TrackingEvent
+	// RemovedEvents are events of the previous snapshot that are not there anymore
+	RemovedEvents []TrackingEvent
+}
+
+// GetHistory re-parses every stored response of every API for the tracking number,
+// which is useful to debug carriers behavior, e.g. events disappearing.
+// APIs that never told us anything are omitted.
+func (svc *Impl) GetHistory(ctx context.Context, trackingNumber string) ([]*APIHistory, error) {
+	var result []*APIHistory
+	for _, apiName := range svc.sortedAPINames() {
+		responses, err := svc.storage.GetHistory(ctx, trackingNumber, apiName)
+		if err != nil {
+			return nil, zaperr.Wrap(
+				err, "failed to get history",
+				zap.String("trackingNumber", trackingNumber),
+				zap.String("apiName", string(apiName)),
+			)
+		}
+		if len(responses) == 0 {
+			continue
+		}
+
+		history := &APIHistory{APIName: apiName}
+		var previous *TrackingInfo
+		for _, resp := range responses {
+			snapshot := &HistorySnapshot{Response: resp}
+			if resp.Status == StatusSuccess {
+				snapshot.TrackingInfo, snapshot.ParseError = svc.parseApiResponse(*resp)
+			}
+			if snapshot.TrackingInfo != nil {
+				snapshot.TrackingInfo.LastFetchedAt = resp.LastFetchedAt
+				var previousEvents []TrackingEvent
+				if previous != nil {
+					previousEvents = previous.Events
+				}
+				snapshot.AddedEvents, snapshot.RemovedEvents = diffEvents(previousEvents, snapshot.TrackingInfo.Events)
+				previous = snapshot.TrackingInfo
+			}
+			history.Snapshots = append(history.Snapshots, snapshot)
+		}
+		result = append(result, history)
+	}
+
+	return result, nil
+}
+
+// diffEvents tells which events of after are not in before, and vice versa.
+// Events are compared as a whole, so an event with corrected description is both removed and added
+func diffEvents(before, after []TrackingEvent) (added, removed []TrackingEvent) {
+	type eventKey struct {
+		unixNano    int64
+		description string
+		status      TrackingStatus
+	}
+	keyOf := func(e TrackingEvent) eventKey {
+		return eventKey{unixNano: e.Time.UnixNano(), description: e.Description, status: e.Status}
+	}
+
+	// counting, since carriers do repeat events sometimes
+	beforeCounts := make(map[eventKey]int, len(before))
+	for _, e := range before {
+		beforeCounts[keyOf(e)]++
+	}
+	afterCounts := make(map[eventKey]int, len(after))
+	for _, e := range after {
+		afterCounts[keyOf(e)]++
+	}
+
+	for _, e := range after {
+		key := keyOf(e)
+		if beforeCounts[key] > 0 {
+			beforeCounts[key]--
+			continue
+		}
+		added = append(added, e)
+	}
+	for _, e := range before {
+		key := keyOf(e)
+		if afterCounts[key] > 0 {
+			afterCounts[key]--
+			continue
+		}
+		removed = append(removed, e)
+	}
+	return added, removed
+}
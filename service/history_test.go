@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/service/mocks"
+	"go.uber.org/zap"
+)
+
+func TestServiceGetHistory(t *testing.T) {
+	storage := mocks.NewStorageMock(t)
+	api1 := mocks.NewPostalAPIMock(t)
+
+	svc := service.NewService(
+		map[service.APIName]service.PostalAPI{api1Name: api1},
+		storage,
+		promMetrics,
+		time.Hour,
+		time.Hour,
+		time.Hour,
+		time.Second,
+		30*24*time.Hour,
+		time.Minute,
+		zap.NewNop(),
+		time.Now,
+	)
+
+	accepted := service.TrackingEvent{Time: time.Unix(100, 0), Description: "accepted", Status: service.TrackingStatusAcceptedByCarrier}
+	departed := service.TrackingEvent{Time: time.Unix(200, 0), Description: "departed", Status: service.TrackingStatusDepartedFromSortingCenter}
+	arrived := service.TrackingEvent{Time: time.Unix(300, 0), Description: "arrived", Status: service.TrackingStatusArrivedAtSortingCenter}
+
+	response := func(fetchedAt int64, status service.ApiResponseStatus, body string) *service.PostalApiResponse {
+		return &service.PostalApiResponse{
+			TrackingNumber: "123",
+			APIName:        api1Name,
+			FirstFetchedAt: time.Unix(fetchedAt, 0),
+			LastFetchedAt:  time.Unix(fetchedAt+10, 0),
+			ResponseBody:   []byte(body),
+			Status:         status,
+		}
+	}
+	responses := []*service.PostalApiResponse{
+		response(1000, service.StatusSuccess, "accepted,departed"),
+		response(2000, service.StatusNotFound, ""),
+		// departed event has disappeared, which is exactly why this endpoint exists
+		response(3000, service.StatusSuccess, "accepted,arrived"),
+		response(4000, service.StatusSuccess, "garbage"),
+	}
+	storage.GetHistoryMock.Expect(context.Background(), "123", api1Name).Return(responses, nil)
+
+	eventsByBody := map[string][]service.TrackingEvent{
+		"accepted,departed": {accepted, departed},
+		"accepted,arrived":  {accepted, arrived},
+	}
+	parseErr := errors.New("unexpected response")
+	api1.ParseMock.Set(func(resp service.PostalApiResponse) (*service.TrackingInfo, error) {
+		events, ok := eventsByBody[string(resp.ResponseBody)]
+		if !ok {
+			return nil, parseErr
+		}
+		return &service.TrackingInfo{TrackingNumber: "123", APIName: api1Name, Events: events}, nil
+	})
+
+	histories, err := svc.GetHistory(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("failed to get history: %v", err)
+	}
+	if len(histories) != 1 || histories[0].APIName != api1Name {
+		t.Fatalf("expected history of %s, got %+v", api1Name, histories)
+	}
+	snapshots := histories[0].Snapshots
+	if len(snapshots) != 4 {
+		t.Fatalf("expected 4 snapshots, got %d", len(snapshots))
+	}
+	for i, snapshot := range snapshots {
+		if snapshot.Response != responses[i] {
+			t.Fatalf("expected snapshot #%d to be of response %+v, got %+v", i, responses[i], snapshot.Response)
+		}
+	}
+
+	first := snapshots[0]
+	if first.TrackingInfo == nil || !first.TrackingInfo.LastFetchedAt.Equal(time.Unix(1010, 0)) {
+		t.Fatalf("expected the first snapshot to be parsed, got %+v", first.TrackingInfo)
+	}
+	if !reflect.DeepEqual(first.AddedEvents, []service.TrackingEvent{accepted, departed}) || len(first.RemovedEvents) != 0 {
+		t.Fatalf("expected all events of the first snapshot to be added, got %+v, %+v", first.AddedEvents, first.RemovedEvents)
+	}
+
+	notFound := snapshots[1]
+	if notFound.TrackingInfo != nil || notFound.ParseError != nil || len(notFound.AddedEvents)+len(notFound.RemovedEvents) != 0 {
+		t.Fatalf("expected not found snapshot to be left alone, got %+v", notFound)
+	}
+
+	// compared to the first snapshot, since not found one has no events to compare with
+	changed := snapshots[2]
+	if !reflect.DeepEqual(changed.AddedEvents, []service.TrackingEvent{arrived}) ||
+		!reflect.DeepEqual(changed.RemovedEvents, []service.TrackingEvent{departed}) {
+		t.Fatalf("expected arrived to be added and departed to be removed, got %+v, %+v", changed.AddedEvents, changed.RemovedEvents)
+	}
+
+	unparseable := snapshots[3]
+	if unparseable.TrackingInfo != nil || !errors.Is(unparseable.ParseError, parseErr) {
+		t.Fatalf("expected parse error to be reported, got %+v", unparseable)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// maxLinkDepth is how many hops of linked tracking numbers we are willing to follow,
+// e.g. cainiao number -> last-mile number is one hop
+const maxLinkDepth = 3
+
+// linkedParcel is a tracking number reached while following links, along with its tracking infos
+type linkedParcel struct {
+	trackingNumber string
+	trackingInfos  []*TrackingInfo
+}
+
+// followLinks transitively tracks tracking numbers linked to the given one,
+// and returns tracking infos of all of them appended to the given ones.
+// Every tracking number is tracked at most once, so link cycles are harmless.
+// Failing to track a linked number is not an error: we still have the parcel's own tracking infos.
+func (svc *Impl) followLinks(ctx context.Context, trackingNumber string, trackingInfos []*TrackingInfo) []*TrackingInfo {
+	visited := map[string]struct{}{trackingNumber: {}}
+	result := trackingInfos
+	current := []linkedParcel{{trackingNumber: trackingNumber, trackingInfos: trackingInfos}}
+
+	for depth := 0; depth < maxLinkDepth && len(current) > 0; depth++ {
+		var next []linkedParcel
+		for _, parcel := range current {
+			for _, linked := range svc.discoverLinks(ctx, parcel.trackingNumber, parcel.trackingInfos) {
+				if _, ok := visited[linked]; ok {
+					continue
+				}
+				visited[linked] = struct{}{}
+
+				linkedInfos, err := svc.getOwnTrackingInfo(ctx, linked)
+				if err != nil {
+					svc.log.Error(
+						"failed to track linked tracking number",
+						zap.String("trackingNumber", trackingNumber),
+						zap.String("linkedTrackingNumber", linked),
+						zap.Error(err),
+					)
+					continue
+				}
+				result = append(result, linkedInfos...)
+				next = append(next, linkedParcel{trackingNumber: linked, trackingInfos: linkedInfos})
+			}
+		}
+		current = next
+	}
+
+	return result
+}
+
+// discoverLinks returns tracking numbers linked to the given one:
+// both previously stored, and freshly reported by APIs in tracking infos.
+// Freshly reported links are persisted, so that we keep following them
+// even if API stops mentioning them.
+func (svc *Impl) discoverLinks(ctx context.Context, trackingNumber string, trackingInfos []*TrackingInfo) []string {
+	links, err := svc.storage.GetLinks(ctx, trackingNumber)
+	if err != nil {
+		svc.log.Error("failed to get stored links", zap.String("trackingNumber", trackingNumber), zap.Error(err))
+	}
+
+	var newLinks []string
+	for _, ti := range trackingInfos {
+		for _, linked := range ti.AdditionalTrackingNumbers {
+			linked = NormalizeTrackingNumber(linked)
+			if linked == "" || linked == trackingNumber || slices.Contains(links, linked) {
+				continue
+			}
+			links = append(links, linked)
+			newLinks = append(newLinks, linked)
+		}
+	}
+
+	if len(newLinks) > 0 {
+		if err := svc.storage.InsertLinks(ctx, trackingNumber, newLinks); err != nil {
+			svc.log.Error(
+				"failed to store links",
+				zap.String("trackingNumber", trackingNumber),
+				zap.Strings("linkedTrackingNumbers", newLinks),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return links
+}
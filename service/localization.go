@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// DefaultLanguage is the language carriers are asked in and responses are stored in
+const DefaultLanguage = "en"
+
+// Languages are the languages events can be described in: either carriers describe them,
+// or we fall back to describing their status ourselves
+var Languages = []string{DefaultLanguage, "ru", "he"}
+
+// Localizer can optionally be implemented by PostalAPI that is able to describe events in languages
+// other than DefaultLanguage. Localized responses are only used for descriptions: everything else,
+// e.g. whether parcel is delivered, is decided by regular responses
+type Localizer interface {
+	// SupportedLanguages are ISO 639-1 codes, e.g. "ru"
+	SupportedLanguages() []string
+	// FetchLocalized follows the same contract as PostalAPI.Fetch,
+	// and its response should be parseable with PostalAPI.Parse
+	FetchLocalized(ctx context.Context, trackingNumber string, language string) PostalApiResponse
+}
+
+// NormalizeLanguage turns language tag, e.g. "ru-RU", into ISO 639-1 code, e.g. "ru"
+func NormalizeLanguage(tag string) string {
+	language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	language, _, _ = strings.Cut(language, "_")
+	return language
+}
+
+// localize describes events in the language: carrier's own descriptions are used where possible,
+// and events carrier can't describe get their status described by our translations
+func (svc *Impl) localize(ctx context.Context, trackingInfos []*TrackingInfo, language string) {
+	language = NormalizeLanguage(language)
+	if language == "" || language == DefaultLanguage {
+		return
+	}
+	for _, info := range trackingInfos {
+		localized := svc.localizeByCarrier(ctx, info, language)
+		for i := range info.Events {
+			if localized[i] {
+				continue
+			}
+			if description, ok := translateStatus(info.Events[i].Status, language); ok {
+				info.Events[i].Description = description
+			}
+		}
+	}
+}
+
+// localizeByCarrier replaces event descriptions with those carrier gave in the language,
+// and tells which events got one. Localized response is stored, and is only fetched again
+// once regular response was fetched after it and localized one doesn't describe every event
+func (svc *Impl) localizeByCarrier(ctx context.Context, info *TrackingInfo, language string) []bool {
+	localized := make([]bool, len(info.Events))
+	localizer, ok := svc.apiMap[info.APIName].(Localizer)
+	if !ok || !slices.Contains(localizer.SupportedLanguages(), language) {
+		return localized
+	}
+	zapFields := []zap.Field{
+		zap.String("trackingNumber", info.TrackingNumber),
+		zap.String("apiName", string(info.APIName)),
+		zap.String("language", language),
+	}
+
+	stored, err := svc.storage.GetLocalized(ctx, info.TrackingNumber, info.APIName, language)
+	if err != nil {
+		svc.log.Error("failed to get localized response", append(zapFields, zap.Error(err))...)
+		return localized
+	}
+	if stored != nil {
+		if allLocalized := svc.applyLocalizedResponse(info, *stored, localized); allLocalized {
+			return localized
+		}
+		if !stored.LastFetchedAt.Before(info.LastFetchedAt) {
+			// carrier has nothing new to tell since
+			return localized
+		}
+	}
+
+	if svc.cooldowns.isActive(info.APIName, svc.now()) {
+		return localized
+	}
+	// fetches are given the same timeout, but can we really trust APIs to respect it?
+	ttlCtx, cancel := context.WithTimeout(ctx, svc.apiFetchTimeout)
+	defer cancel()
+	// concurrent lookups of the same parcel in the same language should not hit the API more than once
+	key := fetchKey{trackingNumber: info.TrackingNumber, apiName: info.APIName, language: language}
+	fetched, err := svc.fetches.do(ttlCtx, key, svc.apiFetchTimeout, func(ctx context.Context) PostalApiResponse {
+		return localizer.FetchLocalized(ctx, info.TrackingNumber, language)
+	})
+	if err != nil {
+		return localized
+	}
+	if svc.handleRateLimited(info.APIName, fetched) || fetched.Status != StatusSuccess {
+		return localized
+	}
+	fetched.Language = language
+	fetched.FirstFetchedAt = svc.now()
+	fetched.LastFetchedAt = fetched.FirstFetchedAt
+	if err := svc.storage.UpsertLocalized(ctx, &fetched); err != nil {
+		svc.log.Error("failed to store localized response", append(zapFields, zap.Error(err))...)
+	}
+	svc.applyLocalizedResponse(info, fetched, localized)
+	return localized
+}
+
+// applyLocalizedResponse takes descriptions of events from localized response, marking events that got one.
+// Returns true if every event is localized
+func (svc *Impl) applyLocalizedResponse(info *TrackingInfo, resp PostalApiResponse, localized []bool) bool {
+	parsed, err := svc.apiMap[resp.APIName].Parse(resp)
+	if err != nil || parsed == nil {
+		return false
+	}
+
+	// same events are told in the same order at the same time, only descriptions differ
+	used := make([]bool, len(parsed.Events))
+	allLocalized := true
+	for i, e := range info.Events {
+		if localized[i] {
+			continue
+		}
+		for j, le := range parsed.Events {
+			if used[j] || !le.Time.Equal(e.Time) || le.RawCode != e.RawCode || le.Status != e.Status {
+				continue
+			}
+			used[j] = true
+			localized[i] = true
+			info.Events[i].Description = le.Description
+			break
+		}
+		allLocalized = allLocalized && localized[i]
+	}
+	return allLocalized
+}
@@ -0,0 +1,230 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/service/mocks"
+	"go.uber.org/zap"
+)
+
+// localizingAPI is a PostalAPI mock that can describe events in Russian
+type localizingAPI struct {
+	*mocks.PostalAPIMock
+	localizedFetches int
+	// fetchDelay is how long localized fetch takes
+	fetchDelay time.Duration
+}
+
+func (a *localizingAPI) SupportedLanguages() []string {
+	return []string{"en", "ru"}
+}
+
+func (a *localizingAPI) FetchLocalized(ctx context.Context, trackingNumber string, language string) service.PostalApiResponse {
+	a.localizedFetches++
+	time.Sleep(a.fetchDelay)
+	return service.PostalApiResponse{
+		TrackingNumber: trackingNumber,
+		APIName:        api1Name,
+		Status:         service.StatusSuccess,
+		ResponseBody:   []byte(language),
+	}
+}
+
+func TestServiceLocalization(t *testing.T) {
+	now := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	accepted := now.Add(-48 * time.Hour)
+	arrived := now.Add(-24 * time.Hour)
+
+	prepareTestSubjects := func() (*service.Impl, *mocks.StorageMock, *localizingAPI) {
+		storage := mocks.NewStorageMock(t)
+		api1 := &localizingAPI{PostalAPIMock: mocks.NewPostalAPIMock(t)}
+		svc := service.NewService(
+			map[service.APIName]service.PostalAPI{api1Name: api1},
+			storage,
+			promMetrics,
+			time.Hour,
+			time.Hour,
+			time.Hour,
+			time.Second,
+			30*24*time.Hour,
+			time.Minute,
+			zap.NewNop(),
+			func() time.Time { return now },
+		)
+
+		storage.GetLatestMock.Return([]*service.PostalApiResponse{{
+			TrackingNumber: "123",
+			APIName:        api1Name,
+			Status:         service.StatusSuccess,
+			ResponseBody:   []byte("en"),
+			LastFetchedAt:  now,
+		}}, nil)
+		storage.GetLinksMock.Return(nil, nil)
+		// carrier is yet to describe the latest event in Russian
+		api1.ParseMock.Set(func(resp service.PostalApiResponse) (*service.TrackingInfo, error) {
+			info := &service.TrackingInfo{
+				TrackingNumber: "123",
+				APIName:        api1Name,
+				Events: []service.TrackingEvent{
+					{Time: accepted, RawCode: "ACCEPT", Status: service.TrackingStatusAcceptedByCarrier, Description: "Accepted"},
+					{Time: arrived, RawCode: "CUSTOMS", Status: service.TrackingStatusArrivedAtCustoms, Description: "Arrived at customs"},
+				},
+			}
+			if string(resp.ResponseBody) == "ru" {
+				info.Events = info.Events[:1]
+				info.Events[0].Description = "Принято"
+			}
+			return info, nil
+		})
+
+		return svc, storage, api1
+	}
+
+	descriptions := func(t *testing.T, infos []*service.TrackingInfo) []string {
+		t.Helper()
+		if len(infos) != 1 {
+			t.Fatalf("expected 1 tracking info, got %d", len(infos))
+		}
+		var result []string
+		for _, e := range infos[0].Events {
+			result = append(result, e.Description)
+		}
+		return result
+	}
+
+	assertDescriptions := func(t *testing.T, actual []string, expected ...string) {
+		t.Helper()
+		if len(actual) != len(expected) {
+			t.Fatalf("expected descriptions %v, got %v", expected, actual)
+		}
+		for i := range expected {
+			if actual[i] != expected[i] {
+				t.Fatalf("expected descriptions %v, got %v", expected, actual)
+			}
+		}
+	}
+
+	t.Run("carrier describes events, the rest are translated", func(t *testing.T) {
+		svc, storage, api1 := prepareTestSubjects()
+		storage.GetLocalizedMock.Return(nil, nil)
+		storage.UpsertLocalizedMock.Inspect(func(ctx context.Context, response *service.PostalApiResponse) {
+			if response.Language != "ru" || !response.LastFetchedAt.Equal(now) {
+				t.Fatalf("unexpected localized response stored: %+v", response)
+			}
+		}).Return(nil)
+
+		infos, err := svc.GetTrackingInfo(context.Background(), "123", "ru-RU")
+		if err != nil {
+			t.Fatalf("failed to get tracking info: %v", err)
+		}
+		assertDescriptions(t, descriptions(t, infos), "Принято", "Прибыло на таможню")
+		if api1.localizedFetches != 1 || storage.UpsertLocalizedAfterCounter() != 1 {
+			t.Fatalf("expected localized response to be fetched and stored once")
+		}
+	})
+
+	t.Run("stored localized response is reused", func(t *testing.T) {
+		svc, storage, api1 := prepareTestSubjects()
+		storage.GetLocalizedMock.Return(&service.PostalApiResponse{
+			TrackingNumber: "123",
+			APIName:        api1Name,
+			Status:         service.StatusSuccess,
+			ResponseBody:   []byte("ru"),
+			Language:       "ru",
+			LastFetchedAt:  now,
+		}, nil)
+
+		infos, err := svc.GetTrackingInfo(context.Background(), "123", "ru")
+		if err != nil {
+			t.Fatalf("failed to get tracking info: %v", err)
+		}
+		assertDescriptions(t, descriptions(t, infos), "Принято", "Прибыло на таможню")
+		if api1.localizedFetches != 0 {
+			t.Fatalf("expected carrier not to be asked again, since nothing changed since")
+		}
+	})
+
+	t.Run("concurrent lookups share localized fetch", func(t *testing.T) {
+		svc, storage, api1 := prepareTestSubjects()
+		api1.fetchDelay = 50 * time.Millisecond // give the other lookup a chance to join
+		storage.GetLocalizedMock.Return(nil, nil)
+		storage.UpsertLocalizedMock.Return(nil)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				infos, err := svc.GetTrackingInfo(context.Background(), "123", "ru")
+				if err != nil {
+					t.Errorf("failed to get tracking info: %v", err)
+					return
+				}
+				if len(infos) != 1 || infos[0].Events[0].Description != "Принято" {
+					t.Errorf("expected carrier's description, got %+v", infos)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if api1.localizedFetches != 1 {
+			t.Fatalf("expected carrier to be asked once, got %d", api1.localizedFetches)
+		}
+	})
+
+	t.Run("language carrier doesn't support is translated", func(t *testing.T) {
+		svc, _, api1 := prepareTestSubjects()
+
+		infos, err := svc.GetTrackingInfo(context.Background(), "123", "he")
+		if err != nil {
+			t.Fatalf("failed to get tracking info: %v", err)
+		}
+		assertDescriptions(t, descriptions(t, infos), "התקבל אצל המוביל", "הגיע למכס")
+		if api1.localizedFetches != 0 {
+			t.Fatalf("expected carrier not to be asked")
+		}
+	})
+
+	t.Run("batch is described in the language too", func(t *testing.T) {
+		svc, storage, _ := prepareTestSubjects()
+		storage.GetLatestBatchMock.Return([]*service.PostalApiResponse{{
+			TrackingNumber: "123",
+			APIName:        api1Name,
+			Status:         service.StatusSuccess,
+			ResponseBody:   []byte("en"),
+			LastFetchedAt:  now,
+		}}, nil)
+		storage.GetLocalizedMock.Return(nil, nil)
+		storage.UpsertLocalizedMock.Return(nil)
+
+		infos, err := svc.GetTrackingInfo(context.Background(), "123", "ru")
+		if err != nil {
+			t.Fatalf("failed to get tracking info: %v", err)
+		}
+		results, err := svc.GetTrackingInfoBatch(context.Background(), []string{"123"}, "ru")
+		if err != nil {
+			t.Fatalf("failed to get tracking info batch: %v", err)
+		}
+		if len(results) != 1 || results[0].Err != nil {
+			t.Fatalf("expected single successful result, got %+v", results)
+		}
+		assertDescriptions(t, descriptions(t, results[0].TrackingInfos), descriptions(t, infos)...)
+	})
+
+	t.Run("default language is left as is", func(t *testing.T) {
+		svc, _, api1 := prepareTestSubjects()
+
+		infos, err := svc.GetTrackingInfo(context.Background(), "123", service.DefaultLanguage)
+		if err != nil {
+			t.Fatalf("failed to get tracking info: %v", err)
+		}
+		assertDescriptions(t, descriptions(t, infos), "Accepted", "Arrived at customs")
+		if api1.localizedFetches != 0 {
+			t.Fatalf("expected carrier not to be asked")
+		}
+	})
+}
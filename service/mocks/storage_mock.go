@@ -16,23 +16,89 @@ import (
 type StorageMock struct {
 	t minimock.Tester
 
+	funcCountDueForRefresh          func(ctx context.Context, query mm_service.RefreshQuery) (i1 int, err error)
+	inspectFuncCountDueForRefresh   func(ctx context.Context, query mm_service.RefreshQuery)
+	afterCountDueForRefreshCounter  uint64
+	beforeCountDueForRefreshCounter uint64
+	CountDueForRefreshMock          mStorageMockCountDueForRefresh
+
+	funcGetDueForRefresh          func(ctx context.Context, query mm_service.RefreshQuery) (ppa1 []*mm_service.PostalApiResponse, err error)
+	inspectFuncGetDueForRefresh   func(ctx context.Context, query mm_service.RefreshQuery)
+	afterGetDueForRefreshCounter  uint64
+	beforeGetDueForRefreshCounter uint64
+	GetDueForRefreshMock          mStorageMockGetDueForRefresh
+
+	funcGetFinalResponsesAfter          func(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int) (ppa1 []*mm_service.PostalApiResponse, err error)
+	inspectFuncGetFinalResponsesAfter   func(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int)
+	afterGetFinalResponsesAfterCounter  uint64
+	beforeGetFinalResponsesAfterCounter uint64
+	GetFinalResponsesAfterMock          mStorageMockGetFinalResponsesAfter
+
+	funcGetHistory          func(ctx context.Context, trackingNumber string, apiName mm_service.APIName) (ppa1 []*mm_service.PostalApiResponse, err error)
+	inspectFuncGetHistory   func(ctx context.Context, trackingNumber string, apiName mm_service.APIName)
+	afterGetHistoryCounter  uint64
+	beforeGetHistoryCounter uint64
+	GetHistoryMock          mStorageMockGetHistory
+
 	funcGetLatest          func(ctx context.Context, trackingNumber string, apiNames []mm_service.APIName) (ppa1 []*mm_service.PostalApiResponse, err error)
 	inspectFuncGetLatest   func(ctx context.Context, trackingNumber string, apiNames []mm_service.APIName)
 	afterGetLatestCounter  uint64
 	beforeGetLatestCounter uint64
 	GetLatestMock          mStorageMockGetLatest
 
+	funcGetLatestBatch          func(ctx context.Context, trackingNumbers []string, apiNames []mm_service.APIName) (ppa1 []*mm_service.PostalApiResponse, err error)
+	inspectFuncGetLatestBatch   func(ctx context.Context, trackingNumbers []string, apiNames []mm_service.APIName)
+	afterGetLatestBatchCounter  uint64
+	beforeGetLatestBatchCounter uint64
+	GetLatestBatchMock          mStorageMockGetLatestBatch
+
+	funcGetLinks          func(ctx context.Context, trackingNumber string) (sa1 []string, err error)
+	inspectFuncGetLinks   func(ctx context.Context, trackingNumber string)
+	afterGetLinksCounter  uint64
+	beforeGetLinksCounter uint64
+	GetLinksMock          mStorageMockGetLinks
+
+	funcGetLocalized          func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, language string) (pp1 *mm_service.PostalApiResponse, err error)
+	inspectFuncGetLocalized   func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, language string)
+	afterGetLocalizedCounter  uint64
+	beforeGetLocalizedCounter uint64
+	GetLocalizedMock          mStorageMockGetLocalized
+
+	funcGetResponsesAfter          func(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int) (ppa1 []*mm_service.PostalApiResponse, err error)
+	inspectFuncGetResponsesAfter   func(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int)
+	afterGetResponsesAfterCounter  uint64
+	beforeGetResponsesAfterCounter uint64
+	GetResponsesAfterMock          mStorageMockGetResponsesAfter
+
 	funcInsert          func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) (err error)
 	inspectFuncInsert   func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse)
 	afterInsertCounter  uint64
 	beforeInsertCounter uint64
 	InsertMock          mStorageMockInsert
 
+	funcInsertLinks          func(ctx context.Context, trackingNumber string, linkedTrackingNumbers []string) (err error)
+	inspectFuncInsertLinks   func(ctx context.Context, trackingNumber string, linkedTrackingNumbers []string)
+	afterInsertLinksCounter  uint64
+	beforeInsertLinksCounter uint64
+	InsertLinksMock          mStorageMockInsertLinks
+
 	funcUpdate          func(ctx context.Context, pp1 *mm_service.PostalApiResponse) (err error)
 	inspectFuncUpdate   func(ctx context.Context, pp1 *mm_service.PostalApiResponse)
 	afterUpdateCounter  uint64
 	beforeUpdateCounter uint64
 	UpdateMock          mStorageMockUpdate
+
+	funcUpsert          func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) (err error)
+	inspectFuncUpsert   func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse)
+	afterUpsertCounter  uint64
+	beforeUpsertCounter uint64
+	UpsertMock          mStorageMockUpsert
+
+	funcUpsertLocalized          func(ctx context.Context, response *mm_service.PostalApiResponse) (err error)
+	inspectFuncUpsertLocalized   func(ctx context.Context, response *mm_service.PostalApiResponse)
+	afterUpsertLocalizedCounter  uint64
+	beforeUpsertLocalizedCounter uint64
+	UpsertLocalizedMock          mStorageMockUpsertLocalized
 }
 
 // NewStorageMock returns a mock for service.Storage
@@ -42,678 +108,3128 @@ func NewStorageMock(t minimock.Tester) *StorageMock {
 		controller.RegisterMocker(m)
 	}
 
+	m.CountDueForRefreshMock = mStorageMockCountDueForRefresh{mock: m}
+	m.CountDueForRefreshMock.callArgs = []*StorageMockCountDueForRefreshParams{}
+
+	m.GetDueForRefreshMock = mStorageMockGetDueForRefresh{mock: m}
+	m.GetDueForRefreshMock.callArgs = []*StorageMockGetDueForRefreshParams{}
+
+	m.GetFinalResponsesAfterMock = mStorageMockGetFinalResponsesAfter{mock: m}
+	m.GetFinalResponsesAfterMock.callArgs = []*StorageMockGetFinalResponsesAfterParams{}
+
+	m.GetHistoryMock = mStorageMockGetHistory{mock: m}
+	m.GetHistoryMock.callArgs = []*StorageMockGetHistoryParams{}
+
 	m.GetLatestMock = mStorageMockGetLatest{mock: m}
 	m.GetLatestMock.callArgs = []*StorageMockGetLatestParams{}
 
+	m.GetLatestBatchMock = mStorageMockGetLatestBatch{mock: m}
+	m.GetLatestBatchMock.callArgs = []*StorageMockGetLatestBatchParams{}
+
+	m.GetLinksMock = mStorageMockGetLinks{mock: m}
+	m.GetLinksMock.callArgs = []*StorageMockGetLinksParams{}
+
+	m.GetLocalizedMock = mStorageMockGetLocalized{mock: m}
+	m.GetLocalizedMock.callArgs = []*StorageMockGetLocalizedParams{}
+
+	m.GetResponsesAfterMock = mStorageMockGetResponsesAfter{mock: m}
+	m.GetResponsesAfterMock.callArgs = []*StorageMockGetResponsesAfterParams{}
+
 	m.InsertMock = mStorageMockInsert{mock: m}
 	m.InsertMock.callArgs = []*StorageMockInsertParams{}
 
+	m.InsertLinksMock = mStorageMockInsertLinks{mock: m}
+	m.InsertLinksMock.callArgs = []*StorageMockInsertLinksParams{}
+
 	m.UpdateMock = mStorageMockUpdate{mock: m}
 	m.UpdateMock.callArgs = []*StorageMockUpdateParams{}
 
+	m.UpsertMock = mStorageMockUpsert{mock: m}
+	m.UpsertMock.callArgs = []*StorageMockUpsertParams{}
+
+	m.UpsertLocalizedMock = mStorageMockUpsertLocalized{mock: m}
+	m.UpsertLocalizedMock.callArgs = []*StorageMockUpsertLocalizedParams{}
+
 	return m
 }
 
-type mStorageMockGetLatest struct {
+type mStorageMockCountDueForRefresh struct {
 	mock               *StorageMock
-	defaultExpectation *StorageMockGetLatestExpectation
-	expectations       []*StorageMockGetLatestExpectation
+	defaultExpectation *StorageMockCountDueForRefreshExpectation
+	expectations       []*StorageMockCountDueForRefreshExpectation
 
-	callArgs []*StorageMockGetLatestParams
+	callArgs []*StorageMockCountDueForRefreshParams
 	mutex    sync.RWMutex
 }
 
-// StorageMockGetLatestExpectation specifies expectation struct of the Storage.GetLatest
-type StorageMockGetLatestExpectation struct {
+// StorageMockCountDueForRefreshExpectation specifies expectation struct of the Storage.CountDueForRefresh
+type StorageMockCountDueForRefreshExpectation struct {
 	mock    *StorageMock
-	params  *StorageMockGetLatestParams
-	results *StorageMockGetLatestResults
+	params  *StorageMockCountDueForRefreshParams
+	results *StorageMockCountDueForRefreshResults
 	Counter uint64
 }
 
-// StorageMockGetLatestParams contains parameters of the Storage.GetLatest
-type StorageMockGetLatestParams struct {
-	ctx            context.Context
-	trackingNumber string
-	apiNames       []mm_service.APIName
+// StorageMockCountDueForRefreshParams contains parameters of the Storage.CountDueForRefresh
+type StorageMockCountDueForRefreshParams struct {
+	ctx   context.Context
+	query mm_service.RefreshQuery
 }
 
-// StorageMockGetLatestResults contains results of the Storage.GetLatest
-type StorageMockGetLatestResults struct {
+// StorageMockCountDueForRefreshResults contains results of the Storage.CountDueForRefresh
+type StorageMockCountDueForRefreshResults struct {
+	i1  int
+	err error
+}
+
+// Expect sets up expected params for Storage.CountDueForRefresh
+func (mmCountDueForRefresh *mStorageMockCountDueForRefresh) Expect(ctx context.Context, query mm_service.RefreshQuery) *mStorageMockCountDueForRefresh {
+	if mmCountDueForRefresh.mock.funcCountDueForRefresh != nil {
+		mmCountDueForRefresh.mock.t.Fatalf("StorageMock.CountDueForRefresh mock is already set by Set")
+	}
+
+	if mmCountDueForRefresh.defaultExpectation == nil {
+		mmCountDueForRefresh.defaultExpectation = &StorageMockCountDueForRefreshExpectation{}
+	}
+
+	mmCountDueForRefresh.defaultExpectation.params = &StorageMockCountDueForRefreshParams{ctx, query}
+	for _, e := range mmCountDueForRefresh.expectations {
+		if minimock.Equal(e.params, mmCountDueForRefresh.defaultExpectation.params) {
+			mmCountDueForRefresh.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmCountDueForRefresh.defaultExpectation.params)
+		}
+	}
+
+	return mmCountDueForRefresh
+}
+
+// Inspect accepts an inspector function that has same arguments as the Storage.CountDueForRefresh
+func (mmCountDueForRefresh *mStorageMockCountDueForRefresh) Inspect(f func(ctx context.Context, query mm_service.RefreshQuery)) *mStorageMockCountDueForRefresh {
+	if mmCountDueForRefresh.mock.inspectFuncCountDueForRefresh != nil {
+		mmCountDueForRefresh.mock.t.Fatalf("Inspect function is already set for StorageMock.CountDueForRefresh")
+	}
+
+	mmCountDueForRefresh.mock.inspectFuncCountDueForRefresh = f
+
+	return mmCountDueForRefresh
+}
+
+// Return sets up results that will be returned by Storage.CountDueForRefresh
+func (mmCountDueForRefresh *mStorageMockCountDueForRefresh) Return(i1 int, err error) *StorageMock {
+	if mmCountDueForRefresh.mock.funcCountDueForRefresh != nil {
+		mmCountDueForRefresh.mock.t.Fatalf("StorageMock.CountDueForRefresh mock is already set by Set")
+	}
+
+	if mmCountDueForRefresh.defaultExpectation == nil {
+		mmCountDueForRefresh.defaultExpectation = &StorageMockCountDueForRefreshExpectation{mock: mmCountDueForRefresh.mock}
+	}
+	mmCountDueForRefresh.defaultExpectation.results = &StorageMockCountDueForRefreshResults{i1, err}
+	return mmCountDueForRefresh.mock
+}
+
+// Set uses given function f to mock the Storage.CountDueForRefresh method
+func (mmCountDueForRefresh *mStorageMockCountDueForRefresh) Set(f func(ctx context.Context, query mm_service.RefreshQuery) (i1 int, err error)) *StorageMock {
+	if mmCountDueForRefresh.defaultExpectation != nil {
+		mmCountDueForRefresh.mock.t.Fatalf("Default expectation is already set for the Storage.CountDueForRefresh method")
+	}
+
+	if len(mmCountDueForRefresh.expectations) > 0 {
+		mmCountDueForRefresh.mock.t.Fatalf("Some expectations are already set for the Storage.CountDueForRefresh method")
+	}
+
+	mmCountDueForRefresh.mock.funcCountDueForRefresh = f
+	return mmCountDueForRefresh.mock
+}
+
+// When sets expectation for the Storage.CountDueForRefresh which will trigger the result defined by the following
+// Then helper
+func (mmCountDueForRefresh *mStorageMockCountDueForRefresh) When(ctx context.Context, query mm_service.RefreshQuery) *StorageMockCountDueForRefreshExpectation {
+	if mmCountDueForRefresh.mock.funcCountDueForRefresh != nil {
+		mmCountDueForRefresh.mock.t.Fatalf("StorageMock.CountDueForRefresh mock is already set by Set")
+	}
+
+	expectation := &StorageMockCountDueForRefreshExpectation{
+		mock:   mmCountDueForRefresh.mock,
+		params: &StorageMockCountDueForRefreshParams{ctx, query},
+	}
+	mmCountDueForRefresh.expectations = append(mmCountDueForRefresh.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Storage.CountDueForRefresh return parameters for the expectation previously defined by the When method
+func (e *StorageMockCountDueForRefreshExpectation) Then(i1 int, err error) *StorageMock {
+	e.results = &StorageMockCountDueForRefreshResults{i1, err}
+	return e.mock
+}
+
+// CountDueForRefresh implements service.Storage
+func (mmCountDueForRefresh *StorageMock) CountDueForRefresh(ctx context.Context, query mm_service.RefreshQuery) (i1 int, err error) {
+	mm_atomic.AddUint64(&mmCountDueForRefresh.beforeCountDueForRefreshCounter, 1)
+	defer mm_atomic.AddUint64(&mmCountDueForRefresh.afterCountDueForRefreshCounter, 1)
+
+	if mmCountDueForRefresh.inspectFuncCountDueForRefresh != nil {
+		mmCountDueForRefresh.inspectFuncCountDueForRefresh(ctx, query)
+	}
+
+	mm_params := &StorageMockCountDueForRefreshParams{ctx, query}
+
+	// Record call args
+	mmCountDueForRefresh.CountDueForRefreshMock.mutex.Lock()
+	mmCountDueForRefresh.CountDueForRefreshMock.callArgs = append(mmCountDueForRefresh.CountDueForRefreshMock.callArgs, mm_params)
+	mmCountDueForRefresh.CountDueForRefreshMock.mutex.Unlock()
+
+	for _, e := range mmCountDueForRefresh.CountDueForRefreshMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.i1, e.results.err
+		}
+	}
+
+	if mmCountDueForRefresh.CountDueForRefreshMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmCountDueForRefresh.CountDueForRefreshMock.defaultExpectation.Counter, 1)
+		mm_want := mmCountDueForRefresh.CountDueForRefreshMock.defaultExpectation.params
+		mm_got := StorageMockCountDueForRefreshParams{ctx, query}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmCountDueForRefresh.t.Errorf("StorageMock.CountDueForRefresh got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmCountDueForRefresh.CountDueForRefreshMock.defaultExpectation.results
+		if mm_results == nil {
+			mmCountDueForRefresh.t.Fatal("No results are set for the StorageMock.CountDueForRefresh")
+		}
+		return (*mm_results).i1, (*mm_results).err
+	}
+	if mmCountDueForRefresh.funcCountDueForRefresh != nil {
+		return mmCountDueForRefresh.funcCountDueForRefresh(ctx, query)
+	}
+	mmCountDueForRefresh.t.Fatalf("Unexpected call to StorageMock.CountDueForRefresh. %v %v", ctx, query)
+	return
+}
+
+// CountDueForRefreshAfterCounter returns a count of finished StorageMock.CountDueForRefresh invocations
+func (mmCountDueForRefresh *StorageMock) CountDueForRefreshAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmCountDueForRefresh.afterCountDueForRefreshCounter)
+}
+
+// CountDueForRefreshBeforeCounter returns a count of StorageMock.CountDueForRefresh invocations
+func (mmCountDueForRefresh *StorageMock) CountDueForRefreshBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmCountDueForRefresh.beforeCountDueForRefreshCounter)
+}
+
+// Calls returns a list of arguments used in each call to StorageMock.CountDueForRefresh.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmCountDueForRefresh *mStorageMockCountDueForRefresh) Calls() []*StorageMockCountDueForRefreshParams {
+	mmCountDueForRefresh.mutex.RLock()
+
+	argCopy := make([]*StorageMockCountDueForRefreshParams, len(mmCountDueForRefresh.callArgs))
+	copy(argCopy, mmCountDueForRefresh.callArgs)
+
+	mmCountDueForRefresh.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockCountDueForRefreshDone returns true if the count of the CountDueForRefresh invocations corresponds
+// the number of defined expectations
+func (m *StorageMock) MinimockCountDueForRefreshDone() bool {
+	for _, e := range m.CountDueForRefreshMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.CountDueForRefreshMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterCountDueForRefreshCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcCountDueForRefresh != nil && mm_atomic.LoadUint64(&m.afterCountDueForRefreshCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockCountDueForRefreshInspect logs each unmet expectation
+func (m *StorageMock) MinimockCountDueForRefreshInspect() {
+	for _, e := range m.CountDueForRefreshMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to StorageMock.CountDueForRefresh with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.CountDueForRefreshMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterCountDueForRefreshCounter) < 1 {
+		if m.CountDueForRefreshMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.CountDueForRefresh")
+		} else {
+			m.t.Errorf("Expected call to StorageMock.CountDueForRefresh with params: %#v", *m.CountDueForRefreshMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcCountDueForRefresh != nil && mm_atomic.LoadUint64(&m.afterCountDueForRefreshCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.CountDueForRefresh")
+	}
+}
+
+type mStorageMockGetDueForRefresh struct {
+	mock               *StorageMock
+	defaultExpectation *StorageMockGetDueForRefreshExpectation
+	expectations       []*StorageMockGetDueForRefreshExpectation
+
+	callArgs []*StorageMockGetDueForRefreshParams
+	mutex    sync.RWMutex
+}
+
+// StorageMockGetDueForRefreshExpectation specifies expectation struct of the Storage.GetDueForRefresh
+type StorageMockGetDueForRefreshExpectation struct {
+	mock    *StorageMock
+	params  *StorageMockGetDueForRefreshParams
+	results *StorageMockGetDueForRefreshResults
+	Counter uint64
+}
+
+// StorageMockGetDueForRefreshParams contains parameters of the Storage.GetDueForRefresh
+type StorageMockGetDueForRefreshParams struct {
+	ctx   context.Context
+	query mm_service.RefreshQuery
+}
+
+// StorageMockGetDueForRefreshResults contains results of the Storage.GetDueForRefresh
+type StorageMockGetDueForRefreshResults struct {
 	ppa1 []*mm_service.PostalApiResponse
 	err  error
 }
 
-// Expect sets up expected params for Storage.GetLatest
-func (mmGetLatest *mStorageMockGetLatest) Expect(ctx context.Context, trackingNumber string, apiNames []mm_service.APIName) *mStorageMockGetLatest {
-	if mmGetLatest.mock.funcGetLatest != nil {
-		mmGetLatest.mock.t.Fatalf("StorageMock.GetLatest mock is already set by Set")
+// Expect sets up expected params for Storage.GetDueForRefresh
+func (mmGetDueForRefresh *mStorageMockGetDueForRefresh) Expect(ctx context.Context, query mm_service.RefreshQuery) *mStorageMockGetDueForRefresh {
+	if mmGetDueForRefresh.mock.funcGetDueForRefresh != nil {
+		mmGetDueForRefresh.mock.t.Fatalf("StorageMock.GetDueForRefresh mock is already set by Set")
 	}
 
-	if mmGetLatest.defaultExpectation == nil {
-		mmGetLatest.defaultExpectation = &StorageMockGetLatestExpectation{}
+	if mmGetDueForRefresh.defaultExpectation == nil {
+		mmGetDueForRefresh.defaultExpectation = &StorageMockGetDueForRefreshExpectation{}
 	}
 
-	mmGetLatest.defaultExpectation.params = &StorageMockGetLatestParams{ctx, trackingNumber, apiNames}
-	for _, e := range mmGetLatest.expectations {
-		if minimock.Equal(e.params, mmGetLatest.defaultExpectation.params) {
-			mmGetLatest.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmGetLatest.defaultExpectation.params)
+	mmGetDueForRefresh.defaultExpectation.params = &StorageMockGetDueForRefreshParams{ctx, query}
+	for _, e := range mmGetDueForRefresh.expectations {
+		if minimock.Equal(e.params, mmGetDueForRefresh.defaultExpectation.params) {
+			mmGetDueForRefresh.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmGetDueForRefresh.defaultExpectation.params)
 		}
 	}
 
-	return mmGetLatest
+	return mmGetDueForRefresh
 }
 
-// Inspect accepts an inspector function that has same arguments as the Storage.GetLatest
-func (mmGetLatest *mStorageMockGetLatest) Inspect(f func(ctx context.Context, trackingNumber string, apiNames []mm_service.APIName)) *mStorageMockGetLatest {
-	if mmGetLatest.mock.inspectFuncGetLatest != nil {
-		mmGetLatest.mock.t.Fatalf("Inspect function is already set for StorageMock.GetLatest")
+// Inspect accepts an inspector function that has same arguments as the Storage.GetDueForRefresh
+func (mmGetDueForRefresh *mStorageMockGetDueForRefresh) Inspect(f func(ctx context.Context, query mm_service.RefreshQuery)) *mStorageMockGetDueForRefresh {
+	if mmGetDueForRefresh.mock.inspectFuncGetDueForRefresh != nil {
+		mmGetDueForRefresh.mock.t.Fatalf("Inspect function is already set for StorageMock.GetDueForRefresh")
 	}
 
-	mmGetLatest.mock.inspectFuncGetLatest = f
+	mmGetDueForRefresh.mock.inspectFuncGetDueForRefresh = f
 
-	return mmGetLatest
+	return mmGetDueForRefresh
 }
 
-// Return sets up results that will be returned by Storage.GetLatest
-func (mmGetLatest *mStorageMockGetLatest) Return(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
-	if mmGetLatest.mock.funcGetLatest != nil {
-		mmGetLatest.mock.t.Fatalf("StorageMock.GetLatest mock is already set by Set")
+// Return sets up results that will be returned by Storage.GetDueForRefresh
+func (mmGetDueForRefresh *mStorageMockGetDueForRefresh) Return(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
+	if mmGetDueForRefresh.mock.funcGetDueForRefresh != nil {
+		mmGetDueForRefresh.mock.t.Fatalf("StorageMock.GetDueForRefresh mock is already set by Set")
 	}
 
-	if mmGetLatest.defaultExpectation == nil {
-		mmGetLatest.defaultExpectation = &StorageMockGetLatestExpectation{mock: mmGetLatest.mock}
+	if mmGetDueForRefresh.defaultExpectation == nil {
+		mmGetDueForRefresh.defaultExpectation = &StorageMockGetDueForRefreshExpectation{mock: mmGetDueForRefresh.mock}
 	}
-	mmGetLatest.defaultExpectation.results = &StorageMockGetLatestResults{ppa1, err}
-	return mmGetLatest.mock
+	mmGetDueForRefresh.defaultExpectation.results = &StorageMockGetDueForRefreshResults{ppa1, err}
+	return mmGetDueForRefresh.mock
 }
 
-// Set uses given function f to mock the Storage.GetLatest method
-func (mmGetLatest *mStorageMockGetLatest) Set(f func(ctx context.Context, trackingNumber string, apiNames []mm_service.APIName) (ppa1 []*mm_service.PostalApiResponse, err error)) *StorageMock {
-	if mmGetLatest.defaultExpectation != nil {
-		mmGetLatest.mock.t.Fatalf("Default expectation is already set for the Storage.GetLatest method")
+// Set uses given function f to mock the Storage.GetDueForRefresh method
+func (mmGetDueForRefresh *mStorageMockGetDueForRefresh) Set(f func(ctx context.Context, query mm_service.RefreshQuery) (ppa1 []*mm_service.PostalApiResponse, err error)) *StorageMock {
+	if mmGetDueForRefresh.defaultExpectation != nil {
+		mmGetDueForRefresh.mock.t.Fatalf("Default expectation is already set for the Storage.GetDueForRefresh method")
 	}
 
-	if len(mmGetLatest.expectations) > 0 {
-		mmGetLatest.mock.t.Fatalf("Some expectations are already set for the Storage.GetLatest method")
+	if len(mmGetDueForRefresh.expectations) > 0 {
+		mmGetDueForRefresh.mock.t.Fatalf("Some expectations are already set for the Storage.GetDueForRefresh method")
 	}
 
-	mmGetLatest.mock.funcGetLatest = f
-	return mmGetLatest.mock
+	mmGetDueForRefresh.mock.funcGetDueForRefresh = f
+	return mmGetDueForRefresh.mock
 }
 
-// When sets expectation for the Storage.GetLatest which will trigger the result defined by the following
+// When sets expectation for the Storage.GetDueForRefresh which will trigger the result defined by the following
 // Then helper
-func (mmGetLatest *mStorageMockGetLatest) When(ctx context.Context, trackingNumber string, apiNames []mm_service.APIName) *StorageMockGetLatestExpectation {
-	if mmGetLatest.mock.funcGetLatest != nil {
-		mmGetLatest.mock.t.Fatalf("StorageMock.GetLatest mock is already set by Set")
+func (mmGetDueForRefresh *mStorageMockGetDueForRefresh) When(ctx context.Context, query mm_service.RefreshQuery) *StorageMockGetDueForRefreshExpectation {
+	if mmGetDueForRefresh.mock.funcGetDueForRefresh != nil {
+		mmGetDueForRefresh.mock.t.Fatalf("StorageMock.GetDueForRefresh mock is already set by Set")
 	}
 
-	expectation := &StorageMockGetLatestExpectation{
-		mock:   mmGetLatest.mock,
-		params: &StorageMockGetLatestParams{ctx, trackingNumber, apiNames},
+	expectation := &StorageMockGetDueForRefreshExpectation{
+		mock:   mmGetDueForRefresh.mock,
+		params: &StorageMockGetDueForRefreshParams{ctx, query},
 	}
-	mmGetLatest.expectations = append(mmGetLatest.expectations, expectation)
+	mmGetDueForRefresh.expectations = append(mmGetDueForRefresh.expectations, expectation)
 	return expectation
 }
 
-// Then sets up Storage.GetLatest return parameters for the expectation previously defined by the When method
-func (e *StorageMockGetLatestExpectation) Then(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
-	e.results = &StorageMockGetLatestResults{ppa1, err}
+// Then sets up Storage.GetDueForRefresh return parameters for the expectation previously defined by the When method
+func (e *StorageMockGetDueForRefreshExpectation) Then(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
+	e.results = &StorageMockGetDueForRefreshResults{ppa1, err}
 	return e.mock
 }
 
-// GetLatest implements service.Storage
-func (mmGetLatest *StorageMock) GetLatest(ctx context.Context, trackingNumber string, apiNames []mm_service.APIName) (ppa1 []*mm_service.PostalApiResponse, err error) {
-	mm_atomic.AddUint64(&mmGetLatest.beforeGetLatestCounter, 1)
-	defer mm_atomic.AddUint64(&mmGetLatest.afterGetLatestCounter, 1)
+// GetDueForRefresh implements service.Storage
+func (mmGetDueForRefresh *StorageMock) GetDueForRefresh(ctx context.Context, query mm_service.RefreshQuery) (ppa1 []*mm_service.PostalApiResponse, err error) {
+	mm_atomic.AddUint64(&mmGetDueForRefresh.beforeGetDueForRefreshCounter, 1)
+	defer mm_atomic.AddUint64(&mmGetDueForRefresh.afterGetDueForRefreshCounter, 1)
 
-	if mmGetLatest.inspectFuncGetLatest != nil {
-		mmGetLatest.inspectFuncGetLatest(ctx, trackingNumber, apiNames)
+	if mmGetDueForRefresh.inspectFuncGetDueForRefresh != nil {
+		mmGetDueForRefresh.inspectFuncGetDueForRefresh(ctx, query)
 	}
 
-	mm_params := &StorageMockGetLatestParams{ctx, trackingNumber, apiNames}
+	mm_params := &StorageMockGetDueForRefreshParams{ctx, query}
 
 	// Record call args
-	mmGetLatest.GetLatestMock.mutex.Lock()
-	mmGetLatest.GetLatestMock.callArgs = append(mmGetLatest.GetLatestMock.callArgs, mm_params)
-	mmGetLatest.GetLatestMock.mutex.Unlock()
+	mmGetDueForRefresh.GetDueForRefreshMock.mutex.Lock()
+	mmGetDueForRefresh.GetDueForRefreshMock.callArgs = append(mmGetDueForRefresh.GetDueForRefreshMock.callArgs, mm_params)
+	mmGetDueForRefresh.GetDueForRefreshMock.mutex.Unlock()
 
-	for _, e := range mmGetLatest.GetLatestMock.expectations {
+	for _, e := range mmGetDueForRefresh.GetDueForRefreshMock.expectations {
 		if minimock.Equal(e.params, mm_params) {
 			mm_atomic.AddUint64(&e.Counter, 1)
 			return e.results.ppa1, e.results.err
 		}
 	}
 
-	if mmGetLatest.GetLatestMock.defaultExpectation != nil {
-		mm_atomic.AddUint64(&mmGetLatest.GetLatestMock.defaultExpectation.Counter, 1)
-		mm_want := mmGetLatest.GetLatestMock.defaultExpectation.params
-		mm_got := StorageMockGetLatestParams{ctx, trackingNumber, apiNames}
+	if mmGetDueForRefresh.GetDueForRefreshMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmGetDueForRefresh.GetDueForRefreshMock.defaultExpectation.Counter, 1)
+		mm_want := mmGetDueForRefresh.GetDueForRefreshMock.defaultExpectation.params
+		mm_got := StorageMockGetDueForRefreshParams{ctx, query}
 		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
-			mmGetLatest.t.Errorf("StorageMock.GetLatest got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+			mmGetDueForRefresh.t.Errorf("StorageMock.GetDueForRefresh got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
 		}
 
-		mm_results := mmGetLatest.GetLatestMock.defaultExpectation.results
+		mm_results := mmGetDueForRefresh.GetDueForRefreshMock.defaultExpectation.results
 		if mm_results == nil {
-			mmGetLatest.t.Fatal("No results are set for the StorageMock.GetLatest")
+			mmGetDueForRefresh.t.Fatal("No results are set for the StorageMock.GetDueForRefresh")
 		}
 		return (*mm_results).ppa1, (*mm_results).err
 	}
-	if mmGetLatest.funcGetLatest != nil {
-		return mmGetLatest.funcGetLatest(ctx, trackingNumber, apiNames)
+	if mmGetDueForRefresh.funcGetDueForRefresh != nil {
+		return mmGetDueForRefresh.funcGetDueForRefresh(ctx, query)
 	}
-	mmGetLatest.t.Fatalf("Unexpected call to StorageMock.GetLatest. %v %v %v", ctx, trackingNumber, apiNames)
+	mmGetDueForRefresh.t.Fatalf("Unexpected call to StorageMock.GetDueForRefresh. %v %v", ctx, query)
 	return
 }
 
-// GetLatestAfterCounter returns a count of finished StorageMock.GetLatest invocations
-func (mmGetLatest *StorageMock) GetLatestAfterCounter() uint64 {
-	return mm_atomic.LoadUint64(&mmGetLatest.afterGetLatestCounter)
+// GetDueForRefreshAfterCounter returns a count of finished StorageMock.GetDueForRefresh invocations
+func (mmGetDueForRefresh *StorageMock) GetDueForRefreshAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetDueForRefresh.afterGetDueForRefreshCounter)
 }
 
-// GetLatestBeforeCounter returns a count of StorageMock.GetLatest invocations
-func (mmGetLatest *StorageMock) GetLatestBeforeCounter() uint64 {
-	return mm_atomic.LoadUint64(&mmGetLatest.beforeGetLatestCounter)
+// GetDueForRefreshBeforeCounter returns a count of StorageMock.GetDueForRefresh invocations
+func (mmGetDueForRefresh *StorageMock) GetDueForRefreshBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetDueForRefresh.beforeGetDueForRefreshCounter)
 }
 
-// Calls returns a list of arguments used in each call to StorageMock.GetLatest.
+// Calls returns a list of arguments used in each call to StorageMock.GetDueForRefresh.
 // The list is in the same order as the calls were made (i.e. recent calls have a higher index)
-func (mmGetLatest *mStorageMockGetLatest) Calls() []*StorageMockGetLatestParams {
-	mmGetLatest.mutex.RLock()
+func (mmGetDueForRefresh *mStorageMockGetDueForRefresh) Calls() []*StorageMockGetDueForRefreshParams {
+	mmGetDueForRefresh.mutex.RLock()
 
-	argCopy := make([]*StorageMockGetLatestParams, len(mmGetLatest.callArgs))
-	copy(argCopy, mmGetLatest.callArgs)
+	argCopy := make([]*StorageMockGetDueForRefreshParams, len(mmGetDueForRefresh.callArgs))
+	copy(argCopy, mmGetDueForRefresh.callArgs)
+
+	mmGetDueForRefresh.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockGetDueForRefreshDone returns true if the count of the GetDueForRefresh invocations corresponds
+// the number of defined expectations
+func (m *StorageMock) MinimockGetDueForRefreshDone() bool {
+	for _, e := range m.GetDueForRefreshMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetDueForRefreshMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetDueForRefreshCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetDueForRefresh != nil && mm_atomic.LoadUint64(&m.afterGetDueForRefreshCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockGetDueForRefreshInspect logs each unmet expectation
+func (m *StorageMock) MinimockGetDueForRefreshInspect() {
+	for _, e := range m.GetDueForRefreshMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to StorageMock.GetDueForRefresh with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetDueForRefreshMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetDueForRefreshCounter) < 1 {
+		if m.GetDueForRefreshMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.GetDueForRefresh")
+		} else {
+			m.t.Errorf("Expected call to StorageMock.GetDueForRefresh with params: %#v", *m.GetDueForRefreshMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetDueForRefresh != nil && mm_atomic.LoadUint64(&m.afterGetDueForRefreshCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.GetDueForRefresh")
+	}
+}
+
+type mStorageMockGetFinalResponsesAfter struct {
+	mock               *StorageMock
+	defaultExpectation *StorageMockGetFinalResponsesAfterExpectation
+	expectations       []*StorageMockGetFinalResponsesAfterExpectation
+
+	callArgs []*StorageMockGetFinalResponsesAfterParams
+	mutex    sync.RWMutex
+}
+
+// StorageMockGetFinalResponsesAfterExpectation specifies expectation struct of the Storage.GetFinalResponsesAfter
+type StorageMockGetFinalResponsesAfterExpectation struct {
+	mock    *StorageMock
+	params  *StorageMockGetFinalResponsesAfterParams
+	results *StorageMockGetFinalResponsesAfterResults
+	Counter uint64
+}
+
+// StorageMockGetFinalResponsesAfterParams contains parameters of the Storage.GetFinalResponsesAfter
+type StorageMockGetFinalResponsesAfterParams struct {
+	ctx     context.Context
+	apiName mm_service.APIName
+	afterID int64
+	limit   int
+}
+
+// StorageMockGetFinalResponsesAfterResults contains results of the Storage.GetFinalResponsesAfter
+type StorageMockGetFinalResponsesAfterResults struct {
+	ppa1 []*mm_service.PostalApiResponse
+	err  error
+}
+
+// Expect sets up expected params for Storage.GetFinalResponsesAfter
+func (mmGetFinalResponsesAfter *mStorageMockGetFinalResponsesAfter) Expect(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int) *mStorageMockGetFinalResponsesAfter {
+	if mmGetFinalResponsesAfter.mock.funcGetFinalResponsesAfter != nil {
+		mmGetFinalResponsesAfter.mock.t.Fatalf("StorageMock.GetFinalResponsesAfter mock is already set by Set")
+	}
+
+	if mmGetFinalResponsesAfter.defaultExpectation == nil {
+		mmGetFinalResponsesAfter.defaultExpectation = &StorageMockGetFinalResponsesAfterExpectation{}
+	}
+
+	mmGetFinalResponsesAfter.defaultExpectation.params = &StorageMockGetFinalResponsesAfterParams{ctx, apiName, afterID, limit}
+	for _, e := range mmGetFinalResponsesAfter.expectations {
+		if minimock.Equal(e.params, mmGetFinalResponsesAfter.defaultExpectation.params) {
+			mmGetFinalResponsesAfter.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmGetFinalResponsesAfter.defaultExpectation.params)
+		}
+	}
+
+	return mmGetFinalResponsesAfter
+}
+
+// Inspect accepts an inspector function that has same arguments as the Storage.GetFinalResponsesAfter
+func (mmGetFinalResponsesAfter *mStorageMockGetFinalResponsesAfter) Inspect(f func(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int)) *mStorageMockGetFinalResponsesAfter {
+	if mmGetFinalResponsesAfter.mock.inspectFuncGetFinalResponsesAfter != nil {
+		mmGetFinalResponsesAfter.mock.t.Fatalf("Inspect function is already set for StorageMock.GetFinalResponsesAfter")
+	}
+
+	mmGetFinalResponsesAfter.mock.inspectFuncGetFinalResponsesAfter = f
+
+	return mmGetFinalResponsesAfter
+}
+
+// Return sets up results that will be returned by Storage.GetFinalResponsesAfter
+func (mmGetFinalResponsesAfter *mStorageMockGetFinalResponsesAfter) Return(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
+	if mmGetFinalResponsesAfter.mock.funcGetFinalResponsesAfter != nil {
+		mmGetFinalResponsesAfter.mock.t.Fatalf("StorageMock.GetFinalResponsesAfter mock is already set by Set")
+	}
+
+	if mmGetFinalResponsesAfter.defaultExpectation == nil {
+		mmGetFinalResponsesAfter.defaultExpectation = &StorageMockGetFinalResponsesAfterExpectation{mock: mmGetFinalResponsesAfter.mock}
+	}
+	mmGetFinalResponsesAfter.defaultExpectation.results = &StorageMockGetFinalResponsesAfterResults{ppa1, err}
+	return mmGetFinalResponsesAfter.mock
+}
+
+// Set uses given function f to mock the Storage.GetFinalResponsesAfter method
+func (mmGetFinalResponsesAfter *mStorageMockGetFinalResponsesAfter) Set(f func(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int) (ppa1 []*mm_service.PostalApiResponse, err error)) *StorageMock {
+	if mmGetFinalResponsesAfter.defaultExpectation != nil {
+		mmGetFinalResponsesAfter.mock.t.Fatalf("Default expectation is already set for the Storage.GetFinalResponsesAfter method")
+	}
+
+	if len(mmGetFinalResponsesAfter.expectations) > 0 {
+		mmGetFinalResponsesAfter.mock.t.Fatalf("Some expectations are already set for the Storage.GetFinalResponsesAfter method")
+	}
+
+	mmGetFinalResponsesAfter.mock.funcGetFinalResponsesAfter = f
+	return mmGetFinalResponsesAfter.mock
+}
+
+// When sets expectation for the Storage.GetFinalResponsesAfter which will trigger the result defined by the following
+// Then helper
+func (mmGetFinalResponsesAfter *mStorageMockGetFinalResponsesAfter) When(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int) *StorageMockGetFinalResponsesAfterExpectation {
+	if mmGetFinalResponsesAfter.mock.funcGetFinalResponsesAfter != nil {
+		mmGetFinalResponsesAfter.mock.t.Fatalf("StorageMock.GetFinalResponsesAfter mock is already set by Set")
+	}
+
+	expectation := &StorageMockGetFinalResponsesAfterExpectation{
+		mock:   mmGetFinalResponsesAfter.mock,
+		params: &StorageMockGetFinalResponsesAfterParams{ctx, apiName, afterID, limit},
+	}
+	mmGetFinalResponsesAfter.expectations = append(mmGetFinalResponsesAfter.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Storage.GetFinalResponsesAfter return parameters for the expectation previously defined by the When method
+func (e *StorageMockGetFinalResponsesAfterExpectation) Then(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
+	e.results = &StorageMockGetFinalResponsesAfterResults{ppa1, err}
+	return e.mock
+}
+
+// GetFinalResponsesAfter implements service.Storage
+func (mmGetFinalResponsesAfter *StorageMock) GetFinalResponsesAfter(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int) (ppa1 []*mm_service.PostalApiResponse, err error) {
+	mm_atomic.AddUint64(&mmGetFinalResponsesAfter.beforeGetFinalResponsesAfterCounter, 1)
+	defer mm_atomic.AddUint64(&mmGetFinalResponsesAfter.afterGetFinalResponsesAfterCounter, 1)
+
+	if mmGetFinalResponsesAfter.inspectFuncGetFinalResponsesAfter != nil {
+		mmGetFinalResponsesAfter.inspectFuncGetFinalResponsesAfter(ctx, apiName, afterID, limit)
+	}
+
+	mm_params := &StorageMockGetFinalResponsesAfterParams{ctx, apiName, afterID, limit}
+
+	// Record call args
+	mmGetFinalResponsesAfter.GetFinalResponsesAfterMock.mutex.Lock()
+	mmGetFinalResponsesAfter.GetFinalResponsesAfterMock.callArgs = append(mmGetFinalResponsesAfter.GetFinalResponsesAfterMock.callArgs, mm_params)
+	mmGetFinalResponsesAfter.GetFinalResponsesAfterMock.mutex.Unlock()
+
+	for _, e := range mmGetFinalResponsesAfter.GetFinalResponsesAfterMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.ppa1, e.results.err
+		}
+	}
+
+	if mmGetFinalResponsesAfter.GetFinalResponsesAfterMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmGetFinalResponsesAfter.GetFinalResponsesAfterMock.defaultExpectation.Counter, 1)
+		mm_want := mmGetFinalResponsesAfter.GetFinalResponsesAfterMock.defaultExpectation.params
+		mm_got := StorageMockGetFinalResponsesAfterParams{ctx, apiName, afterID, limit}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmGetFinalResponsesAfter.t.Errorf("StorageMock.GetFinalResponsesAfter got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmGetFinalResponsesAfter.GetFinalResponsesAfterMock.defaultExpectation.results
+		if mm_results == nil {
+			mmGetFinalResponsesAfter.t.Fatal("No results are set for the StorageMock.GetFinalResponsesAfter")
+		}
+		return (*mm_results).ppa1, (*mm_results).err
+	}
+	if mmGetFinalResponsesAfter.funcGetFinalResponsesAfter != nil {
+		return mmGetFinalResponsesAfter.funcGetFinalResponsesAfter(ctx, apiName, afterID, limit)
+	}
+	mmGetFinalResponsesAfter.t.Fatalf("Unexpected call to StorageMock.GetFinalResponsesAfter. %v %v %v %v", ctx, apiName, afterID, limit)
+	return
+}
+
+// GetFinalResponsesAfterAfterCounter returns a count of finished StorageMock.GetFinalResponsesAfter invocations
+func (mmGetFinalResponsesAfter *StorageMock) GetFinalResponsesAfterAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetFinalResponsesAfter.afterGetFinalResponsesAfterCounter)
+}
+
+// GetFinalResponsesAfterBeforeCounter returns a count of StorageMock.GetFinalResponsesAfter invocations
+func (mmGetFinalResponsesAfter *StorageMock) GetFinalResponsesAfterBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetFinalResponsesAfter.beforeGetFinalResponsesAfterCounter)
+}
+
+// Calls returns a list of arguments used in each call to StorageMock.GetFinalResponsesAfter.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmGetFinalResponsesAfter *mStorageMockGetFinalResponsesAfter) Calls() []*StorageMockGetFinalResponsesAfterParams {
+	mmGetFinalResponsesAfter.mutex.RLock()
+
+	argCopy := make([]*StorageMockGetFinalResponsesAfterParams, len(mmGetFinalResponsesAfter.callArgs))
+	copy(argCopy, mmGetFinalResponsesAfter.callArgs)
+
+	mmGetFinalResponsesAfter.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockGetFinalResponsesAfterDone returns true if the count of the GetFinalResponsesAfter invocations corresponds
+// the number of defined expectations
+func (m *StorageMock) MinimockGetFinalResponsesAfterDone() bool {
+	for _, e := range m.GetFinalResponsesAfterMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetFinalResponsesAfterMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetFinalResponsesAfterCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetFinalResponsesAfter != nil && mm_atomic.LoadUint64(&m.afterGetFinalResponsesAfterCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockGetFinalResponsesAfterInspect logs each unmet expectation
+func (m *StorageMock) MinimockGetFinalResponsesAfterInspect() {
+	for _, e := range m.GetFinalResponsesAfterMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to StorageMock.GetFinalResponsesAfter with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetFinalResponsesAfterMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetFinalResponsesAfterCounter) < 1 {
+		if m.GetFinalResponsesAfterMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.GetFinalResponsesAfter")
+		} else {
+			m.t.Errorf("Expected call to StorageMock.GetFinalResponsesAfter with params: %#v", *m.GetFinalResponsesAfterMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetFinalResponsesAfter != nil && mm_atomic.LoadUint64(&m.afterGetFinalResponsesAfterCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.GetFinalResponsesAfter")
+	}
+}
+
+type mStorageMockGetHistory struct {
+	mock               *StorageMock
+	defaultExpectation *StorageMockGetHistoryExpectation
+	expectations       []*StorageMockGetHistoryExpectation
+
+	callArgs []*StorageMockGetHistoryParams
+	mutex    sync.RWMutex
+}
+
+// StorageMockGetHistoryExpectation specifies expectation struct of the Storage.GetHistory
+type StorageMockGetHistoryExpectation struct {
+	mock    *StorageMock
+	params  *StorageMockGetHistoryParams
+	results *StorageMockGetHistoryResults
+	Counter uint64
+}
+
+// StorageMockGetHistoryParams contains parameters of the Storage.GetHistory
+type StorageMockGetHistoryParams struct {
+	ctx            context.Context
+	trackingNumber string
+	apiName        mm_service.APIName
+}
+
+// StorageMockGetHistoryResults contains results of the Storage.GetHistory
+type StorageMockGetHistoryResults struct {
+	ppa1 []*mm_service.PostalApiResponse
+	err  error
+}
+
+// Expect sets up expected params for Storage.GetHistory
+func (mmGetHistory *mStorageMockGetHistory) Expect(ctx context.Context, trackingNumber string, apiName mm_service.APIName) *mStorageMockGetHistory {
+	if mmGetHistory.mock.funcGetHistory != nil {
+		mmGetHistory.mock.t.Fatalf("StorageMock.GetHistory mock is already set by Set")
+	}
+
+	if mmGetHistory.defaultExpectation == nil {
+		mmGetHistory.defaultExpectation = &StorageMockGetHistoryExpectation{}
+	}
+
+	mmGetHistory.defaultExpectation.params = &StorageMockGetHistoryParams{ctx, trackingNumber, apiName}
+	for _, e := range mmGetHistory.expectations {
+		if minimock.Equal(e.params, mmGetHistory.defaultExpectation.params) {
+			mmGetHistory.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmGetHistory.defaultExpectation.params)
+		}
+	}
+
+	return mmGetHistory
+}
+
+// Inspect accepts an inspector function that has same arguments as the Storage.GetHistory
+func (mmGetHistory *mStorageMockGetHistory) Inspect(f func(ctx context.Context, trackingNumber string, apiName mm_service.APIName)) *mStorageMockGetHistory {
+	if mmGetHistory.mock.inspectFuncGetHistory != nil {
+		mmGetHistory.mock.t.Fatalf("Inspect function is already set for StorageMock.GetHistory")
+	}
+
+	mmGetHistory.mock.inspectFuncGetHistory = f
+
+	return mmGetHistory
+}
+
+// Return sets up results that will be returned by Storage.GetHistory
+func (mmGetHistory *mStorageMockGetHistory) Return(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
+	if mmGetHistory.mock.funcGetHistory != nil {
+		mmGetHistory.mock.t.Fatalf("StorageMock.GetHistory mock is already set by Set")
+	}
+
+	if mmGetHistory.defaultExpectation == nil {
+		mmGetHistory.defaultExpectation = &StorageMockGetHistoryExpectation{mock: mmGetHistory.mock}
+	}
+	mmGetHistory.defaultExpectation.results = &StorageMockGetHistoryResults{ppa1, err}
+	return mmGetHistory.mock
+}
+
+// Set uses given function f to mock the Storage.GetHistory method
+func (mmGetHistory *mStorageMockGetHistory) Set(f func(ctx context.Context, trackingNumber string, apiName mm_service.APIName) (ppa1 []*mm_service.PostalApiResponse, err error)) *StorageMock {
+	if mmGetHistory.defaultExpectation != nil {
+		mmGetHistory.mock.t.Fatalf("Default expectation is already set for the Storage.GetHistory method")
+	}
+
+	if len(mmGetHistory.expectations) > 0 {
+		mmGetHistory.mock.t.Fatalf("Some expectations are already set for the Storage.GetHistory method")
+	}
+
+	mmGetHistory.mock.funcGetHistory = f
+	return mmGetHistory.mock
+}
+
+// When sets expectation for the Storage.GetHistory which will trigger the result defined by the following
+// Then helper
+func (mmGetHistory *mStorageMockGetHistory) When(ctx context.Context, trackingNumber string, apiName mm_service.APIName) *StorageMockGetHistoryExpectation {
+	if mmGetHistory.mock.funcGetHistory != nil {
+		mmGetHistory.mock.t.Fatalf("StorageMock.GetHistory mock is already set by Set")
+	}
+
+	expectation := &StorageMockGetHistoryExpectation{
+		mock:   mmGetHistory.mock,
+		params: &StorageMockGetHistoryParams{ctx, trackingNumber, apiName},
+	}
+	mmGetHistory.expectations = append(mmGetHistory.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Storage.GetHistory return parameters for the expectation previously defined by the When method
+func (e *StorageMockGetHistoryExpectation) Then(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
+	e.results = &StorageMockGetHistoryResults{ppa1, err}
+	return e.mock
+}
+
+// GetHistory implements service.Storage
+func (mmGetHistory *StorageMock) GetHistory(ctx context.Context, trackingNumber string, apiName mm_service.APIName) (ppa1 []*mm_service.PostalApiResponse, err error) {
+	mm_atomic.AddUint64(&mmGetHistory.beforeGetHistoryCounter, 1)
+	defer mm_atomic.AddUint64(&mmGetHistory.afterGetHistoryCounter, 1)
+
+	if mmGetHistory.inspectFuncGetHistory != nil {
+		mmGetHistory.inspectFuncGetHistory(ctx, trackingNumber, apiName)
+	}
+
+	mm_params := &StorageMockGetHistoryParams{ctx, trackingNumber, apiName}
+
+	// Record call args
+	mmGetHistory.GetHistoryMock.mutex.Lock()
+	mmGetHistory.GetHistoryMock.callArgs = append(mmGetHistory.GetHistoryMock.callArgs, mm_params)
+	mmGetHistory.GetHistoryMock.mutex.Unlock()
+
+	for _, e := range mmGetHistory.GetHistoryMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.ppa1, e.results.err
+		}
+	}
+
+	if mmGetHistory.GetHistoryMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmGetHistory.GetHistoryMock.defaultExpectation.Counter, 1)
+		mm_want := mmGetHistory.GetHistoryMock.defaultExpectation.params
+		mm_got := StorageMockGetHistoryParams{ctx, trackingNumber, apiName}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmGetHistory.t.Errorf("StorageMock.GetHistory got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmGetHistory.GetHistoryMock.defaultExpectation.results
+		if mm_results == nil {
+			mmGetHistory.t.Fatal("No results are set for the StorageMock.GetHistory")
+		}
+		return (*mm_results).ppa1, (*mm_results).err
+	}
+	if mmGetHistory.funcGetHistory != nil {
+		return mmGetHistory.funcGetHistory(ctx, trackingNumber, apiName)
+	}
+	mmGetHistory.t.Fatalf("Unexpected call to StorageMock.GetHistory. %v %v %v", ctx, trackingNumber, apiName)
+	return
+}
+
+// GetHistoryAfterCounter returns a count of finished StorageMock.GetHistory invocations
+func (mmGetHistory *StorageMock) GetHistoryAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetHistory.afterGetHistoryCounter)
+}
+
+// GetHistoryBeforeCounter returns a count of StorageMock.GetHistory invocations
+func (mmGetHistory *StorageMock) GetHistoryBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetHistory.beforeGetHistoryCounter)
+}
+
+// Calls returns a list of arguments used in each call to StorageMock.GetHistory.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmGetHistory *mStorageMockGetHistory) Calls() []*StorageMockGetHistoryParams {
+	mmGetHistory.mutex.RLock()
+
+	argCopy := make([]*StorageMockGetHistoryParams, len(mmGetHistory.callArgs))
+	copy(argCopy, mmGetHistory.callArgs)
+
+	mmGetHistory.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockGetHistoryDone returns true if the count of the GetHistory invocations corresponds
+// the number of defined expectations
+func (m *StorageMock) MinimockGetHistoryDone() bool {
+	for _, e := range m.GetHistoryMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetHistoryMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetHistoryCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetHistory != nil && mm_atomic.LoadUint64(&m.afterGetHistoryCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockGetHistoryInspect logs each unmet expectation
+func (m *StorageMock) MinimockGetHistoryInspect() {
+	for _, e := range m.GetHistoryMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to StorageMock.GetHistory with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetHistoryMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetHistoryCounter) < 1 {
+		if m.GetHistoryMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.GetHistory")
+		} else {
+			m.t.Errorf("Expected call to StorageMock.GetHistory with params: %#v", *m.GetHistoryMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetHistory != nil && mm_atomic.LoadUint64(&m.afterGetHistoryCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.GetHistory")
+	}
+}
+
+type mStorageMockGetLatest struct {
+	mock               *StorageMock
+	defaultExpectation *StorageMockGetLatestExpectation
+	expectations       []*StorageMockGetLatestExpectation
+
+	callArgs []*StorageMockGetLatestParams
+	mutex    sync.RWMutex
+}
+
+// StorageMockGetLatestExpectation specifies expectation struct of the Storage.GetLatest
+type StorageMockGetLatestExpectation struct {
+	mock    *StorageMock
+	params  *StorageMockGetLatestParams
+	results *StorageMockGetLatestResults
+	Counter uint64
+}
+
+// StorageMockGetLatestParams contains parameters of the Storage.GetLatest
+type StorageMockGetLatestParams struct {
+	ctx            context.Context
+	trackingNumber string
+	apiNames       []mm_service.APIName
+}
+
+// StorageMockGetLatestResults contains results of the Storage.GetLatest
+type StorageMockGetLatestResults struct {
+	ppa1 []*mm_service.PostalApiResponse
+	err  error
+}
+
+// Expect sets up expected params for Storage.GetLatest
+func (mmGetLatest *mStorageMockGetLatest) Expect(ctx context.Context, trackingNumber string, apiNames []mm_service.APIName) *mStorageMockGetLatest {
+	if mmGetLatest.mock.funcGetLatest != nil {
+		mmGetLatest.mock.t.Fatalf("StorageMock.GetLatest mock is already set by Set")
+	}
+
+	if mmGetLatest.defaultExpectation == nil {
+		mmGetLatest.defaultExpectation = &StorageMockGetLatestExpectation{}
+	}
+
+	mmGetLatest.defaultExpectation.params = &StorageMockGetLatestParams{ctx, trackingNumber, apiNames}
+	for _, e := range mmGetLatest.expectations {
+		if minimock.Equal(e.params, mmGetLatest.defaultExpectation.params) {
+			mmGetLatest.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmGetLatest.defaultExpectation.params)
+		}
+	}
+
+	return mmGetLatest
+}
+
+// Inspect accepts an inspector function that has same arguments as the Storage.GetLatest
+func (mmGetLatest *mStorageMockGetLatest) Inspect(f func(ctx context.Context, trackingNumber string, apiNames []mm_service.APIName)) *mStorageMockGetLatest {
+	if mmGetLatest.mock.inspectFuncGetLatest != nil {
+		mmGetLatest.mock.t.Fatalf("Inspect function is already set for StorageMock.GetLatest")
+	}
+
+	mmGetLatest.mock.inspectFuncGetLatest = f
+
+	return mmGetLatest
+}
+
+// Return sets up results that will be returned by Storage.GetLatest
+func (mmGetLatest *mStorageMockGetLatest) Return(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
+	if mmGetLatest.mock.funcGetLatest != nil {
+		mmGetLatest.mock.t.Fatalf("StorageMock.GetLatest mock is already set by Set")
+	}
+
+	if mmGetLatest.defaultExpectation == nil {
+		mmGetLatest.defaultExpectation = &StorageMockGetLatestExpectation{mock: mmGetLatest.mock}
+	}
+	mmGetLatest.defaultExpectation.results = &StorageMockGetLatestResults{ppa1, err}
+	return mmGetLatest.mock
+}
+
+// Set uses given function f to mock the Storage.GetLatest method
+func (mmGetLatest *mStorageMockGetLatest) Set(f func(ctx context.Context, trackingNumber string, apiNames []mm_service.APIName) (ppa1 []*mm_service.PostalApiResponse, err error)) *StorageMock {
+	if mmGetLatest.defaultExpectation != nil {
+		mmGetLatest.mock.t.Fatalf("Default expectation is already set for the Storage.GetLatest method")
+	}
+
+	if len(mmGetLatest.expectations) > 0 {
+		mmGetLatest.mock.t.Fatalf("Some expectations are already set for the Storage.GetLatest method")
+	}
+
+	mmGetLatest.mock.funcGetLatest = f
+	return mmGetLatest.mock
+}
+
+// When sets expectation for the Storage.GetLatest which will trigger the result defined by the following
+// Then helper
+func (mmGetLatest *mStorageMockGetLatest) When(ctx context.Context, trackingNumber string, apiNames []mm_service.APIName) *StorageMockGetLatestExpectation {
+	if mmGetLatest.mock.funcGetLatest != nil {
+		mmGetLatest.mock.t.Fatalf("StorageMock.GetLatest mock is already set by Set")
+	}
+
+	expectation := &StorageMockGetLatestExpectation{
+		mock:   mmGetLatest.mock,
+		params: &StorageMockGetLatestParams{ctx, trackingNumber, apiNames},
+	}
+	mmGetLatest.expectations = append(mmGetLatest.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Storage.GetLatest return parameters for the expectation previously defined by the When method
+func (e *StorageMockGetLatestExpectation) Then(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
+	e.results = &StorageMockGetLatestResults{ppa1, err}
+	return e.mock
+}
+
+// GetLatest implements service.Storage
+func (mmGetLatest *StorageMock) GetLatest(ctx context.Context, trackingNumber string, apiNames []mm_service.APIName) (ppa1 []*mm_service.PostalApiResponse, err error) {
+	mm_atomic.AddUint64(&mmGetLatest.beforeGetLatestCounter, 1)
+	defer mm_atomic.AddUint64(&mmGetLatest.afterGetLatestCounter, 1)
+
+	if mmGetLatest.inspectFuncGetLatest != nil {
+		mmGetLatest.inspectFuncGetLatest(ctx, trackingNumber, apiNames)
+	}
+
+	mm_params := &StorageMockGetLatestParams{ctx, trackingNumber, apiNames}
+
+	// Record call args
+	mmGetLatest.GetLatestMock.mutex.Lock()
+	mmGetLatest.GetLatestMock.callArgs = append(mmGetLatest.GetLatestMock.callArgs, mm_params)
+	mmGetLatest.GetLatestMock.mutex.Unlock()
+
+	for _, e := range mmGetLatest.GetLatestMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.ppa1, e.results.err
+		}
+	}
+
+	if mmGetLatest.GetLatestMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmGetLatest.GetLatestMock.defaultExpectation.Counter, 1)
+		mm_want := mmGetLatest.GetLatestMock.defaultExpectation.params
+		mm_got := StorageMockGetLatestParams{ctx, trackingNumber, apiNames}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmGetLatest.t.Errorf("StorageMock.GetLatest got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmGetLatest.GetLatestMock.defaultExpectation.results
+		if mm_results == nil {
+			mmGetLatest.t.Fatal("No results are set for the StorageMock.GetLatest")
+		}
+		return (*mm_results).ppa1, (*mm_results).err
+	}
+	if mmGetLatest.funcGetLatest != nil {
+		return mmGetLatest.funcGetLatest(ctx, trackingNumber, apiNames)
+	}
+	mmGetLatest.t.Fatalf("Unexpected call to StorageMock.GetLatest. %v %v %v", ctx, trackingNumber, apiNames)
+	return
+}
+
+// GetLatestAfterCounter returns a count of finished StorageMock.GetLatest invocations
+func (mmGetLatest *StorageMock) GetLatestAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetLatest.afterGetLatestCounter)
+}
+
+// GetLatestBeforeCounter returns a count of StorageMock.GetLatest invocations
+func (mmGetLatest *StorageMock) GetLatestBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetLatest.beforeGetLatestCounter)
+}
+
+// Calls returns a list of arguments used in each call to StorageMock.GetLatest.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmGetLatest *mStorageMockGetLatest) Calls() []*StorageMockGetLatestParams {
+	mmGetLatest.mutex.RLock()
+
+	argCopy := make([]*StorageMockGetLatestParams, len(mmGetLatest.callArgs))
+	copy(argCopy, mmGetLatest.callArgs)
+
+	mmGetLatest.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockGetLatestDone returns true if the count of the GetLatest invocations corresponds
+// the number of defined expectations
+func (m *StorageMock) MinimockGetLatestDone() bool {
+	for _, e := range m.GetLatestMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetLatestMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetLatestCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetLatest != nil && mm_atomic.LoadUint64(&m.afterGetLatestCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockGetLatestInspect logs each unmet expectation
+func (m *StorageMock) MinimockGetLatestInspect() {
+	for _, e := range m.GetLatestMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to StorageMock.GetLatest with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetLatestMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetLatestCounter) < 1 {
+		if m.GetLatestMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.GetLatest")
+		} else {
+			m.t.Errorf("Expected call to StorageMock.GetLatest with params: %#v", *m.GetLatestMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetLatest != nil && mm_atomic.LoadUint64(&m.afterGetLatestCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.GetLatest")
+	}
+}
+
+type mStorageMockGetLatestBatch struct {
+	mock               *StorageMock
+	defaultExpectation *StorageMockGetLatestBatchExpectation
+	expectations       []*StorageMockGetLatestBatchExpectation
+
+	callArgs []*StorageMockGetLatestBatchParams
+	mutex    sync.RWMutex
+}
+
+// StorageMockGetLatestBatchExpectation specifies expectation struct of the Storage.GetLatestBatch
+type StorageMockGetLatestBatchExpectation struct {
+	mock    *StorageMock
+	params  *StorageMockGetLatestBatchParams
+	results *StorageMockGetLatestBatchResults
+	Counter uint64
+}
+
+// StorageMockGetLatestBatchParams contains parameters of the Storage.GetLatestBatch
+type StorageMockGetLatestBatchParams struct {
+	ctx             context.Context
+	trackingNumbers []string
+	apiNames        []mm_service.APIName
+}
+
+// StorageMockGetLatestBatchResults contains results of the Storage.GetLatestBatch
+type StorageMockGetLatestBatchResults struct {
+	ppa1 []*mm_service.PostalApiResponse
+	err  error
+}
+
+// Expect sets up expected params for Storage.GetLatestBatch
+func (mmGetLatestBatch *mStorageMockGetLatestBatch) Expect(ctx context.Context, trackingNumbers []string, apiNames []mm_service.APIName) *mStorageMockGetLatestBatch {
+	if mmGetLatestBatch.mock.funcGetLatestBatch != nil {
+		mmGetLatestBatch.mock.t.Fatalf("StorageMock.GetLatestBatch mock is already set by Set")
+	}
+
+	if mmGetLatestBatch.defaultExpectation == nil {
+		mmGetLatestBatch.defaultExpectation = &StorageMockGetLatestBatchExpectation{}
+	}
+
+	mmGetLatestBatch.defaultExpectation.params = &StorageMockGetLatestBatchParams{ctx, trackingNumbers, apiNames}
+	for _, e := range mmGetLatestBatch.expectations {
+		if minimock.Equal(e.params, mmGetLatestBatch.defaultExpectation.params) {
+			mmGetLatestBatch.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmGetLatestBatch.defaultExpectation.params)
+		}
+	}
+
+	return mmGetLatestBatch
+}
+
+// Inspect accepts an inspector function that has same arguments as the Storage.GetLatestBatch
+func (mmGetLatestBatch *mStorageMockGetLatestBatch) Inspect(f func(ctx context.Context, trackingNumbers []string, apiNames []mm_service.APIName)) *mStorageMockGetLatestBatch {
+	if mmGetLatestBatch.mock.inspectFuncGetLatestBatch != nil {
+		mmGetLatestBatch.mock.t.Fatalf("Inspect function is already set for StorageMock.GetLatestBatch")
+	}
+
+	mmGetLatestBatch.mock.inspectFuncGetLatestBatch = f
+
+	return mmGetLatestBatch
+}
+
+// Return sets up results that will be returned by Storage.GetLatestBatch
+func (mmGetLatestBatch *mStorageMockGetLatestBatch) Return(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
+	if mmGetLatestBatch.mock.funcGetLatestBatch != nil {
+		mmGetLatestBatch.mock.t.Fatalf("StorageMock.GetLatestBatch mock is already set by Set")
+	}
+
+	if mmGetLatestBatch.defaultExpectation == nil {
+		mmGetLatestBatch.defaultExpectation = &StorageMockGetLatestBatchExpectation{mock: mmGetLatestBatch.mock}
+	}
+	mmGetLatestBatch.defaultExpectation.results = &StorageMockGetLatestBatchResults{ppa1, err}
+	return mmGetLatestBatch.mock
+}
+
+// Set uses given function f to mock the Storage.GetLatestBatch method
+func (mmGetLatestBatch *mStorageMockGetLatestBatch) Set(f func(ctx context.Context, trackingNumbers []string, apiNames []mm_service.APIName) (ppa1 []*mm_service.PostalApiResponse, err error)) *StorageMock {
+	if mmGetLatestBatch.defaultExpectation != nil {
+		mmGetLatestBatch.mock.t.Fatalf("Default expectation is already set for the Storage.GetLatestBatch method")
+	}
+
+	if len(mmGetLatestBatch.expectations) > 0 {
+		mmGetLatestBatch.mock.t.Fatalf("Some expectations are already set for the Storage.GetLatestBatch method")
+	}
+
+	mmGetLatestBatch.mock.funcGetLatestBatch = f
+	return mmGetLatestBatch.mock
+}
+
+// When sets expectation for the Storage.GetLatestBatch which will trigger the result defined by the following
+// Then helper
+func (mmGetLatestBatch *mStorageMockGetLatestBatch) When(ctx context.Context, trackingNumbers []string, apiNames []mm_service.APIName) *StorageMockGetLatestBatchExpectation {
+	if mmGetLatestBatch.mock.funcGetLatestBatch != nil {
+		mmGetLatestBatch.mock.t.Fatalf("StorageMock.GetLatestBatch mock is already set by Set")
+	}
+
+	expectation := &StorageMockGetLatestBatchExpectation{
+		mock:   mmGetLatestBatch.mock,
+		params: &StorageMockGetLatestBatchParams{ctx, trackingNumbers, apiNames},
+	}
+	mmGetLatestBatch.expectations = append(mmGetLatestBatch.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Storage.GetLatestBatch return parameters for the expectation previously defined by the When method
+func (e *StorageMockGetLatestBatchExpectation) Then(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
+	e.results = &StorageMockGetLatestBatchResults{ppa1, err}
+	return e.mock
+}
+
+// GetLatestBatch implements service.Storage
+func (mmGetLatestBatch *StorageMock) GetLatestBatch(ctx context.Context, trackingNumbers []string, apiNames []mm_service.APIName) (ppa1 []*mm_service.PostalApiResponse, err error) {
+	mm_atomic.AddUint64(&mmGetLatestBatch.beforeGetLatestBatchCounter, 1)
+	defer mm_atomic.AddUint64(&mmGetLatestBatch.afterGetLatestBatchCounter, 1)
+
+	if mmGetLatestBatch.inspectFuncGetLatestBatch != nil {
+		mmGetLatestBatch.inspectFuncGetLatestBatch(ctx, trackingNumbers, apiNames)
+	}
+
+	mm_params := &StorageMockGetLatestBatchParams{ctx, trackingNumbers, apiNames}
+
+	// Record call args
+	mmGetLatestBatch.GetLatestBatchMock.mutex.Lock()
+	mmGetLatestBatch.GetLatestBatchMock.callArgs = append(mmGetLatestBatch.GetLatestBatchMock.callArgs, mm_params)
+	mmGetLatestBatch.GetLatestBatchMock.mutex.Unlock()
+
+	for _, e := range mmGetLatestBatch.GetLatestBatchMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.ppa1, e.results.err
+		}
+	}
+
+	if mmGetLatestBatch.GetLatestBatchMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmGetLatestBatch.GetLatestBatchMock.defaultExpectation.Counter, 1)
+		mm_want := mmGetLatestBatch.GetLatestBatchMock.defaultExpectation.params
+		mm_got := StorageMockGetLatestBatchParams{ctx, trackingNumbers, apiNames}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmGetLatestBatch.t.Errorf("StorageMock.GetLatestBatch got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmGetLatestBatch.GetLatestBatchMock.defaultExpectation.results
+		if mm_results == nil {
+			mmGetLatestBatch.t.Fatal("No results are set for the StorageMock.GetLatestBatch")
+		}
+		return (*mm_results).ppa1, (*mm_results).err
+	}
+	if mmGetLatestBatch.funcGetLatestBatch != nil {
+		return mmGetLatestBatch.funcGetLatestBatch(ctx, trackingNumbers, apiNames)
+	}
+	mmGetLatestBatch.t.Fatalf("Unexpected call to StorageMock.GetLatestBatch. %v %v %v", ctx, trackingNumbers, apiNames)
+	return
+}
+
+// GetLatestBatchAfterCounter returns a count of finished StorageMock.GetLatestBatch invocations
+func (mmGetLatestBatch *StorageMock) GetLatestBatchAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetLatestBatch.afterGetLatestBatchCounter)
+}
+
+// GetLatestBatchBeforeCounter returns a count of StorageMock.GetLatestBatch invocations
+func (mmGetLatestBatch *StorageMock) GetLatestBatchBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetLatestBatch.beforeGetLatestBatchCounter)
+}
+
+// Calls returns a list of arguments used in each call to StorageMock.GetLatestBatch.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmGetLatestBatch *mStorageMockGetLatestBatch) Calls() []*StorageMockGetLatestBatchParams {
+	mmGetLatestBatch.mutex.RLock()
+
+	argCopy := make([]*StorageMockGetLatestBatchParams, len(mmGetLatestBatch.callArgs))
+	copy(argCopy, mmGetLatestBatch.callArgs)
+
+	mmGetLatestBatch.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockGetLatestBatchDone returns true if the count of the GetLatestBatch invocations corresponds
+// the number of defined expectations
+func (m *StorageMock) MinimockGetLatestBatchDone() bool {
+	for _, e := range m.GetLatestBatchMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetLatestBatchMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetLatestBatchCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetLatestBatch != nil && mm_atomic.LoadUint64(&m.afterGetLatestBatchCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockGetLatestBatchInspect logs each unmet expectation
+func (m *StorageMock) MinimockGetLatestBatchInspect() {
+	for _, e := range m.GetLatestBatchMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to StorageMock.GetLatestBatch with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetLatestBatchMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetLatestBatchCounter) < 1 {
+		if m.GetLatestBatchMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.GetLatestBatch")
+		} else {
+			m.t.Errorf("Expected call to StorageMock.GetLatestBatch with params: %#v", *m.GetLatestBatchMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetLatestBatch != nil && mm_atomic.LoadUint64(&m.afterGetLatestBatchCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.GetLatestBatch")
+	}
+}
+
+type mStorageMockGetLinks struct {
+	mock               *StorageMock
+	defaultExpectation *StorageMockGetLinksExpectation
+	expectations       []*StorageMockGetLinksExpectation
+
+	callArgs []*StorageMockGetLinksParams
+	mutex    sync.RWMutex
+}
+
+// StorageMockGetLinksExpectation specifies expectation struct of the Storage.GetLinks
+type StorageMockGetLinksExpectation struct {
+	mock    *StorageMock
+	params  *StorageMockGetLinksParams
+	results *StorageMockGetLinksResults
+	Counter uint64
+}
+
+// StorageMockGetLinksParams contains parameters of the Storage.GetLinks
+type StorageMockGetLinksParams struct {
+	ctx            context.Context
+	trackingNumber string
+}
+
+// StorageMockGetLinksResults contains results of the Storage.GetLinks
+type StorageMockGetLinksResults struct {
+	sa1 []string
+	err error
+}
+
+// Expect sets up expected params for Storage.GetLinks
+func (mmGetLinks *mStorageMockGetLinks) Expect(ctx context.Context, trackingNumber string) *mStorageMockGetLinks {
+	if mmGetLinks.mock.funcGetLinks != nil {
+		mmGetLinks.mock.t.Fatalf("StorageMock.GetLinks mock is already set by Set")
+	}
+
+	if mmGetLinks.defaultExpectation == nil {
+		mmGetLinks.defaultExpectation = &StorageMockGetLinksExpectation{}
+	}
+
+	mmGetLinks.defaultExpectation.params = &StorageMockGetLinksParams{ctx, trackingNumber}
+	for _, e := range mmGetLinks.expectations {
+		if minimock.Equal(e.params, mmGetLinks.defaultExpectation.params) {
+			mmGetLinks.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmGetLinks.defaultExpectation.params)
+		}
+	}
+
+	return mmGetLinks
+}
+
+// Inspect accepts an inspector function that has same arguments as the Storage.GetLinks
+func (mmGetLinks *mStorageMockGetLinks) Inspect(f func(ctx context.Context, trackingNumber string)) *mStorageMockGetLinks {
+	if mmGetLinks.mock.inspectFuncGetLinks != nil {
+		mmGetLinks.mock.t.Fatalf("Inspect function is already set for StorageMock.GetLinks")
+	}
+
+	mmGetLinks.mock.inspectFuncGetLinks = f
+
+	return mmGetLinks
+}
+
+// Return sets up results that will be returned by Storage.GetLinks
+func (mmGetLinks *mStorageMockGetLinks) Return(sa1 []string, err error) *StorageMock {
+	if mmGetLinks.mock.funcGetLinks != nil {
+		mmGetLinks.mock.t.Fatalf("StorageMock.GetLinks mock is already set by Set")
+	}
+
+	if mmGetLinks.defaultExpectation == nil {
+		mmGetLinks.defaultExpectation = &StorageMockGetLinksExpectation{mock: mmGetLinks.mock}
+	}
+	mmGetLinks.defaultExpectation.results = &StorageMockGetLinksResults{sa1, err}
+	return mmGetLinks.mock
+}
+
+// Set uses given function f to mock the Storage.GetLinks method
+func (mmGetLinks *mStorageMockGetLinks) Set(f func(ctx context.Context, trackingNumber string) (sa1 []string, err error)) *StorageMock {
+	if mmGetLinks.defaultExpectation != nil {
+		mmGetLinks.mock.t.Fatalf("Default expectation is already set for the Storage.GetLinks method")
+	}
+
+	if len(mmGetLinks.expectations) > 0 {
+		mmGetLinks.mock.t.Fatalf("Some expectations are already set for the Storage.GetLinks method")
+	}
+
+	mmGetLinks.mock.funcGetLinks = f
+	return mmGetLinks.mock
+}
+
+// When sets expectation for the Storage.GetLinks which will trigger the result defined by the following
+// Then helper
+func (mmGetLinks *mStorageMockGetLinks) When(ctx context.Context, trackingNumber string) *StorageMockGetLinksExpectation {
+	if mmGetLinks.mock.funcGetLinks != nil {
+		mmGetLinks.mock.t.Fatalf("StorageMock.GetLinks mock is already set by Set")
+	}
+
+	expectation := &StorageMockGetLinksExpectation{
+		mock:   mmGetLinks.mock,
+		params: &StorageMockGetLinksParams{ctx, trackingNumber},
+	}
+	mmGetLinks.expectations = append(mmGetLinks.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Storage.GetLinks return parameters for the expectation previously defined by the When method
+func (e *StorageMockGetLinksExpectation) Then(sa1 []string, err error) *StorageMock {
+	e.results = &StorageMockGetLinksResults{sa1, err}
+	return e.mock
+}
+
+// GetLinks implements service.Storage
+func (mmGetLinks *StorageMock) GetLinks(ctx context.Context, trackingNumber string) (sa1 []string, err error) {
+	mm_atomic.AddUint64(&mmGetLinks.beforeGetLinksCounter, 1)
+	defer mm_atomic.AddUint64(&mmGetLinks.afterGetLinksCounter, 1)
+
+	if mmGetLinks.inspectFuncGetLinks != nil {
+		mmGetLinks.inspectFuncGetLinks(ctx, trackingNumber)
+	}
+
+	mm_params := &StorageMockGetLinksParams{ctx, trackingNumber}
+
+	// Record call args
+	mmGetLinks.GetLinksMock.mutex.Lock()
+	mmGetLinks.GetLinksMock.callArgs = append(mmGetLinks.GetLinksMock.callArgs, mm_params)
+	mmGetLinks.GetLinksMock.mutex.Unlock()
+
+	for _, e := range mmGetLinks.GetLinksMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.sa1, e.results.err
+		}
+	}
+
+	if mmGetLinks.GetLinksMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmGetLinks.GetLinksMock.defaultExpectation.Counter, 1)
+		mm_want := mmGetLinks.GetLinksMock.defaultExpectation.params
+		mm_got := StorageMockGetLinksParams{ctx, trackingNumber}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmGetLinks.t.Errorf("StorageMock.GetLinks got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmGetLinks.GetLinksMock.defaultExpectation.results
+		if mm_results == nil {
+			mmGetLinks.t.Fatal("No results are set for the StorageMock.GetLinks")
+		}
+		return (*mm_results).sa1, (*mm_results).err
+	}
+	if mmGetLinks.funcGetLinks != nil {
+		return mmGetLinks.funcGetLinks(ctx, trackingNumber)
+	}
+	mmGetLinks.t.Fatalf("Unexpected call to StorageMock.GetLinks. %v %v", ctx, trackingNumber)
+	return
+}
+
+// GetLinksAfterCounter returns a count of finished StorageMock.GetLinks invocations
+func (mmGetLinks *StorageMock) GetLinksAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetLinks.afterGetLinksCounter)
+}
+
+// GetLinksBeforeCounter returns a count of StorageMock.GetLinks invocations
+func (mmGetLinks *StorageMock) GetLinksBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetLinks.beforeGetLinksCounter)
+}
+
+// Calls returns a list of arguments used in each call to StorageMock.GetLinks.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmGetLinks *mStorageMockGetLinks) Calls() []*StorageMockGetLinksParams {
+	mmGetLinks.mutex.RLock()
+
+	argCopy := make([]*StorageMockGetLinksParams, len(mmGetLinks.callArgs))
+	copy(argCopy, mmGetLinks.callArgs)
+
+	mmGetLinks.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockGetLinksDone returns true if the count of the GetLinks invocations corresponds
+// the number of defined expectations
+func (m *StorageMock) MinimockGetLinksDone() bool {
+	for _, e := range m.GetLinksMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetLinksMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetLinksCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetLinks != nil && mm_atomic.LoadUint64(&m.afterGetLinksCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockGetLinksInspect logs each unmet expectation
+func (m *StorageMock) MinimockGetLinksInspect() {
+	for _, e := range m.GetLinksMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to StorageMock.GetLinks with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetLinksMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetLinksCounter) < 1 {
+		if m.GetLinksMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.GetLinks")
+		} else {
+			m.t.Errorf("Expected call to StorageMock.GetLinks with params: %#v", *m.GetLinksMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetLinks != nil && mm_atomic.LoadUint64(&m.afterGetLinksCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.GetLinks")
+	}
+}
+
+type mStorageMockGetLocalized struct {
+	mock               *StorageMock
+	defaultExpectation *StorageMockGetLocalizedExpectation
+	expectations       []*StorageMockGetLocalizedExpectation
+
+	callArgs []*StorageMockGetLocalizedParams
+	mutex    sync.RWMutex
+}
+
+// StorageMockGetLocalizedExpectation specifies expectation struct of the Storage.GetLocalized
+type StorageMockGetLocalizedExpectation struct {
+	mock    *StorageMock
+	params  *StorageMockGetLocalizedParams
+	results *StorageMockGetLocalizedResults
+	Counter uint64
+}
+
+// StorageMockGetLocalizedParams contains parameters of the Storage.GetLocalized
+type StorageMockGetLocalizedParams struct {
+	ctx            context.Context
+	trackingNumber string
+	apiName        mm_service.APIName
+	language       string
+}
+
+// StorageMockGetLocalizedResults contains results of the Storage.GetLocalized
+type StorageMockGetLocalizedResults struct {
+	pp1 *mm_service.PostalApiResponse
+	err error
+}
+
+// Expect sets up expected params for Storage.GetLocalized
+func (mmGetLocalized *mStorageMockGetLocalized) Expect(ctx context.Context, trackingNumber string, apiName mm_service.APIName, language string) *mStorageMockGetLocalized {
+	if mmGetLocalized.mock.funcGetLocalized != nil {
+		mmGetLocalized.mock.t.Fatalf("StorageMock.GetLocalized mock is already set by Set")
+	}
+
+	if mmGetLocalized.defaultExpectation == nil {
+		mmGetLocalized.defaultExpectation = &StorageMockGetLocalizedExpectation{}
+	}
+
+	mmGetLocalized.defaultExpectation.params = &StorageMockGetLocalizedParams{ctx, trackingNumber, apiName, language}
+	for _, e := range mmGetLocalized.expectations {
+		if minimock.Equal(e.params, mmGetLocalized.defaultExpectation.params) {
+			mmGetLocalized.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmGetLocalized.defaultExpectation.params)
+		}
+	}
+
+	return mmGetLocalized
+}
+
+// Inspect accepts an inspector function that has same arguments as the Storage.GetLocalized
+func (mmGetLocalized *mStorageMockGetLocalized) Inspect(f func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, language string)) *mStorageMockGetLocalized {
+	if mmGetLocalized.mock.inspectFuncGetLocalized != nil {
+		mmGetLocalized.mock.t.Fatalf("Inspect function is already set for StorageMock.GetLocalized")
+	}
+
+	mmGetLocalized.mock.inspectFuncGetLocalized = f
+
+	return mmGetLocalized
+}
+
+// Return sets up results that will be returned by Storage.GetLocalized
+func (mmGetLocalized *mStorageMockGetLocalized) Return(pp1 *mm_service.PostalApiResponse, err error) *StorageMock {
+	if mmGetLocalized.mock.funcGetLocalized != nil {
+		mmGetLocalized.mock.t.Fatalf("StorageMock.GetLocalized mock is already set by Set")
+	}
+
+	if mmGetLocalized.defaultExpectation == nil {
+		mmGetLocalized.defaultExpectation = &StorageMockGetLocalizedExpectation{mock: mmGetLocalized.mock}
+	}
+	mmGetLocalized.defaultExpectation.results = &StorageMockGetLocalizedResults{pp1, err}
+	return mmGetLocalized.mock
+}
+
+// Set uses given function f to mock the Storage.GetLocalized method
+func (mmGetLocalized *mStorageMockGetLocalized) Set(f func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, language string) (pp1 *mm_service.PostalApiResponse, err error)) *StorageMock {
+	if mmGetLocalized.defaultExpectation != nil {
+		mmGetLocalized.mock.t.Fatalf("Default expectation is already set for the Storage.GetLocalized method")
+	}
+
+	if len(mmGetLocalized.expectations) > 0 {
+		mmGetLocalized.mock.t.Fatalf("Some expectations are already set for the Storage.GetLocalized method")
+	}
+
+	mmGetLocalized.mock.funcGetLocalized = f
+	return mmGetLocalized.mock
+}
+
+// When sets expectation for the Storage.GetLocalized which will trigger the result defined by the following
+// Then helper
+func (mmGetLocalized *mStorageMockGetLocalized) When(ctx context.Context, trackingNumber string, apiName mm_service.APIName, language string) *StorageMockGetLocalizedExpectation {
+	if mmGetLocalized.mock.funcGetLocalized != nil {
+		mmGetLocalized.mock.t.Fatalf("StorageMock.GetLocalized mock is already set by Set")
+	}
+
+	expectation := &StorageMockGetLocalizedExpectation{
+		mock:   mmGetLocalized.mock,
+		params: &StorageMockGetLocalizedParams{ctx, trackingNumber, apiName, language},
+	}
+	mmGetLocalized.expectations = append(mmGetLocalized.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Storage.GetLocalized return parameters for the expectation previously defined by the When method
+func (e *StorageMockGetLocalizedExpectation) Then(pp1 *mm_service.PostalApiResponse, err error) *StorageMock {
+	e.results = &StorageMockGetLocalizedResults{pp1, err}
+	return e.mock
+}
+
+// GetLocalized implements service.Storage
+func (mmGetLocalized *StorageMock) GetLocalized(ctx context.Context, trackingNumber string, apiName mm_service.APIName, language string) (pp1 *mm_service.PostalApiResponse, err error) {
+	mm_atomic.AddUint64(&mmGetLocalized.beforeGetLocalizedCounter, 1)
+	defer mm_atomic.AddUint64(&mmGetLocalized.afterGetLocalizedCounter, 1)
+
+	if mmGetLocalized.inspectFuncGetLocalized != nil {
+		mmGetLocalized.inspectFuncGetLocalized(ctx, trackingNumber, apiName, language)
+	}
+
+	mm_params := &StorageMockGetLocalizedParams{ctx, trackingNumber, apiName, language}
+
+	// Record call args
+	mmGetLocalized.GetLocalizedMock.mutex.Lock()
+	mmGetLocalized.GetLocalizedMock.callArgs = append(mmGetLocalized.GetLocalizedMock.callArgs, mm_params)
+	mmGetLocalized.GetLocalizedMock.mutex.Unlock()
+
+	for _, e := range mmGetLocalized.GetLocalizedMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.pp1, e.results.err
+		}
+	}
+
+	if mmGetLocalized.GetLocalizedMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmGetLocalized.GetLocalizedMock.defaultExpectation.Counter, 1)
+		mm_want := mmGetLocalized.GetLocalizedMock.defaultExpectation.params
+		mm_got := StorageMockGetLocalizedParams{ctx, trackingNumber, apiName, language}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmGetLocalized.t.Errorf("StorageMock.GetLocalized got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmGetLocalized.GetLocalizedMock.defaultExpectation.results
+		if mm_results == nil {
+			mmGetLocalized.t.Fatal("No results are set for the StorageMock.GetLocalized")
+		}
+		return (*mm_results).pp1, (*mm_results).err
+	}
+	if mmGetLocalized.funcGetLocalized != nil {
+		return mmGetLocalized.funcGetLocalized(ctx, trackingNumber, apiName, language)
+	}
+	mmGetLocalized.t.Fatalf("Unexpected call to StorageMock.GetLocalized. %v %v %v %v", ctx, trackingNumber, apiName, language)
+	return
+}
+
+// GetLocalizedAfterCounter returns a count of finished StorageMock.GetLocalized invocations
+func (mmGetLocalized *StorageMock) GetLocalizedAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetLocalized.afterGetLocalizedCounter)
+}
+
+// GetLocalizedBeforeCounter returns a count of StorageMock.GetLocalized invocations
+func (mmGetLocalized *StorageMock) GetLocalizedBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetLocalized.beforeGetLocalizedCounter)
+}
+
+// Calls returns a list of arguments used in each call to StorageMock.GetLocalized.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmGetLocalized *mStorageMockGetLocalized) Calls() []*StorageMockGetLocalizedParams {
+	mmGetLocalized.mutex.RLock()
+
+	argCopy := make([]*StorageMockGetLocalizedParams, len(mmGetLocalized.callArgs))
+	copy(argCopy, mmGetLocalized.callArgs)
+
+	mmGetLocalized.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockGetLocalizedDone returns true if the count of the GetLocalized invocations corresponds
+// the number of defined expectations
+func (m *StorageMock) MinimockGetLocalizedDone() bool {
+	for _, e := range m.GetLocalizedMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetLocalizedMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetLocalizedCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetLocalized != nil && mm_atomic.LoadUint64(&m.afterGetLocalizedCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockGetLocalizedInspect logs each unmet expectation
+func (m *StorageMock) MinimockGetLocalizedInspect() {
+	for _, e := range m.GetLocalizedMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to StorageMock.GetLocalized with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetLocalizedMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetLocalizedCounter) < 1 {
+		if m.GetLocalizedMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.GetLocalized")
+		} else {
+			m.t.Errorf("Expected call to StorageMock.GetLocalized with params: %#v", *m.GetLocalizedMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetLocalized != nil && mm_atomic.LoadUint64(&m.afterGetLocalizedCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.GetLocalized")
+	}
+}
+
+type mStorageMockGetResponsesAfter struct {
+	mock               *StorageMock
+	defaultExpectation *StorageMockGetResponsesAfterExpectation
+	expectations       []*StorageMockGetResponsesAfterExpectation
+
+	callArgs []*StorageMockGetResponsesAfterParams
+	mutex    sync.RWMutex
+}
+
+// StorageMockGetResponsesAfterExpectation specifies expectation struct of the Storage.GetResponsesAfter
+type StorageMockGetResponsesAfterExpectation struct {
+	mock    *StorageMock
+	params  *StorageMockGetResponsesAfterParams
+	results *StorageMockGetResponsesAfterResults
+	Counter uint64
+}
+
+// StorageMockGetResponsesAfterParams contains parameters of the Storage.GetResponsesAfter
+type StorageMockGetResponsesAfterParams struct {
+	ctx     context.Context
+	apiName mm_service.APIName
+	afterID int64
+	limit   int
+}
+
+// StorageMockGetResponsesAfterResults contains results of the Storage.GetResponsesAfter
+type StorageMockGetResponsesAfterResults struct {
+	ppa1 []*mm_service.PostalApiResponse
+	err  error
+}
+
+// Expect sets up expected params for Storage.GetResponsesAfter
+func (mmGetResponsesAfter *mStorageMockGetResponsesAfter) Expect(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int) *mStorageMockGetResponsesAfter {
+	if mmGetResponsesAfter.mock.funcGetResponsesAfter != nil {
+		mmGetResponsesAfter.mock.t.Fatalf("StorageMock.GetResponsesAfter mock is already set by Set")
+	}
+
+	if mmGetResponsesAfter.defaultExpectation == nil {
+		mmGetResponsesAfter.defaultExpectation = &StorageMockGetResponsesAfterExpectation{}
+	}
+
+	mmGetResponsesAfter.defaultExpectation.params = &StorageMockGetResponsesAfterParams{ctx, apiName, afterID, limit}
+	for _, e := range mmGetResponsesAfter.expectations {
+		if minimock.Equal(e.params, mmGetResponsesAfter.defaultExpectation.params) {
+			mmGetResponsesAfter.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmGetResponsesAfter.defaultExpectation.params)
+		}
+	}
+
+	return mmGetResponsesAfter
+}
+
+// Inspect accepts an inspector function that has same arguments as the Storage.GetResponsesAfter
+func (mmGetResponsesAfter *mStorageMockGetResponsesAfter) Inspect(f func(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int)) *mStorageMockGetResponsesAfter {
+	if mmGetResponsesAfter.mock.inspectFuncGetResponsesAfter != nil {
+		mmGetResponsesAfter.mock.t.Fatalf("Inspect function is already set for StorageMock.GetResponsesAfter")
+	}
+
+	mmGetResponsesAfter.mock.inspectFuncGetResponsesAfter = f
+
+	return mmGetResponsesAfter
+}
+
+// Return sets up results that will be returned by Storage.GetResponsesAfter
+func (mmGetResponsesAfter *mStorageMockGetResponsesAfter) Return(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
+	if mmGetResponsesAfter.mock.funcGetResponsesAfter != nil {
+		mmGetResponsesAfter.mock.t.Fatalf("StorageMock.GetResponsesAfter mock is already set by Set")
+	}
+
+	if mmGetResponsesAfter.defaultExpectation == nil {
+		mmGetResponsesAfter.defaultExpectation = &StorageMockGetResponsesAfterExpectation{mock: mmGetResponsesAfter.mock}
+	}
+	mmGetResponsesAfter.defaultExpectation.results = &StorageMockGetResponsesAfterResults{ppa1, err}
+	return mmGetResponsesAfter.mock
+}
+
+// Set uses given function f to mock the Storage.GetResponsesAfter method
+func (mmGetResponsesAfter *mStorageMockGetResponsesAfter) Set(f func(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int) (ppa1 []*mm_service.PostalApiResponse, err error)) *StorageMock {
+	if mmGetResponsesAfter.defaultExpectation != nil {
+		mmGetResponsesAfter.mock.t.Fatalf("Default expectation is already set for the Storage.GetResponsesAfter method")
+	}
+
+	if len(mmGetResponsesAfter.expectations) > 0 {
+		mmGetResponsesAfter.mock.t.Fatalf("Some expectations are already set for the Storage.GetResponsesAfter method")
+	}
+
+	mmGetResponsesAfter.mock.funcGetResponsesAfter = f
+	return mmGetResponsesAfter.mock
+}
+
+// When sets expectation for the Storage.GetResponsesAfter which will trigger the result defined by the following
+// Then helper
+func (mmGetResponsesAfter *mStorageMockGetResponsesAfter) When(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int) *StorageMockGetResponsesAfterExpectation {
+	if mmGetResponsesAfter.mock.funcGetResponsesAfter != nil {
+		mmGetResponsesAfter.mock.t.Fatalf("StorageMock.GetResponsesAfter mock is already set by Set")
+	}
+
+	expectation := &StorageMockGetResponsesAfterExpectation{
+		mock:   mmGetResponsesAfter.mock,
+		params: &StorageMockGetResponsesAfterParams{ctx, apiName, afterID, limit},
+	}
+	mmGetResponsesAfter.expectations = append(mmGetResponsesAfter.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Storage.GetResponsesAfter return parameters for the expectation previously defined by the When method
+func (e *StorageMockGetResponsesAfterExpectation) Then(ppa1 []*mm_service.PostalApiResponse, err error) *StorageMock {
+	e.results = &StorageMockGetResponsesAfterResults{ppa1, err}
+	return e.mock
+}
+
+// GetResponsesAfter implements service.Storage
+func (mmGetResponsesAfter *StorageMock) GetResponsesAfter(ctx context.Context, apiName mm_service.APIName, afterID int64, limit int) (ppa1 []*mm_service.PostalApiResponse, err error) {
+	mm_atomic.AddUint64(&mmGetResponsesAfter.beforeGetResponsesAfterCounter, 1)
+	defer mm_atomic.AddUint64(&mmGetResponsesAfter.afterGetResponsesAfterCounter, 1)
+
+	if mmGetResponsesAfter.inspectFuncGetResponsesAfter != nil {
+		mmGetResponsesAfter.inspectFuncGetResponsesAfter(ctx, apiName, afterID, limit)
+	}
+
+	mm_params := &StorageMockGetResponsesAfterParams{ctx, apiName, afterID, limit}
+
+	// Record call args
+	mmGetResponsesAfter.GetResponsesAfterMock.mutex.Lock()
+	mmGetResponsesAfter.GetResponsesAfterMock.callArgs = append(mmGetResponsesAfter.GetResponsesAfterMock.callArgs, mm_params)
+	mmGetResponsesAfter.GetResponsesAfterMock.mutex.Unlock()
+
+	for _, e := range mmGetResponsesAfter.GetResponsesAfterMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.ppa1, e.results.err
+		}
+	}
+
+	if mmGetResponsesAfter.GetResponsesAfterMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmGetResponsesAfter.GetResponsesAfterMock.defaultExpectation.Counter, 1)
+		mm_want := mmGetResponsesAfter.GetResponsesAfterMock.defaultExpectation.params
+		mm_got := StorageMockGetResponsesAfterParams{ctx, apiName, afterID, limit}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmGetResponsesAfter.t.Errorf("StorageMock.GetResponsesAfter got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmGetResponsesAfter.GetResponsesAfterMock.defaultExpectation.results
+		if mm_results == nil {
+			mmGetResponsesAfter.t.Fatal("No results are set for the StorageMock.GetResponsesAfter")
+		}
+		return (*mm_results).ppa1, (*mm_results).err
+	}
+	if mmGetResponsesAfter.funcGetResponsesAfter != nil {
+		return mmGetResponsesAfter.funcGetResponsesAfter(ctx, apiName, afterID, limit)
+	}
+	mmGetResponsesAfter.t.Fatalf("Unexpected call to StorageMock.GetResponsesAfter. %v %v %v %v", ctx, apiName, afterID, limit)
+	return
+}
+
+// GetResponsesAfterAfterCounter returns a count of finished StorageMock.GetResponsesAfter invocations
+func (mmGetResponsesAfter *StorageMock) GetResponsesAfterAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetResponsesAfter.afterGetResponsesAfterCounter)
+}
+
+// GetResponsesAfterBeforeCounter returns a count of StorageMock.GetResponsesAfter invocations
+func (mmGetResponsesAfter *StorageMock) GetResponsesAfterBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetResponsesAfter.beforeGetResponsesAfterCounter)
+}
+
+// Calls returns a list of arguments used in each call to StorageMock.GetResponsesAfter.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmGetResponsesAfter *mStorageMockGetResponsesAfter) Calls() []*StorageMockGetResponsesAfterParams {
+	mmGetResponsesAfter.mutex.RLock()
+
+	argCopy := make([]*StorageMockGetResponsesAfterParams, len(mmGetResponsesAfter.callArgs))
+	copy(argCopy, mmGetResponsesAfter.callArgs)
+
+	mmGetResponsesAfter.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockGetResponsesAfterDone returns true if the count of the GetResponsesAfter invocations corresponds
+// the number of defined expectations
+func (m *StorageMock) MinimockGetResponsesAfterDone() bool {
+	for _, e := range m.GetResponsesAfterMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetResponsesAfterMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetResponsesAfterCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetResponsesAfter != nil && mm_atomic.LoadUint64(&m.afterGetResponsesAfterCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockGetResponsesAfterInspect logs each unmet expectation
+func (m *StorageMock) MinimockGetResponsesAfterInspect() {
+	for _, e := range m.GetResponsesAfterMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to StorageMock.GetResponsesAfter with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetResponsesAfterMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetResponsesAfterCounter) < 1 {
+		if m.GetResponsesAfterMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.GetResponsesAfter")
+		} else {
+			m.t.Errorf("Expected call to StorageMock.GetResponsesAfter with params: %#v", *m.GetResponsesAfterMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetResponsesAfter != nil && mm_atomic.LoadUint64(&m.afterGetResponsesAfterCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.GetResponsesAfter")
+	}
+}
+
+type mStorageMockInsert struct {
+	mock               *StorageMock
+	defaultExpectation *StorageMockInsertExpectation
+	expectations       []*StorageMockInsertExpectation
+
+	callArgs []*StorageMockInsertParams
+	mutex    sync.RWMutex
+}
+
+// StorageMockInsertExpectation specifies expectation struct of the Storage.Insert
+type StorageMockInsertExpectation struct {
+	mock    *StorageMock
+	params  *StorageMockInsertParams
+	results *StorageMockInsertResults
+	Counter uint64
+}
+
+// StorageMockInsertParams contains parameters of the Storage.Insert
+type StorageMockInsertParams struct {
+	ctx            context.Context
+	trackingNumber string
+	apiName        mm_service.APIName
+	response       *mm_service.PostalApiResponse
+}
+
+// StorageMockInsertResults contains results of the Storage.Insert
+type StorageMockInsertResults struct {
+	err error
+}
+
+// Expect sets up expected params for Storage.Insert
+func (mmInsert *mStorageMockInsert) Expect(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) *mStorageMockInsert {
+	if mmInsert.mock.funcInsert != nil {
+		mmInsert.mock.t.Fatalf("StorageMock.Insert mock is already set by Set")
+	}
+
+	if mmInsert.defaultExpectation == nil {
+		mmInsert.defaultExpectation = &StorageMockInsertExpectation{}
+	}
+
+	mmInsert.defaultExpectation.params = &StorageMockInsertParams{ctx, trackingNumber, apiName, response}
+	for _, e := range mmInsert.expectations {
+		if minimock.Equal(e.params, mmInsert.defaultExpectation.params) {
+			mmInsert.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmInsert.defaultExpectation.params)
+		}
+	}
+
+	return mmInsert
+}
+
+// Inspect accepts an inspector function that has same arguments as the Storage.Insert
+func (mmInsert *mStorageMockInsert) Inspect(f func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse)) *mStorageMockInsert {
+	if mmInsert.mock.inspectFuncInsert != nil {
+		mmInsert.mock.t.Fatalf("Inspect function is already set for StorageMock.Insert")
+	}
+
+	mmInsert.mock.inspectFuncInsert = f
+
+	return mmInsert
+}
+
+// Return sets up results that will be returned by Storage.Insert
+func (mmInsert *mStorageMockInsert) Return(err error) *StorageMock {
+	if mmInsert.mock.funcInsert != nil {
+		mmInsert.mock.t.Fatalf("StorageMock.Insert mock is already set by Set")
+	}
+
+	if mmInsert.defaultExpectation == nil {
+		mmInsert.defaultExpectation = &StorageMockInsertExpectation{mock: mmInsert.mock}
+	}
+	mmInsert.defaultExpectation.results = &StorageMockInsertResults{err}
+	return mmInsert.mock
+}
+
+// Set uses given function f to mock the Storage.Insert method
+func (mmInsert *mStorageMockInsert) Set(f func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) (err error)) *StorageMock {
+	if mmInsert.defaultExpectation != nil {
+		mmInsert.mock.t.Fatalf("Default expectation is already set for the Storage.Insert method")
+	}
+
+	if len(mmInsert.expectations) > 0 {
+		mmInsert.mock.t.Fatalf("Some expectations are already set for the Storage.Insert method")
+	}
+
+	mmInsert.mock.funcInsert = f
+	return mmInsert.mock
+}
+
+// When sets expectation for the Storage.Insert which will trigger the result defined by the following
+// Then helper
+func (mmInsert *mStorageMockInsert) When(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) *StorageMockInsertExpectation {
+	if mmInsert.mock.funcInsert != nil {
+		mmInsert.mock.t.Fatalf("StorageMock.Insert mock is already set by Set")
+	}
+
+	expectation := &StorageMockInsertExpectation{
+		mock:   mmInsert.mock,
+		params: &StorageMockInsertParams{ctx, trackingNumber, apiName, response},
+	}
+	mmInsert.expectations = append(mmInsert.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Storage.Insert return parameters for the expectation previously defined by the When method
+func (e *StorageMockInsertExpectation) Then(err error) *StorageMock {
+	e.results = &StorageMockInsertResults{err}
+	return e.mock
+}
+
+// Insert implements service.Storage
+func (mmInsert *StorageMock) Insert(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) (err error) {
+	mm_atomic.AddUint64(&mmInsert.beforeInsertCounter, 1)
+	defer mm_atomic.AddUint64(&mmInsert.afterInsertCounter, 1)
+
+	if mmInsert.inspectFuncInsert != nil {
+		mmInsert.inspectFuncInsert(ctx, trackingNumber, apiName, response)
+	}
+
+	mm_params := &StorageMockInsertParams{ctx, trackingNumber, apiName, response}
+
+	// Record call args
+	mmInsert.InsertMock.mutex.Lock()
+	mmInsert.InsertMock.callArgs = append(mmInsert.InsertMock.callArgs, mm_params)
+	mmInsert.InsertMock.mutex.Unlock()
+
+	for _, e := range mmInsert.InsertMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.err
+		}
+	}
+
+	if mmInsert.InsertMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmInsert.InsertMock.defaultExpectation.Counter, 1)
+		mm_want := mmInsert.InsertMock.defaultExpectation.params
+		mm_got := StorageMockInsertParams{ctx, trackingNumber, apiName, response}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmInsert.t.Errorf("StorageMock.Insert got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmInsert.InsertMock.defaultExpectation.results
+		if mm_results == nil {
+			mmInsert.t.Fatal("No results are set for the StorageMock.Insert")
+		}
+		return (*mm_results).err
+	}
+	if mmInsert.funcInsert != nil {
+		return mmInsert.funcInsert(ctx, trackingNumber, apiName, response)
+	}
+	mmInsert.t.Fatalf("Unexpected call to StorageMock.Insert. %v %v %v %v", ctx, trackingNumber, apiName, response)
+	return
+}
+
+// InsertAfterCounter returns a count of finished StorageMock.Insert invocations
+func (mmInsert *StorageMock) InsertAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmInsert.afterInsertCounter)
+}
+
+// InsertBeforeCounter returns a count of StorageMock.Insert invocations
+func (mmInsert *StorageMock) InsertBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmInsert.beforeInsertCounter)
+}
+
+// Calls returns a list of arguments used in each call to StorageMock.Insert.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmInsert *mStorageMockInsert) Calls() []*StorageMockInsertParams {
+	mmInsert.mutex.RLock()
+
+	argCopy := make([]*StorageMockInsertParams, len(mmInsert.callArgs))
+	copy(argCopy, mmInsert.callArgs)
+
+	mmInsert.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockInsertDone returns true if the count of the Insert invocations corresponds
+// the number of defined expectations
+func (m *StorageMock) MinimockInsertDone() bool {
+	for _, e := range m.InsertMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.InsertMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterInsertCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcInsert != nil && mm_atomic.LoadUint64(&m.afterInsertCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockInsertInspect logs each unmet expectation
+func (m *StorageMock) MinimockInsertInspect() {
+	for _, e := range m.InsertMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to StorageMock.Insert with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.InsertMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterInsertCounter) < 1 {
+		if m.InsertMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.Insert")
+		} else {
+			m.t.Errorf("Expected call to StorageMock.Insert with params: %#v", *m.InsertMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcInsert != nil && mm_atomic.LoadUint64(&m.afterInsertCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.Insert")
+	}
+}
+
+type mStorageMockInsertLinks struct {
+	mock               *StorageMock
+	defaultExpectation *StorageMockInsertLinksExpectation
+	expectations       []*StorageMockInsertLinksExpectation
+
+	callArgs []*StorageMockInsertLinksParams
+	mutex    sync.RWMutex
+}
+
+// StorageMockInsertLinksExpectation specifies expectation struct of the Storage.InsertLinks
+type StorageMockInsertLinksExpectation struct {
+	mock    *StorageMock
+	params  *StorageMockInsertLinksParams
+	results *StorageMockInsertLinksResults
+	Counter uint64
+}
+
+// StorageMockInsertLinksParams contains parameters of the Storage.InsertLinks
+type StorageMockInsertLinksParams struct {
+	ctx                   context.Context
+	trackingNumber        string
+	linkedTrackingNumbers []string
+}
+
+// StorageMockInsertLinksResults contains results of the Storage.InsertLinks
+type StorageMockInsertLinksResults struct {
+	err error
+}
+
+// Expect sets up expected params for Storage.InsertLinks
+func (mmInsertLinks *mStorageMockInsertLinks) Expect(ctx context.Context, trackingNumber string, linkedTrackingNumbers []string) *mStorageMockInsertLinks {
+	if mmInsertLinks.mock.funcInsertLinks != nil {
+		mmInsertLinks.mock.t.Fatalf("StorageMock.InsertLinks mock is already set by Set")
+	}
+
+	if mmInsertLinks.defaultExpectation == nil {
+		mmInsertLinks.defaultExpectation = &StorageMockInsertLinksExpectation{}
+	}
+
+	mmInsertLinks.defaultExpectation.params = &StorageMockInsertLinksParams{ctx, trackingNumber, linkedTrackingNumbers}
+	for _, e := range mmInsertLinks.expectations {
+		if minimock.Equal(e.params, mmInsertLinks.defaultExpectation.params) {
+			mmInsertLinks.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmInsertLinks.defaultExpectation.params)
+		}
+	}
+
+	return mmInsertLinks
+}
+
+// Inspect accepts an inspector function that has same arguments as the Storage.InsertLinks
+func (mmInsertLinks *mStorageMockInsertLinks) Inspect(f func(ctx context.Context, trackingNumber string, linkedTrackingNumbers []string)) *mStorageMockInsertLinks {
+	if mmInsertLinks.mock.inspectFuncInsertLinks != nil {
+		mmInsertLinks.mock.t.Fatalf("Inspect function is already set for StorageMock.InsertLinks")
+	}
+
+	mmInsertLinks.mock.inspectFuncInsertLinks = f
+
+	return mmInsertLinks
+}
+
+// Return sets up results that will be returned by Storage.InsertLinks
+func (mmInsertLinks *mStorageMockInsertLinks) Return(err error) *StorageMock {
+	if mmInsertLinks.mock.funcInsertLinks != nil {
+		mmInsertLinks.mock.t.Fatalf("StorageMock.InsertLinks mock is already set by Set")
+	}
+
+	if mmInsertLinks.defaultExpectation == nil {
+		mmInsertLinks.defaultExpectation = &StorageMockInsertLinksExpectation{mock: mmInsertLinks.mock}
+	}
+	mmInsertLinks.defaultExpectation.results = &StorageMockInsertLinksResults{err}
+	return mmInsertLinks.mock
+}
+
+// Set uses given function f to mock the Storage.InsertLinks method
+func (mmInsertLinks *mStorageMockInsertLinks) Set(f func(ctx context.Context, trackingNumber string, linkedTrackingNumbers []string) (err error)) *StorageMock {
+	if mmInsertLinks.defaultExpectation != nil {
+		mmInsertLinks.mock.t.Fatalf("Default expectation is already set for the Storage.InsertLinks method")
+	}
+
+	if len(mmInsertLinks.expectations) > 0 {
+		mmInsertLinks.mock.t.Fatalf("Some expectations are already set for the Storage.InsertLinks method")
+	}
+
+	mmInsertLinks.mock.funcInsertLinks = f
+	return mmInsertLinks.mock
+}
+
+// When sets expectation for the Storage.InsertLinks which will trigger the result defined by the following
+// Then helper
+func (mmInsertLinks *mStorageMockInsertLinks) When(ctx context.Context, trackingNumber string, linkedTrackingNumbers []string) *StorageMockInsertLinksExpectation {
+	if mmInsertLinks.mock.funcInsertLinks != nil {
+		mmInsertLinks.mock.t.Fatalf("StorageMock.InsertLinks mock is already set by Set")
+	}
+
+	expectation := &StorageMockInsertLinksExpectation{
+		mock:   mmInsertLinks.mock,
+		params: &StorageMockInsertLinksParams{ctx, trackingNumber, linkedTrackingNumbers},
+	}
+	mmInsertLinks.expectations = append(mmInsertLinks.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Storage.InsertLinks return parameters for the expectation previously defined by the When method
+func (e *StorageMockInsertLinksExpectation) Then(err error) *StorageMock {
+	e.results = &StorageMockInsertLinksResults{err}
+	return e.mock
+}
+
+// InsertLinks implements service.Storage
+func (mmInsertLinks *StorageMock) InsertLinks(ctx context.Context, trackingNumber string, linkedTrackingNumbers []string) (err error) {
+	mm_atomic.AddUint64(&mmInsertLinks.beforeInsertLinksCounter, 1)
+	defer mm_atomic.AddUint64(&mmInsertLinks.afterInsertLinksCounter, 1)
+
+	if mmInsertLinks.inspectFuncInsertLinks != nil {
+		mmInsertLinks.inspectFuncInsertLinks(ctx, trackingNumber, linkedTrackingNumbers)
+	}
+
+	mm_params := &StorageMockInsertLinksParams{ctx, trackingNumber, linkedTrackingNumbers}
+
+	// Record call args
+	mmInsertLinks.InsertLinksMock.mutex.Lock()
+	mmInsertLinks.InsertLinksMock.callArgs = append(mmInsertLinks.InsertLinksMock.callArgs, mm_params)
+	mmInsertLinks.InsertLinksMock.mutex.Unlock()
+
+	for _, e := range mmInsertLinks.InsertLinksMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.err
+		}
+	}
+
+	if mmInsertLinks.InsertLinksMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmInsertLinks.InsertLinksMock.defaultExpectation.Counter, 1)
+		mm_want := mmInsertLinks.InsertLinksMock.defaultExpectation.params
+		mm_got := StorageMockInsertLinksParams{ctx, trackingNumber, linkedTrackingNumbers}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmInsertLinks.t.Errorf("StorageMock.InsertLinks got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmInsertLinks.InsertLinksMock.defaultExpectation.results
+		if mm_results == nil {
+			mmInsertLinks.t.Fatal("No results are set for the StorageMock.InsertLinks")
+		}
+		return (*mm_results).err
+	}
+	if mmInsertLinks.funcInsertLinks != nil {
+		return mmInsertLinks.funcInsertLinks(ctx, trackingNumber, linkedTrackingNumbers)
+	}
+	mmInsertLinks.t.Fatalf("Unexpected call to StorageMock.InsertLinks. %v %v %v", ctx, trackingNumber, linkedTrackingNumbers)
+	return
+}
+
+// InsertLinksAfterCounter returns a count of finished StorageMock.InsertLinks invocations
+func (mmInsertLinks *StorageMock) InsertLinksAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmInsertLinks.afterInsertLinksCounter)
+}
+
+// InsertLinksBeforeCounter returns a count of StorageMock.InsertLinks invocations
+func (mmInsertLinks *StorageMock) InsertLinksBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmInsertLinks.beforeInsertLinksCounter)
+}
+
+// Calls returns a list of arguments used in each call to StorageMock.InsertLinks.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmInsertLinks *mStorageMockInsertLinks) Calls() []*StorageMockInsertLinksParams {
+	mmInsertLinks.mutex.RLock()
+
+	argCopy := make([]*StorageMockInsertLinksParams, len(mmInsertLinks.callArgs))
+	copy(argCopy, mmInsertLinks.callArgs)
+
+	mmInsertLinks.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockInsertLinksDone returns true if the count of the InsertLinks invocations corresponds
+// the number of defined expectations
+func (m *StorageMock) MinimockInsertLinksDone() bool {
+	for _, e := range m.InsertLinksMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.InsertLinksMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterInsertLinksCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcInsertLinks != nil && mm_atomic.LoadUint64(&m.afterInsertLinksCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockInsertLinksInspect logs each unmet expectation
+func (m *StorageMock) MinimockInsertLinksInspect() {
+	for _, e := range m.InsertLinksMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to StorageMock.InsertLinks with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.InsertLinksMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterInsertLinksCounter) < 1 {
+		if m.InsertLinksMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.InsertLinks")
+		} else {
+			m.t.Errorf("Expected call to StorageMock.InsertLinks with params: %#v", *m.InsertLinksMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcInsertLinks != nil && mm_atomic.LoadUint64(&m.afterInsertLinksCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.InsertLinks")
+	}
+}
+
+type mStorageMockUpdate struct {
+	mock               *StorageMock
+	defaultExpectation *StorageMockUpdateExpectation
+	expectations       []*StorageMockUpdateExpectation
+
+	callArgs []*StorageMockUpdateParams
+	mutex    sync.RWMutex
+}
+
+// StorageMockUpdateExpectation specifies expectation struct of the Storage.Update
+type StorageMockUpdateExpectation struct {
+	mock    *StorageMock
+	params  *StorageMockUpdateParams
+	results *StorageMockUpdateResults
+	Counter uint64
+}
+
+// StorageMockUpdateParams contains parameters of the Storage.Update
+type StorageMockUpdateParams struct {
+	ctx context.Context
+	pp1 *mm_service.PostalApiResponse
+}
+
+// StorageMockUpdateResults contains results of the Storage.Update
+type StorageMockUpdateResults struct {
+	err error
+}
+
+// Expect sets up expected params for Storage.Update
+func (mmUpdate *mStorageMockUpdate) Expect(ctx context.Context, pp1 *mm_service.PostalApiResponse) *mStorageMockUpdate {
+	if mmUpdate.mock.funcUpdate != nil {
+		mmUpdate.mock.t.Fatalf("StorageMock.Update mock is already set by Set")
+	}
+
+	if mmUpdate.defaultExpectation == nil {
+		mmUpdate.defaultExpectation = &StorageMockUpdateExpectation{}
+	}
+
+	mmUpdate.defaultExpectation.params = &StorageMockUpdateParams{ctx, pp1}
+	for _, e := range mmUpdate.expectations {
+		if minimock.Equal(e.params, mmUpdate.defaultExpectation.params) {
+			mmUpdate.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmUpdate.defaultExpectation.params)
+		}
+	}
+
+	return mmUpdate
+}
+
+// Inspect accepts an inspector function that has same arguments as the Storage.Update
+func (mmUpdate *mStorageMockUpdate) Inspect(f func(ctx context.Context, pp1 *mm_service.PostalApiResponse)) *mStorageMockUpdate {
+	if mmUpdate.mock.inspectFuncUpdate != nil {
+		mmUpdate.mock.t.Fatalf("Inspect function is already set for StorageMock.Update")
+	}
+
+	mmUpdate.mock.inspectFuncUpdate = f
+
+	return mmUpdate
+}
+
+// Return sets up results that will be returned by Storage.Update
+func (mmUpdate *mStorageMockUpdate) Return(err error) *StorageMock {
+	if mmUpdate.mock.funcUpdate != nil {
+		mmUpdate.mock.t.Fatalf("StorageMock.Update mock is already set by Set")
+	}
+
+	if mmUpdate.defaultExpectation == nil {
+		mmUpdate.defaultExpectation = &StorageMockUpdateExpectation{mock: mmUpdate.mock}
+	}
+	mmUpdate.defaultExpectation.results = &StorageMockUpdateResults{err}
+	return mmUpdate.mock
+}
+
+// Set uses given function f to mock the Storage.Update method
+func (mmUpdate *mStorageMockUpdate) Set(f func(ctx context.Context, pp1 *mm_service.PostalApiResponse) (err error)) *StorageMock {
+	if mmUpdate.defaultExpectation != nil {
+		mmUpdate.mock.t.Fatalf("Default expectation is already set for the Storage.Update method")
+	}
+
+	if len(mmUpdate.expectations) > 0 {
+		mmUpdate.mock.t.Fatalf("Some expectations are already set for the Storage.Update method")
+	}
+
+	mmUpdate.mock.funcUpdate = f
+	return mmUpdate.mock
+}
+
+// When sets expectation for the Storage.Update which will trigger the result defined by the following
+// Then helper
+func (mmUpdate *mStorageMockUpdate) When(ctx context.Context, pp1 *mm_service.PostalApiResponse) *StorageMockUpdateExpectation {
+	if mmUpdate.mock.funcUpdate != nil {
+		mmUpdate.mock.t.Fatalf("StorageMock.Update mock is already set by Set")
+	}
+
+	expectation := &StorageMockUpdateExpectation{
+		mock:   mmUpdate.mock,
+		params: &StorageMockUpdateParams{ctx, pp1},
+	}
+	mmUpdate.expectations = append(mmUpdate.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Storage.Update return parameters for the expectation previously defined by the When method
+func (e *StorageMockUpdateExpectation) Then(err error) *StorageMock {
+	e.results = &StorageMockUpdateResults{err}
+	return e.mock
+}
+
+// Update implements service.Storage
+func (mmUpdate *StorageMock) Update(ctx context.Context, pp1 *mm_service.PostalApiResponse) (err error) {
+	mm_atomic.AddUint64(&mmUpdate.beforeUpdateCounter, 1)
+	defer mm_atomic.AddUint64(&mmUpdate.afterUpdateCounter, 1)
+
+	if mmUpdate.inspectFuncUpdate != nil {
+		mmUpdate.inspectFuncUpdate(ctx, pp1)
+	}
+
+	mm_params := &StorageMockUpdateParams{ctx, pp1}
+
+	// Record call args
+	mmUpdate.UpdateMock.mutex.Lock()
+	mmUpdate.UpdateMock.callArgs = append(mmUpdate.UpdateMock.callArgs, mm_params)
+	mmUpdate.UpdateMock.mutex.Unlock()
+
+	for _, e := range mmUpdate.UpdateMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.err
+		}
+	}
+
+	if mmUpdate.UpdateMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmUpdate.UpdateMock.defaultExpectation.Counter, 1)
+		mm_want := mmUpdate.UpdateMock.defaultExpectation.params
+		mm_got := StorageMockUpdateParams{ctx, pp1}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmUpdate.t.Errorf("StorageMock.Update got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmUpdate.UpdateMock.defaultExpectation.results
+		if mm_results == nil {
+			mmUpdate.t.Fatal("No results are set for the StorageMock.Update")
+		}
+		return (*mm_results).err
+	}
+	if mmUpdate.funcUpdate != nil {
+		return mmUpdate.funcUpdate(ctx, pp1)
+	}
+	mmUpdate.t.Fatalf("Unexpected call to StorageMock.Update. %v %v", ctx, pp1)
+	return
+}
+
+// UpdateAfterCounter returns a count of finished StorageMock.Update invocations
+func (mmUpdate *StorageMock) UpdateAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmUpdate.afterUpdateCounter)
+}
+
+// UpdateBeforeCounter returns a count of StorageMock.Update invocations
+func (mmUpdate *StorageMock) UpdateBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmUpdate.beforeUpdateCounter)
+}
+
+// Calls returns a list of arguments used in each call to StorageMock.Update.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmUpdate *mStorageMockUpdate) Calls() []*StorageMockUpdateParams {
+	mmUpdate.mutex.RLock()
 
-	mmGetLatest.mutex.RUnlock()
+	argCopy := make([]*StorageMockUpdateParams, len(mmUpdate.callArgs))
+	copy(argCopy, mmUpdate.callArgs)
+
+	mmUpdate.mutex.RUnlock()
 
 	return argCopy
 }
 
-// MinimockGetLatestDone returns true if the count of the GetLatest invocations corresponds
+// MinimockUpdateDone returns true if the count of the Update invocations corresponds
 // the number of defined expectations
-func (m *StorageMock) MinimockGetLatestDone() bool {
-	for _, e := range m.GetLatestMock.expectations {
+func (m *StorageMock) MinimockUpdateDone() bool {
+	for _, e := range m.UpdateMock.expectations {
 		if mm_atomic.LoadUint64(&e.Counter) < 1 {
 			return false
 		}
 	}
 
 	// if default expectation was set then invocations count should be greater than zero
-	if m.GetLatestMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetLatestCounter) < 1 {
+	if m.UpdateMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterUpdateCounter) < 1 {
 		return false
 	}
 	// if func was set then invocations count should be greater than zero
-	if m.funcGetLatest != nil && mm_atomic.LoadUint64(&m.afterGetLatestCounter) < 1 {
+	if m.funcUpdate != nil && mm_atomic.LoadUint64(&m.afterUpdateCounter) < 1 {
 		return false
 	}
 	return true
 }
 
-// MinimockGetLatestInspect logs each unmet expectation
-func (m *StorageMock) MinimockGetLatestInspect() {
-	for _, e := range m.GetLatestMock.expectations {
+// MinimockUpdateInspect logs each unmet expectation
+func (m *StorageMock) MinimockUpdateInspect() {
+	for _, e := range m.UpdateMock.expectations {
 		if mm_atomic.LoadUint64(&e.Counter) < 1 {
-			m.t.Errorf("Expected call to StorageMock.GetLatest with params: %#v", *e.params)
+			m.t.Errorf("Expected call to StorageMock.Update with params: %#v", *e.params)
 		}
 	}
 
 	// if default expectation was set then invocations count should be greater than zero
-	if m.GetLatestMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetLatestCounter) < 1 {
-		if m.GetLatestMock.defaultExpectation.params == nil {
-			m.t.Error("Expected call to StorageMock.GetLatest")
+	if m.UpdateMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterUpdateCounter) < 1 {
+		if m.UpdateMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.Update")
 		} else {
-			m.t.Errorf("Expected call to StorageMock.GetLatest with params: %#v", *m.GetLatestMock.defaultExpectation.params)
+			m.t.Errorf("Expected call to StorageMock.Update with params: %#v", *m.UpdateMock.defaultExpectation.params)
 		}
 	}
 	// if func was set then invocations count should be greater than zero
-	if m.funcGetLatest != nil && mm_atomic.LoadUint64(&m.afterGetLatestCounter) < 1 {
-		m.t.Error("Expected call to StorageMock.GetLatest")
+	if m.funcUpdate != nil && mm_atomic.LoadUint64(&m.afterUpdateCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.Update")
 	}
 }
 
-type mStorageMockInsert struct {
+type mStorageMockUpsert struct {
 	mock               *StorageMock
-	defaultExpectation *StorageMockInsertExpectation
-	expectations       []*StorageMockInsertExpectation
+	defaultExpectation *StorageMockUpsertExpectation
+	expectations       []*StorageMockUpsertExpectation
 
-	callArgs []*StorageMockInsertParams
+	callArgs []*StorageMockUpsertParams
 	mutex    sync.RWMutex
 }
 
-// StorageMockInsertExpectation specifies expectation struct of the Storage.Insert
-type StorageMockInsertExpectation struct {
+// StorageMockUpsertExpectation specifies expectation struct of the Storage.Upsert
+type StorageMockUpsertExpectation struct {
 	mock    *StorageMock
-	params  *StorageMockInsertParams
-	results *StorageMockInsertResults
+	params  *StorageMockUpsertParams
+	results *StorageMockUpsertResults
 	Counter uint64
 }
 
-// StorageMockInsertParams contains parameters of the Storage.Insert
-type StorageMockInsertParams struct {
+// StorageMockUpsertParams contains parameters of the Storage.Upsert
+type StorageMockUpsertParams struct {
 	ctx            context.Context
 	trackingNumber string
 	apiName        mm_service.APIName
 	response       *mm_service.PostalApiResponse
 }
 
-// StorageMockInsertResults contains results of the Storage.Insert
-type StorageMockInsertResults struct {
+// StorageMockUpsertResults contains results of the Storage.Upsert
+type StorageMockUpsertResults struct {
 	err error
 }
 
-// Expect sets up expected params for Storage.Insert
-func (mmInsert *mStorageMockInsert) Expect(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) *mStorageMockInsert {
-	if mmInsert.mock.funcInsert != nil {
-		mmInsert.mock.t.Fatalf("StorageMock.Insert mock is already set by Set")
+// Expect sets up expected params for Storage.Upsert
+func (mmUpsert *mStorageMockUpsert) Expect(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) *mStorageMockUpsert {
+	if mmUpsert.mock.funcUpsert != nil {
+		mmUpsert.mock.t.Fatalf("StorageMock.Upsert mock is already set by Set")
 	}
 
-	if mmInsert.defaultExpectation == nil {
-		mmInsert.defaultExpectation = &StorageMockInsertExpectation{}
+	if mmUpsert.defaultExpectation == nil {
+		mmUpsert.defaultExpectation = &StorageMockUpsertExpectation{}
 	}
 
-	mmInsert.defaultExpectation.params = &StorageMockInsertParams{ctx, trackingNumber, apiName, response}
-	for _, e := range mmInsert.expectations {
-		if minimock.Equal(e.params, mmInsert.defaultExpectation.params) {
-			mmInsert.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmInsert.defaultExpectation.params)
+	mmUpsert.defaultExpectation.params = &StorageMockUpsertParams{ctx, trackingNumber, apiName, response}
+	for _, e := range mmUpsert.expectations {
+		if minimock.Equal(e.params, mmUpsert.defaultExpectation.params) {
+			mmUpsert.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmUpsert.defaultExpectation.params)
 		}
 	}
 
-	return mmInsert
+	return mmUpsert
 }
 
-// Inspect accepts an inspector function that has same arguments as the Storage.Insert
-func (mmInsert *mStorageMockInsert) Inspect(f func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse)) *mStorageMockInsert {
-	if mmInsert.mock.inspectFuncInsert != nil {
-		mmInsert.mock.t.Fatalf("Inspect function is already set for StorageMock.Insert")
+// Inspect accepts an inspector function that has same arguments as the Storage.Upsert
+func (mmUpsert *mStorageMockUpsert) Inspect(f func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse)) *mStorageMockUpsert {
+	if mmUpsert.mock.inspectFuncUpsert != nil {
+		mmUpsert.mock.t.Fatalf("Inspect function is already set for StorageMock.Upsert")
 	}
 
-	mmInsert.mock.inspectFuncInsert = f
+	mmUpsert.mock.inspectFuncUpsert = f
 
-	return mmInsert
+	return mmUpsert
 }
 
-// Return sets up results that will be returned by Storage.Insert
-func (mmInsert *mStorageMockInsert) Return(err error) *StorageMock {
-	if mmInsert.mock.funcInsert != nil {
-		mmInsert.mock.t.Fatalf("StorageMock.Insert mock is already set by Set")
+// Return sets up results that will be returned by Storage.Upsert
+func (mmUpsert *mStorageMockUpsert) Return(err error) *StorageMock {
+	if mmUpsert.mock.funcUpsert != nil {
+		mmUpsert.mock.t.Fatalf("StorageMock.Upsert mock is already set by Set")
 	}
 
-	if mmInsert.defaultExpectation == nil {
-		mmInsert.defaultExpectation = &StorageMockInsertExpectation{mock: mmInsert.mock}
+	if mmUpsert.defaultExpectation == nil {
+		mmUpsert.defaultExpectation = &StorageMockUpsertExpectation{mock: mmUpsert.mock}
 	}
-	mmInsert.defaultExpectation.results = &StorageMockInsertResults{err}
-	return mmInsert.mock
+	mmUpsert.defaultExpectation.results = &StorageMockUpsertResults{err}
+	return mmUpsert.mock
 }
 
-// Set uses given function f to mock the Storage.Insert method
-func (mmInsert *mStorageMockInsert) Set(f func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) (err error)) *StorageMock {
-	if mmInsert.defaultExpectation != nil {
-		mmInsert.mock.t.Fatalf("Default expectation is already set for the Storage.Insert method")
+// Set uses given function f to mock the Storage.Upsert method
+func (mmUpsert *mStorageMockUpsert) Set(f func(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) (err error)) *StorageMock {
+	if mmUpsert.defaultExpectation != nil {
+		mmUpsert.mock.t.Fatalf("Default expectation is already set for the Storage.Upsert method")
 	}
 
-	if len(mmInsert.expectations) > 0 {
-		mmInsert.mock.t.Fatalf("Some expectations are already set for the Storage.Insert method")
+	if len(mmUpsert.expectations) > 0 {
+		mmUpsert.mock.t.Fatalf("Some expectations are already set for the Storage.Upsert method")
 	}
 
-	mmInsert.mock.funcInsert = f
-	return mmInsert.mock
+	mmUpsert.mock.funcUpsert = f
+	return mmUpsert.mock
 }
 
-// When sets expectation for the Storage.Insert which will trigger the result defined by the following
+// When sets expectation for the Storage.Upsert which will trigger the result defined by the following
 // Then helper
-func (mmInsert *mStorageMockInsert) When(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) *StorageMockInsertExpectation {
-	if mmInsert.mock.funcInsert != nil {
-		mmInsert.mock.t.Fatalf("StorageMock.Insert mock is already set by Set")
+func (mmUpsert *mStorageMockUpsert) When(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) *StorageMockUpsertExpectation {
+	if mmUpsert.mock.funcUpsert != nil {
+		mmUpsert.mock.t.Fatalf("StorageMock.Upsert mock is already set by Set")
 	}
 
-	expectation := &StorageMockInsertExpectation{
-		mock:   mmInsert.mock,
-		params: &StorageMockInsertParams{ctx, trackingNumber, apiName, response},
+	expectation := &StorageMockUpsertExpectation{
+		mock:   mmUpsert.mock,
+		params: &StorageMockUpsertParams{ctx, trackingNumber, apiName, response},
 	}
-	mmInsert.expectations = append(mmInsert.expectations, expectation)
+	mmUpsert.expectations = append(mmUpsert.expectations, expectation)
 	return expectation
 }
 
-// Then sets up Storage.Insert return parameters for the expectation previously defined by the When method
-func (e *StorageMockInsertExpectation) Then(err error) *StorageMock {
-	e.results = &StorageMockInsertResults{err}
+// Then sets up Storage.Upsert return parameters for the expectation previously defined by the When method
+func (e *StorageMockUpsertExpectation) Then(err error) *StorageMock {
+	e.results = &StorageMockUpsertResults{err}
 	return e.mock
 }
 
-// Insert implements service.Storage
-func (mmInsert *StorageMock) Insert(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) (err error) {
-	mm_atomic.AddUint64(&mmInsert.beforeInsertCounter, 1)
-	defer mm_atomic.AddUint64(&mmInsert.afterInsertCounter, 1)
+// Upsert implements service.Storage
+func (mmUpsert *StorageMock) Upsert(ctx context.Context, trackingNumber string, apiName mm_service.APIName, response *mm_service.PostalApiResponse) (err error) {
+	mm_atomic.AddUint64(&mmUpsert.beforeUpsertCounter, 1)
+	defer mm_atomic.AddUint64(&mmUpsert.afterUpsertCounter, 1)
 
-	if mmInsert.inspectFuncInsert != nil {
-		mmInsert.inspectFuncInsert(ctx, trackingNumber, apiName, response)
+	if mmUpsert.inspectFuncUpsert != nil {
+		mmUpsert.inspectFuncUpsert(ctx, trackingNumber, apiName, response)
 	}
 
-	mm_params := &StorageMockInsertParams{ctx, trackingNumber, apiName, response}
+	mm_params := &StorageMockUpsertParams{ctx, trackingNumber, apiName, response}
 
 	// Record call args
-	mmInsert.InsertMock.mutex.Lock()
-	mmInsert.InsertMock.callArgs = append(mmInsert.InsertMock.callArgs, mm_params)
-	mmInsert.InsertMock.mutex.Unlock()
+	mmUpsert.UpsertMock.mutex.Lock()
+	mmUpsert.UpsertMock.callArgs = append(mmUpsert.UpsertMock.callArgs, mm_params)
+	mmUpsert.UpsertMock.mutex.Unlock()
 
-	for _, e := range mmInsert.InsertMock.expectations {
+	for _, e := range mmUpsert.UpsertMock.expectations {
 		if minimock.Equal(e.params, mm_params) {
 			mm_atomic.AddUint64(&e.Counter, 1)
 			return e.results.err
 		}
 	}
 
-	if mmInsert.InsertMock.defaultExpectation != nil {
-		mm_atomic.AddUint64(&mmInsert.InsertMock.defaultExpectation.Counter, 1)
-		mm_want := mmInsert.InsertMock.defaultExpectation.params
-		mm_got := StorageMockInsertParams{ctx, trackingNumber, apiName, response}
+	if mmUpsert.UpsertMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmUpsert.UpsertMock.defaultExpectation.Counter, 1)
+		mm_want := mmUpsert.UpsertMock.defaultExpectation.params
+		mm_got := StorageMockUpsertParams{ctx, trackingNumber, apiName, response}
 		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
-			mmInsert.t.Errorf("StorageMock.Insert got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+			mmUpsert.t.Errorf("StorageMock.Upsert got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
 		}
 
-		mm_results := mmInsert.InsertMock.defaultExpectation.results
+		mm_results := mmUpsert.UpsertMock.defaultExpectation.results
 		if mm_results == nil {
-			mmInsert.t.Fatal("No results are set for the StorageMock.Insert")
+			mmUpsert.t.Fatal("No results are set for the StorageMock.Upsert")
 		}
 		return (*mm_results).err
 	}
-	if mmInsert.funcInsert != nil {
-		return mmInsert.funcInsert(ctx, trackingNumber, apiName, response)
+	if mmUpsert.funcUpsert != nil {
+		return mmUpsert.funcUpsert(ctx, trackingNumber, apiName, response)
 	}
-	mmInsert.t.Fatalf("Unexpected call to StorageMock.Insert. %v %v %v %v", ctx, trackingNumber, apiName, response)
+	mmUpsert.t.Fatalf("Unexpected call to StorageMock.Upsert. %v %v %v %v", ctx, trackingNumber, apiName, response)
 	return
 }
 
-// InsertAfterCounter returns a count of finished StorageMock.Insert invocations
-func (mmInsert *StorageMock) InsertAfterCounter() uint64 {
-	return mm_atomic.LoadUint64(&mmInsert.afterInsertCounter)
+// UpsertAfterCounter returns a count of finished StorageMock.Upsert invocations
+func (mmUpsert *StorageMock) UpsertAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmUpsert.afterUpsertCounter)
 }
 
-// InsertBeforeCounter returns a count of StorageMock.Insert invocations
-func (mmInsert *StorageMock) InsertBeforeCounter() uint64 {
-	return mm_atomic.LoadUint64(&mmInsert.beforeInsertCounter)
+// UpsertBeforeCounter returns a count of StorageMock.Upsert invocations
+func (mmUpsert *StorageMock) UpsertBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmUpsert.beforeUpsertCounter)
 }
 
-// Calls returns a list of arguments used in each call to StorageMock.Insert.
+// Calls returns a list of arguments used in each call to StorageMock.Upsert.
 // The list is in the same order as the calls were made (i.e. recent calls have a higher index)
-func (mmInsert *mStorageMockInsert) Calls() []*StorageMockInsertParams {
-	mmInsert.mutex.RLock()
+func (mmUpsert *mStorageMockUpsert) Calls() []*StorageMockUpsertParams {
+	mmUpsert.mutex.RLock()
 
-	argCopy := make([]*StorageMockInsertParams, len(mmInsert.callArgs))
-	copy(argCopy, mmInsert.callArgs)
+	argCopy := make([]*StorageMockUpsertParams, len(mmUpsert.callArgs))
+	copy(argCopy, mmUpsert.callArgs)
 
-	mmInsert.mutex.RUnlock()
+	mmUpsert.mutex.RUnlock()
 
 	return argCopy
 }
 
-// MinimockInsertDone returns true if the count of the Insert invocations corresponds
+// MinimockUpsertDone returns true if the count of the Upsert invocations corresponds
 // the number of defined expectations
-func (m *StorageMock) MinimockInsertDone() bool {
-	for _, e := range m.InsertMock.expectations {
+func (m *StorageMock) MinimockUpsertDone() bool {
+	for _, e := range m.UpsertMock.expectations {
 		if mm_atomic.LoadUint64(&e.Counter) < 1 {
 			return false
 		}
 	}
 
 	// if default expectation was set then invocations count should be greater than zero
-	if m.InsertMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterInsertCounter) < 1 {
+	if m.UpsertMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterUpsertCounter) < 1 {
 		return false
 	}
 	// if func was set then invocations count should be greater than zero
-	if m.funcInsert != nil && mm_atomic.LoadUint64(&m.afterInsertCounter) < 1 {
+	if m.funcUpsert != nil && mm_atomic.LoadUint64(&m.afterUpsertCounter) < 1 {
 		return false
 	}
 	return true
 }
 
-// MinimockInsertInspect logs each unmet expectation
-func (m *StorageMock) MinimockInsertInspect() {
-	for _, e := range m.InsertMock.expectations {
+// MinimockUpsertInspect logs each unmet expectation
+func (m *StorageMock) MinimockUpsertInspect() {
+	for _, e := range m.UpsertMock.expectations {
 		if mm_atomic.LoadUint64(&e.Counter) < 1 {
-			m.t.Errorf("Expected call to StorageMock.Insert with params: %#v", *e.params)
+			m.t.Errorf("Expected call to StorageMock.Upsert with params: %#v", *e.params)
 		}
 	}
 
 	// if default expectation was set then invocations count should be greater than zero
-	if m.InsertMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterInsertCounter) < 1 {
-		if m.InsertMock.defaultExpectation.params == nil {
-			m.t.Error("Expected call to StorageMock.Insert")
+	if m.UpsertMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterUpsertCounter) < 1 {
+		if m.UpsertMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.Upsert")
 		} else {
-			m.t.Errorf("Expected call to StorageMock.Insert with params: %#v", *m.InsertMock.defaultExpectation.params)
+			m.t.Errorf("Expected call to StorageMock.Upsert with params: %#v", *m.UpsertMock.defaultExpectation.params)
 		}
 	}
 	// if func was set then invocations count should be greater than zero
-	if m.funcInsert != nil && mm_atomic.LoadUint64(&m.afterInsertCounter) < 1 {
-		m.t.Error("Expected call to StorageMock.Insert")
+	if m.funcUpsert != nil && mm_atomic.LoadUint64(&m.afterUpsertCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.Upsert")
 	}
 }
 
-type mStorageMockUpdate struct {
+type mStorageMockUpsertLocalized struct {
 	mock               *StorageMock
-	defaultExpectation *StorageMockUpdateExpectation
-	expectations       []*StorageMockUpdateExpectation
+	defaultExpectation *StorageMockUpsertLocalizedExpectation
+	expectations       []*StorageMockUpsertLocalizedExpectation
 
-	callArgs []*StorageMockUpdateParams
+	callArgs []*StorageMockUpsertLocalizedParams
 	mutex    sync.RWMutex
 }
 
-// StorageMockUpdateExpectation specifies expectation struct of the Storage.Update
-type StorageMockUpdateExpectation struct {
+// StorageMockUpsertLocalizedExpectation specifies expectation struct of the Storage.UpsertLocalized
+type StorageMockUpsertLocalizedExpectation struct {
 	mock    *StorageMock
-	params  *StorageMockUpdateParams
-	results *StorageMockUpdateResults
+	params  *StorageMockUpsertLocalizedParams
+	results *StorageMockUpsertLocalizedResults
 	Counter uint64
 }
 
-// StorageMockUpdateParams contains parameters of the Storage.Update
-type StorageMockUpdateParams struct {
-	ctx context.Context
-	pp1 *mm_service.PostalApiResponse
+// StorageMockUpsertLocalizedParams contains parameters of the Storage.UpsertLocalized
+type StorageMockUpsertLocalizedParams struct {
+	ctx      context.Context
+	response *mm_service.PostalApiResponse
 }
 
-// StorageMockUpdateResults contains results of the Storage.Update
-type StorageMockUpdateResults struct {
+// StorageMockUpsertLocalizedResults contains results of the Storage.UpsertLocalized
+type StorageMockUpsertLocalizedResults struct {
 	err error
 }
 
-// Expect sets up expected params for Storage.Update
-func (mmUpdate *mStorageMockUpdate) Expect(ctx context.Context, pp1 *mm_service.PostalApiResponse) *mStorageMockUpdate {
-	if mmUpdate.mock.funcUpdate != nil {
-		mmUpdate.mock.t.Fatalf("StorageMock.Update mock is already set by Set")
+// Expect sets up expected params for Storage.UpsertLocalized
+func (mmUpsertLocalized *mStorageMockUpsertLocalized) Expect(ctx context.Context, response *mm_service.PostalApiResponse) *mStorageMockUpsertLocalized {
+	if mmUpsertLocalized.mock.funcUpsertLocalized != nil {
+		mmUpsertLocalized.mock.t.Fatalf("StorageMock.UpsertLocalized mock is already set by Set")
 	}
 
-	if mmUpdate.defaultExpectation == nil {
-		mmUpdate.defaultExpectation = &StorageMockUpdateExpectation{}
+	if mmUpsertLocalized.defaultExpectation == nil {
+		mmUpsertLocalized.defaultExpectation = &StorageMockUpsertLocalizedExpectation{}
 	}
 
-	mmUpdate.defaultExpectation.params = &StorageMockUpdateParams{ctx, pp1}
-	for _, e := range mmUpdate.expectations {
-		if minimock.Equal(e.params, mmUpdate.defaultExpectation.params) {
-			mmUpdate.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmUpdate.defaultExpectation.params)
+	mmUpsertLocalized.defaultExpectation.params = &StorageMockUpsertLocalizedParams{ctx, response}
+	for _, e := range mmUpsertLocalized.expectations {
+		if minimock.Equal(e.params, mmUpsertLocalized.defaultExpectation.params) {
+			mmUpsertLocalized.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmUpsertLocalized.defaultExpectation.params)
 		}
 	}
 
-	return mmUpdate
+	return mmUpsertLocalized
 }
 
-// Inspect accepts an inspector function that has same arguments as the Storage.Update
-func (mmUpdate *mStorageMockUpdate) Inspect(f func(ctx context.Context, pp1 *mm_service.PostalApiResponse)) *mStorageMockUpdate {
-	if mmUpdate.mock.inspectFuncUpdate != nil {
-		mmUpdate.mock.t.Fatalf("Inspect function is already set for StorageMock.Update")
+// Inspect accepts an inspector function that has same arguments as the Storage.UpsertLocalized
+func (mmUpsertLocalized *mStorageMockUpsertLocalized) Inspect(f func(ctx context.Context, response *mm_service.PostalApiResponse)) *mStorageMockUpsertLocalized {
+	if mmUpsertLocalized.mock.inspectFuncUpsertLocalized != nil {
+		mmUpsertLocalized.mock.t.Fatalf("Inspect function is already set for StorageMock.UpsertLocalized")
 	}
 
-	mmUpdate.mock.inspectFuncUpdate = f
+	mmUpsertLocalized.mock.inspectFuncUpsertLocalized = f
 
-	return mmUpdate
+	return mmUpsertLocalized
 }
 
-// Return sets up results that will be returned by Storage.Update
-func (mmUpdate *mStorageMockUpdate) Return(err error) *StorageMock {
-	if mmUpdate.mock.funcUpdate != nil {
-		mmUpdate.mock.t.Fatalf("StorageMock.Update mock is already set by Set")
+// Return sets up results that will be returned by Storage.UpsertLocalized
+func (mmUpsertLocalized *mStorageMockUpsertLocalized) Return(err error) *StorageMock {
+	if mmUpsertLocalized.mock.funcUpsertLocalized != nil {
+		mmUpsertLocalized.mock.t.Fatalf("StorageMock.UpsertLocalized mock is already set by Set")
 	}
 
-	if mmUpdate.defaultExpectation == nil {
-		mmUpdate.defaultExpectation = &StorageMockUpdateExpectation{mock: mmUpdate.mock}
+	if mmUpsertLocalized.defaultExpectation == nil {
+		mmUpsertLocalized.defaultExpectation = &StorageMockUpsertLocalizedExpectation{mock: mmUpsertLocalized.mock}
 	}
-	mmUpdate.defaultExpectation.results = &StorageMockUpdateResults{err}
-	return mmUpdate.mock
+	mmUpsertLocalized.defaultExpectation.results = &StorageMockUpsertLocalizedResults{err}
+	return mmUpsertLocalized.mock
 }
 
-// Set uses given function f to mock the Storage.Update method
-func (mmUpdate *mStorageMockUpdate) Set(f func(ctx context.Context, pp1 *mm_service.PostalApiResponse) (err error)) *StorageMock {
-	if mmUpdate.defaultExpectation != nil {
-		mmUpdate.mock.t.Fatalf("Default expectation is already set for the Storage.Update method")
+// Set uses given function f to mock the Storage.UpsertLocalized method
+func (mmUpsertLocalized *mStorageMockUpsertLocalized) Set(f func(ctx context.Context, response *mm_service.PostalApiResponse) (err error)) *StorageMock {
+	if mmUpsertLocalized.defaultExpectation != nil {
+		mmUpsertLocalized.mock.t.Fatalf("Default expectation is already set for the Storage.UpsertLocalized method")
 	}
 
-	if len(mmUpdate.expectations) > 0 {
-		mmUpdate.mock.t.Fatalf("Some expectations are already set for the Storage.Update method")
+	if len(mmUpsertLocalized.expectations) > 0 {
+		mmUpsertLocalized.mock.t.Fatalf("Some expectations are already set for the Storage.UpsertLocalized method")
 	}
 
-	mmUpdate.mock.funcUpdate = f
-	return mmUpdate.mock
+	mmUpsertLocalized.mock.funcUpsertLocalized = f
+	return mmUpsertLocalized.mock
 }
 
-// When sets expectation for the Storage.Update which will trigger the result defined by the following
+// When sets expectation for the Storage.UpsertLocalized which will trigger the result defined by the following
 // Then helper
-func (mmUpdate *mStorageMockUpdate) When(ctx context.Context, pp1 *mm_service.PostalApiResponse) *StorageMockUpdateExpectation {
-	if mmUpdate.mock.funcUpdate != nil {
-		mmUpdate.mock.t.Fatalf("StorageMock.Update mock is already set by Set")
+func (mmUpsertLocalized *mStorageMockUpsertLocalized) When(ctx context.Context, response *mm_service.PostalApiResponse) *StorageMockUpsertLocalizedExpectation {
+	if mmUpsertLocalized.mock.funcUpsertLocalized != nil {
+		mmUpsertLocalized.mock.t.Fatalf("StorageMock.UpsertLocalized mock is already set by Set")
 	}
 
-	expectation := &StorageMockUpdateExpectation{
-		mock:   mmUpdate.mock,
-		params: &StorageMockUpdateParams{ctx, pp1},
+	expectation := &StorageMockUpsertLocalizedExpectation{
+		mock:   mmUpsertLocalized.mock,
+		params: &StorageMockUpsertLocalizedParams{ctx, response},
 	}
-	mmUpdate.expectations = append(mmUpdate.expectations, expectation)
+	mmUpsertLocalized.expectations = append(mmUpsertLocalized.expectations, expectation)
 	return expectation
 }
 
-// Then sets up Storage.Update return parameters for the expectation previously defined by the When method
-func (e *StorageMockUpdateExpectation) Then(err error) *StorageMock {
-	e.results = &StorageMockUpdateResults{err}
+// Then sets up Storage.UpsertLocalized return parameters for the expectation previously defined by the When method
+func (e *StorageMockUpsertLocalizedExpectation) Then(err error) *StorageMock {
+	e.results = &StorageMockUpsertLocalizedResults{err}
 	return e.mock
 }
 
-// Update implements service.Storage
-func (mmUpdate *StorageMock) Update(ctx context.Context, pp1 *mm_service.PostalApiResponse) (err error) {
-	mm_atomic.AddUint64(&mmUpdate.beforeUpdateCounter, 1)
-	defer mm_atomic.AddUint64(&mmUpdate.afterUpdateCounter, 1)
+// UpsertLocalized implements service.Storage
+func (mmUpsertLocalized *StorageMock) UpsertLocalized(ctx context.Context, response *mm_service.PostalApiResponse) (err error) {
+	mm_atomic.AddUint64(&mmUpsertLocalized.beforeUpsertLocalizedCounter, 1)
+	defer mm_atomic.AddUint64(&mmUpsertLocalized.afterUpsertLocalizedCounter, 1)
 
-	if mmUpdate.inspectFuncUpdate != nil {
-		mmUpdate.inspectFuncUpdate(ctx, pp1)
+	if mmUpsertLocalized.inspectFuncUpsertLocalized != nil {
+		mmUpsertLocalized.inspectFuncUpsertLocalized(ctx, response)
 	}
 
-	mm_params := &StorageMockUpdateParams{ctx, pp1}
+	mm_params := &StorageMockUpsertLocalizedParams{ctx, response}
 
 	// Record call args
-	mmUpdate.UpdateMock.mutex.Lock()
-	mmUpdate.UpdateMock.callArgs = append(mmUpdate.UpdateMock.callArgs, mm_params)
-	mmUpdate.UpdateMock.mutex.Unlock()
+	mmUpsertLocalized.UpsertLocalizedMock.mutex.Lock()
+	mmUpsertLocalized.UpsertLocalizedMock.callArgs = append(mmUpsertLocalized.UpsertLocalizedMock.callArgs, mm_params)
+	mmUpsertLocalized.UpsertLocalizedMock.mutex.Unlock()
 
-	for _, e := range mmUpdate.UpdateMock.expectations {
+	for _, e := range mmUpsertLocalized.UpsertLocalizedMock.expectations {
 		if minimock.Equal(e.params, mm_params) {
 			mm_atomic.AddUint64(&e.Counter, 1)
 			return e.results.err
 		}
 	}
 
-	if mmUpdate.UpdateMock.defaultExpectation != nil {
-		mm_atomic.AddUint64(&mmUpdate.UpdateMock.defaultExpectation.Counter, 1)
-		mm_want := mmUpdate.UpdateMock.defaultExpectation.params
-		mm_got := StorageMockUpdateParams{ctx, pp1}
+	if mmUpsertLocalized.UpsertLocalizedMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmUpsertLocalized.UpsertLocalizedMock.defaultExpectation.Counter, 1)
+		mm_want := mmUpsertLocalized.UpsertLocalizedMock.defaultExpectation.params
+		mm_got := StorageMockUpsertLocalizedParams{ctx, response}
 		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
-			mmUpdate.t.Errorf("StorageMock.Update got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+			mmUpsertLocalized.t.Errorf("StorageMock.UpsertLocalized got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
 		}
 
-		mm_results := mmUpdate.UpdateMock.defaultExpectation.results
+		mm_results := mmUpsertLocalized.UpsertLocalizedMock.defaultExpectation.results
 		if mm_results == nil {
-			mmUpdate.t.Fatal("No results are set for the StorageMock.Update")
+			mmUpsertLocalized.t.Fatal("No results are set for the StorageMock.UpsertLocalized")
 		}
 		return (*mm_results).err
 	}
-	if mmUpdate.funcUpdate != nil {
-		return mmUpdate.funcUpdate(ctx, pp1)
+	if mmUpsertLocalized.funcUpsertLocalized != nil {
+		return mmUpsertLocalized.funcUpsertLocalized(ctx, response)
 	}
-	mmUpdate.t.Fatalf("Unexpected call to StorageMock.Update. %v %v", ctx, pp1)
+	mmUpsertLocalized.t.Fatalf("Unexpected call to StorageMock.UpsertLocalized. %v %v", ctx, response)
 	return
 }
 
-// UpdateAfterCounter returns a count of finished StorageMock.Update invocations
-func (mmUpdate *StorageMock) UpdateAfterCounter() uint64 {
-	return mm_atomic.LoadUint64(&mmUpdate.afterUpdateCounter)
+// UpsertLocalizedAfterCounter returns a count of finished StorageMock.UpsertLocalized invocations
+func (mmUpsertLocalized *StorageMock) UpsertLocalizedAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmUpsertLocalized.afterUpsertLocalizedCounter)
 }
 
-// UpdateBeforeCounter returns a count of StorageMock.Update invocations
-func (mmUpdate *StorageMock) UpdateBeforeCounter() uint64 {
-	return mm_atomic.LoadUint64(&mmUpdate.beforeUpdateCounter)
+// UpsertLocalizedBeforeCounter returns a count of StorageMock.UpsertLocalized invocations
+func (mmUpsertLocalized *StorageMock) UpsertLocalizedBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmUpsertLocalized.beforeUpsertLocalizedCounter)
 }
 
-// Calls returns a list of arguments used in each call to StorageMock.Update.
+// Calls returns a list of arguments used in each call to StorageMock.UpsertLocalized.
 // The list is in the same order as the calls were made (i.e. recent calls have a higher index)
-func (mmUpdate *mStorageMockUpdate) Calls() []*StorageMockUpdateParams {
-	mmUpdate.mutex.RLock()
+func (mmUpsertLocalized *mStorageMockUpsertLocalized) Calls() []*StorageMockUpsertLocalizedParams {
+	mmUpsertLocalized.mutex.RLock()
 
-	argCopy := make([]*StorageMockUpdateParams, len(mmUpdate.callArgs))
-	copy(argCopy, mmUpdate.callArgs)
+	argCopy := make([]*StorageMockUpsertLocalizedParams, len(mmUpsertLocalized.callArgs))
+	copy(argCopy, mmUpsertLocalized.callArgs)
 
-	mmUpdate.mutex.RUnlock()
+	mmUpsertLocalized.mutex.RUnlock()
 
 	return argCopy
 }
 
-// MinimockUpdateDone returns true if the count of the Update invocations corresponds
+// MinimockUpsertLocalizedDone returns true if the count of the UpsertLocalized invocations corresponds
 // the number of defined expectations
-func (m *StorageMock) MinimockUpdateDone() bool {
-	for _, e := range m.UpdateMock.expectations {
+func (m *StorageMock) MinimockUpsertLocalizedDone() bool {
+	for _, e := range m.UpsertLocalizedMock.expectations {
 		if mm_atomic.LoadUint64(&e.Counter) < 1 {
 			return false
 		}
 	}
 
 	// if default expectation was set then invocations count should be greater than zero
-	if m.UpdateMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterUpdateCounter) < 1 {
+	if m.UpsertLocalizedMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterUpsertLocalizedCounter) < 1 {
 		return false
 	}
 	// if func was set then invocations count should be greater than zero
-	if m.funcUpdate != nil && mm_atomic.LoadUint64(&m.afterUpdateCounter) < 1 {
+	if m.funcUpsertLocalized != nil && mm_atomic.LoadUint64(&m.afterUpsertLocalizedCounter) < 1 {
 		return false
 	}
 	return true
 }
 
-// MinimockUpdateInspect logs each unmet expectation
-func (m *StorageMock) MinimockUpdateInspect() {
-	for _, e := range m.UpdateMock.expectations {
+// MinimockUpsertLocalizedInspect logs each unmet expectation
+func (m *StorageMock) MinimockUpsertLocalizedInspect() {
+	for _, e := range m.UpsertLocalizedMock.expectations {
 		if mm_atomic.LoadUint64(&e.Counter) < 1 {
-			m.t.Errorf("Expected call to StorageMock.Update with params: %#v", *e.params)
+			m.t.Errorf("Expected call to StorageMock.UpsertLocalized with params: %#v", *e.params)
 		}
 	}
 
 	// if default expectation was set then invocations count should be greater than zero
-	if m.UpdateMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterUpdateCounter) < 1 {
-		if m.UpdateMock.defaultExpectation.params == nil {
-			m.t.Error("Expected call to StorageMock.Update")
+	if m.UpsertLocalizedMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterUpsertLocalizedCounter) < 1 {
+		if m.UpsertLocalizedMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to StorageMock.UpsertLocalized")
 		} else {
-			m.t.Errorf("Expected call to StorageMock.Update with params: %#v", *m.UpdateMock.defaultExpectation.params)
+			m.t.Errorf("Expected call to StorageMock.UpsertLocalized with params: %#v", *m.UpsertLocalizedMock.defaultExpectation.params)
 		}
 	}
 	// if func was set then invocations count should be greater than zero
-	if m.funcUpdate != nil && mm_atomic.LoadUint64(&m.afterUpdateCounter) < 1 {
-		m.t.Error("Expected call to StorageMock.Update")
+	if m.funcUpsertLocalized != nil && mm_atomic.LoadUint64(&m.afterUpsertLocalizedCounter) < 1 {
+		m.t.Error("Expected call to StorageMock.UpsertLocalized")
 	}
 }
 
 // MinimockFinish checks that all mocked methods have been called the expected number of times
 func (m *StorageMock) MinimockFinish() {
 	if !m.minimockDone() {
+		m.MinimockCountDueForRefreshInspect()
+
+		m.MinimockGetDueForRefreshInspect()
+
+		m.MinimockGetFinalResponsesAfterInspect()
+
+		m.MinimockGetHistoryInspect()
+
 		m.MinimockGetLatestInspect()
 
+		m.MinimockGetLatestBatchInspect()
+
+		m.MinimockGetLinksInspect()
+
+		m.MinimockGetLocalizedInspect()
+
+		m.MinimockGetResponsesAfterInspect()
+
 		m.MinimockInsertInspect()
 
+		m.MinimockInsertLinksInspect()
+
 		m.MinimockUpdateInspect()
+
+		m.MinimockUpsertInspect()
+
+		m.MinimockUpsertLocalizedInspect()
 		m.t.FailNow()
 	}
 }
@@ -737,7 +3253,18 @@ func (m *StorageMock) MinimockWait(timeout mm_time.Duration) {
 func (m *StorageMock) minimockDone() bool {
 	done := true
 	return done &&
+		m.MinimockCountDueForRefreshDone() &&
+		m.MinimockGetDueForRefreshDone() &&
+		m.MinimockGetFinalResponsesAfterDone() &&
+		m.MinimockGetHistoryDone() &&
 		m.MinimockGetLatestDone() &&
+		m.MinimockGetLatestBatchDone() &&
+		m.MinimockGetLinksDone() &&
+		m.MinimockGetLocalizedDone() &&
+		m.MinimockGetResponsesAfterDone() &&
 		m.MinimockInsertDone() &&
-		m.MinimockUpdateDone()
+		m.MinimockInsertLinksDone() &&
+		m.MinimockUpdateDone() &&
+		m.MinimockUpsertDone() &&
+		m.MinimockUpsertLocalizedDone()
 }
@@ -0,0 +1,119 @@
+package service
+
+// Phase is a coarse stage of parcel's journey, for when detailed TrackingStatus is too much
+type Phase string
+
+const (
+	// PhaseUnknown means none of the events tell where parcel is
+	PhaseUnknown              Phase = ""
+	PhaseInfoReceived         Phase = "info_received"
+	PhaseInTransitAtOrigin    Phase = "in_transit_at_origin"
+	PhaseExportCustoms        Phase = "export_customs"
+	PhaseInternationalTransit Phase = "international_transit"
+	PhaseImportCustoms        Phase = "import_customs"
+	PhaseOutForDelivery       Phase = "out_for_delivery"
+	PhaseReadyForPickup       Phase = "ready_for_pickup"
+	PhaseDelivered            Phase = "delivered"
+	// PhaseException means parcel won't be delivered: it's returned, lost, cancelled and so on
+	PhaseException Phase = "exception"
+)
+
+// phaseProgress is how far, in percents, parcel has got once it's in the phase.
+// Exception is not there: it doesn't tell how far parcel has got before things went wrong
+var phaseProgress = map[Phase]int{
+	PhaseInfoReceived:         5,
+	PhaseInTransitAtOrigin:    20,
+	PhaseExportCustoms:        35,
+	PhaseInternationalTransit: 50,
+	PhaseImportCustoms:        70,
+	PhaseOutForDelivery:       85,
+	PhaseReadyForPickup:       90,
+	PhaseDelivered:            100,
+}
+
+// Phase tells which phase of the journey event of this status belongs to
+func (s TrackingStatus) Phase() Phase {
+	switch s {
+	case TrackingStatusShipmentInfoReceived,
+		TrackingStatusPackagingComplete,
+		TrackingStatusWMSConfirmed:
+		return PhaseInfoReceived
+	case TrackingStatusDispatchedFromWarehouse,
+		TrackingStatusAcceptedByCarrier,
+		TrackingStatusArrivedAtSortingCenter,
+		TrackingStatusDepartedFromSortingCenter,
+		TrackingStatusArrivedAtDepartureHub,
+		TrackingStatusTransitPortRerouteCb:
+		return PhaseInTransitAtOrigin
+	case TrackingStatusExportCustomsClearanceStarted,
+		TrackingStatusExportCustomsClearanceSuccess:
+		return PhaseExportCustoms
+	case TrackingStatusLeavignDepartureRegion,
+		TrackingStatusDepartedOriginRegion,
+		TrackingStatusArrivedAtLinehaulOffice:
+		return PhaseInternationalTransit
+	case TrackingStatusArrivedAtCustoms,
+		TrackingStatusImportCustomsClearanceStarted,
+		TrackingStatusImportCustomsClearanceSuccess,
+		TrackingStatusDepartedFromCustoms:
+		return PhaseImportCustoms
+	case TrackingStatusOutForDelivery:
+		return PhaseOutForDelivery
+	case TrackingStatusReadyForPickup:
+		return PhaseReadyForPickup
+	case TrackingStatusDelivered,
+		TrackingStatusPickedUpFromLocker:
+		return PhaseDelivered
+	case TrackingStatusReturnedToSender,
+		TrackingStatusLost,
+		TrackingStatusDestroyed,
+		TrackingStatusSeizedByCustoms,
+		TrackingStatusCancelled:
+		return PhaseException
+	default:
+		return PhaseUnknown
+	}
+}
+
+// CurrentPhase is the phase of the most recent event that tells one.
+// Terminal state trumps whatever events came after it, e.g. "in transit" back to sender after return
+func (ti *TrackingInfo) CurrentPhase() Phase {
+	switch ti.TerminalState() {
+	case TerminalStateNone:
+	case TerminalStateDelivered, TerminalStatePickedUp:
+		return PhaseDelivered
+	default:
+		return PhaseException
+	}
+
+	phase := PhaseUnknown
+	var latest *TrackingEvent
+	for i := range ti.Events {
+		e := &ti.Events[i]
+		eventPhase := e.Status.Phase()
+		if eventPhase == PhaseUnknown {
+			continue
+		}
+		if latest == nil || !e.Time.Before(latest.Time) {
+			latest = e
+			phase = eventPhase
+		}
+	}
+	return phase
+}
+
+// Progress is how far, in percents, parcel has got on its way: 100 once it's delivered.
+// It's the furthest phase any of the events has reached, so it never goes back
+// because of an event that carrier reported late
+func (ti *TrackingInfo) Progress() int {
+	if ti.CurrentPhase() == PhaseDelivered {
+		return phaseProgress[PhaseDelivered]
+	}
+	progress := 0
+	for _, e := range ti.Events {
+		if p := phaseProgress[e.Status.Phase()]; p > progress && p < phaseProgress[PhaseDelivered] {
+			progress = p
+		}
+	}
+	return progress
+}
@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+)
+
+func TestPhase(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2023, 1, 1, hour, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("every known status has a phase", func(t *testing.T) {
+		statuses := []service.TrackingStatus{
+			service.TrackingStatusShipmentInfoReceived,
+			service.TrackingStatusPackagingComplete,
+			service.TrackingStatusDispatchedFromWarehouse,
+			service.TrackingStatusWMSConfirmed,
+			service.TrackingStatusArrivedAtSortingCenter,
+			service.TrackingStatusAcceptedByCarrier,
+			service.TrackingStatusDepartedFromSortingCenter,
+			service.TrackingStatusArrivedAtDepartureHub,
+			service.TrackingStatusTransitPortRerouteCb,
+			service.TrackingStatusExportCustomsClearanceStarted,
+			service.TrackingStatusLeavignDepartureRegion,
+			service.TrackingStatusImportCustomsClearanceStarted,
+			service.TrackingStatusImportCustomsClearanceSuccess,
+			service.TrackingStatusDepartedOriginRegion,
+			service.TrackingStatusArrivedAtLinehaulOffice,
+			service.TrackingStatusArrivedAtCustoms,
+			service.TrackingStatusDepartedFromCustoms,
+			service.TrackingStatusExportCustomsClearanceSuccess,
+			service.TrackingStatusOutForDelivery,
+			service.TrackingStatusReadyForPickup,
+			service.TrackingStatusDelivered,
+			service.TrackingStatusPickedUpFromLocker,
+			service.TrackingStatusReturnedToSender,
+			service.TrackingStatusLost,
+			service.TrackingStatusDestroyed,
+			service.TrackingStatusSeizedByCustoms,
+			service.TrackingStatusCancelled,
+		}
+		for _, status := range statuses {
+			if status.Phase() == service.PhaseUnknown {
+				t.Fatalf("expected %s to have a phase", status)
+			}
+		}
+		if service.TrackingStatusUnknown.Phase() != service.PhaseUnknown {
+			t.Fatalf("expected unknown status to have unknown phase")
+		}
+	})
+
+	t.Run("current phase is of the latest event", func(t *testing.T) {
+		info := &service.TrackingInfo{Events: []service.TrackingEvent{
+			{Time: at(5), Status: service.TrackingStatusImportCustomsClearanceStarted},
+			{Time: at(1), Status: service.TrackingStatusAcceptedByCarrier},
+			{Time: at(6), Status: service.TrackingStatusUnknown},
+		}}
+		if phase := info.CurrentPhase(); phase != service.PhaseImportCustoms {
+			t.Fatalf("expected import customs phase, got %q", phase)
+		}
+		if progress := info.Progress(); progress != 70 {
+			t.Fatalf("expected progress of import customs, got %d", progress)
+		}
+	})
+
+	t.Run("progress does not go back because of late events", func(t *testing.T) {
+		info := &service.TrackingInfo{Events: []service.TrackingEvent{
+			{Time: at(1), Status: service.TrackingStatusOutForDelivery},
+			{Time: at(2), Status: service.TrackingStatusArrivedAtSortingCenter},
+		}}
+		if phase := info.CurrentPhase(); phase != service.PhaseInTransitAtOrigin {
+			t.Fatalf("expected the latest event's phase, got %q", phase)
+		}
+		if progress := info.Progress(); progress != 85 {
+			t.Fatalf("expected progress of out for delivery, got %d", progress)
+		}
+	})
+
+	t.Run("terminal state trumps later events", func(t *testing.T) {
+		delivered := &service.TrackingInfo{Events: []service.TrackingEvent{
+			{Time: at(1), Status: service.TrackingStatusPickedUpFromLocker},
+			{Time: at(2), Status: service.TrackingStatusArrivedAtSortingCenter},
+		}}
+		if phase := delivered.CurrentPhase(); phase != service.PhaseDelivered || delivered.Progress() != 100 {
+			t.Fatalf("expected parcel to be delivered, got %q, %d", phase, delivered.Progress())
+		}
+
+		returned := &service.TrackingInfo{Events: []service.TrackingEvent{
+			{Time: at(1), Status: service.TrackingStatusExportCustomsClearanceStarted},
+			{Time: at(2), Status: service.TrackingStatusReturnedToSender},
+			{Time: at(3), Status: service.TrackingStatusArrivedAtSortingCenter},
+		}}
+		if phase := returned.CurrentPhase(); phase != service.PhaseException || returned.Progress() != 35 {
+			t.Fatalf("expected exception with progress reached before it, got %q, %d", phase, returned.Progress())
+		}
+	})
+
+	t.Run("no events", func(t *testing.T) {
+		info := &service.TrackingInfo{}
+		if info.CurrentPhase() != service.PhaseUnknown || info.Progress() != 0 {
+			t.Fatalf("expected unknown phase and no progress, got %q, %d", info.CurrentPhase(), info.Progress())
+		}
+	})
+}
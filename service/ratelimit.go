@@ -0,0 +1,88 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// cooldowns tracks APIs that told us to back off, and until when
+type cooldowns struct {
+	mu    sync.Mutex
+	until map[APIName]time.Time
+}
+
+// extend makes API cool down until given moment, unless it is already cooling down for longer.
+// Returns true if cooldown was extended.
+func (c *cooldowns) extend(apiName APIName, until time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.until == nil {
+		c.until = make(map[APIName]time.Time)
+	}
+	if !until.After(c.until[apiName]) {
+		return false
+	}
+	c.until[apiName] = until
+	return true
+}
+
+// isActive reports whether API is still cooling down, so it should not be fetched
+func (c *cooldowns) isActive(apiName APIName, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return now.Before(c.until[apiName])
+}
+
+// handleRateLimited starts API cooldown if response indicates we were rate limited.
+// Returns true if that's the case: such response tells nothing about the parcel,
+// so it should be neither stored nor parsed, and we should keep serving stored response instead.
+func (svc *Impl) handleRateLimited(apiName APIName, resp PostalApiResponse) bool {
+	if resp.Status != StatusRateLimitExceeded {
+		return false
+	}
+	cooldown := resp.RetryAfter
+	if cooldown <= 0 {
+		cooldown = svc.rateLimitCooldown
+	}
+	until := svc.now().Add(cooldown)
+	if svc.cooldowns.extend(apiName, until) {
+		svc.log.Warn(
+			"API rate limit exceeded, cooling down",
+			zap.String("apiName", string(apiName)),
+			zap.Duration("cooldown", cooldown),
+		)
+		svc.metrics.RateLimitedUntil(apiName, until)
+	}
+	return true
+}
+
+// isUnanswered tells whether API didn't tell anything about the parcel, since it rate limited us
+// or wasn't even asked, being considered down. Such response should be neither stored nor parsed,
+// and we should keep serving stored response instead.
+func (svc *Impl) isUnanswered(apiName APIName, resp PostalApiResponse) bool {
+	return svc.handleRateLimited(apiName, resp) || resp.Status == StatusCircuitOpen
+}
+
+// ParseRetryAfter parses Retry-After header value, which is either a number of seconds or an HTTP date.
+// Returns 0 if header is missing or malformed, so that caller falls back to its default cooldown.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,153 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/service/mocks"
+	"go.uber.org/zap"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"120", 2 * time.Minute},
+		{" 5 ", 5 * time.Second},
+		{"Sun, 01 Jan 2023 12:10:00 GMT", 10 * time.Minute},
+		{"Sun, 01 Jan 2023 11:00:00 GMT", 0},
+		{"-1", 0},
+		{"soon", 0},
+		{"", 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			if actual := service.ParseRetryAfter(tc.value, now); actual != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestServiceRateLimit(t *testing.T) {
+	storage := mocks.NewStorageMock(t)
+	api1 := mocks.NewPostalAPIMock(t)
+
+	now := time.Now()
+	svc := service.NewService(
+		map[service.APIName]service.PostalAPI{api1Name: api1},
+		storage,
+		promMetrics,
+		time.Hour,
+		time.Hour,
+		time.Hour,
+		time.Second,
+		30*24*time.Hour,
+		time.Minute,
+		zap.NewNop(),
+		func() time.Time { return now },
+	)
+
+	// stored response is stale, so we'd like to refetch it every time
+	storedResponse := &service.PostalApiResponse{
+		TrackingNumber: "123",
+		APIName:        api1Name,
+		Status:         service.StatusSuccess,
+		ResponseBody:   []byte("foo"),
+		LastFetchedAt:  now.Add(-2 * time.Hour),
+	}
+	storage.GetLatestMock.Return([]*service.PostalApiResponse{storedResponse}, nil)
+	storage.GetLinksMock.Return(nil, nil)
+	api1.ParseMock.Return(&service.TrackingInfo{TrackingNumber: "123", APIName: api1Name}, nil)
+	api1.FetchMock.Return(service.PostalApiResponse{
+		TrackingNumber: "123",
+		APIName:        api1Name,
+		Status:         service.StatusRateLimitExceeded,
+		RetryAfter:     10 * time.Minute,
+	})
+	// storage.Upsert is not expected: rate limited response should not replace the stored one
+
+	track := func() {
+		t.Helper()
+		tracking, err := svc.GetTrackingInfo(context.Background(), "123", service.DefaultLanguage)
+		if err != nil {
+			t.Fatalf("failed to get tracking info: %v", err)
+		}
+		if len(tracking) != 1 {
+			t.Fatalf("expected stored tracking info to be served, got %d tracking infos", len(tracking))
+		}
+	}
+
+	track()
+	if api1.FetchAfterCounter() != 1 {
+		t.Fatalf("expected API to be hit once, got %d", api1.FetchAfterCounter())
+	}
+
+	now = now.Add(9 * time.Minute)
+	track()
+	if api1.FetchAfterCounter() != 1 {
+		t.Fatalf("expected API not to be hit during cooldown, got %d hits", api1.FetchAfterCounter())
+	}
+
+	// rate limited responses are never stored, so it's up to the stored response whether to ask again after cooldown
+	now = now.Add(2 * time.Minute)
+	track()
+	if api1.FetchAfterCounter() != 2 {
+		t.Fatalf("expected API to be hit after cooldown, since stored response is stale, got %d hits", api1.FetchAfterCounter())
+	}
+
+	now = now.Add(11 * time.Minute)
+	storedResponse.LastFetchedAt = now.Add(-time.Minute)
+	track()
+	if api1.FetchAfterCounter() != 2 {
+		t.Fatalf("expected API not to be hit after cooldown, since stored response is fresh, got %d hits", api1.FetchAfterCounter())
+	}
+}
+
+func TestServiceCircuitOpen(t *testing.T) {
+	storage := mocks.NewStorageMock(t)
+	api1 := mocks.NewPostalAPIMock(t)
+
+	now := time.Now()
+	svc := service.NewService(
+		map[service.APIName]service.PostalAPI{api1Name: api1},
+		storage,
+		promMetrics,
+		time.Hour,
+		time.Hour,
+		time.Hour,
+		time.Second,
+		30*24*time.Hour,
+		time.Minute,
+		zap.NewNop(),
+		func() time.Time { return now },
+	)
+
+	storage.GetLatestMock.Return([]*service.PostalApiResponse{{
+		TrackingNumber: "123",
+		APIName:        api1Name,
+		Status:         service.StatusSuccess,
+		ResponseBody:   []byte("foo"),
+		LastFetchedAt:  now.Add(-2 * time.Hour),
+	}}, nil)
+	storage.GetLinksMock.Return(nil, nil)
+	api1.ParseMock.Return(&service.TrackingInfo{TrackingNumber: "123", APIName: api1Name}, nil)
+	api1.FetchMock.Return(service.PostalApiResponse{
+		TrackingNumber: "123",
+		APIName:        api1Name,
+		Status:         service.StatusCircuitOpen,
+	})
+	// storage.Upsert is not expected: API was not asked, so stored response is still the best we know
+
+	tracking, err := svc.GetTrackingInfo(context.Background(), "123", service.DefaultLanguage)
+	if err != nil {
+		t.Fatalf("failed to get tracking info: %v", err)
+	}
+	if len(tracking) != 1 {
+		t.Fatalf("expected stored tracking info to be served, got %d tracking infos", len(tracking))
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hori-ryota/zaperr"
+	"go.uber.org/zap"
+)
+
+// ReparseReport tells what has changed after stored responses of a single API were parsed again
+type ReparseReport struct {
+	APIName APIName
+	// Responses is how many stored responses were looked at
+	Responses int
+	// StatusChanges is how many responses changed their status, e.g. from unknown_error to success
+	StatusChanges map[StatusChange]int
+	// FinalChanges is how many responses changed their mind on whether parcel has reached terminal state
+	FinalChanges int
+	// UnknownCodes is how many responses have events with raw codes the parser could not map to a known status
+	UnknownCodes map[string]int
+}
+
+type StatusChange struct {
+	From ApiResponseStatus
+	To   ApiResponseStatus
+}
+
+// Reparse parses every stored response again, which is useful after parser was fixed or improved:
+// responses that failed to parse before become successful, and conclusions derived from parsing
+// (e.g. whether parcel is delivered) are updated. Responses are processed batchSize at a time.
+// In case of dryRun nothing is updated, and reports tell what would have changed.
+func (svc *Impl) Reparse(ctx context.Context, batchSize int, dryRun bool) ([]*ReparseReport, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size should be positive, got %d", batchSize)
+	}
+	var reports []*ReparseReport
+	for _, apiName := range svc.sortedAPINames() {
+		report := &ReparseReport{
+			APIName:       apiName,
+			StatusChanges: map[StatusChange]int{},
+			UnknownCodes:  map[string]int{},
+		}
+		reports = append(reports, report)
+
+		err := svc.walkResponses(ctx, apiName, batchSize, func(resp *PostalApiResponse) error {
+			return svc.reparseResponse(ctx, resp, report, dryRun)
+		})
+		if err != nil {
+			return reports, err
+		}
+
+		svc.log.Info(
+			"reparsed stored responses",
+			zap.String("apiName", string(apiName)),
+			zap.Int("responses", report.Responses),
+			zap.Any("statusChanges", report.StatusChanges),
+			zap.Int("finalChanges", report.FinalChanges),
+			zap.Bool("dryRun", dryRun),
+		)
+	}
+
+	return reports, nil
+}
+
+func (svc *Impl) reparseResponse(ctx context.Context, resp *PostalApiResponse, report *ReparseReport, dryRun bool) error {
+	report.Responses++
+
+	// only these statuses depend on parsing, the rest are what API told us
+	if resp.Status != StatusSuccess && resp.Status != StatusUnknownError {
+		return nil
+	}
+
+	updated := *resp
+	parsed, err := svc.apiMap[resp.APIName].Parse(*resp)
+	if err == nil && parsed != nil {
+		updated.Status = StatusSuccess
+		updated.IsFinal = parsed.IsTerminal()
+		for _, code := range unknownCodes(parsed) {
+			report.UnknownCodes[code]++
+		}
+	} else if resp.Status == StatusSuccess {
+		updated.Status = StatusUnknownError
+	}
+
+	if updated.Status == resp.Status && updated.IsFinal == resp.IsFinal {
+		return nil
+	}
+	if updated.Status != resp.Status {
+		report.StatusChanges[StatusChange{From: resp.Status, To: updated.Status}]++
+	}
+	if updated.IsFinal != resp.IsFinal {
+		report.FinalChanges++
+	}
+	if dryRun {
+		return nil
+	}
+	if err := svc.storage.Update(ctx, &updated); err != nil {
+		return zaperr.Wrap(err, "failed to update reparsed response", zap.Int64("id", resp.ID))
+	}
+	return nil
+}
+
+// walkResponses calls fn for every stored response of API, loading them batchSize at a time
+func (svc *Impl) walkResponses(
+	ctx context.Context,
+	apiName APIName,
+	batchSize int,
+	fn func(resp *PostalApiResponse) error,
+) error {
+	return svc.walkPages(ctx, apiName, batchSize, svc.storage.GetResponsesAfter, fn)
+}
+
+// walkFinalResponses calls fn for the latest successful response of every parcel that has reached terminal state
+// according to API, loading them batchSize at a time
+func (svc *Impl) walkFinalResponses(
+	ctx context.Context,
+	apiName APIName,
+	batchSize int,
+	fn func(resp *PostalApiResponse) error,
+) error {
+	return svc.walkPages(ctx, apiName, batchSize, svc.storage.GetFinalResponsesAfter, fn)
+}
+
+// walkPages calls fn for every response getPage returns, page after page, until it runs out of them
+func (svc *Impl) walkPages(
+	ctx context.Context,
+	apiName APIName,
+	batchSize int,
+	getPage func(ctx context.Context, apiName APIName, afterID int64, limit int) ([]*PostalApiResponse, error),
+	fn func(resp *PostalApiResponse) error,
+) error {
+	var afterID int64
+	for {
+		responses, err := getPage(ctx, apiName, afterID, batchSize)
+		if err != nil {
+			return zaperr.Wrap(
+				err, "failed to get stored responses",
+				zap.String("apiName", string(apiName)),
+				zap.Int64("afterID", afterID),
+			)
+		}
+		for _, resp := range responses {
+			if err := fn(resp); err != nil {
+				return err
+			}
+			afterID = resp.ID
+		}
+		if len(responses) == 0 || len(responses) < batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/service/mocks"
+	"go.uber.org/zap"
+)
+
+func TestServiceReparse(t *testing.T) {
+	prepareTestSubjects := func() (*service.Impl, *mocks.StorageMock, *mocks.PostalAPIMock) {
+		storage := mocks.NewStorageMock(t)
+		api1 := mocks.NewPostalAPIMock(t)
+		svc := service.NewService(
+			map[service.APIName]service.PostalAPI{api1Name: api1},
+			storage,
+			promMetrics,
+			time.Hour,
+			time.Hour,
+			time.Hour,
+			time.Second,
+			30*24*time.Hour,
+			time.Minute,
+			zap.NewNop(),
+			time.Now,
+		)
+
+		delivered := service.TrackingEvent{Description: "delivered", Status: service.TrackingStatusDelivered}
+		unknown := service.TrackingEvent{Description: "something new", Status: service.TrackingStatusUnknown, RawCode: "NEW_CODE"}
+		api1.ParseMock.Set(func(resp service.PostalApiResponse) (*service.TrackingInfo, error) {
+			switch string(resp.ResponseBody) {
+			case "parseable":
+				return &service.TrackingInfo{Events: []service.TrackingEvent{unknown}}, nil
+			case "delivered":
+				return &service.TrackingInfo{Events: []service.TrackingEvent{unknown, delivered}}, nil
+			default:
+				return nil, errors.New("unexpected response")
+			}
+		})
+
+		// 2 pages of 2 responses, and an empty one
+		responses := []*service.PostalApiResponse{
+			{ID: 1, APIName: api1Name, Status: service.StatusUnknownError, ResponseBody: []byte("parseable")},
+			{ID: 2, APIName: api1Name, Status: service.StatusSuccess, ResponseBody: []byte("delivered")},
+			{ID: 3, APIName: api1Name, Status: service.StatusSuccess, ResponseBody: []byte("garbage")},
+			{ID: 4, APIName: api1Name, Status: service.StatusNotFound, ResponseBody: []byte("garbage")},
+		}
+		storage.GetResponsesAfterMock.Set(func(_ context.Context, apiName service.APIName, afterID int64, limit int) ([]*service.PostalApiResponse, error) {
+			if apiName != api1Name || limit != 2 {
+				t.Fatalf("unexpected page request: %s, %d", apiName, limit)
+			}
+			var page []*service.PostalApiResponse
+			for _, resp := range responses {
+				if resp.ID > afterID && len(page) < limit {
+					page = append(page, resp)
+				}
+			}
+			return page, nil
+		})
+
+		return svc, storage, api1
+	}
+
+	t.Run("updates responses that are now understood differently", func(t *testing.T) {
+		svc, storage, _ := prepareTestSubjects()
+
+		updated := map[int64]service.PostalApiResponse{}
+		storage.UpdateMock.Set(func(_ context.Context, resp *service.PostalApiResponse) error {
+			updated[resp.ID] = *resp
+			return nil
+		})
+
+		reports, err := svc.Reparse(context.Background(), 2, false)
+		if err != nil {
+			t.Fatalf("failed to reparse: %v", err)
+		}
+
+		if len(updated) != 3 {
+			t.Fatalf("expected 3 responses to be updated, got %v", updated)
+		}
+		if resp := updated[1]; resp.Status != service.StatusSuccess || resp.IsFinal {
+			t.Fatalf("expected parseable response to become successful, got %+v", resp)
+		}
+		if resp := updated[2]; resp.Status != service.StatusSuccess || !resp.IsFinal {
+			t.Fatalf("expected delivered response to become final, got %+v", resp)
+		}
+		if resp := updated[3]; resp.Status != service.StatusUnknownError {
+			t.Fatalf("expected unparseable response to become unknown error, got %+v", resp)
+		}
+
+		if len(reports) != 1 {
+			t.Fatalf("expected a report per API, got %d", len(reports))
+		}
+		report := reports[0]
+		if report.APIName != api1Name || report.Responses != 4 || report.FinalChanges != 1 {
+			t.Fatalf("unexpected report %+v", report)
+		}
+		expectedChanges := map[service.StatusChange]int{
+			{From: service.StatusUnknownError, To: service.StatusSuccess}: 1,
+			{From: service.StatusSuccess, To: service.StatusUnknownError}: 1,
+		}
+		if len(report.StatusChanges) != len(expectedChanges) {
+			t.Fatalf("expected status changes %v, got %v", expectedChanges, report.StatusChanges)
+		}
+		for change, count := range expectedChanges {
+			if report.StatusChanges[change] != count {
+				t.Fatalf("expected status changes %v, got %v", expectedChanges, report.StatusChanges)
+			}
+		}
+		if len(report.UnknownCodes) != 1 || report.UnknownCodes["NEW_CODE"] != 2 {
+			t.Fatalf("expected unknown codes to be counted, got %v", report.UnknownCodes)
+		}
+	})
+
+	t.Run("dry run updates nothing", func(t *testing.T) {
+		svc, _, _ := prepareTestSubjects()
+
+		// UpdateMock is not set, so any update would fail the test
+		reports, err := svc.Reparse(context.Background(), 2, true)
+		if err != nil {
+			t.Fatalf("failed to reparse: %v", err)
+		}
+		if len(reports) != 1 || len(reports[0].StatusChanges) != 2 || reports[0].FinalChanges != 1 {
+			t.Fatalf("expected dry run to report changes anyway, got %+v", reports)
+		}
+	})
+
+	t.Run("batch size should be positive", func(t *testing.T) {
+		svc, storage, _ := prepareTestSubjects()
+
+		for _, batchSize := range []int{0, -1} {
+			if _, err := svc.Reparse(context.Background(), batchSize, true); err == nil {
+				t.Fatalf("expected batch size %d to be rejected", batchSize)
+			}
+		}
+		if storage.GetResponsesAfterAfterCounter() != 0 {
+			t.Fatalf("expected storage not to be asked")
+		}
+	})
+}
@@ -1,9 +1,9 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -15,7 +15,11 @@ import (
 type APIName string
 
 type Service interface {
-	GetTrackingInfo(ctx context.Context, trackingNumber string) ([]*TrackingInfo, error)
+	GetTrackingInfo(ctx context.Context, trackingNumber string, language string) ([]*TrackingInfo, error)
+	GetTrackingInfoBatch(ctx context.Context, trackingNumbers []string, language string) ([]*BatchResult, error)
+	DetectCarriers(trackingNumber string) *CarrierDetection
+	GetHistory(ctx context.Context, trackingNumber string) ([]*APIHistory, error)
+	GetUnknownCodes(ctx context.Context) (*UnknownCodesReport, error)
 }
 
 func NewService(
@@ -27,6 +31,7 @@ func NewService(
 	unknownErrorCheckInterval time.Duration,
 	apiFetchTimeout time.Duration,
 	expiryTimeout time.Duration,
+	rateLimitCooldown time.Duration,
 	logger *zap.Logger,
 	now func() time.Time,
 ) *Impl {
@@ -40,6 +45,7 @@ func NewService(
 		unknownErrorCheckInterval: unknownErrorCheckInterval,
 		apiFetchTimeout:           apiFetchTimeout,
 		expiryTimeout:             expiryTimeout,
+		rateLimitCooldown:         rateLimitCooldown,
 		log:                       logger,
 		now:                       now,
 	}
@@ -56,26 +62,86 @@ type Impl struct {
 	notFoundCheckInterval     time.Duration
 	unknownErrorCheckInterval time.Duration
 	expiryTimeout             time.Duration
+	rateLimitCooldown         time.Duration
 	log                       *zap.Logger
 	now                       func() time.Time
 	apiFetchTimeout           time.Duration
+	changeListeners           []ChangeListener
+	fetches                   fetchCoalescer
+	cooldowns                 cooldowns
+	transitTimes              transitTimes
+	unknownCodes              unknownCodesCache
+}
+
+// AddChangeListener registers a listener to be notified of parcel changes.
+// It is not safe to call concurrently with tracking.
+func (svc *Impl) AddChangeListener(listener ChangeListener) {
+	svc.changeListeners = append(svc.changeListeners, listener)
+}
+
+func (svc *Impl) notifyChanged(ctx context.Context, trackingInfo *TrackingInfo) {
+	for _, listener := range svc.changeListeners {
+		listener.TrackingInfoChanged(ctx, trackingInfo)
+	}
 }
 
 // Storage contains whole history of PostalAPI responses.
 // It is used to avoid unnecessary calls to the API.
-// We store whole history of responses for further analysis.
+// We store history of responses for further analysis, for as long as retention policy allows (see retention package).
 // However, this storage is only concerned with the last response.
 type Storage interface {
 	GetLatest(ctx context.Context, trackingNumber string, apiNames []APIName) ([]*PostalApiResponse, error)
+	// GetLatestBatch is the same as GetLatest, but for many tracking numbers at once
+	GetLatestBatch(ctx context.Context, trackingNumbers []string, apiNames []APIName) ([]*PostalApiResponse, error)
 	// Insert signature requires trackingNumber and apiName just to add gravity to api contract
 	// PostalApiResponse could have no
 	Insert(ctx context.Context, trackingNumber string, apiName APIName, response *PostalApiResponse) error
+	// Upsert stores freshly fetched response as the latest one for its tracking number and API.
+	// If the latest stored response has the very same body (e.g. concurrent lookup has just stored it),
+	// that response is updated instead, so there is never more than one latest response.
+	// response.ID and response.FirstFetchedAt are set to those of the stored response.
+	Upsert(ctx context.Context, trackingNumber string, apiName APIName, response *PostalApiResponse) error
 	Update(context.Context, *PostalApiResponse) error
+	// GetDueForRefresh returns up to query.Limit latest responses of non-final parcels that were fetched long enough ago,
+	// most overdue first. Only TrackingNumber, APIName, Status and LastFetchedAt are loaded
+	GetDueForRefresh(ctx context.Context, query RefreshQuery) ([]*PostalApiResponse, error)
+	// CountDueForRefresh returns how many parcels GetDueForRefresh would return responses of, were there no limit
+	CountDueForRefresh(ctx context.Context, query RefreshQuery) (int, error)
+	// GetHistory returns every stored response for tracking number and API, in order they were first fetched
+	GetHistory(ctx context.Context, trackingNumber string, apiName APIName) ([]*PostalApiResponse, error)
+	// GetResponsesAfter returns up to limit stored responses of API with ID greater than afterID, in order of ID,
+	// so that all the responses can be walked through page by page
+	GetResponsesAfter(ctx context.Context, apiName APIName, afterID int64, limit int) ([]*PostalApiResponse, error)
+	// GetFinalResponsesAfter is GetResponsesAfter that only returns the latest successful responses of parcels
+	// that have reached terminal state
+	GetFinalResponsesAfter(ctx context.Context, apiName APIName, afterID int64, limit int) ([]*PostalApiResponse, error)
+	// GetLinks returns tracking numbers previously found to be linked to the given one
+	GetLinks(ctx context.Context, trackingNumber string) ([]string, error)
+	// InsertLinks persists links between tracking numbers, ignoring already known ones
+	InsertLinks(ctx context.Context, trackingNumber string, linkedTrackingNumbers []string) error
+	// GetLocalized returns the latest localized response for tracking number and API in the language,
+	// or nil if there is none. Only the latest localized response is kept
+	GetLocalized(ctx context.Context, trackingNumber string, apiName APIName, language string) (*PostalApiResponse, error)
+	// UpsertLocalized stores localized response, replacing the previous one in the same language
+	UpsertLocalized(ctx context.Context, response *PostalApiResponse) error
+}
+
+// RefreshQuery describes which of the latest responses are due for a refresh:
+// depending on the response status, it should have been fetched before a certain moment,
+// but not before NotExpiredSince, since parcels we haven't heard of for so long are not tracked anymore
+type RefreshQuery struct {
+	APINames                  []APIName
+	NotExpiredSince           time.Time
+	SuccessFetchedBefore      time.Time
+	NotFoundFetchedBefore     time.Time
+	UnknownErrorFetchedBefore time.Time
+	Limit                     int
 }
 
 // Metrics describes what custom metrics service should report on
 type Metrics interface {
-	ParcelDelivered()
+	// ParcelFinal reports that parcel has reached terminal state, so no requests will hit APIs
+	ParcelFinal(state TerminalState)
 
 	FetchedChanged(APIName)
 	FetchedFirst(APIName)
@@ -87,6 +153,23 @@ type Metrics interface {
 	CacheBustAfterSuccess(apiName APIName, willRefetch bool)
 	CacheBustAfterUnknownError(apiName APIName, willRefetch bool)
 	CacheBustAfterNotFoundError(apiName APIName, willRefetch bool)
+
+	// RateLimitedUntil reports that API told us to back off, so it won't be fetched until given moment
+	RateLimitedUntil(apiName APIName, until time.Time)
+	// CircuitStateChanged reports that circuit breaker around API changed its state, e.g. from "closed" to "open"
+	CircuitStateChanged(apiName APIName, from string, to string)
+	// UnknownEventCode reports that freshly fetched response has events with a raw code we can't map to a status.
+	// Codes themselves are carriers' to choose, so GetUnknownCodes tells which ones
+	UnknownEventCode(apiName APIName)
+	// RelevanceChangedByCountries reports that countries parcel goes between made us ask API we wouldn't ask otherwise
+	// (relevant is true), or not ask API we would (relevant is false)
+	RelevanceChangedByCountries(apiName APIName, relevant bool)
+}
+
+// ChangeListener is notified whenever we learn something new about a parcel:
+// it was fetched for the first time, or its response has changed
+type ChangeListener interface {
+	TrackingInfoChanged(ctx context.Context, trackingInfo *TrackingInfo)
 }
 
 // PostalAPI represents a single postal service API.
@@ -100,8 +183,32 @@ type PostalAPI interface {
 	Parse(rawResponse PostalApiResponse) (*TrackingInfo, error)
 }
 
-func (svc *Impl) GetTrackingInfo(ctx context.Context, trackingNumber string) ([]*TrackingInfo, error) {
-	now := svc.now()
+// BatchFetcher can optionally be implemented by PostalAPI
+// that is able to fetch many tracking numbers with a single request.
+type BatchFetcher interface {
+	// FetchBatch follows the same contract as PostalAPI.Fetch,
+	// and should return exactly one response per tracking number
+	FetchBatch(ctx context.Context, trackingNumbers []string) []PostalApiResponse
+	// MaxBatchSize is how many tracking numbers API accepts at once
+	MaxBatchSize() int
+}
+
+// GetTrackingInfo tracks the parcel with all relevant APIs.
+// Tracking numbers linked to it (e.g. a last-mile number) are tracked as well,
+// and their tracking infos are returned along with the parcel's own.
+// Events are described in the language, as far as carriers and our own translations allow (see Languages).
+func (svc *Impl) GetTrackingInfo(ctx context.Context, trackingNumber string, language string) ([]*TrackingInfo, error) {
+	trackingInfos, err := svc.getOwnTrackingInfo(ctx, trackingNumber)
+	if err != nil {
+		return nil, err
+	}
+	trackingInfos = svc.followLinks(ctx, trackingNumber, trackingInfos)
+	svc.localize(ctx, trackingInfos, language)
+	return trackingInfos, nil
+}
+
+// getOwnTrackingInfo is GetTrackingInfo without following linked tracking numbers
+func (svc *Impl) getOwnTrackingInfo(ctx context.Context, trackingNumber string) ([]*TrackingInfo, error) {
 	storedResponsesMap, err := svc.loadRawResponsesMap(ctx, trackingNumber)
 	svc.log.Info(
 		"loaded stored responses",
@@ -112,29 +219,192 @@ func (svc *Impl) GetTrackingInfo(ctx context.Context, trackingNumber string) ([]
 		return nil, zaperr.Wrap(err, "loadRawResponsesMap")
 	}
 
-	apisToHit, isParcelDelivered, parsedResponsesMap := svc.analyzeStoredResponses(storedResponsesMap)
+	return svc.trackParcel(ctx, trackingNumber, storedResponsesMap), nil
+}
 
-	if isParcelDelivered {
+// BatchResult is a result of tracking a single parcel within a batch
+type BatchResult struct {
+	TrackingNumber string
+	TrackingInfos  []*TrackingInfo
+	Err            error
+}
+
+// batchConcurrency is how many parcels of a batch are tracked simultaneously
+const batchConcurrency = 8
+
+// GetTrackingInfoBatch is the same as GetTrackingInfo, but for many tracking numbers at once.
+// Stored responses are loaded with a single query, APIs capable of batch fetching are asked
+// with as few requests as possible, and the rest of fetches are done by a bounded pool of workers.
+// Results are returned in the same order as tracking numbers; duplicate tracking numbers are tracked once.
+// Error is only returned if the whole batch failed.
+func (svc *Impl) GetTrackingInfoBatch(ctx context.Context, trackingNumbers []string, language string) ([]*BatchResult, error) {
+	trackingNumbers = uniqueStrings(trackingNumbers)
+
+	storedResponses, err := svc.storage.GetLatestBatch(ctx, trackingNumbers, svc.apiNames)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to get latest responses", zap.Int("batchSize", len(trackingNumbers)))
+	}
+	storedResponsesMaps := make(map[string]map[APIName]*PostalApiResponse, len(trackingNumbers))
+	for _, resp := range storedResponses {
+		if storedResponsesMaps[resp.TrackingNumber] == nil {
+			storedResponsesMaps[resp.TrackingNumber] = make(map[APIName]*PostalApiResponse)
+		}
+		storedResponsesMaps[resp.TrackingNumber][resp.APIName] = resp
+	}
+	svc.log.Info(
+		"loaded stored responses for a batch",
+		zap.Int("batchSize", len(trackingNumbers)),
+		zap.Int("count", len(storedResponses)),
+	)
+
+	plans := make([]*trackingPlan, len(trackingNumbers))
+	for i, trackingNumber := range trackingNumbers {
+		plans[i] = svc.planTracking(trackingNumber, storedResponsesMaps[trackingNumber])
+	}
+
+	batchFetchedMaps := svc.fetchBatchResponses(ctx, plans)
+
+	results := make([]*BatchResult, len(trackingNumbers))
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < batchConcurrency && w < len(trackingNumbers); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				plan := plans[i]
+				result := &BatchResult{TrackingNumber: plan.trackingNumber}
+				results[i] = result
+				if err := ctx.Err(); err != nil {
+					result.Err = err
+					continue
+				}
+
+				// APIs capable of batch fetching were already asked, here we only fetch the rest one by one
+				var apisToHit []APIName
+				for _, apiName := range plan.apisToHit {
+					if _, isBatchFetcher := svc.apiMap[apiName].(BatchFetcher); !isBatchFetcher {
+						apisToHit = append(apisToHit, apiName)
+					}
+				}
+				fetchedResponsesMap := svc.fetchResponses(ctx, plan.trackingNumber, apisToHit)
+				for apiName, resp := range batchFetchedMaps[plan.trackingNumber] {
+					fetchedResponsesMap[apiName] = resp
+				}
+
+				result.TrackingInfos = svc.followLinks(
+					ctx,
+					plan.trackingNumber,
+					svc.processFetchedResponses(ctx, plan, fetchedResponsesMap),
+				)
+				svc.localize(ctx, result.TrackingInfos, language)
+			}
+		}()
+	}
+	for i := range trackingNumbers {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	return results, nil
+}
+
+// trackingPlan is what we've learned about a single parcel from its stored responses
+type trackingPlan struct {
+	trackingNumber     string
+	storedResponsesMap map[APIName]*PostalApiResponse
+	apisToHit          []APIName
+	parcelState        TerminalState
+	parsedResponsesMap map[APIName]*TrackingInfo
+}
+
+// trackParcel decides which APIs to hit based on stored responses,
+// fetches them, stores the results and returns parsed tracking infos.
+// It does not return errors: anything that went wrong with a particular API
+// is reflected in the stored responses, and such API is absent from the result.
+func (svc *Impl) trackParcel(
+	ctx context.Context,
+	trackingNumber string,
+	storedResponsesMap map[APIName]*PostalApiResponse,
+) []*TrackingInfo {
+	plan := svc.planTracking(trackingNumber, storedResponsesMap)
+	fetchedResponsesMap := svc.fetchResponses(ctx, trackingNumber, plan.apisToHit)
+	return svc.processFetchedResponses(ctx, plan, fetchedResponsesMap)
+}
+
+func (svc *Impl) planTracking(
+	trackingNumber string,
+	storedResponsesMap map[APIName]*PostalApiResponse,
+) *trackingPlan {
+	detection := svc.DetectCarriers(trackingNumber)
+	svc.log.Info(
+		"detected carriers",
+		zap.String("trackingNumber", trackingNumber),
+		zap.Any("formats", detection.Formats),
+		zap.Any("candidates", detection.Candidates),
+	)
+
+	apisToHit, parcelState, parsedResponsesMap := svc.analyzeStoredResponses(storedResponsesMap, detection)
+
+	return &trackingPlan{
+		trackingNumber:     trackingNumber,
+		storedResponsesMap: storedResponsesMap,
+		apisToHit:          apisToHit,
+		parcelState:        parcelState,
+		parsedResponsesMap: parsedResponsesMap,
+	}
+}
+
+// processFetchedResponses compares fetched responses with stored ones,
+// stores whatever is new, and returns parsed tracking infos
+func (svc *Impl) processFetchedResponses(
+	ctx context.Context,
+	plan *trackingPlan,
+	fetchedResponsesMap map[APIName]PostalApiResponse,
+) []*TrackingInfo {
+	now := svc.now()
+	trackingNumber := plan.trackingNumber
+	storedResponsesMap := plan.storedResponsesMap
+	parsedResponsesMap := plan.parsedResponsesMap
+
+	if plan.parcelState != TerminalStateNone {
 		svc.log.Info(
-			"parcel is delivered",
+			"parcel has reached terminal state",
 			zap.String("trackingNumber", trackingNumber),
+			zap.String("state", string(plan.parcelState)),
 		)
-		svc.metrics.ParcelDelivered()
-		return maps.Values(parsedResponsesMap), nil
+		svc.metrics.ParcelFinal(plan.parcelState)
+		// responses of other APIs don't know yet that the parcel's journey has ended,
+		// mark them, so they are not picked up for refresh anymore
+		for _, stored := range storedResponsesMap {
+			if stored.IsFinal {
+				continue
+			}
+			stored.IsFinal = true
+			if err := svc.storage.Update(ctx, stored); err != nil {
+				svc.log.Error("failed to mark stored response as final", zap.Error(err))
+			}
+		}
+		return maps.Values(parsedResponsesMap)
 	}
 
-	fetchedResponsesMap := svc.fetchResponses(ctx, trackingNumber, apisToHit)
-
 	result := make([]*TrackingInfo, 0, len(fetchedResponsesMap)+len(storedResponsesMap))
 
 	// getParsedResp is a convenience function to get parsed response from the map
 	// or parse it if it's not there yet
 	getParsedResp := func(apiName APIName, rawResp PostalApiResponse) (*TrackingInfo, error) {
 		if parsed, exists := parsedResponsesMap[apiName]; exists {
+			if parsed != nil {
+				svc.fillETA(parsed)
+			}
 			return parsed, nil
 		}
 		parsed, err := svc.parseApiResponse(rawResp)
 		if err == nil {
+			if parsed != nil {
+				svc.fillETA(parsed)
+			}
 			return parsed, nil
 		}
 		svc.log.Error(
@@ -152,13 +422,17 @@ func (svc *Impl) GetTrackingInfo(ctx context.Context, trackingNumber string) ([]
 		stored := storedResponsesMap[apiName]
 
 		switch {
+		case stored == nil && !wasFetched:
+			// API was not asked (e.g. irrelevant tracking number format, or fetch timed out),
+			// and we know nothing from it, so there's nothing to report
+			continue
 		case stored != nil && !wasFetched:
 			// Stored response was found, but was too fresh to re-fetch, no need to update, just return it
 			if parsed, err := getParsedResp(apiName, *stored); err == nil && parsed != nil {
 				parsed.LastFetchedAt = stored.LastFetchedAt
 				result = append(result, parsed)
 			}
-		case stored == nil || !bytes.Equal(stored.ResponseBody, fetched.ResponseBody):
+		case stored == nil || stored.Hash() != fetched.Hash():
 			if stored == nil {
 				fetched.FirstFetchedAt = now
 				svc.metrics.FetchedFirst(apiName)
@@ -167,9 +441,16 @@ func (svc *Impl) GetTrackingInfo(ctx context.Context, trackingNumber string) ([]
 			}
 			fetched.LastFetchedAt = now
 
+			var changedInfo *TrackingInfo
 			if fetched.Status == StatusSuccess {
 				if parsed, err := getParsedResp(apiName, fetched); err == nil && parsed != nil {
+					fetched.IsFinal = parsed.IsTerminal()
+					parsed.LastFetchedAt = fetched.LastFetchedAt
 					result = append(result, parsed)
+					changedInfo = parsed
+					for range unknownCodes(parsed) {
+						svc.metrics.UnknownEventCode(apiName)
+					}
 				} else if err != nil {
 					fetched.Status = StatusUnknownError
 					if err := svc.storage.Update(ctx, &fetched); err != nil {
@@ -178,15 +459,19 @@ func (svc *Impl) GetTrackingInfo(ctx context.Context, trackingNumber string) ([]
 				}
 			}
 
-			if err := svc.storage.Insert(ctx, trackingNumber, apiName, &fetched); err != nil {
-				// TODO: check for duplicate key error, find correct entry and update it
+			if err := svc.storage.Upsert(ctx, trackingNumber, apiName, &fetched); err != nil {
+				svc.log.Error("failed to store fetched response", zap.Error(err))
+			} else if changedInfo != nil {
+				svc.notifyChanged(ctx, changedInfo)
 			}
-		default: // stored != nil && stored.ResponseBody == fetched.ResponseBody
+		default: // stored != nil && stored.Hash() == fetched.Hash()
 			// We already have this response, just update the timestamp
 			svc.metrics.FetchedUnchanged(apiName)
 			stored.LastFetchedAt = now
 
 			if parsed, err := getParsedResp(apiName, *stored); err == nil && parsed != nil {
+				stored.IsFinal = parsed.IsTerminal()
+				parsed.LastFetchedAt = stored.LastFetchedAt
 				result = append(result, parsed)
 			} else if err != nil {
 				svc.log.Error("failed to parse stored response", zap.Error(err))
@@ -198,9 +483,76 @@ func (svc *Impl) GetTrackingInfo(ctx context.Context, trackingNumber string) ([]
 		}
 	}
 
+	return result
+}
+
+// DueRefresh is a parcel that should be refreshed since DueAt
+type DueRefresh struct {
+	TrackingNumber string
+	DueAt          time.Time
+}
+
+// ListDueForRefresh returns up to limit parcels that GetTrackingInfo would re-fetch if asked now,
+// most overdue first
+func (svc *Impl) ListDueForRefresh(ctx context.Context, limit int) ([]DueRefresh, error) {
+	query := svc.refreshQuery()
+	// every API of a parcel might be due, so fetch enough responses to fill the limit anyway
+	query.Limit = limit * len(svc.apiNames)
+	responses, err := svc.storage.GetDueForRefresh(ctx, query)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to get responses due for refresh")
+	}
+
+	dueAtMap := make(map[string]time.Time, len(responses))
+	for _, resp := range responses {
+		var dueAt time.Time
+		switch resp.Status {
+		case StatusSuccess:
+			dueAt = resp.LastFetchedAt.Add(svc.okCheckInterval)
+		case StatusNotFound:
+			dueAt = resp.LastFetchedAt.Add(svc.notFoundCheckInterval)
+		default:
+			dueAt = resp.LastFetchedAt.Add(svc.unknownErrorCheckInterval)
+		}
+		if existing, exists := dueAtMap[resp.TrackingNumber]; !exists || dueAt.Before(existing) {
+			dueAtMap[resp.TrackingNumber] = dueAt
+		}
+	}
+
+	result := make([]DueRefresh, 0, len(dueAtMap))
+	for trackingNumber, dueAt := range dueAtMap {
+		result = append(result, DueRefresh{TrackingNumber: trackingNumber, DueAt: dueAt})
+	}
+	slices.SortFunc(result, func(a, b DueRefresh) int {
+		return a.DueAt.Compare(b.DueAt)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
 	return result, nil
 }
 
+// CountDueForRefresh returns how many parcels ListDueForRefresh would return, were there no limit
+func (svc *Impl) CountDueForRefresh(ctx context.Context) (int, error) {
+	count, err := svc.storage.CountDueForRefresh(ctx, svc.refreshQuery())
+	if err != nil {
+		return 0, zaperr.Wrap(err, "failed to count parcels due for refresh")
+	}
+	return count, nil
+}
+
+// refreshQuery describes latest responses that are due for refresh as of now
+func (svc *Impl) refreshQuery() RefreshQuery {
+	now := svc.now()
+	return RefreshQuery{
+		APINames:                  svc.apiNames,
+		NotExpiredSince:           now.Add(-svc.expiryTimeout),
+		SuccessFetchedBefore:      now.Add(-svc.okCheckInterval),
+		NotFoundFetchedBefore:     now.Add(-svc.notFoundCheckInterval),
+		UnknownErrorFetchedBefore: now.Add(-svc.unknownErrorCheckInterval),
+	}
+}
+
 // loadRawResponsesMap loads the last responses from all APIs,
 // and returns them as a map[apiName]response
 func (svc *Impl) loadRawResponsesMap(
@@ -219,38 +571,42 @@ func (svc *Impl) loadRawResponsesMap(
 }
 
 // analyzeStoredResponses goes through the last responses from all APIs
-// and returns:
-// - apisToHit: list of APIs that should be re-fetched for new responses
-// - isDelivered: whether the package is delivered
+// and, taking into account which APIs are relevant for the tracking number format,
+// returns:
+// - apisToHit: list of APIs that should be re-fetched for new responses,
+// which is reconsidered once we know which countries parcel goes between
+// - parcelState: terminal state of the parcel according to any of the APIs, if it has reached one
 // - parsedResponsesMap: result of responses parsing
 func (svc *Impl) analyzeStoredResponses(
 	lastRespMap map[APIName]*PostalApiResponse,
+	detection *CarrierDetection,
 ) (
 	apisToHit []APIName,
-	isParcelDelivered bool,
+	parcelState TerminalState,
 	parsedResponsesMap map[APIName]*TrackingInfo,
 ) {
 	parsedResponsesMap = make(map[APIName]*TrackingInfo, len(lastRespMap))
-	isParcelDelivered = false
+	parcelState = TerminalStateNone
 	apiHitDecisionMap := make(map[APIName]bool, len(svc.apiNames))
 	for _, apiName := range svc.apiNames {
 		apiHitDecisionMap[apiName] = false
 		resp := lastRespMap[apiName]
 
 		if resp == nil {
-			// new tracking numbers we've never seen before
-			apiHitDecisionMap[apiName] = true
+			// new tracking numbers we've never seen before,
+			// only worth asking APIs that can possibly know about this format
+			apiHitDecisionMap[apiName] = detection.IsCandidate(apiName)
 			continue
 		}
 
 		if parsed, err := svc.parseApiResponse(*resp); err == nil { // we can dereference here because we know that resp != nil
 			parsedResponsesMap[apiName] = parsed
-			if !isParcelDelivered && parsed.IsDelivered() {
-				isParcelDelivered = true
+			if parcelState == TerminalStateNone {
+				parcelState = parsed.TerminalState()
 			}
 		}
 
-		if isParcelDelivered {
+		if parcelState != TerminalStateNone {
 			continue
 			// We don't need to analyze whether to hit the APIs or not: we won't.
 			// But we do need to continue parsing the responses
@@ -262,7 +618,7 @@ func (svc *Impl) analyzeStoredResponses(
 			// Tracking number we've seen long time ago.
 			// This can be a tracking number reuse (it happens),
 			// so we should treat is as a new tracking number
-			apiHitDecisionMap[apiName] = true
+			apiHitDecisionMap[apiName] = detection.IsCandidate(apiName)
 			continue
 		case resp.Status == StatusSuccess:
 			// We already got updates for this tracking number.
@@ -289,22 +645,27 @@ func (svc *Impl) analyzeStoredResponses(
 			shouldRefetch := svc.now().After(recheckAt)
 			apiHitDecisionMap[apiName] = shouldRefetch
 			svc.metrics.CacheBustAfterNotFoundError(apiName, shouldRefetch)
-		default:
-			// TODO: check for mentioned countries, and reconsider relevance of the API
 		}
 	}
 
-	if isParcelDelivered {
-		return nil, true, parsedResponsesMap
+	if parcelState != TerminalStateNone {
+		return nil, parcelState, parsedResponsesMap
 	}
 
+	svc.reconsiderRelevance(detection.TrackingNumber, lastRespMap, parsedResponsesMap, apiHitDecisionMap)
+
 	for apiName, shouldHit := range apiHitDecisionMap {
-		if shouldHit {
-			apisToHit = append(apisToHit, apiName)
+		if !shouldHit {
+			continue
+		}
+		if svc.cooldowns.isActive(apiName, svc.now()) {
+			// API told us to back off, stored response (if any) will have to do for now
+			continue
 		}
+		apisToHit = append(apisToHit, apiName)
 	}
 
-	return apisToHit, isParcelDelivered, parsedResponsesMap
+	return apisToHit, parcelState, parsedResponsesMap
 }
 
 // fetchResponses fetches responses from all the APIs in parallel.
@@ -319,6 +680,7 @@ func (svc *Impl) fetchResponses(
 	resultsChan := make(chan PostalApiResponse, len(apisToHit))
 	wg := sync.WaitGroup{}
 
+	// fetches are given the same timeout, but can we really trust APIs to respect it?
 	ttlCtx, cancel := context.WithTimeout(ctx, svc.apiFetchTimeout)
 	defer cancel()
 	for _, apiName := range apisToHit {
@@ -326,31 +688,113 @@ func (svc *Impl) fetchResponses(
 		go func(apiName APIName) {
 			defer wg.Done()
 
-			svc.metrics.APIHit(apiName)
-			resp := svc.apiMap[apiName].Fetch(ttlCtx, trackingNumber)
+			// concurrent lookups of the same parcel should not hit the API more than once
+			key := fetchKey{trackingNumber: trackingNumber, apiName: apiName}
+			resp, err := svc.fetches.do(ttlCtx, key, svc.apiFetchTimeout, func(ctx context.Context) PostalApiResponse {
+				svc.metrics.APIHit(apiName)
+				return svc.apiMap[apiName].Fetch(ctx, trackingNumber)
+			})
+			if err != nil { // we are not waiting for it anymore
+				return
+			}
 			resultsChan <- resp
 		}(apiName)
 	}
 
 	wg.Wait()
-	for i := 0; i < len(apisToHit); i++ {
-		select {
-		case <-ttlCtx.Done(): // yes, we pass the context to the API,
-			// but can we really trust them to respect it?
-			return fetchedResponsesMap
-		case resp := <-resultsChan:
-			fetchedResponsesMap[resp.APIName] = resp
+	close(resultsChan)
+	for resp := range resultsChan {
+		if svc.isUnanswered(resp.APIName, resp) {
 			continue
 		}
+		fetchedResponsesMap[resp.APIName] = resp
 	}
-	close(resultsChan)
 
 	return fetchedResponsesMap
 }
 
+func uniqueStrings(strs []string) []string {
+	seen := make(map[string]bool, len(strs))
+	result := make([]string, 0, len(strs))
+	for _, s := range strs {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// fetchBatchResponses asks APIs capable of batch fetching about all the parcels that need it,
+// and returns responses as a map[trackingNumber]map[apiName]response
+func (svc *Impl) fetchBatchResponses(
+	ctx context.Context,
+	plans []*trackingPlan,
+) map[string]map[APIName]PostalApiResponse {
+	fetchedResponsesMaps := make(map[string]map[APIName]PostalApiResponse, len(plans))
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+
+	ttlCtx, cancel := context.WithTimeout(ctx, svc.apiFetchTimeout)
+	defer cancel()
+	for _, apiName := range svc.apiNames {
+		batchFetcher, ok := svc.apiMap[apiName].(BatchFetcher)
+		if !ok {
+			continue
+		}
+
+		var trackingNumbers []string
+		for _, plan := range plans {
+			if slices.Contains(plan.apisToHit, apiName) {
+				trackingNumbers = append(trackingNumbers, plan.trackingNumber)
+			}
+		}
+
+		for len(trackingNumbers) > 0 {
+			chunk := trackingNumbers[:min(len(trackingNumbers), batchFetcher.MaxBatchSize())]
+			trackingNumbers = trackingNumbers[len(chunk):]
+
+			wg.Add(1)
+			go func(apiName APIName, chunk []string) {
+				defer wg.Done()
+
+				svc.metrics.APIHit(apiName)
+				responses := batchFetcher.FetchBatch(ttlCtx, chunk)
+
+				mu.Lock()
+				defer mu.Unlock()
+				for _, resp := range responses {
+					if !slices.Contains(chunk, resp.TrackingNumber) || svc.isUnanswered(apiName, resp) {
+						continue
+					}
+					if fetchedResponsesMaps[resp.TrackingNumber] == nil {
+						fetchedResponsesMaps[resp.TrackingNumber] = make(map[APIName]PostalApiResponse)
+					}
+					fetchedResponsesMaps[resp.TrackingNumber][apiName] = resp
+				}
+			}(apiName, chunk)
+		}
+	}
+	wg.Wait()
+
+	return fetchedResponsesMaps
+}
+
 func (svc *Impl) parseApiResponse(resp PostalApiResponse) (*TrackingInfo, error) {
 	if resp.Status != StatusSuccess {
 		return nil, fmt.Errorf("not parsing a response with a non-success status")
 	}
-	return svc.apiMap[resp.APIName].Parse(resp)
+	parsed, err := svc.apiMap[resp.APIName].Parse(resp)
+	if err != nil || parsed == nil {
+		return parsed, err
+	}
+	normalizeCountries(parsed)
+	return parsed, nil
+}
+
+// sortedAPINames returns names of all the APIs, so that they can be iterated in a stable order
+func (svc *Impl) sortedAPINames() []APIName {
+	apiNames := maps.Keys(svc.apiMap)
+	slices.Sort(apiNames)
+	return apiNames
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/dir01/parcels/metrics"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -14,6 +15,8 @@ import (
 
 var api1Name service.APIName = "api1"
 
+var promMetrics = metrics.NewPrometheus()
+
 //go:generate minimock -g -i github.com/dir01/parcels/service.Storage,github.com/dir01/parcels/service.PostalAPI -o ./mocks -s _mock.go
 func TestService(t *testing.T) {
 	logger, err := zap.NewDevelopment()
@@ -21,8 +24,6 @@ func TestService(t *testing.T) {
 		t.Fatalf("failed to create logger: %v", err)
 	}
 
-	promMetrics := metrics.NewPrometheus()
-
 	okCheckInterval := 24 * time.Hour
 	notFoundCheckInterval := 3 * 24 * time.Hour
 	unknownErrorCheckInterval := 3 * time.Hour
@@ -56,6 +57,7 @@ func TestService(t *testing.T) {
 			unknownErrorCheckInterval,
 			apiFetchTimeout,
 			expiryTimeout,
+			time.Hour,
 			logger,
 			func() time.Time {
 				return now
@@ -70,6 +72,7 @@ func TestService(t *testing.T) {
 		svc, storage, setNow, api1 := prepareTestSubjects()
 
 		storage.GetLatestMock.Expect(callCtx, "123", []service.APIName{api1Name}).Return(nil, nil)
+		storage.GetLinksMock.Expect(callCtx, "123").Return(nil, nil)
 
 		api1Response := service.PostalApiResponse{
 			TrackingNumber: "123",
@@ -93,7 +96,7 @@ func TestService(t *testing.T) {
 		api1Response.FirstFetchedAt = now
 		api1Response.LastFetchedAt = now
 
-		storage.InsertMock.Expect(callCtx, "123", api1Name, &api1Response).Return(nil)
+		storage.UpsertMock.Expect(callCtx, "123", api1Name, &api1Response).Return(nil)
 
 		api1TrackingInfo := &service.TrackingInfo{
 			TrackingNumber: "123",
@@ -101,7 +104,7 @@ func TestService(t *testing.T) {
 		}
 		api1.ParseMock.Expect(api1Response).Return(api1TrackingInfo, nil)
 
-		tracking, err := svc.GetTrackingInfo(callCtx, "123")
+		tracking, err := svc.GetTrackingInfo(callCtx, "123", service.DefaultLanguage)
 		if err != nil {
 			t.Fatalf("failed to get tracking info: %v", err)
 		}
@@ -131,13 +134,14 @@ func TestService(t *testing.T) {
 		storage.GetLatestMock.
 			Expect(callCtx, "123", []service.APIName{api1Name}).
 			Return([]*service.PostalApiResponse{&storedRawResponse}, nil)
+		storage.GetLinksMock.Expect(callCtx, "123").Return(nil, nil)
 		parsedTrackingInfo := &service.TrackingInfo{
 			TrackingNumber: "123",
 			APIName:        api1Name,
 		}
 		api1.ParseMock.Expect(storedRawResponse).Return(parsedTrackingInfo, nil)
 
-		tr, err := svc.GetTrackingInfo(callCtx, "123")
+		tr, err := svc.GetTrackingInfo(callCtx, "123", service.DefaultLanguage)
 		if err != nil {
 			t.Fatalf("failed to get tracking info: %v", err)
 		}
@@ -155,11 +159,96 @@ func TestService(t *testing.T) {
 		}
 	})
 
+	t.Run("stored tracking - returned to sender is not refetched", func(t *testing.T) {
+		callCtx := context.Background()
+		svc, storage, setNow, api1 := prepareTestSubjects()
+
+		now := time.Now()
+		setNow(now)
+
+		// stale enough to be refetched, if parcel was still on its way
+		storedRawResponse := service.PostalApiResponse{
+			TrackingNumber: "123",
+			APIName:        api1Name,
+			Status:         service.StatusSuccess,
+			ResponseBody:   []byte("foo"),
+			LastFetchedAt:  now.Add(-2 * okCheckInterval),
+		}
+		storage.GetLatestMock.
+			Expect(callCtx, "123", []service.APIName{api1Name}).
+			Return([]*service.PostalApiResponse{&storedRawResponse}, nil)
+		storage.GetLinksMock.Expect(callCtx, "123").Return(nil, nil)
+		api1.ParseMock.Return(&service.TrackingInfo{
+			TrackingNumber: "123",
+			APIName:        api1Name,
+			Events: []service.TrackingEvent{
+				{Time: now.Add(-3 * okCheckInterval), Status: service.TrackingStatusAcceptedByCarrier},
+				{Time: now.Add(-2 * okCheckInterval), Status: service.TrackingStatusReturnedToSender},
+			},
+		}, nil)
+		storage.UpdateMock.Inspect(func(ctx context.Context, resp *service.PostalApiResponse) {
+			if !resp.IsFinal {
+				t.Fatalf("expected response to be marked as final, got %+v", resp)
+			}
+		}).Return(nil)
+
+		// FetchMock is not set, so any fetch would fail the test
+		tr, err := svc.GetTrackingInfo(callCtx, "123", service.DefaultLanguage)
+		if err != nil {
+			t.Fatalf("failed to get tracking info: %v", err)
+		}
+		if len(tr) != 1 || tr[0].TerminalState() != service.TerminalStateReturned {
+			t.Fatalf("expected parcel to be returned, got %+v", tr)
+		}
+		if storage.UpdateAfterCounter() != 1 {
+			t.Fatalf("expected stored response to be updated once, got %d", storage.UpdateAfterCounter())
+		}
+	})
+
+	t.Run("countries are normalized", func(t *testing.T) {
+		callCtx := context.Background()
+		svc, storage, setNow, api1 := prepareTestSubjects()
+
+		now := time.Now()
+		setNow(now)
+
+		storage.GetLatestMock.Return([]*service.PostalApiResponse{{
+			TrackingNumber: "123",
+			APIName:        api1Name,
+			Status:         service.StatusSuccess,
+			ResponseBody:   []byte("foo"),
+			LastFetchedAt:  now,
+		}}, nil)
+		storage.GetLinksMock.Return(nil, nil)
+		api1.ParseMock.Return(&service.TrackingInfo{
+			TrackingNumber:     "123",
+			APIName:            api1Name,
+			OriginCountry:      "Mainland China",
+			DestinationCountry: "Atlantis",
+		}, nil)
+
+		tr, err := svc.GetTrackingInfo(callCtx, "123", service.DefaultLanguage)
+		if err != nil {
+			t.Fatalf("failed to get tracking info: %v", err)
+		}
+		if len(tr) != 1 {
+			t.Fatalf("expected 1 tracking info, got %d", len(tr))
+		}
+		info := tr[0]
+		if info.OriginCountry != "CN" || info.OriginCountryRaw != "Mainland China" {
+			t.Fatalf("expected origin country to be normalized, got %q (%q)", info.OriginCountry, info.OriginCountryRaw)
+		}
+		if info.DestinationCountry != "" || info.DestinationCountryRaw != "Atlantis" {
+			t.Fatalf("expected unfamiliar destination country to be kept raw only, got %q (%q)", info.DestinationCountry, info.DestinationCountryRaw)
+		}
+	})
+
 	t.Run("not found tracking number", func(t *testing.T) {
 		callCtx := context.WithValue(context.Background(), "foo", "bar")
 		svc, storage, setNow, api1 := prepareTestSubjects()
 
 		storage.GetLatestMock.Expect(callCtx, "123", []service.APIName{api1Name}).Return(nil, nil)
+		storage.GetLinksMock.Expect(callCtx, "123").Return(nil, nil)
 
 		api1Response := service.PostalApiResponse{
 			TrackingNumber: "123",
@@ -179,7 +268,7 @@ func TestService(t *testing.T) {
 		now := time.Now()
 		setNow(now)
 
-		storage.InsertMock.Expect(callCtx, "123", api1Name, &service.PostalApiResponse{
+		storage.UpsertMock.Expect(callCtx, "123", api1Name, &service.PostalApiResponse{
 			TrackingNumber: "123",
 			APIName:        api1Name,
 			FirstFetchedAt: now,
@@ -188,7 +277,7 @@ func TestService(t *testing.T) {
 			Status:         service.StatusNotFound,
 		}).Return(nil)
 
-		tracking, err := svc.GetTrackingInfo(callCtx, "123")
+		tracking, err := svc.GetTrackingInfo(callCtx, "123", service.DefaultLanguage)
 		if err != nil {
 			t.Fatalf("failed to get tracking info: %v", err)
 		}
@@ -198,4 +287,359 @@ func TestService(t *testing.T) {
 		}
 	})
 
+	t.Run("batch", func(t *testing.T) {
+		callCtx := context.Background()
+		svc, storage, setNow, api1 := prepareTestSubjects()
+
+		now := time.Now()
+		setNow(now)
+
+		storedRawResponse := service.PostalApiResponse{
+			TrackingNumber: "123",
+			APIName:        api1Name,
+			Status:         service.StatusSuccess,
+			ResponseBody:   []byte("foo"),
+			LastFetchedAt:  now.Add(-(okCheckInterval / 2)),
+		}
+		storage.GetLatestBatchMock.
+			Expect(callCtx, []string{"123", "456"}, []service.APIName{api1Name}).
+			Return([]*service.PostalApiResponse{&storedRawResponse}, nil)
+		storage.GetLinksMock.Return(nil, nil)
+		storedTrackingInfo := &service.TrackingInfo{TrackingNumber: "123", APIName: api1Name}
+		api1.ParseMock.Expect(storedRawResponse).Return(storedTrackingInfo, nil)
+
+		api1.FetchMock.Inspect(func(ctx context.Context, trackingNumber string) {
+			if trackingNumber != "456" {
+				t.Fatalf("expected only 456 to be fetched, got %s", trackingNumber)
+			}
+		}).Return(service.PostalApiResponse{
+			TrackingNumber: "456",
+			APIName:        api1Name,
+			Status:         service.StatusNotFound,
+		})
+		storage.UpsertMock.Expect(callCtx, "456", api1Name, &service.PostalApiResponse{
+			TrackingNumber: "456",
+			APIName:        api1Name,
+			FirstFetchedAt: now,
+			LastFetchedAt:  now,
+			Status:         service.StatusNotFound,
+		}).Return(nil)
+
+		results, err := svc.GetTrackingInfoBatch(callCtx, []string{"123", "456", "123"}, service.DefaultLanguage)
+		if err != nil {
+			t.Fatalf("failed to get tracking info batch: %v", err)
+		}
+
+		if len(results) != 2 {
+			t.Fatalf("expected 2 results, got %d", len(results))
+		}
+		if results[0].TrackingNumber != "123" || len(results[0].TrackingInfos) != 1 || results[0].Err != nil {
+			t.Fatalf("expected stored tracking info for 123, got %+v", results[0])
+		}
+		if results[1].TrackingNumber != "456" || len(results[1].TrackingInfos) != 0 || results[1].Err != nil {
+			t.Fatalf("expected no tracking info for 456, got %+v", results[1])
+		}
+	})
+
+	t.Run("follows linked tracking numbers", func(t *testing.T) {
+		callCtx := context.Background()
+		svc, storage, setNow, api1 := prepareTestSubjects()
+
+		now := time.Now()
+		setNow(now)
+
+		// 123 mentions 456, which mentions 123 back, and also 789 is known to be linked to 456 from before
+		storedResponses := map[string]*service.PostalApiResponse{}
+		parsedTrackingInfos := map[string]*service.TrackingInfo{}
+		for tn, additional := range map[string][]string{"123": {"456"}, "456": {"123"}, "789": nil} {
+			storedResponses[tn] = &service.PostalApiResponse{
+				TrackingNumber: tn,
+				APIName:        api1Name,
+				Status:         service.StatusSuccess,
+				ResponseBody:   []byte(tn),
+				LastFetchedAt:  now.Add(-(okCheckInterval / 2)),
+			}
+			parsedTrackingInfos[tn] = &service.TrackingInfo{
+				TrackingNumber:            tn,
+				APIName:                   api1Name,
+				AdditionalTrackingNumbers: additional,
+			}
+		}
+		storage.GetLatestMock.Set(func(ctx context.Context, trackingNumber string, apiNames []service.APIName) ([]*service.PostalApiResponse, error) {
+			return []*service.PostalApiResponse{storedResponses[trackingNumber]}, nil
+		})
+		api1.ParseMock.Set(func(rawResponse service.PostalApiResponse) (*service.TrackingInfo, error) {
+			return parsedTrackingInfos[rawResponse.TrackingNumber], nil
+		})
+		storage.GetLinksMock.Set(func(ctx context.Context, trackingNumber string) ([]string, error) {
+			if trackingNumber == "456" {
+				return []string{"789"}, nil
+			}
+			return nil, nil
+		})
+		insertedLinks := map[string][]string{}
+		storage.InsertLinksMock.Set(func(ctx context.Context, trackingNumber string, linkedTrackingNumbers []string) error {
+			insertedLinks[trackingNumber] = linkedTrackingNumbers
+			return nil
+		})
+
+		tracking, err := svc.GetTrackingInfo(callCtx, "123", service.DefaultLanguage)
+		if err != nil {
+			t.Fatalf("failed to get tracking info: %v", err)
+		}
+
+		var trackingNumbers []string
+		for _, ti := range tracking {
+			trackingNumbers = append(trackingNumbers, ti.TrackingNumber)
+		}
+		if !reflect.DeepEqual(trackingNumbers, []string{"123", "456", "789"}) {
+			t.Fatalf("expected tracking infos of 123, 456 and 789, got %v", trackingNumbers)
+		}
+		expectedLinks := map[string][]string{"123": {"456"}, "456": {"123"}}
+		if !reflect.DeepEqual(insertedLinks, expectedLinks) {
+			t.Fatalf("expected links %v to be stored, got %v", expectedLinks, insertedLinks)
+		}
+	})
+
+	t.Run("list due for refresh", func(t *testing.T) {
+		callCtx := context.Background()
+		svc, storage, setNow, _ := prepareTestSubjects()
+
+		now := time.Now()
+		setNow(now)
+
+		storage.GetDueForRefreshMock.Expect(callCtx, service.RefreshQuery{
+			APINames:                  []service.APIName{api1Name},
+			NotExpiredSince:           now.Add(-expiryTimeout),
+			SuccessFetchedBefore:      now.Add(-okCheckInterval),
+			NotFoundFetchedBefore:     now.Add(-notFoundCheckInterval),
+			UnknownErrorFetchedBefore: now.Add(-unknownErrorCheckInterval),
+			Limit:                     10,
+		}).Return([]*service.PostalApiResponse{
+			{TrackingNumber: "123", Status: service.StatusSuccess, LastFetchedAt: now.Add(-okCheckInterval - time.Hour)},
+			{TrackingNumber: "456", Status: service.StatusNotFound, LastFetchedAt: now.Add(-notFoundCheckInterval - 2*time.Hour)},
+		}, nil)
+
+		due, err := svc.ListDueForRefresh(callCtx, 10)
+		if err != nil {
+			t.Fatalf("failed to list due for refresh: %v", err)
+		}
+
+		expected := []service.DueRefresh{
+			{TrackingNumber: "456", DueAt: now.Add(-2 * time.Hour)},
+			{TrackingNumber: "123", DueAt: now.Add(-time.Hour)},
+		}
+		if !reflect.DeepEqual(due, expected) {
+			t.Fatalf("expected %v, got %v", expected, due)
+		}
+	})
+
+}
+
+// batchFetchingAPI is a PostalAPI mock that is also capable of batch fetching
+type batchFetchingAPI struct {
+	*mocks.PostalAPIMock
+	fetchBatch func(ctx context.Context, trackingNumbers []string) []service.PostalApiResponse
+}
+
+func (a batchFetchingAPI) FetchBatch(ctx context.Context, trackingNumbers []string) []service.PostalApiResponse {
+	return a.fetchBatch(ctx, trackingNumbers)
+}
+
+func (a batchFetchingAPI) MaxBatchSize() int {
+	return 2
+}
+
+func TestServiceBatchFetcher(t *testing.T) {
+	storage := mocks.NewStorageMock(t)
+	api1 := mocks.NewPostalAPIMock(t)
+
+	var batches [][]string
+	mu := sync.Mutex{}
+	apiMap := map[service.APIName]service.PostalAPI{
+		api1Name: batchFetchingAPI{api1, func(ctx context.Context, trackingNumbers []string) []service.PostalApiResponse {
+			mu.Lock()
+			batches = append(batches, trackingNumbers)
+			mu.Unlock()
+			var responses []service.PostalApiResponse
+			for _, tn := range trackingNumbers {
+				responses = append(responses, service.PostalApiResponse{
+					TrackingNumber: tn,
+					APIName:        api1Name,
+					Status:         service.StatusNotFound,
+				})
+			}
+			return responses
+		}},
+	}
+
+	svc := service.NewService(
+		apiMap,
+		storage,
+		promMetrics,
+		time.Hour,
+		time.Hour,
+		time.Hour,
+		time.Second,
+		time.Hour,
+		time.Hour,
+		zap.NewNop(),
+		time.Now,
+	)
+
+	storage.GetLatestBatchMock.Return(nil, nil)
+	storage.GetLinksMock.Return(nil, nil)
+	storage.UpsertMock.Return(nil)
+	// api1.Fetch is not expected, so minimock would fail the test if it was called
+
+	results, err := svc.GetTrackingInfoBatch(context.Background(), []string{"1", "2", "3"}, service.DefaultLanguage)
+	if err != nil {
+		t.Fatalf("failed to get tracking info batch: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if len(batches) != 2 {
+		t.Fatalf("expected 2 batches of at most 2 tracking numbers, got %v", batches)
+	}
+	if storage.UpsertAfterCounter() != 3 {
+		t.Fatalf("expected every fetched response to be stored, got %d inserts", storage.UpsertAfterCounter())
+	}
+}
+
+func TestServiceCoalescesConcurrentLookups(t *testing.T) {
+	storage := mocks.NewStorageMock(t)
+	api1 := mocks.NewPostalAPIMock(t)
+	svc := service.NewService(
+		map[service.APIName]service.PostalAPI{api1Name: api1},
+		storage,
+		promMetrics,
+		time.Hour,
+		time.Hour,
+		time.Hour,
+		time.Second,
+		time.Hour,
+		time.Hour,
+		zap.NewNop(),
+		time.Now,
+	)
+
+	const callers = 2
+	// both lookups find nothing stored at the same time, so both decide to fetch
+	arrived := sync.WaitGroup{}
+	arrived.Add(callers)
+	storage.GetLatestMock.Set(func(ctx context.Context, trackingNumber string, apiNames []service.APIName) ([]*service.PostalApiResponse, error) {
+		arrived.Done()
+		arrived.Wait()
+		return nil, nil
+	})
+	storage.GetLinksMock.Return(nil, nil)
+	storage.UpsertMock.Return(nil)
+	api1.FetchMock.Set(func(ctx context.Context, trackingNumber string) service.PostalApiResponse {
+		time.Sleep(50 * time.Millisecond) // give the other caller a chance to join
+		return service.PostalApiResponse{
+			TrackingNumber: trackingNumber,
+			APIName:        api1Name,
+			Status:         service.StatusNotFound,
+		}
+	})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.GetTrackingInfo(context.Background(), "123", service.DefaultLanguage); err != nil {
+				t.Errorf("failed to get tracking info: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if api1.FetchAfterCounter() != 1 {
+		t.Fatalf("expected API to be hit once, got %d", api1.FetchAfterCounter())
+	}
+	if storage.UpsertAfterCounter() != callers {
+		t.Fatalf("expected every lookup to upsert its response, got %d", storage.UpsertAfterCounter())
+	}
+}
+
+func TestServiceCoalescedFetchOutlivesCallers(t *testing.T) {
+	storage := mocks.NewStorageMock(t)
+	api1 := mocks.NewPostalAPIMock(t)
+	svc := service.NewService(
+		map[service.APIName]service.PostalAPI{api1Name: api1},
+		storage,
+		promMetrics,
+		time.Hour,
+		time.Hour,
+		time.Hour,
+		time.Second,
+		time.Hour,
+		time.Hour,
+		zap.NewNop(),
+		time.Now,
+	)
+
+	const callers = 2
+	// both lookups find nothing stored at the same time, so both decide to fetch
+	arrived := sync.WaitGroup{}
+	arrived.Add(callers)
+	storage.GetLatestMock.Set(func(ctx context.Context, trackingNumber string, apiNames []service.APIName) ([]*service.PostalApiResponse, error) {
+		arrived.Done()
+		arrived.Wait()
+		return nil, nil
+	})
+	storage.GetLinksMock.Return(nil, nil)
+	storage.UpsertMock.Return(nil)
+	started := make(chan struct{})
+	startedOnce := sync.Once{}
+	release := make(chan struct{})
+	var fetchErr error
+	api1.FetchMock.Set(func(ctx context.Context, trackingNumber string) service.PostalApiResponse {
+		startedOnce.Do(func() { close(started) })
+		<-release
+		fetchErr = ctx.Err()
+		return service.PostalApiResponse{
+			TrackingNumber: trackingNumber,
+			APIName:        api1Name,
+			Status:         service.StatusNotFound,
+		}
+	})
+
+	// whichever of the lookups has started the fetch, the one that gives up should neither wait for it nor cancel it
+	givingUpCtx, giveUp := context.WithCancel(context.Background())
+	gaveUp := make(chan struct{})
+	go func() {
+		defer close(gaveUp)
+		svc.GetTrackingInfo(givingUpCtx, "123", service.DefaultLanguage)
+	}()
+	waited := make(chan struct{})
+	go func() {
+		defer close(waited)
+		if _, err := svc.GetTrackingInfo(context.Background(), "123", service.DefaultLanguage); err != nil {
+			t.Errorf("failed to get tracking info: %v", err)
+		}
+	}()
+
+	<-started
+	giveUp()
+	select {
+	case <-gaveUp:
+	case <-time.After(time.Second):
+		t.Fatalf("lookup that gave up is still waiting for fetch")
+	}
+	time.Sleep(50 * time.Millisecond) // give the other lookup a chance to join
+	close(release)
+
+	<-waited
+	if storage.UpsertAfterCounter() != 1 {
+		t.Fatalf("expected only lookup that waited to store fetched response, got %d upserts", storage.UpsertAfterCounter())
+	}
+	if fetchErr != nil {
+		t.Fatalf("expected fetch not to be cancelled, got %v", fetchErr)
+	}
+	if api1.FetchAfterCounter() != 1 {
+		t.Fatalf("expected API to be hit once, got %d", api1.FetchAfterCounter())
+	}
 }
@@ -1,35 +1,59 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
+
+	"github.com/dir01/parcels/countries"
 )
 
 // TrackingInfo represents final result of the service:
 // parsed and normalized representation of parcel tracking info.
 type TrackingInfo struct {
-	TrackingNumber            string
-	APIName                   APIName
-	LastFetchedAt             time.Time
+	TrackingNumber string
+	APIName        APIName
+	LastFetchedAt  time.Time
+	// OriginCountry and DestinationCountry are ISO 3166-1 alpha-2 codes, or empty if carrier doesn't tell
+	// or we can't tell which country carrier means. Parsers fill them with whatever carrier says,
+	// and service normalizes them, keeping what carrier said in OriginCountryRaw and DestinationCountryRaw
 	OriginCountry             string
 	DestinationCountry        string
+	OriginCountryRaw          string
+	DestinationCountryRaw     string
 	Events                    []TrackingEvent
 	AdditionalTrackingNumbers []string
+	// ETA is when parcel is expected to be delivered, if carrier told us or we can estimate it
+	ETA *ETA
 }
 
+// IsDelivered tells whether parcel has reached the recipient, be it delivered or picked up from a pickup point.
+// Just like TerminalState, it's decided by the latest terminal event, so parcel delivered and then returned is not delivered
 func (ti *TrackingInfo) IsDelivered() bool {
-	// iterating backwards, since, `DELIVERED` event is most likely the last one
-	for i := len(ti.Events) - 1; i >= 0; i-- {
-		if ti.Events[i].Status == TrackingStatusDelivered {
-			return true
-		}
-	}
-	return false
+	return ti.TerminalState().IsDelivered()
 }
 
 type TrackingEvent struct {
+	// Time is in the timezone of the place event happened at, if carrier tells it, and in UTC otherwise
 	Time        time.Time
 	Description string
 	Status      TrackingStatus
+	// Location is where event happened, if carrier tells it
+	Location *Location
+	// RawCode is how carrier itself calls the event, e.g. cainiao's action code.
+	// It's what Status is mapped from, so it's the thing to look at when Status is unknown
+	RawCode string
+	// RawDescription is event description exactly as carrier gave it, before any normalization
+	RawDescription string
+}
+
+// Location is a place where tracking event happened. Carriers are rarely precise, so any field can be empty
+type Location struct {
+	City string
+	// Country is ISO 3166-1 alpha-2 code, e.g. "CN"
+	Country string
+	// Facility is e.g. sorting center or post office
+	Facility string
 }
 
 type TrackingStatus string
@@ -53,7 +77,15 @@ const (
 	TrackingStatusArrivedAtCustoms              TrackingStatus = "ARRIVED_AT_CUSTOMS"
 	TrackingStatusDepartedFromCustoms           TrackingStatus = "DEPARTED_FROM_CUSTOMS"
 	TrackingStatusExportCustomsClearanceSuccess TrackingStatus = "EXPORT_CUSTOMS_CLEARANCE_SUCCESS"
+	TrackingStatusOutForDelivery                TrackingStatus = "OUT_FOR_DELIVERY"
+	TrackingStatusReadyForPickup                TrackingStatus = "READY_FOR_PICKUP" // at a locker or any other pickup point
 	TrackingStatusDelivered                     TrackingStatus = "DELIVERED"
+	TrackingStatusPickedUpFromLocker            TrackingStatus = "PICKED_UP_FROM_LOCKER" // or from any other pickup point
+	TrackingStatusReturnedToSender              TrackingStatus = "RETURNED_TO_SENDER"
+	TrackingStatusLost                          TrackingStatus = "LOST"
+	TrackingStatusDestroyed                     TrackingStatus = "DESTROYED"
+	TrackingStatusSeizedByCustoms               TrackingStatus = "SEIZED_BY_CUSTOMS"
+	TrackingStatusCancelled                     TrackingStatus = "CANCELLED"
 	TrackingStatusUnknown                       TrackingStatus = "UNKNOWN"
 )
 
@@ -68,7 +100,33 @@ type PostalApiResponse struct {
 	FirstFetchedAt time.Time
 	LastFetchedAt  time.Time
 	ResponseBody   []byte
-	Status         ApiResponseStatus
+	// ResponseHash is a hex-encoded SHA-256 of ResponseBody, as computed by storage.
+	// Might be empty for responses that were never stored, use Hash() to get it anyway
+	ResponseHash string
+	Status       ApiResponseStatus
+	// IsFinal indicates that, according to this response, parcel has reached any terminal state
+	// (delivered, picked up, returned, lost, cancelled; see TerminalState), so there is no need to ever refresh it again
+	IsFinal bool
+	// RetryAfter is how long API asked us to wait before asking again.
+	// Only makes sense for StatusRateLimitExceeded, and is never stored.
+	RetryAfter time.Duration
+	// Language is only set for localized responses, that are only used to describe events in that language
+	// (see Localizer). Regular responses are in DefaultLanguage
+	Language string
+}
+
+// Hash returns hash of the response body, so that responses can be compared without comparing bodies
+func (r PostalApiResponse) Hash() string {
+	if r.ResponseHash != "" {
+		return r.ResponseHash
+	}
+	return HashResponseBody(r.ResponseBody)
+}
+
+// HashResponseBody is the one and only way to compute PostalApiResponse.ResponseHash
+func HashResponseBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
 }
 
 type ApiResponseStatus string
@@ -78,4 +136,22 @@ const (
 	StatusRateLimitExceeded ApiResponseStatus = "rate_limit_exceeded"
 	StatusNotFound          ApiResponseStatus = "not_found"
 	StatusUnknownError      ApiResponseStatus = "unknown_error"
+	// StatusCircuitOpen means API was not even asked, since it's considered down (see circuitbreaker package).
+	// Such response tells nothing about the parcel, and is never stored
+	StatusCircuitOpen ApiResponseStatus = "circuit_open"
 )
+
+// normalizeCountries turns countries parser has given into ISO codes, keeping them as they were in raw fields.
+// Tracking info that is already normalized is left alone
+func normalizeCountries(ti *TrackingInfo) {
+	if ti.OriginCountryRaw != "" || ti.DestinationCountryRaw != "" {
+		return
+	}
+	ti.OriginCountryRaw, ti.OriginCountry = ti.OriginCountry, normalizeCountry(ti.OriginCountry)
+	ti.DestinationCountryRaw, ti.DestinationCountry = ti.DestinationCountry, normalizeCountry(ti.DestinationCountry)
+}
+
+func normalizeCountry(raw string) string {
+	code, _ := countries.Normalize(raw)
+	return code
+}
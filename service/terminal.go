@@ -0,0 +1,78 @@
+package service
+
+import "time"
+
+// TerminalState is how parcel's journey has ended.
+// Once parcel is in a terminal state, nothing is going to happen to it, so there is no point in tracking it anymore
+type TerminalState string
+
+const (
+	// TerminalStateNone means parcel is still on its way
+	TerminalStateNone      TerminalState = ""
+	TerminalStateDelivered TerminalState = "delivered"
+	// TerminalStatePickedUp means recipient has picked parcel up from a locker or a pickup point
+	TerminalStatePickedUp TerminalState = "picked_up"
+	TerminalStateReturned TerminalState = "returned"
+	// TerminalStateLost means parcel is not coming, be it lost, destroyed or seized by customs
+	TerminalStateLost      TerminalState = "lost"
+	TerminalStateCancelled TerminalState = "cancelled"
+)
+
+// IsDelivered tells whether parcel has reached the recipient in this state, be it delivered or picked up
+func (s TerminalState) IsDelivered() bool {
+	return s == TerminalStateDelivered || s == TerminalStatePickedUp
+}
+
+// TerminalState tells which terminal state, if any, event of this status puts parcel into
+func (s TrackingStatus) TerminalState() TerminalState {
+	switch s {
+	case TrackingStatusDelivered:
+		return TerminalStateDelivered
+	case TrackingStatusPickedUpFromLocker:
+		return TerminalStatePickedUp
+	case TrackingStatusReturnedToSender:
+		return TerminalStateReturned
+	case TrackingStatusLost, TrackingStatusDestroyed, TrackingStatusSeizedByCustoms:
+		return TerminalStateLost
+	case TrackingStatusCancelled:
+		return TerminalStateCancelled
+	default:
+		return TerminalStateNone
+	}
+}
+
+// TerminalState is the state of the most recent terminal event, if any.
+// Events are not assumed to be sorted, since carriers list them in different orders
+func (ti *TrackingInfo) TerminalState() TerminalState {
+	i := latestTerminalEvent(len(ti.Events), func(i int) (time.Time, TrackingStatus) {
+		return ti.Events[i].Time, ti.Events[i].Status
+	})
+	if i < 0 {
+		return TerminalStateNone
+	}
+	return ti.Events[i].Status.TerminalState()
+}
+
+// latestTerminalEvent returns index of the most recent of count events that puts parcel into terminal state,
+// or -1 if there's none. It's the one rule for both a single API's track and the merged timeline:
+// whatever happened last wins, e.g. parcel that was delivered and then returned is returned.
+// Of events that happened at the same time, the last one wins
+func latestTerminalEvent(count int, event func(i int) (time.Time, TrackingStatus)) int {
+	latest := -1
+	var latestTime time.Time
+	for i := 0; i < count; i++ {
+		t, status := event(i)
+		if status.TerminalState() == TerminalStateNone {
+			continue
+		}
+		if latest < 0 || !t.Before(latestTime) {
+			latest, latestTime = i, t
+		}
+	}
+	return latest
+}
+
+// IsTerminal tells whether parcel has reached any of the terminal states, so there's no need to track it anymore
+func (ti *TrackingInfo) IsTerminal() bool {
+	return ti.TerminalState() != TerminalStateNone
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+)
+
+func TestTerminalState(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2023, 1, 1, hour, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("statuses", func(t *testing.T) {
+		expected := map[service.TrackingStatus]service.TerminalState{
+			service.TrackingStatusDelivered:          service.TerminalStateDelivered,
+			service.TrackingStatusPickedUpFromLocker: service.TerminalStatePickedUp,
+			service.TrackingStatusReturnedToSender:   service.TerminalStateReturned,
+			service.TrackingStatusLost:               service.TerminalStateLost,
+			service.TrackingStatusDestroyed:          service.TerminalStateLost,
+			service.TrackingStatusSeizedByCustoms:    service.TerminalStateLost,
+			service.TrackingStatusCancelled:          service.TerminalStateCancelled,
+			service.TrackingStatusArrivedAtCustoms:   service.TerminalStateNone,
+			service.TrackingStatusUnknown:            service.TerminalStateNone,
+		}
+		for status, state := range expected {
+			if actual := status.TerminalState(); actual != state {
+				t.Fatalf("expected %s to be %q, got %q", status, state, actual)
+			}
+		}
+	})
+
+	t.Run("latest terminal event wins regardless of order", func(t *testing.T) {
+		info := &service.TrackingInfo{Events: []service.TrackingEvent{
+			{Time: at(5), Status: service.TrackingStatusReturnedToSender},
+			{Time: at(1), Status: service.TrackingStatusLost},
+			{Time: at(7), Status: service.TrackingStatusArrivedAtSortingCenter},
+		}}
+		if state := info.TerminalState(); state != service.TerminalStateReturned || !info.IsTerminal() {
+			t.Fatalf("expected parcel to be returned, got %q", state)
+		}
+	})
+
+	t.Run("picked up parcel is delivered, returned one is not", func(t *testing.T) {
+		pickedUp := &service.TrackingInfo{Events: []service.TrackingEvent{
+			{Time: at(1), Status: service.TrackingStatusReadyForPickup},
+			{Time: at(2), Status: service.TrackingStatusPickedUpFromLocker},
+		}}
+		if !pickedUp.IsDelivered() {
+			t.Fatalf("expected picked up parcel to be delivered")
+		}
+		returned := &service.TrackingInfo{Events: []service.TrackingEvent{
+			{Time: at(1), Status: service.TrackingStatusDelivered},
+			{Time: at(2), Status: service.TrackingStatusReturnedToSender},
+		}}
+		if returned.IsDelivered() {
+			t.Fatalf("expected parcel delivered and then returned not to be delivered")
+		}
+	})
+
+	t.Run("no terminal events", func(t *testing.T) {
+		info := &service.TrackingInfo{Events: []service.TrackingEvent{
+			{Time: at(1), Status: service.TrackingStatusAcceptedByCarrier},
+		}}
+		if info.IsTerminal() {
+			t.Fatalf("expected parcel to be on its way, got %q", info.TerminalState())
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DefaultMergeTolerance is how far apart in time events reported by different APIs
+// can be while still being considered the same event.
+// Carriers tend to report the same scan with a slight delay, or in a different timezone-less format.
+const DefaultMergeTolerance = 2 * time.Hour
+
+// MergedTimeline is a single timeline of a parcel built from tracks of all APIs
+type MergedTimeline struct {
+	Events []MergedEvent
+	// CurrentStatus is the status of the latest event, unless parcel has reached terminal state
+	// according to any of the APIs, in which case it's the status of the latest terminal event.
+	// TerminalState follows the same rule as TrackingInfo.TerminalState, so they never contradict each other
+	CurrentStatus TrackingStatus
+	// IsDelivered is true for parcels picked up by the recipient as well, see TerminalState.IsDelivered
+	IsDelivered   bool
+	TerminalState TerminalState
+}
+
+// MergedEvent is an event of a merged timeline.
+// APINames lists all APIs that reported this event, in order of their reports.
+type MergedEvent struct {
+	Time        time.Time
+	Description string
+	Status      TrackingStatus
+	// Location is told by the first API that knows it
+	Location *Location
+	APINames []APIName
+}
+
+// MergeTimeline merges events from all tracking infos into a single timeline sorted by time.
+// Events of different APIs that have the same status, similar descriptions
+// and are no more than tolerance apart are collapsed into one.
+// Events of the same API are never collapsed, since API knows better.
+func MergeTimeline(trackingInfos []*TrackingInfo, tolerance time.Duration) *MergedTimeline {
+	type sourcedEvent struct {
+		TrackingEvent
+		apiName APIName
+	}
+
+	var all []sourcedEvent
+	for _, ti := range trackingInfos {
+		for _, e := range ti.Events {
+			all = append(all, sourcedEvent{TrackingEvent: e, apiName: ti.APIName})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if !all[i].Time.Equal(all[j].Time) {
+			return all[i].Time.Before(all[j].Time)
+		}
+		return all[i].apiName < all[j].apiName
+	})
+
+	timeline := &MergedTimeline{Events: []MergedEvent{}, CurrentStatus: TrackingStatusUnknown}
+
+	for _, e := range all {
+		if idx := findDuplicate(timeline.Events, e.TrackingEvent, e.apiName, tolerance); idx >= 0 {
+			timeline.Events[idx].APINames = append(timeline.Events[idx].APINames, e.apiName)
+			if timeline.Events[idx].Location == nil {
+				timeline.Events[idx].Location = e.Location
+			}
+			continue
+		}
+		timeline.Events = append(timeline.Events, MergedEvent{
+			Time:        e.Time,
+			Description: e.Description,
+			Status:      e.Status,
+			Location:    e.Location,
+			APINames:    []APIName{e.apiName},
+		})
+	}
+
+	if len(timeline.Events) > 0 {
+		timeline.CurrentStatus = timeline.Events[len(timeline.Events)-1].Status
+	}
+	latestTerminal := latestTerminalEvent(len(timeline.Events), func(i int) (time.Time, TrackingStatus) {
+		return timeline.Events[i].Time, timeline.Events[i].Status
+	})
+	if latestTerminal >= 0 {
+		timeline.CurrentStatus = timeline.Events[latestTerminal].Status
+		timeline.TerminalState = timeline.CurrentStatus.TerminalState()
+		timeline.IsDelivered = timeline.TerminalState.IsDelivered()
+	}
+
+	return timeline
+}
+
+// findDuplicate looks back through already merged events (which are sorted by time)
+// for an event that is the same as the given one, reported by some other API.
+// Returns -1 if there is none.
+func findDuplicate(merged []MergedEvent, event TrackingEvent, apiName APIName, tolerance time.Duration) int {
+	for i := len(merged) - 1; i >= 0; i-- {
+		m := merged[i]
+		if event.Time.Sub(m.Time) > tolerance {
+			break
+		}
+		if m.Status != event.Status || !similarDescriptions(m.Description, event.Description) {
+			continue
+		}
+		reportedByThisAPI := false
+		for _, n := range m.APINames {
+			if n == apiName {
+				reportedByThisAPI = true
+				break
+			}
+		}
+		if !reportedByThisAPI {
+			return i
+		}
+	}
+	return -1
+}
+
+// similarDescriptions is true when descriptions, ignoring case and punctuation,
+// are the same, one contains the other, or they share at least half of their words
+func similarDescriptions(a, b string) bool {
+	wordsA, wordsB := descriptionWords(a), descriptionWords(b)
+	normA, normB := strings.Join(wordsA, " "), strings.Join(wordsB, " ")
+	if normA == normB {
+		return true
+	}
+	if normA == "" || normB == "" {
+		return false
+	}
+	if strings.Contains(normA, normB) || strings.Contains(normB, normA) {
+		return true
+	}
+
+	setA := make(map[string]struct{}, len(wordsA))
+	for _, w := range wordsA {
+		setA[w] = struct{}{}
+	}
+	union := make(map[string]struct{}, len(wordsA)+len(wordsB))
+	for w := range setA {
+		union[w] = struct{}{}
+	}
+	common := 0
+	for _, w := range wordsB {
+		if _, seen := union[w]; seen {
+			if _, inA := setA[w]; inA {
+				common++
+				delete(setA, w) // count each common word once
+			}
+			continue
+		}
+		union[w] = struct{}{}
+	}
+	return len(union) > 0 && common*2 >= len(union)
+}
+
+func descriptionWords(description string) []string {
+	return strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
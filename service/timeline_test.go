@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+)
+
+func TestMergeTimeline(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2023, 1, 1, hour, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("collapses near-duplicate events of different APIs", func(t *testing.T) {
+		timeline := service.MergeTimeline([]*service.TrackingInfo{
+			{
+				APIName: "cainiao",
+				Events: []service.TrackingEvent{
+					{Time: at(1), Description: "Accepted by carrier", Status: service.TrackingStatusAcceptedByCarrier},
+					{Time: at(5), Description: "Arrived at customs", Status: service.TrackingStatusArrivedAtCustoms},
+				},
+			},
+			{
+				APIName: "local_post",
+				Events: []service.TrackingEvent{
+					{Time: at(6), Description: "Parcel arrived at customs.", Status: service.TrackingStatusArrivedAtCustoms},
+					{Time: at(20), Description: "Delivered to recipient", Status: service.TrackingStatusDelivered},
+				},
+			},
+		}, 2*time.Hour)
+
+		expected := []service.MergedEvent{
+			{Time: at(1), Description: "Accepted by carrier", Status: service.TrackingStatusAcceptedByCarrier, APINames: []service.APIName{"cainiao"}},
+			{Time: at(5), Description: "Arrived at customs", Status: service.TrackingStatusArrivedAtCustoms, APINames: []service.APIName{"cainiao", "local_post"}},
+			{Time: at(20), Description: "Delivered to recipient", Status: service.TrackingStatusDelivered, APINames: []service.APIName{"local_post"}},
+		}
+		if !reflect.DeepEqual(timeline.Events, expected) {
+			t.Fatalf("expected %+v, got %+v", expected, timeline.Events)
+		}
+		if timeline.CurrentStatus != service.TrackingStatusDelivered || !timeline.IsDelivered {
+			t.Fatalf("expected parcel to be delivered, got %s", timeline.CurrentStatus)
+		}
+	})
+
+	t.Run("keeps events outside of tolerance window or with different status", func(t *testing.T) {
+		timeline := service.MergeTimeline([]*service.TrackingInfo{
+			{
+				APIName: "api1",
+				Events: []service.TrackingEvent{
+					{Time: at(1), Description: "Arrived at customs", Status: service.TrackingStatusArrivedAtCustoms},
+					{Time: at(10), Description: "Departed from customs", Status: service.TrackingStatusDepartedFromCustoms},
+				},
+			},
+			{
+				APIName: "api2",
+				Events: []service.TrackingEvent{
+					{Time: at(4), Description: "Arrived at customs", Status: service.TrackingStatusArrivedAtCustoms},
+					{Time: at(10), Description: "Departed from customs", Status: service.TrackingStatusUnknown},
+				},
+			},
+		}, 2*time.Hour)
+
+		if len(timeline.Events) != 4 {
+			t.Fatalf("expected 4 events, got %+v", timeline.Events)
+		}
+		if timeline.IsDelivered {
+			t.Fatalf("expected parcel not to be delivered")
+		}
+		// api2's event sorts last among simultaneous ones
+		if timeline.CurrentStatus != service.TrackingStatusUnknown {
+			t.Fatalf("expected current status to be the latest one, got %s", timeline.CurrentStatus)
+		}
+	})
+
+	t.Run("does not collapse events of the same API", func(t *testing.T) {
+		timeline := service.MergeTimeline([]*service.TrackingInfo{
+			{
+				APIName: "api1",
+				Events: []service.TrackingEvent{
+					{Time: at(1), Description: "In transit", Status: service.TrackingStatusUnknown},
+					{Time: at(2), Description: "In transit", Status: service.TrackingStatusUnknown},
+				},
+			},
+		}, 2*time.Hour)
+
+		if len(timeline.Events) != 2 {
+			t.Fatalf("expected 2 events, got %+v", timeline.Events)
+		}
+	})
+
+	t.Run("reports terminal state other than delivery", func(t *testing.T) {
+		timeline := service.MergeTimeline([]*service.TrackingInfo{
+			{
+				APIName: "api1",
+				Events: []service.TrackingEvent{
+					{Time: at(1), Description: "Returned to sender", Status: service.TrackingStatusReturnedToSender},
+					{Time: at(2), Description: "In transit", Status: service.TrackingStatusUnknown},
+				},
+			},
+		}, 2*time.Hour)
+
+		if timeline.IsDelivered || timeline.TerminalState != service.TerminalStateReturned {
+			t.Fatalf("expected parcel to be returned, got %q", timeline.TerminalState)
+		}
+		if timeline.CurrentStatus != service.TrackingStatusReturnedToSender {
+			t.Fatalf("expected current status to be of the terminal event, got %s", timeline.CurrentStatus)
+		}
+	})
+
+	t.Run("the latest terminal event wins, same as for a single API", func(t *testing.T) {
+		trackingInfos := []*service.TrackingInfo{
+			{
+				APIName: "api1",
+				Events:  []service.TrackingEvent{{Time: at(1), Description: "Delivered", Status: service.TrackingStatusDelivered}},
+			},
+			{
+				APIName: "api2",
+				Events:  []service.TrackingEvent{{Time: at(5), Description: "Returned", Status: service.TrackingStatusReturnedToSender}},
+			},
+		}
+		timeline := service.MergeTimeline(trackingInfos, 2*time.Hour)
+
+		if timeline.IsDelivered ||
+			timeline.TerminalState != service.TerminalStateReturned ||
+			timeline.CurrentStatus != service.TrackingStatusReturnedToSender {
+			t.Fatalf("expected parcel delivered and then returned to be returned, got %+v", timeline)
+		}
+
+		// the same events told by a single API
+		single := &service.TrackingInfo{Events: append(trackingInfos[0].Events, trackingInfos[1].Events...)}
+		if single.TerminalState() != timeline.TerminalState {
+			t.Fatalf("expected merged timeline to agree with a single API, got %q and %q", timeline.TerminalState, single.TerminalState())
+		}
+	})
+
+	t.Run("keeps location of the first API that knows it", func(t *testing.T) {
+		shenzhen := &service.Location{City: "Shenzhen", Country: "CN"}
+		timeline := service.MergeTimeline([]*service.TrackingInfo{
+			{
+				APIName: "api1",
+				Events:  []service.TrackingEvent{{Time: at(1), Description: "Accepted", Status: service.TrackingStatusAcceptedByCarrier}},
+			},
+			{
+				APIName: "api2",
+				Events:  []service.TrackingEvent{{Time: at(2), Description: "Accepted", Status: service.TrackingStatusAcceptedByCarrier, Location: shenzhen}},
+			},
+		}, 2*time.Hour)
+
+		if len(timeline.Events) != 1 || timeline.Events[0].Location != shenzhen {
+			t.Fatalf("expected a single event in %+v, got %+v", shenzhen, timeline.Events)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		timeline := service.MergeTimeline(nil, 2*time.Hour)
+		if len(timeline.Events) != 0 || timeline.CurrentStatus != service.TrackingStatusUnknown {
+			t.Fatalf("unexpected timeline: %+v", timeline)
+		}
+	})
+}
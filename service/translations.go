@@ -0,0 +1,72 @@
+package service
+
+// statusTranslations describe statuses for events carriers can't describe in the language themselves.
+// There's none for DefaultLanguage, since carriers always describe events in it.
+// Unknown status is not there on purpose: carrier's own description, even in a foreign language,
+// tells more than "unknown"
+var statusTranslations = map[string]map[TrackingStatus]string{
+	"ru": {
+		TrackingStatusShipmentInfoReceived:          "Получена информация об отправлении",
+		TrackingStatusPackagingComplete:             "Упаковка завершена",
+		TrackingStatusDispatchedFromWarehouse:       "Отправлено со склада",
+		TrackingStatusWMSConfirmed:                  "Подтверждено складом",
+		TrackingStatusArrivedAtSortingCenter:        "Прибыло в сортировочный центр",
+		TrackingStatusAcceptedByCarrier:             "Принято перевозчиком",
+		TrackingStatusDepartedFromSortingCenter:     "Покинуло сортировочный центр",
+		TrackingStatusArrivedAtDepartureHub:         "Прибыло в транспортный узел отправления",
+		TrackingStatusTransitPortRerouteCb:          "Перенаправлено в транзитном порту",
+		TrackingStatusExportCustomsClearanceStarted: "Начато экспортное таможенное оформление",
+		TrackingStatusLeavignDepartureRegion:        "Покидает страну отправления",
+		TrackingStatusImportCustomsClearanceStarted: "Начато импортное таможенное оформление",
+		TrackingStatusImportCustomsClearanceSuccess: "Импортное таможенное оформление завершено",
+		TrackingStatusDepartedOriginRegion:          "Покинуло страну отправления",
+		TrackingStatusArrivedAtLinehaulOffice:       "Прибыло в магистральный офис",
+		TrackingStatusArrivedAtCustoms:              "Прибыло на таможню",
+		TrackingStatusDepartedFromCustoms:           "Покинуло таможню",
+		TrackingStatusExportCustomsClearanceSuccess: "Экспортное таможенное оформление завершено",
+		TrackingStatusOutForDelivery:                "Передано курьеру для доставки",
+		TrackingStatusReadyForPickup:                "Готово к выдаче",
+		TrackingStatusDelivered:                     "Вручено",
+		TrackingStatusPickedUpFromLocker:            "Получено в пункте выдачи",
+		TrackingStatusReturnedToSender:              "Возвращено отправителю",
+		TrackingStatusLost:                          "Утеряно",
+		TrackingStatusDestroyed:                     "Уничтожено",
+		TrackingStatusSeizedByCustoms:               "Задержано таможней",
+		TrackingStatusCancelled:                     "Отменено",
+	},
+	"he": {
+		TrackingStatusShipmentInfoReceived:          "התקבל מידע על המשלוח",
+		TrackingStatusPackagingComplete:             "האריזה הושלמה",
+		TrackingStatusDispatchedFromWarehouse:       "נשלח מהמחסן",
+		TrackingStatusWMSConfirmed:                  "אושר על ידי המחסן",
+		TrackingStatusArrivedAtSortingCenter:        "הגיע למרכז המיון",
+		TrackingStatusAcceptedByCarrier:             "התקבל אצל המוביל",
+		TrackingStatusDepartedFromSortingCenter:     "יצא ממרכז המיון",
+		TrackingStatusArrivedAtDepartureHub:         "הגיע למרכז השילוח",
+		TrackingStatusTransitPortRerouteCb:          "נותב מחדש בנמל המעבר",
+		TrackingStatusExportCustomsClearanceStarted: "החל שחרור מכס ביצוא",
+		TrackingStatusLeavignDepartureRegion:        "עוזב את ארץ המוצא",
+		TrackingStatusImportCustomsClearanceStarted: "החל שחרור מכס ביבוא",
+		TrackingStatusImportCustomsClearanceSuccess: "שחרור מכס ביבוא הושלם",
+		TrackingStatusDepartedOriginRegion:          "יצא מארץ המוצא",
+		TrackingStatusArrivedAtLinehaulOffice:       "הגיע למשרד ההובלה",
+		TrackingStatusArrivedAtCustoms:              "הגיע למכס",
+		TrackingStatusDepartedFromCustoms:           "יצא מהמכס",
+		TrackingStatusExportCustomsClearanceSuccess: "שחרור מכס ביצוא הושלם",
+		TrackingStatusOutForDelivery:                "יצא למסירה",
+		TrackingStatusReadyForPickup:                "מוכן לאיסוף",
+		TrackingStatusDelivered:                     "נמסר",
+		TrackingStatusPickedUpFromLocker:            "נאסף",
+		TrackingStatusReturnedToSender:              "הוחזר לשולח",
+		TrackingStatusLost:                          "אבד",
+		TrackingStatusDestroyed:                     "הושמד",
+		TrackingStatusSeizedByCustoms:               "עוכב במכס",
+		TrackingStatusCancelled:                     "בוטל",
+	},
+}
+
+// translateStatus describes status in the language, if we know how
+func translateStatus(status TrackingStatus, language string) (string, bool) {
+	description, ok := statusTranslations[language][status]
+	return description, ok
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hori-ryota/zaperr"
+	"go.uber.org/zap"
+)
+
+// unknownCodesBatchSize is how many stored responses are loaded at once when looking for unknown codes
+const unknownCodesBatchSize = 500
+
+// unknownCodeSamples is how many tracking numbers are given as an example of an unknown code
+const unknownCodeSamples = 5
+
+// UnknownCode is a raw carrier code that parser doesn't know how to map to a status
+type UnknownCode struct {
+	APIName APIName
+	Code    string
+	// SampleDescription is raw description of one of the events with this code, to give an idea what it means
+	SampleDescription string
+	// TrackingNumbers is how many parcels have ever had events with this code
+	TrackingNumbers int
+	// SampleTrackingNumbers are a few of those parcels, to look at their history
+	SampleTrackingNumbers []string
+}
+
+// UnknownCodesReport is unknown codes as of the latest refresh
+type UnknownCodesReport struct {
+	Codes []*UnknownCode
+	// RefreshedAt is when stored responses were last looked through, zero if they have not been yet
+	RefreshedAt time.Time
+}
+
+// unknownCodesCache keeps the latest UnknownCodesReport, so that asking for it doesn't parse every stored response
+type unknownCodesCache struct {
+	mu     sync.RWMutex
+	report UnknownCodesReport
+}
+
+func (c *unknownCodesCache) replace(report UnknownCodesReport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.report = report
+}
+
+func (c *unknownCodesCache) get() UnknownCodesReport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.report
+}
+
+// GetUnknownCodes tells which carrier codes are not mapped to a status yet, most widespread first,
+// as of the latest RefreshUnknownCodes
+func (svc *Impl) GetUnknownCodes(_ context.Context) (*UnknownCodesReport, error) {
+	report := svc.unknownCodes.get()
+	return &report, nil
+}
+
+// RunUnknownCodesRefresh refreshes unknown codes every interval, until context is done
+func (svc *Impl) RunUnknownCodesRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := svc.RefreshUnknownCodes(ctx); err != nil {
+			svc.log.Error("failed to refresh unknown codes", zaperr.ToField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			svc.log.Info("unknown codes refresh stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshUnknownCodes parses all the stored responses to find out which carrier codes are not mapped to a status yet.
+// It's expensive, so it's meant to be run periodically in background.
+func (svc *Impl) RefreshUnknownCodes(ctx context.Context) error {
+	startedAt := svc.now()
+	var result []*UnknownCode
+	for _, apiName := range svc.sortedAPINames() {
+		codes := map[string]*UnknownCode{}
+		trackingNumbers := map[string]map[string]struct{}{}
+
+		err := svc.walkResponses(ctx, apiName, unknownCodesBatchSize, func(resp *PostalApiResponse) error {
+			if resp.Status != StatusSuccess {
+				return nil
+			}
+			parsed, err := svc.parseApiResponse(*resp)
+			if err != nil || parsed == nil {
+				return nil
+			}
+			for _, e := range parsed.Events {
+				if e.Status != TrackingStatusUnknown {
+					continue
+				}
+				code, exists := codes[e.RawCode]
+				if !exists {
+					code = &UnknownCode{APIName: apiName, Code: e.RawCode, SampleDescription: e.RawDescription}
+					codes[e.RawCode] = code
+					trackingNumbers[e.RawCode] = map[string]struct{}{}
+				}
+				if _, seen := trackingNumbers[e.RawCode][resp.TrackingNumber]; seen {
+					continue
+				}
+				trackingNumbers[e.RawCode][resp.TrackingNumber] = struct{}{}
+				code.TrackingNumbers++
+				if len(code.SampleTrackingNumbers) < unknownCodeSamples {
+					code.SampleTrackingNumbers = append(code.SampleTrackingNumbers, resp.TrackingNumber)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, code := range codes {
+			result = append(result, code)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].TrackingNumbers != result[j].TrackingNumbers {
+			return result[i].TrackingNumbers > result[j].TrackingNumbers
+		}
+		if result[i].APIName != result[j].APIName {
+			return result[i].APIName < result[j].APIName
+		}
+		return result[i].Code < result[j].Code
+	})
+	svc.unknownCodes.replace(UnknownCodesReport{Codes: result, RefreshedAt: startedAt})
+
+	svc.log.Info("refreshed unknown codes", zap.Int("codes", len(result)))
+	return nil
+}
+
+// unknownCodes returns distinct raw codes of events that parser could not map to a status
+func unknownCodes(info *TrackingInfo) []string {
+	var codes []string
+	for _, e := range info.Events {
+		if e.Status == TrackingStatusUnknown && !slices.Contains(codes, e.RawCode) {
+			codes = append(codes, e.RawCode)
+		}
+	}
+	return codes
+}
@@ -0,0 +1,92 @@
+package service_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/service/mocks"
+	"go.uber.org/zap"
+)
+
+func TestServiceGetUnknownCodes(t *testing.T) {
+	storage := mocks.NewStorageMock(t)
+	api1 := mocks.NewPostalAPIMock(t)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	svc := service.NewService(
+		map[service.APIName]service.PostalAPI{api1Name: api1},
+		storage,
+		promMetrics,
+		time.Hour,
+		time.Hour,
+		time.Hour,
+		time.Second,
+		30*24*time.Hour,
+		time.Minute,
+		zap.NewNop(),
+		func() time.Time { return now },
+	)
+
+	known := service.TrackingEvent{Status: service.TrackingStatusDelivered, RawCode: "DELIVERED"}
+	rare := service.TrackingEvent{Status: service.TrackingStatusUnknown, RawCode: "RARE", RawDescription: "rare"}
+	common := service.TrackingEvent{Status: service.TrackingStatusUnknown, RawCode: "COMMON", RawDescription: "common"}
+	eventsByBody := map[string][]service.TrackingEvent{
+		"rare":   {rare, common},
+		"common": {common, known},
+	}
+	api1.ParseMock.Set(func(resp service.PostalApiResponse) (*service.TrackingInfo, error) {
+		return &service.TrackingInfo{TrackingNumber: resp.TrackingNumber, Events: eventsByBody[string(resp.ResponseBody)]}, nil
+	})
+
+	responses := []*service.PostalApiResponse{
+		{ID: 1, TrackingNumber: "1", APIName: api1Name, Status: service.StatusSuccess, ResponseBody: []byte("rare")},
+		// history of the same parcel should not count twice
+		{ID: 2, TrackingNumber: "1", APIName: api1Name, Status: service.StatusSuccess, ResponseBody: []byte("common")},
+		{ID: 3, TrackingNumber: "2", APIName: api1Name, Status: service.StatusSuccess, ResponseBody: []byte("common")},
+		{ID: 4, TrackingNumber: "3", APIName: api1Name, Status: service.StatusNotFound, ResponseBody: []byte("rare")},
+	}
+	storage.GetResponsesAfterMock.Set(func(_ context.Context, _ service.APIName, afterID int64, limit int) ([]*service.PostalApiResponse, error) {
+		var page []*service.PostalApiResponse
+		for _, resp := range responses {
+			if resp.ID > afterID && len(page) < limit {
+				page = append(page, resp)
+			}
+		}
+		return page, nil
+	})
+
+	report, err := svc.GetUnknownCodes(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get unknown codes: %v", err)
+	}
+	if len(report.Codes) != 0 || !report.RefreshedAt.IsZero() {
+		t.Fatalf("expected nothing before refresh, got %+v", report)
+	}
+
+	if err := svc.RefreshUnknownCodes(context.Background()); err != nil {
+		t.Fatalf("failed to refresh unknown codes: %v", err)
+	}
+	// responses are only parsed on refresh, not every time unknown codes are asked for
+	storage.GetResponsesAfterMock.Set(func(_ context.Context, _ service.APIName, _ int64, _ int) ([]*service.PostalApiResponse, error) {
+		t.Fatalf("responses should not be loaded to get unknown codes")
+		return nil, nil
+	})
+	report, err = svc.GetUnknownCodes(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get unknown codes: %v", err)
+	}
+	if !report.RefreshedAt.Equal(now) {
+		t.Fatalf("expected refreshed at %v, got %v", now, report.RefreshedAt)
+	}
+	codes := report.Codes
+
+	expected := []*service.UnknownCode{
+		{APIName: api1Name, Code: "COMMON", SampleDescription: "common", TrackingNumbers: 2, SampleTrackingNumbers: []string{"1", "2"}},
+		{APIName: api1Name, Code: "RARE", SampleDescription: "rare", TrackingNumbers: 1, SampleTrackingNumbers: []string{"1"}},
+	}
+	if !reflect.DeepEqual(codes, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, codes)
+	}
+}
@@ -0,0 +1,72 @@
+package sqlite_storage
+
+import (
+	"context"
+
+	"github.com/dir01/parcels/service"
+	"github.com/hori-ryota/zaperr"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// BackfillResponseHashes hashes and compresses responses that were stored before
+// response_hash column was introduced. It processes batchSize rows per transaction,
+// is safe to interrupt and re-run, and returns how many responses it has updated.
+func BackfillResponseHashes(ctx context.Context, db *sqlx.DB, batchSize int) (int, error) {
+	var updated int
+	var lastID int64
+	for {
+		n, batchLastID, err := backfillBatch(ctx, db, lastID, batchSize)
+		updated += n
+		if err != nil {
+			return updated, err
+		}
+		if n < batchSize {
+			return updated, nil
+		}
+		lastID = batchLastID
+	}
+}
+
+func backfillBatch(ctx context.Context, db *sqlx.DB, afterID int64, batchSize int) (int, int64, error) {
+	zapFields := []zap.Field{zap.Int64("afterID", afterID), zap.Int("batchSize", batchSize)}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, zaperr.Wrap(err, "failed to BeginTxx", zapFields...)
+	}
+	defer tx.Rollback()
+
+	var rows []struct {
+		ID           int64          `db:"id"`
+		ResponseBody compressedBody `db:"response_body"`
+	}
+	err = tx.SelectContext(ctx, &rows, `
+		SELECT id, response_body
+		FROM postal_api_responses
+		WHERE response_hash = '' AND id > ?
+		ORDER BY id
+		LIMIT ?
+	`, afterID, batchSize)
+	if err != nil {
+		return 0, 0, zaperr.Wrap(err, "failed to SelectContext", zapFields...)
+	}
+
+	var lastID int64
+	for _, row := range rows {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE postal_api_responses
+			SET response_body = ?, response_hash = ?
+			WHERE id = ?
+		`, row.ResponseBody, service.HashResponseBody(row.ResponseBody), row.ID)
+		if err != nil {
+			return 0, 0, zaperr.Wrap(err, "failed to update response", append(zapFields, zap.Int64("id", row.ID))...)
+		}
+		lastID = row.ID
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, zaperr.Wrap(err, "failed to Commit", zapFields...)
+	}
+	return len(rows), lastID, nil
+}
@@ -0,0 +1,65 @@
+package sqlite_storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql/driver"
+	"fmt"
+	"io"
+)
+
+// gzipMagic is how every gzip stream starts. Responses are JSON or HTML,
+// so an uncompressed body stored before compression was introduced never starts like that
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// compressedBody is a response body that is stored gzipped,
+// while still being readable from rows that were stored before compression was introduced
+type compressedBody []byte
+
+func (b compressedBody) Value() (driver.Value, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, fmt.Errorf("failed to compress response body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress response body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (b *compressedBody) Scan(src any) error {
+	var raw []byte
+	switch src := src.(type) {
+	case []byte:
+		raw = src
+	case string:
+		raw = []byte(src)
+	case nil:
+		*b = nil
+		return nil
+	default:
+		return fmt.Errorf("unexpected response body type %T", src)
+	}
+
+	if !isCompressed(raw) {
+		// driver might reuse the buffer, so we have to copy it
+		*b = bytes.Clone(raw)
+		return nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("failed to decompress response body: %w", err)
+	}
+	decompressed, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to decompress response body: %w", err)
+	}
+	*b = decompressed
+	return nil
+}
+
+func isCompressed(raw []byte) bool {
+	return bytes.HasPrefix(raw, gzipMagic)
+}
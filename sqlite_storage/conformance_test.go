@@ -0,0 +1,46 @@
+package sqlite_storage_test
+
+import (
+	"testing"
+
+	"github.com/dir01/parcels/retention"
+	"github.com/dir01/parcels/retention/sqlstorage"
+	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/sqlite_storage"
+	"github.com/dir01/parcels/storagetest"
+	"github.com/dir01/parcels/subscriptions"
+	"github.com/jmoiron/sqlx"
+	"github.com/rubenv/sql-migrate"
+)
+
+func newTestDB(t *testing.T) *sqlx.DB {
+	db := sqlx.MustConnect("sqlite3", ":memory:")
+	db.SetMaxOpenConns(1) // every connection to :memory: is a separate database
+	t.Cleanup(func() { _ = db.Close() })
+	migrations := &migrate.FileMigrationSource{
+		Dir: "../db/migrations",
+	}
+	if _, err := migrate.Exec(db.DB, "sqlite3", migrations, migrate.Up); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+	return db
+}
+
+func TestConformance(t *testing.T) {
+	storagetest.TestStorage(t, func(t *testing.T) service.Storage {
+		return sqlite_storage.NewStorage(newTestDB(t))
+	})
+	storagetest.TestRetentionStorage(t, func(t *testing.T) (service.Storage, retention.Storage) {
+		db := newTestDB(t)
+		return sqlite_storage.NewStorage(db), sqlite_storage.NewRetentionStorage(db)
+	})
+	t.Run("retention one response at a time", func(t *testing.T) {
+		storagetest.TestRetentionStorage(t, func(t *testing.T) (service.Storage, retention.Storage) {
+			db := newTestDB(t)
+			return sqlite_storage.NewStorage(db), sqlstorage.New(db, 1)
+		})
+	})
+	storagetest.TestSubscriptionsStorage(t, func(t *testing.T) subscriptions.Storage {
+		return sqlite_storage.NewSubscriptionsStorage(newTestDB(t))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/subscriptions"
 )
 
 type DBRawPostalApiResponse struct {
@@ -12,8 +13,12 @@ type DBRawPostalApiResponse struct {
 	TrackingNumber string          `db:"tracking_number"`
 	FirstFetchedAt int64           `db:"first_fetched_at"`
 	LastFetchedAt  int64           `db:"last_fetched_at"`
-	ResponseBody   []byte          `db:"response_body"`
+	ResponseBody   compressedBody  `db:"response_body"`
+	ResponseHash   string          `db:"response_hash"`
 	Status         string          `db:"status"`
+	IsFinal        bool            `db:"is_final"`
+	// IsLatest is maintained by storage itself, there can only be one latest response per tracking number and API
+	IsLatest bool `db:"is_latest"`
 }
 
 func (r DBRawPostalApiResponse) ToBusinessModel() *service.PostalApiResponse {
@@ -24,7 +29,9 @@ func (r DBRawPostalApiResponse) ToBusinessModel() *service.PostalApiResponse {
 		FirstFetchedAt: fromUnixTime(r.FirstFetchedAt),
 		LastFetchedAt:  fromUnixTime(r.LastFetchedAt),
 		ResponseBody:   r.ResponseBody,
+		ResponseHash:   r.ResponseHash,
 		Status:         service.ApiResponseStatus(r.Status),
+		IsFinal:        r.IsFinal,
 	}
 }
 
@@ -35,6 +42,56 @@ func (r DBRawPostalApiResponse) FromBusinessModel(rawResp *service.PostalApiResp
 	r.FirstFetchedAt = toUnixTime(rawResp.FirstFetchedAt)
 	r.LastFetchedAt = toUnixTime(rawResp.LastFetchedAt)
 	r.ResponseBody = rawResp.ResponseBody
+	r.ResponseHash = service.HashResponseBody(rawResp.ResponseBody)
+	r.Status = string(rawResp.Status)
+	r.IsFinal = rawResp.IsFinal
+	return &r
+}
+
+// DBDueResponse is the part of DBRawPostalApiResponse that is enough to tell when it's due for refresh
+type DBDueResponse struct {
+	TrackingNumber string          `db:"tracking_number"`
+	APIName        service.APIName `db:"api_name"`
+	Status         string          `db:"status"`
+	LastFetchedAt  int64           `db:"last_fetched_at"`
+}
+
+func (r DBDueResponse) ToBusinessModel() *service.PostalApiResponse {
+	return &service.PostalApiResponse{
+		TrackingNumber: r.TrackingNumber,
+		APIName:        r.APIName,
+		Status:         service.ApiResponseStatus(r.Status),
+		LastFetchedAt:  fromUnixTime(r.LastFetchedAt),
+	}
+}
+
+type DBLocalizedResponse struct {
+	TrackingNumber string          `db:"tracking_number"`
+	APIName        service.APIName `db:"api_name"`
+	Language       string          `db:"language"`
+	FetchedAt      int64           `db:"fetched_at"`
+	ResponseBody   compressedBody  `db:"response_body"`
+	Status         string          `db:"status"`
+}
+
+func (r DBLocalizedResponse) ToBusinessModel() *service.PostalApiResponse {
+	return &service.PostalApiResponse{
+		APIName:        r.APIName,
+		TrackingNumber: r.TrackingNumber,
+		FirstFetchedAt: fromUnixTime(r.FetchedAt),
+		LastFetchedAt:  fromUnixTime(r.FetchedAt),
+		ResponseBody:   r.ResponseBody,
+		Status:         service.ApiResponseStatus(r.Status),
+		Language:       r.Language,
+	}
+}
+
+func (r DBLocalizedResponse) FromBusinessModel(rawResp *service.PostalApiResponse) *DBLocalizedResponse {
+	r.TrackingNumber = rawResp.TrackingNumber
+	r.APIName = rawResp.APIName
+	r.Language = rawResp.Language
+	r.FetchedAt = toUnixTime(rawResp.LastFetchedAt)
+	r.ResponseBody = rawResp.ResponseBody
 	r.Status = string(rawResp.Status)
 	return &r
 }
@@ -46,3 +103,72 @@ func toUnixTime(t time.Time) int64 {
 func fromUnixTime(t int64) time.Time {
 	return time.Unix(t, 0)
 }
+
+type DBSubscription struct {
+	ID             int64  `db:"id"`
+	TrackingNumber string `db:"tracking_number"`
+	CallbackURL    string `db:"callback_url"`
+	Secret         string `db:"secret"`
+	CreatedAt      int64  `db:"created_at"`
+}
+
+func (s DBSubscription) ToBusinessModel() *subscriptions.Subscription {
+	return &subscriptions.Subscription{
+		ID:             s.ID,
+		TrackingNumber: s.TrackingNumber,
+		CallbackURL:    s.CallbackURL,
+		Secret:         s.Secret,
+		CreatedAt:      fromUnixTime(s.CreatedAt),
+	}
+}
+
+func (s DBSubscription) FromBusinessModel(sub *subscriptions.Subscription) *DBSubscription {
+	s.ID = sub.ID
+	s.TrackingNumber = sub.TrackingNumber
+	s.CallbackURL = sub.CallbackURL
+	s.Secret = sub.Secret
+	s.CreatedAt = toUnixTime(sub.CreatedAt)
+	return &s
+}
+
+type DBWebhookDelivery struct {
+	ID             int64  `db:"id"`
+	SubscriptionID int64  `db:"subscription_id"`
+	CallbackURL    string `db:"callback_url"`
+	Secret         string `db:"secret"`
+	Payload        []byte `db:"payload"`
+	Status         string `db:"status"`
+	Attempts       int    `db:"attempts"`
+	NextAttemptAt  int64  `db:"next_attempt_at"`
+	LastError      string `db:"last_error"`
+	CreatedAt      int64  `db:"created_at"`
+}
+
+func (d DBWebhookDelivery) ToBusinessModel() *subscriptions.Delivery {
+	return &subscriptions.Delivery{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		CallbackURL:    d.CallbackURL,
+		Secret:         d.Secret,
+		Payload:        d.Payload,
+		Status:         subscriptions.DeliveryStatus(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  fromUnixTime(d.NextAttemptAt),
+		LastError:      d.LastError,
+		CreatedAt:      fromUnixTime(d.CreatedAt),
+	}
+}
+
+func (d DBWebhookDelivery) FromBusinessModel(delivery *subscriptions.Delivery) *DBWebhookDelivery {
+	d.ID = delivery.ID
+	d.SubscriptionID = delivery.SubscriptionID
+	d.CallbackURL = delivery.CallbackURL
+	d.Secret = delivery.Secret
+	d.Payload = delivery.Payload
+	d.Status = string(delivery.Status)
+	d.Attempts = delivery.Attempts
+	d.NextAttemptAt = toUnixTime(delivery.NextAttemptAt)
+	d.LastError = delivery.LastError
+	d.CreatedAt = toUnixTime(delivery.CreatedAt)
+	return &d
+}
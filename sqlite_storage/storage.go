@@ -2,7 +2,8 @@ package sqlite_storage
 
 import (
 	"context"
-	"strings"
+	"database/sql"
+	"errors"
 
 	"github.com/dir01/parcels/service"
 	"github.com/hori-ryota/zaperr"
@@ -24,40 +25,129 @@ func (s sqliteStorage) GetLatest(
 	trackingNumber string,
 	apiNames []service.APIName,
 ) ([]*service.PostalApiResponse, error) {
-	var apiNamesStr []string
-	for _, apiName := range apiNames {
-		apiNamesStr = append(apiNamesStr, string(apiName))
+	if len(apiNames) == 0 {
+		return nil, nil
 	}
 	zapFields := []zap.Field{
 		zap.String("trackingNumber", trackingNumber),
-		zap.Strings("apiNames", apiNamesStr),
+		zap.Any("apiNames", apiNames),
 	}
-	rows, err := s.db.QueryxContext(ctx, `
-		SELECT p1.*
-		FROM postal_api_responses p1
-		JOIN (
-			SELECT api_name, MAX(last_fetched_at) as max_fetched
-			FROM postal_api_responses
-			WHERE tracking_number = ?
-			AND api_name IN (?)
-			GROUP BY api_name
-		) p2 ON p1.api_name = p2.api_name AND p1.last_fetched_at = p2.max_fetched
-		WHERE p1.tracking_number = ?
-		AND p1.api_name IN (?)
-`, trackingNumber, strings.Join(apiNamesStr, ","), trackingNumber, strings.Join(apiNamesStr, ","))
+	query, args, err := sqlx.In(`
+		SELECT *
+		FROM postal_api_responses
+		WHERE tracking_number = ?
+		AND api_name IN (?)
+		AND is_latest = 1
+`, trackingNumber, apiNames)
 	if err != nil {
-		return nil, zaperr.Wrap(err, "failed to QueryxContext", zapFields...)
+		return nil, zaperr.Wrap(err, "failed to build IN query", zapFields...)
 	}
-	defer rows.Close()
 
 	var dbStructs []DBRawPostalApiResponse
-	for rows.Next() {
-		var dbStruct DBRawPostalApiResponse
-		err := rows.StructScan(&dbStruct)
-		if err != nil {
-			return nil, zaperr.Wrap(err, "failed to StructScan", zapFields...)
-		}
-		dbStructs = append(dbStructs, dbStruct)
+	if err := s.db.SelectContext(ctx, &dbStructs, s.db.Rebind(query), args...); err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zapFields...)
+	}
+
+	var businessStructs []*service.PostalApiResponse
+	for _, dbStruct := range dbStructs {
+		businessStructs = append(businessStructs, dbStruct.ToBusinessModel())
+	}
+
+	return businessStructs, nil
+}
+
+func (s sqliteStorage) GetLatestBatch(
+	ctx context.Context,
+	trackingNumbers []string,
+	apiNames []service.APIName,
+) ([]*service.PostalApiResponse, error) {
+	if len(trackingNumbers) == 0 || len(apiNames) == 0 {
+		return nil, nil
+	}
+	zapFields := []zap.Field{
+		zap.Strings("trackingNumbers", trackingNumbers),
+		zap.Any("apiNames", apiNames),
+	}
+	query, args, err := sqlx.In(`
+		SELECT *
+		FROM postal_api_responses
+		WHERE tracking_number IN (?)
+		AND api_name IN (?)
+		AND is_latest = 1
+`, trackingNumbers, apiNames)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to build IN query", zapFields...)
+	}
+
+	var dbStructs []DBRawPostalApiResponse
+	if err := s.db.SelectContext(ctx, &dbStructs, s.db.Rebind(query), args...); err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zapFields...)
+	}
+
+	var businessStructs []*service.PostalApiResponse
+	for _, dbStruct := range dbStructs {
+		businessStructs = append(businessStructs, dbStruct.ToBusinessModel())
+	}
+
+	return businessStructs, nil
+}
+
+// dueForRefreshCondition matches responses due for refresh, given dueForRefreshArgs
+const dueForRefreshCondition = `
+		api_name IN (?)
+		AND is_latest = 1
+		AND last_fetched_at > ?
+		AND is_final = 0
+		AND (
+			(status = ? AND last_fetched_at <= ?)
+			OR (status = ? AND last_fetched_at <= ?)
+			OR (status = ? AND last_fetched_at <= ?)
+		)
+`
+
+func dueForRefreshArgs(query service.RefreshQuery) []any {
+	return []any{
+		query.APINames,
+		toUnixTime(query.NotExpiredSince),
+		service.StatusSuccess, toUnixTime(query.SuccessFetchedBefore),
+		service.StatusNotFound, toUnixTime(query.NotFoundFetchedBefore),
+		service.StatusUnknownError, toUnixTime(query.UnknownErrorFetchedBefore),
+	}
+}
+
+func (s sqliteStorage) GetDueForRefresh(
+	ctx context.Context,
+	query service.RefreshQuery,
+) ([]*service.PostalApiResponse, error) {
+	if len(query.APINames) == 0 {
+		return nil, nil
+	}
+	zapFields := []zap.Field{zap.Any("query", query)}
+	// response is overdue by how long ago it passed the moment it should have been fetched before
+	args := append(dueForRefreshArgs(query),
+		service.StatusSuccess, toUnixTime(query.SuccessFetchedBefore),
+		service.StatusNotFound, toUnixTime(query.NotFoundFetchedBefore),
+		toUnixTime(query.UnknownErrorFetchedBefore),
+		query.Limit,
+	)
+	sqlQuery, args, err := sqlx.In(`
+		SELECT tracking_number, api_name, status, last_fetched_at
+		FROM postal_api_responses
+		WHERE `+dueForRefreshCondition+`
+		ORDER BY CASE status
+			WHEN ? THEN last_fetched_at - ?
+			WHEN ? THEN last_fetched_at - ?
+			ELSE last_fetched_at - ?
+		END
+		LIMIT ?
+`, args...)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to build IN query", zapFields...)
+	}
+
+	var dbStructs []DBDueResponse
+	if err := s.db.SelectContext(ctx, &dbStructs, s.db.Rebind(sqlQuery), args...); err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zapFields...)
 	}
 
 	var businessStructs []*service.PostalApiResponse
@@ -68,19 +158,132 @@ func (s sqliteStorage) GetLatest(
 	return businessStructs, nil
 }
 
+func (s sqliteStorage) CountDueForRefresh(ctx context.Context, query service.RefreshQuery) (int, error) {
+	if len(query.APINames) == 0 {
+		return 0, nil
+	}
+	zapFields := []zap.Field{zap.Any("query", query)}
+	sqlQuery, args, err := sqlx.In(`
+		SELECT COUNT(DISTINCT tracking_number)
+		FROM postal_api_responses
+		WHERE `+dueForRefreshCondition, dueForRefreshArgs(query)...)
+	if err != nil {
+		return 0, zaperr.Wrap(err, "failed to build IN query", zapFields...)
+	}
+
+	var count int
+	if err := s.db.GetContext(ctx, &count, s.db.Rebind(sqlQuery), args...); err != nil {
+		return 0, zaperr.Wrap(err, "failed to GetContext", zapFields...)
+	}
+	return count, nil
+}
+
 func (s sqliteStorage) Insert(ctx context.Context, trackingNumber string, apiName service.APIName, response *service.PostalApiResponse) error {
 	dbStruct := DBRawPostalApiResponse{}.FromBusinessModel(response)
 	dbStruct.TrackingNumber = trackingNumber
 	dbStruct.APIName = apiName
-	_, err := s.db.NamedExecContext(ctx, `
+	zapFields := []zap.Field{zap.Any("dbStruct", dbStruct)}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to BeginTxx", zapFields...)
+	}
+	defer tx.Rollback()
+
+	if err := s.insert(ctx, tx, dbStruct); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return zaperr.Wrap(err, "failed to Commit", zapFields...)
+	}
+	response.ID = dbStruct.ID
+	return nil
+}
+
+func (s sqliteStorage) Upsert(ctx context.Context, trackingNumber string, apiName service.APIName, response *service.PostalApiResponse) error {
+	dbStruct := DBRawPostalApiResponse{}.FromBusinessModel(response)
+	dbStruct.TrackingNumber = trackingNumber
+	dbStruct.APIName = apiName
+	zapFields := []zap.Field{zap.Any("dbStruct", dbStruct)}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to BeginTxx", zapFields...)
+	}
+	defer tx.Rollback()
+
+	var latest DBRawPostalApiResponse
+	err = tx.GetContext(ctx, &latest, `
+		SELECT *
+		FROM postal_api_responses
+		WHERE tracking_number = ? AND api_name = ? AND is_latest = 1
+	`, trackingNumber, apiName)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) || (err == nil && latest.ToBusinessModel().Hash() != dbStruct.ResponseHash):
+		if err := s.insert(ctx, tx, dbStruct); err != nil {
+			return err
+		}
+	case err == nil:
+		// someone has just stored the very same response, so we only need to refresh it
+		_, err := tx.ExecContext(ctx, `
+			UPDATE postal_api_responses
+			SET last_fetched_at = MAX(last_fetched_at, ?), status = ?, is_final = ?
+			WHERE id = ?
+		`, dbStruct.LastFetchedAt, dbStruct.Status, dbStruct.IsFinal, latest.ID)
+		if err != nil {
+			return zaperr.Wrap(err, "failed to update latest response", zapFields...)
+		}
+		dbStruct.ID = latest.ID
+		dbStruct.FirstFetchedAt = latest.FirstFetchedAt
+	default:
+		return zaperr.Wrap(err, "failed to get latest response", zapFields...)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return zaperr.Wrap(err, "failed to Commit", zapFields...)
+	}
+	response.ID = dbStruct.ID
+	response.FirstFetchedAt = fromUnixTime(dbStruct.FirstFetchedAt)
+	return nil
+}
+
+// insert appends response to the history and makes the most recently fetched response the latest one
+func (s sqliteStorage) insert(ctx context.Context, tx *sqlx.Tx, dbStruct *DBRawPostalApiResponse) error {
+	zapFields := []zap.Field{zap.Any("dbStruct", dbStruct)}
+	result, err := tx.NamedExecContext(ctx, `
 		INSERT INTO postal_api_responses 
-		    (api_name, tracking_number, first_fetched_at, last_fetched_at, response_body, status)
+		    (api_name, tracking_number, first_fetched_at, last_fetched_at, response_body, response_hash, status, is_final)
 		VALUES 
-		    (:api_name, :tracking_number, :first_fetched_at, :last_fetched_at, :response_body, :status)
+		    (:api_name, :tracking_number, :first_fetched_at, :last_fetched_at, :response_body, :response_hash, :status, :is_final)
 	`, dbStruct)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to NamedExecContext", zapFields...)
+	}
+	if dbStruct.ID, err = result.LastInsertId(); err != nil {
+		return zaperr.Wrap(err, "failed to get LastInsertId", zapFields...)
+	}
 
+	_, err = tx.ExecContext(ctx, `
+		UPDATE postal_api_responses
+		SET is_latest = 0
+		WHERE tracking_number = ? AND api_name = ? AND is_latest = 1
+	`, dbStruct.TrackingNumber, dbStruct.APIName)
 	if err != nil {
-		return zaperr.Wrap(err, "failed to NamedExecContext", zap.Any("dbStruct", dbStruct))
+		return zaperr.Wrap(err, "failed to unmark latest response", zapFields...)
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE postal_api_responses
+		SET is_latest = 1
+		WHERE id = (
+			SELECT id
+			FROM postal_api_responses
+			WHERE tracking_number = ? AND api_name = ?
+			ORDER BY last_fetched_at DESC, id DESC
+			LIMIT 1
+		)
+	`, dbStruct.TrackingNumber, dbStruct.APIName)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to mark latest response", zapFields...)
 	}
 	return nil
 }
@@ -94,7 +297,9 @@ func (s sqliteStorage) Update(ctx context.Context, response *service.PostalApiRe
 		    first_fetched_at = :first_fetched_at,
 		    last_fetched_at = :last_fetched_at,
 		    response_body = :response_body,
-		    status = :status
+		    response_hash = :response_hash,
+		    status = :status,
+		    is_final = :is_final
 		WHERE id = :id
 	`, dbStruct)
 
@@ -103,3 +308,181 @@ func (s sqliteStorage) Update(ctx context.Context, response *service.PostalApiRe
 	}
 	return nil
 }
+
+func (s sqliteStorage) GetHistory(
+	ctx context.Context,
+	trackingNumber string,
+	apiName service.APIName,
+) ([]*service.PostalApiResponse, error) {
+	zapFields := []zap.Field{
+		zap.String("trackingNumber", trackingNumber),
+		zap.String("apiName", string(apiName)),
+	}
+	var dbStructs []DBRawPostalApiResponse
+	err := s.db.SelectContext(ctx, &dbStructs, `
+		SELECT *
+		FROM postal_api_responses
+		WHERE tracking_number = ? AND api_name = ?
+		ORDER BY first_fetched_at, id
+	`, trackingNumber, apiName)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zapFields...)
+	}
+
+	var businessStructs []*service.PostalApiResponse
+	for _, dbStruct := range dbStructs {
+		businessStructs = append(businessStructs, dbStruct.ToBusinessModel())
+	}
+
+	return businessStructs, nil
+}
+
+func (s sqliteStorage) GetResponsesAfter(
+	ctx context.Context,
+	apiName service.APIName,
+	afterID int64,
+	limit int,
+) ([]*service.PostalApiResponse, error) {
+	zapFields := []zap.Field{
+		zap.String("apiName", string(apiName)),
+		zap.Int64("afterID", afterID),
+		zap.Int("limit", limit),
+	}
+	var dbStructs []DBRawPostalApiResponse
+	err := s.db.SelectContext(ctx, &dbStructs, `
+		SELECT *
+		FROM postal_api_responses
+		WHERE api_name = ? AND id > ?
+		ORDER BY id
+		LIMIT ?
+	`, apiName, afterID, limit)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zapFields...)
+	}
+
+	var businessStructs []*service.PostalApiResponse
+	for _, dbStruct := range dbStructs {
+		businessStructs = append(businessStructs, dbStruct.ToBusinessModel())
+	}
+
+	return businessStructs, nil
+}
+
+func (s sqliteStorage) GetFinalResponsesAfter(
+	ctx context.Context,
+	apiName service.APIName,
+	afterID int64,
+	limit int,
+) ([]*service.PostalApiResponse, error) {
+	zapFields := []zap.Field{
+		zap.String("apiName", string(apiName)),
+		zap.Int64("afterID", afterID),
+		zap.Int("limit", limit),
+	}
+	var dbStructs []DBRawPostalApiResponse
+	err := s.db.SelectContext(ctx, &dbStructs, `
+		SELECT *
+		FROM postal_api_responses
+		WHERE api_name = ? AND id > ?
+		AND is_latest = 1
+		AND is_final = 1
+		AND status = 'success'
+		ORDER BY id
+		LIMIT ?
+	`, apiName, afterID, limit)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zapFields...)
+	}
+
+	var businessStructs []*service.PostalApiResponse
+	for _, dbStruct := range dbStructs {
+		businessStructs = append(businessStructs, dbStruct.ToBusinessModel())
+	}
+
+	return businessStructs, nil
+}
+
+func (s sqliteStorage) GetLinks(ctx context.Context, trackingNumber string) ([]string, error) {
+	var linked []string
+	err := s.db.SelectContext(ctx, &linked, `
+		SELECT linked_tracking_number
+		FROM tracking_number_links
+		WHERE tracking_number = ?
+		ORDER BY rowid
+	`, trackingNumber)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zap.String("trackingNumber", trackingNumber))
+	}
+	return linked, nil
+}
+
+func (s sqliteStorage) InsertLinks(ctx context.Context, trackingNumber string, linkedTrackingNumbers []string) error {
+	zapFields := []zap.Field{
+		zap.String("trackingNumber", trackingNumber),
+		zap.Strings("linkedTrackingNumbers", linkedTrackingNumbers),
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to BeginTxx", zapFields...)
+	}
+	defer tx.Rollback()
+
+	for _, linked := range linkedTrackingNumbers {
+		_, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO tracking_number_links (tracking_number, linked_tracking_number)
+			VALUES (?, ?)
+		`, trackingNumber, linked)
+		if err != nil {
+			return zaperr.Wrap(err, "failed to ExecContext", zapFields...)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return zaperr.Wrap(err, "failed to Commit", zapFields...)
+	}
+	return nil
+}
+
+func (s sqliteStorage) GetLocalized(
+	ctx context.Context,
+	trackingNumber string,
+	apiName service.APIName,
+	language string,
+) (*service.PostalApiResponse, error) {
+	zapFields := []zap.Field{
+		zap.String("trackingNumber", trackingNumber),
+		zap.String("apiName", string(apiName)),
+		zap.String("language", language),
+	}
+	var dbStruct DBLocalizedResponse
+	err := s.db.GetContext(ctx, &dbStruct, `
+		SELECT *
+		FROM localized_responses
+		WHERE tracking_number = ? AND api_name = ? AND language = ?
+	`, trackingNumber, apiName, language)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to GetContext", zapFields...)
+	}
+	return dbStruct.ToBusinessModel(), nil
+}
+
+func (s sqliteStorage) UpsertLocalized(ctx context.Context, response *service.PostalApiResponse) error {
+	dbStruct := DBLocalizedResponse{}.FromBusinessModel(response)
+	_, err := s.db.NamedExecContext(ctx, `
+		INSERT INTO localized_responses
+		    (tracking_number, api_name, language, fetched_at, response_body, status)
+		VALUES
+		    (:tracking_number, :api_name, :language, :fetched_at, :response_body, :status)
+		ON CONFLICT (tracking_number, api_name, language) DO UPDATE
+		SET fetched_at = excluded.fetched_at,
+		    response_body = excluded.response_body,
+		    status = excluded.status
+	`, dbStruct)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to NamedExecContext", zap.Any("dbStruct", dbStruct))
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
@@ -13,9 +14,10 @@ import (
 )
 
 func TestStorage(t *testing.T) {
-	prepareTestSubject := func() service.Storage {
+	newDB := func(t *testing.T) *sqlx.DB {
 		db := sqlx.MustConnect("sqlite3", ":memory:")
-		storage := NewStorage(db)
+		db.SetMaxOpenConns(1) // every connection to :memory: is a separate database
+		t.Cleanup(func() { _ = db.Close() })
 		migrations := &migrate.FileMigrationSource{
 			Dir: "../db/migrations",
 		}
@@ -23,7 +25,10 @@ func TestStorage(t *testing.T) {
 		if err != nil {
 			t.Fatalf("failed to apply migrations: %v", err)
 		}
-		return storage
+		return db
+	}
+	prepareTestSubject := func() service.Storage {
+		return NewStorage(newDB(t))
 	}
 
 	t.Run("Insert and GetLatest", func(t *testing.T) {
@@ -186,6 +191,86 @@ func TestStorage(t *testing.T) {
 
 	})
 
+	t.Run("GetLatestBatch returns only latest for each tracking number", func(t *testing.T) {
+		storage := prepareTestSubject()
+
+		for _, resp := range []service.PostalApiResponse{
+			{TrackingNumber: "tn-1", APIName: "some-api-name", LastFetchedAt: time.Unix(2000, 0), ResponseBody: []byte("body"), Status: service.StatusSuccess},
+			{TrackingNumber: "tn-1", APIName: "some-api-name", LastFetchedAt: time.Unix(3000, 0), ResponseBody: []byte("body"), Status: service.StatusSuccess},
+			{TrackingNumber: "tn-2", APIName: "some-api-name", LastFetchedAt: time.Unix(2000, 0), ResponseBody: []byte("body"), Status: service.StatusNotFound},
+			{TrackingNumber: "tn-3", APIName: "some-api-name", LastFetchedAt: time.Unix(2000, 0), ResponseBody: []byte("body"), Status: service.StatusSuccess},
+		} {
+			resp := resp
+			if err := storage.Insert(context.TODO(), resp.TrackingNumber, resp.APIName, &resp); err != nil {
+				t.Fatalf("failed to insert: %v", err)
+			}
+		}
+
+		latest, err := storage.GetLatestBatch(context.TODO(), []string{"tn-1", "tn-2"}, []service.APIName{"some-api-name"})
+		if err != nil {
+			t.Fatalf("failed to get latest batch: %v", err)
+		}
+		if len(latest) != 2 {
+			t.Fatalf("expected 2 responses, got %d", len(latest))
+		}
+		for _, resp := range latest {
+			switch resp.TrackingNumber {
+			case "tn-1":
+				if resp.LastFetchedAt != time.Unix(3000, 0) {
+					t.Fatalf("expected latest response for tn-1, got %+v", resp)
+				}
+			case "tn-2":
+				if resp.Status != service.StatusNotFound {
+					t.Fatalf("expected not found response for tn-2, got %+v", resp)
+				}
+			default:
+				t.Fatalf("unexpected tracking number %s", resp.TrackingNumber)
+			}
+		}
+	})
+
+	t.Run("GetDueForRefresh", func(t *testing.T) {
+		storage := prepareTestSubject()
+
+		for _, resp := range []service.PostalApiResponse{
+			{TrackingNumber: "success-due", LastFetchedAt: time.Unix(1000, 0), Status: service.StatusSuccess},
+			{TrackingNumber: "success-fresh", LastFetchedAt: time.Unix(1000, 0), Status: service.StatusSuccess},
+			{TrackingNumber: "success-fresh", LastFetchedAt: time.Unix(5000, 0), Status: service.StatusSuccess},
+			{TrackingNumber: "success-final", LastFetchedAt: time.Unix(1000, 0), Status: service.StatusSuccess, IsFinal: true},
+			{TrackingNumber: "success-expired", LastFetchedAt: time.Unix(10, 0), Status: service.StatusSuccess},
+			{TrackingNumber: "not-found-due", LastFetchedAt: time.Unix(400, 0), Status: service.StatusNotFound},
+			{TrackingNumber: "not-found-fresh", LastFetchedAt: time.Unix(1000, 0), Status: service.StatusNotFound},
+			{TrackingNumber: "unknown-error-due", LastFetchedAt: time.Unix(2500, 0), Status: service.StatusUnknownError},
+		} {
+			resp := resp
+			resp.ResponseBody = []byte("body")
+			if err := storage.Insert(context.TODO(), resp.TrackingNumber, "some-api-name", &resp); err != nil {
+				t.Fatalf("failed to insert: %v", err)
+			}
+		}
+
+		due, err := storage.GetDueForRefresh(context.TODO(), service.RefreshQuery{
+			APINames:                  []service.APIName{"some-api-name"},
+			NotExpiredSince:           time.Unix(100, 0),
+			SuccessFetchedBefore:      time.Unix(2000, 0),
+			NotFoundFetchedBefore:     time.Unix(500, 0),
+			UnknownErrorFetchedBefore: time.Unix(3000, 0),
+			Limit:                     10,
+		})
+		if err != nil {
+			t.Fatalf("failed to get due for refresh: %v", err)
+		}
+
+		var dueTrackingNumbers []string
+		for _, resp := range due {
+			dueTrackingNumbers = append(dueTrackingNumbers, resp.TrackingNumber)
+		}
+		expected := []string{"success-due", "unknown-error-due", "not-found-due"}
+		if !reflect.DeepEqual(dueTrackingNumbers, expected) {
+			t.Fatalf("expected %v to be due, most overdue first, got %v", expected, dueTrackingNumbers)
+		}
+	})
+
 	t.Run("Insert respects context", func(t *testing.T) {
 
 		storage := prepareTestSubject()
@@ -232,4 +317,201 @@ func TestStorage(t *testing.T) {
 			t.Fatalf("expected context deadline exceeded, got %v", err)
 		}
 	})
+
+	t.Run("InsertLinks and GetLinks", func(t *testing.T) {
+		storage := prepareTestSubject()
+		ctx := context.TODO()
+
+		if err := storage.InsertLinks(ctx, "123", []string{"456", "789"}); err != nil {
+			t.Fatalf("failed to insert links: %v", err)
+		}
+		// already known links are ignored
+		if err := storage.InsertLinks(ctx, "123", []string{"456"}); err != nil {
+			t.Fatalf("failed to insert links: %v", err)
+		}
+
+		links, err := storage.GetLinks(ctx, "123")
+		if err != nil {
+			t.Fatalf("failed to get links: %v", err)
+		}
+		if !reflect.DeepEqual(links, []string{"456", "789"}) {
+			t.Fatalf("expected links to be [456 789], got %v", links)
+		}
+
+		links, err = storage.GetLinks(ctx, "456")
+		if err != nil {
+			t.Fatalf("failed to get links: %v", err)
+		}
+		if len(links) != 0 {
+			t.Fatalf("expected links to be directional, got %v", links)
+		}
+	})
+
+	t.Run("Upsert never produces duplicate latest responses", func(t *testing.T) {
+		storage := prepareTestSubject()
+		ctx := context.TODO()
+
+		resp := func(lastFetchedAt int64, body string) *service.PostalApiResponse {
+			return &service.PostalApiResponse{
+				TrackingNumber: "123",
+				APIName:        "api1",
+				FirstFetchedAt: time.Unix(lastFetchedAt, 0),
+				LastFetchedAt:  time.Unix(lastFetchedAt, 0),
+				ResponseBody:   []byte(body),
+				Status:         service.StatusSuccess,
+			}
+		}
+
+		// two concurrent lookups fetched the same response
+		first, second := resp(1000, "foo"), resp(1000, "foo")
+		if err := storage.Upsert(ctx, "123", "api1", first); err != nil {
+			t.Fatalf("failed to upsert: %v", err)
+		}
+		if err := storage.Upsert(ctx, "123", "api1", second); err != nil {
+			t.Fatalf("failed to upsert: %v", err)
+		}
+		if first.ID == 0 || second.ID != first.ID {
+			t.Fatalf("expected the same response to be updated, got ids %d and %d", first.ID, second.ID)
+		}
+
+		// later on, response changes
+		changed := resp(2000, "bar")
+		if err := storage.Upsert(ctx, "123", "api1", changed); err != nil {
+			t.Fatalf("failed to upsert: %v", err)
+		}
+		if changed.ID == first.ID {
+			t.Fatalf("expected changed response to be inserted")
+		}
+
+		// and then changes back, which is a new response again
+		changedBack := resp(3000, "foo")
+		if err := storage.Upsert(ctx, "123", "api1", changedBack); err != nil {
+			t.Fatalf("failed to upsert: %v", err)
+		}
+
+		latest, err := storage.GetLatestBatch(ctx, []string{"123"}, []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		if len(latest) != 1 || latest[0].ID != changedBack.ID {
+			t.Fatalf("expected only the last response to be latest, got %+v", latest)
+		}
+	})
+
+	t.Run("Insert out of order keeps the most recently fetched response latest", func(t *testing.T) {
+		storage := prepareTestSubject()
+		ctx := context.TODO()
+
+		newer := &service.PostalApiResponse{
+			LastFetchedAt: time.Unix(3000, 0),
+			ResponseBody:  []byte("newer"),
+			Status:        service.StatusSuccess,
+		}
+		older := &service.PostalApiResponse{
+			LastFetchedAt: time.Unix(2000, 0),
+			ResponseBody:  []byte("older"),
+			Status:        service.StatusSuccess,
+		}
+		for _, r := range []*service.PostalApiResponse{newer, older} {
+			if err := storage.Insert(ctx, "123", "api1", r); err != nil {
+				t.Fatalf("failed to insert: %v", err)
+			}
+		}
+
+		latest, err := storage.GetLatest(ctx, "123", []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		if len(latest) != 1 || latest[0].ID != newer.ID {
+			t.Fatalf("expected newer response to be latest, got %+v", latest)
+		}
+	})
+
+	t.Run("response bodies are stored compressed", func(t *testing.T) {
+		db := newDB(t)
+		storage := NewStorage(db)
+		ctx := context.TODO()
+
+		body := bytes.Repeat([]byte(`{"status":"in transit"}`), 100)
+		resp := &service.PostalApiResponse{LastFetchedAt: time.Unix(1000, 0), ResponseBody: body, Status: service.StatusSuccess}
+		if err := storage.Insert(ctx, "123", "api1", resp); err != nil {
+			t.Fatalf("failed to insert: %v", err)
+		}
+
+		var stored []byte
+		if err := db.GetContext(ctx, &stored, `SELECT response_body FROM postal_api_responses WHERE id = ?`, resp.ID); err != nil {
+			t.Fatalf("failed to select raw body: %v", err)
+		}
+		if !isCompressed(stored) || len(stored) >= len(body) {
+			t.Fatalf("expected body of %d bytes to be stored compressed, got %d bytes", len(body), len(stored))
+		}
+
+		latest, err := storage.GetLatest(ctx, "123", []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		if len(latest) != 1 || !bytes.Equal(latest[0].ResponseBody, body) {
+			t.Fatalf("expected body to be decompressed, got %+v", latest)
+		}
+	})
+
+	t.Run("responses stored before hashing are readable and backfilled", func(t *testing.T) {
+		db := newDB(t)
+		storage := NewStorage(db)
+		ctx := context.TODO()
+
+		for i, body := range []string{"legacy1", "legacy2", "legacy3"} {
+			_, err := db.ExecContext(ctx, `
+				INSERT INTO postal_api_responses
+				    (api_name, tracking_number, first_fetched_at, last_fetched_at, response_body, status, is_latest)
+				VALUES ('api1', ?, 1000, 1000, ?, 'success', 1)
+			`, body, body)
+			if err != nil {
+				t.Fatalf("failed to insert legacy response #%d: %v", i, err)
+			}
+		}
+
+		latest, err := storage.GetLatest(ctx, "legacy1", []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		if len(latest) != 1 || string(latest[0].ResponseBody) != "legacy1" || latest[0].Hash() != service.HashResponseBody([]byte("legacy1")) {
+			t.Fatalf("expected legacy response to be readable, got %+v", latest)
+		}
+
+		// the same response fetched again should be recognized as unchanged even without stored hash
+		same := &service.PostalApiResponse{LastFetchedAt: time.Unix(2000, 0), ResponseBody: []byte("legacy1"), Status: service.StatusSuccess}
+		if err := storage.Upsert(ctx, "legacy1", "api1", same); err != nil {
+			t.Fatalf("failed to upsert: %v", err)
+		}
+		if same.ID != latest[0].ID {
+			t.Fatalf("expected legacy response to be updated, got new response %d", same.ID)
+		}
+
+		updated, err := BackfillResponseHashes(ctx, db, 2)
+		if err != nil {
+			t.Fatalf("failed to backfill: %v", err)
+		}
+		if updated != 3 {
+			t.Fatalf("expected 3 responses to be backfilled, got %d", updated)
+		}
+
+		var rows []struct {
+			TrackingNumber string `db:"tracking_number"`
+			ResponseBody   []byte `db:"response_body"`
+			ResponseHash   string `db:"response_hash"`
+		}
+		if err := db.SelectContext(ctx, &rows, `SELECT tracking_number, response_body, response_hash FROM postal_api_responses`); err != nil {
+			t.Fatalf("failed to select backfilled responses: %v", err)
+		}
+		for _, row := range rows {
+			if !isCompressed(row.ResponseBody) || row.ResponseHash != service.HashResponseBody([]byte(row.TrackingNumber)) {
+				t.Fatalf("expected response %s to be compressed and hashed, got %+v", row.TrackingNumber, row)
+			}
+		}
+
+		if updated, err := BackfillResponseHashes(ctx, db, 2); err != nil || updated != 0 {
+			t.Fatalf("expected nothing left to backfill, got %d, %v", updated, err)
+		}
+	})
 }
@@ -0,0 +1,157 @@
+package sqlite_storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/dir01/parcels/subscriptions"
+	"github.com/hori-ryota/zaperr"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+func NewSubscriptionsStorage(db *sqlx.DB) subscriptions.Storage {
+	return &sqliteSubscriptionsStorage{db: db}
+}
+
+type sqliteSubscriptionsStorage struct {
+	db *sqlx.DB
+}
+
+func (s sqliteSubscriptionsStorage) CreateSubscription(ctx context.Context, subscription *subscriptions.Subscription) error {
+	dbStruct := DBSubscription{}.FromBusinessModel(subscription)
+	res, err := s.db.NamedExecContext(ctx, `
+		INSERT INTO subscriptions
+		    (tracking_number, callback_url, secret, created_at)
+		VALUES
+		    (:tracking_number, :callback_url, :secret, :created_at)
+	`, dbStruct)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to NamedExecContext", zap.String("trackingNumber", subscription.TrackingNumber))
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return zaperr.Wrap(err, "failed to get LastInsertId")
+	}
+	subscription.ID = id
+	return nil
+}
+
+func (s sqliteSubscriptionsStorage) GetSubscriptions(ctx context.Context, trackingNumber string) ([]*subscriptions.Subscription, error) {
+	var dbStructs []DBSubscription
+	err := s.db.SelectContext(ctx, &dbStructs, `
+		SELECT *
+		FROM subscriptions
+		WHERE tracking_number = ?
+		ORDER BY id
+	`, trackingNumber)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext", zap.String("trackingNumber", trackingNumber))
+	}
+
+	var businessStructs []*subscriptions.Subscription
+	for _, dbStruct := range dbStructs {
+		businessStructs = append(businessStructs, dbStruct.ToBusinessModel())
+	}
+	return businessStructs, nil
+}
+
+func (s sqliteSubscriptionsStorage) InsertDeliveries(ctx context.Context, deliveries []*subscriptions.Delivery) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to BeginTxx")
+	}
+	defer tx.Rollback()
+
+	for _, delivery := range deliveries {
+		dbStruct := DBWebhookDelivery{}.FromBusinessModel(delivery)
+		res, err := tx.NamedExecContext(ctx, `
+			INSERT INTO webhook_deliveries
+			    (subscription_id, payload, status, attempts, next_attempt_at, last_error, created_at)
+			VALUES
+			    (:subscription_id, :payload, :status, :attempts, :next_attempt_at, :last_error, :created_at)
+		`, dbStruct)
+		if err != nil {
+			return zaperr.Wrap(err, "failed to NamedExecContext", zap.Int64("subscriptionID", delivery.SubscriptionID))
+		}
+		if delivery.ID, err = res.LastInsertId(); err != nil {
+			return zaperr.Wrap(err, "failed to get LastInsertId")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return zaperr.Wrap(err, "failed to Commit")
+	}
+	return nil
+}
+
+func (s sqliteSubscriptionsStorage) ClaimDueDeliveries(
+	ctx context.Context,
+	now time.Time,
+	leaseUntil time.Time,
+	limit int,
+) ([]*subscriptions.Delivery, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to BeginTxx")
+	}
+	defer tx.Rollback()
+
+	var dbStructs []DBWebhookDelivery
+	err = tx.SelectContext(ctx, &dbStructs, `
+		SELECT d.*, s.callback_url, s.secret
+		FROM webhook_deliveries d
+		JOIN subscriptions s ON s.id = d.subscription_id
+		WHERE d.status = ?
+		AND d.next_attempt_at <= ?
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT ?
+	`, subscriptions.DeliveryStatusPending, toUnixTime(now), limit)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to SelectContext")
+	}
+	if len(dbStructs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, 0, len(dbStructs))
+	for _, dbStruct := range dbStructs {
+		ids = append(ids, dbStruct.ID)
+	}
+	query, args, err := sqlx.In(`
+		UPDATE webhook_deliveries
+		SET next_attempt_at = ?
+		WHERE id IN (?)
+	`, toUnixTime(leaseUntil), ids)
+	if err != nil {
+		return nil, zaperr.Wrap(err, "failed to build IN query")
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+		return nil, zaperr.Wrap(err, "failed to ExecContext")
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, zaperr.Wrap(err, "failed to Commit")
+	}
+
+	var businessStructs []*subscriptions.Delivery
+	for _, dbStruct := range dbStructs {
+		businessStructs = append(businessStructs, dbStruct.ToBusinessModel())
+	}
+	return businessStructs, nil
+}
+
+func (s sqliteSubscriptionsStorage) UpdateDelivery(ctx context.Context, delivery *subscriptions.Delivery) error {
+	dbStruct := DBWebhookDelivery{}.FromBusinessModel(delivery)
+	_, err := s.db.NamedExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = :status,
+		    attempts = :attempts,
+		    next_attempt_at = :next_attempt_at,
+		    last_error = :last_error
+		WHERE id = :id
+	`, dbStruct)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to NamedExecContext", zap.Int64("deliveryID", delivery.ID))
+	}
+	return nil
+}
@@ -0,0 +1,757 @@
+// Package storagetest is a conformance test suite that every service.Storage implementation should pass,
+// so that storages are interchangeable.
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/retention"
+	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/subscriptions"
+)
+
+// TestStorage runs the suite against storages created by newStorage.
+// Every subtest gets a fresh storage, and newStorage is expected to clean up after itself with t.Cleanup.
+func TestStorage(t *testing.T, newStorage func(t *testing.T) service.Storage) {
+	ctx := context.Background()
+
+	newResponse := func(trackingNumber string, apiName service.APIName, lastFetchedAt int64, body string) *service.PostalApiResponse {
+		return &service.PostalApiResponse{
+			TrackingNumber: trackingNumber,
+			APIName:        apiName,
+			FirstFetchedAt: time.Unix(lastFetchedAt, 0),
+			LastFetchedAt:  time.Unix(lastFetchedAt, 0),
+			ResponseBody:   []byte(body),
+			Status:         service.StatusSuccess,
+		}
+	}
+
+	insert := func(t *testing.T, storage service.Storage, responses ...*service.PostalApiResponse) {
+		t.Helper()
+		for _, resp := range responses {
+			if err := storage.Insert(ctx, resp.TrackingNumber, resp.APIName, resp); err != nil {
+				t.Fatalf("failed to insert: %v", err)
+			}
+		}
+	}
+
+	t.Run("Insert and GetLatest", func(t *testing.T) {
+		storage := newStorage(t)
+		resp := newResponse("123", "api1", 2000, "body")
+		resp.FirstFetchedAt = time.Unix(1000, 0)
+		resp.IsFinal = true
+		insert(t, storage, resp)
+		if resp.ID == 0 {
+			t.Fatalf("expected ID to be set on insert")
+		}
+
+		latest, err := storage.GetLatest(ctx, "123", []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		if len(latest) != 1 {
+			t.Fatalf("expected 1 response, got %d", len(latest))
+		}
+		assertResponsesEqual(t, latest[0], resp)
+	})
+
+	t.Run("GetLatest returns only latest", func(t *testing.T) {
+		storage := newStorage(t)
+		older, newer := newResponse("123", "api1", 2000, "old"), newResponse("123", "api1", 3000, "new")
+		insert(t, storage, older, newer)
+
+		latest, err := storage.GetLatest(ctx, "123", []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		if len(latest) != 1 {
+			t.Fatalf("expected 1 response, got %d", len(latest))
+		}
+		assertResponsesEqual(t, latest[0], newer)
+	})
+
+	t.Run("GetLatest of unknown tracking number", func(t *testing.T) {
+		storage := newStorage(t)
+
+		latest, err := storage.GetLatest(ctx, "123", []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		if len(latest) != 0 {
+			t.Fatalf("expected no responses, got %d", len(latest))
+		}
+	})
+
+	t.Run("GetLatest with multiple APIs", func(t *testing.T) {
+		storage := newStorage(t)
+		latest1, latest2 := newResponse("123", "api1", 2000, "new1"), newResponse("123", "api2", 1500, "new2")
+		insert(t, storage,
+			newResponse("123", "api1", 1000, "old1"),
+			latest1,
+			newResponse("123", "api2", 1000, "old2"),
+			latest2,
+			newResponse("123", "api3", 3000, "not asked for"),
+			newResponse("456", "api1", 3000, "another parcel"),
+		)
+
+		latest, err := storage.GetLatest(ctx, "123", []service.APIName{"api1", "api2"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		sortResponses(latest)
+		if len(latest) != 2 {
+			t.Fatalf("expected 2 responses, got %d", len(latest))
+		}
+		assertResponsesEqual(t, latest[0], latest1)
+		assertResponsesEqual(t, latest[1], latest2)
+
+		latest, err = storage.GetLatest(ctx, "123", nil)
+		if err != nil {
+			t.Fatalf("failed to get latest without APIs: %v", err)
+		}
+		if len(latest) != 0 {
+			t.Fatalf("expected no responses without APIs, got %d", len(latest))
+		}
+	})
+
+	t.Run("GetLatest with ties on last_fetched_at", func(t *testing.T) {
+		storage := newStorage(t)
+		first, second := newResponse("123", "api1", 2000, "first"), newResponse("123", "api1", 2000, "second")
+		insert(t, storage, first, second)
+
+		latest, err := storage.GetLatest(ctx, "123", []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		if len(latest) != 1 {
+			t.Fatalf("expected tie to be resolved into 1 response, got %d", len(latest))
+		}
+		assertResponsesEqual(t, latest[0], second)
+
+		latest, err = storage.GetLatestBatch(ctx, []string{"123"}, []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest batch: %v", err)
+		}
+		if len(latest) != 1 {
+			t.Fatalf("expected tie to be resolved into 1 response, got %d", len(latest))
+		}
+		assertResponsesEqual(t, latest[0], second)
+	})
+
+	t.Run("GetLatest returns expired records", func(t *testing.T) {
+		// it's up to the service to decide that a response is too old to be trusted,
+		// storage should return it anyway
+		storage := newStorage(t)
+		ancient := newResponse("123", "api1", 1, "ancient")
+		insert(t, storage, ancient)
+
+		latest, err := storage.GetLatest(ctx, "123", []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		if len(latest) != 1 {
+			t.Fatalf("expected 1 response, got %d", len(latest))
+		}
+		assertResponsesEqual(t, latest[0], ancient)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		storage := newStorage(t)
+		resp := newResponse("123", "api1", 2000, "body")
+		insert(t, storage, resp)
+
+		resp.LastFetchedAt = time.Unix(3000, 0)
+		resp.ResponseBody = []byte("updated body")
+		resp.Status = service.StatusUnknownError
+		resp.IsFinal = true
+		if err := storage.Update(ctx, resp); err != nil {
+			t.Fatalf("failed to update: %v", err)
+		}
+
+		latest, err := storage.GetLatest(ctx, "123", []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		if len(latest) != 1 {
+			t.Fatalf("expected 1 response, got %d", len(latest))
+		}
+		assertResponsesEqual(t, latest[0], resp)
+	})
+
+	t.Run("GetLatestBatch", func(t *testing.T) {
+		storage := newStorage(t)
+		latest1 := newResponse("1", "api1", 2000, "new")
+		latest2 := newResponse("2", "api1", 1000, "body")
+		latest3 := newResponse("2", "api2", 1000, "body")
+		insert(t, storage,
+			newResponse("1", "api1", 1000, "old"),
+			latest1,
+			latest2,
+			latest3,
+			newResponse("2", "api3", 1000, "not asked for"),
+			newResponse("3", "api1", 1000, "not asked for"),
+		)
+
+		latest, err := storage.GetLatestBatch(ctx, []string{"1", "2", "4"}, []service.APIName{"api1", "api2"})
+		if err != nil {
+			t.Fatalf("failed to get latest batch: %v", err)
+		}
+		sortResponses(latest)
+		if len(latest) != 3 {
+			t.Fatalf("expected 3 responses, got %d", len(latest))
+		}
+		assertResponsesEqual(t, latest[0], latest1)
+		assertResponsesEqual(t, latest[1], latest2)
+		assertResponsesEqual(t, latest[2], latest3)
+	})
+
+	t.Run("Upsert never produces duplicate latest responses", func(t *testing.T) {
+		storage := newStorage(t)
+
+		// two concurrent lookups fetched the same response
+		first, second := newResponse("123", "api1", 1000, "foo"), newResponse("123", "api1", 1001, "foo")
+		for _, resp := range []*service.PostalApiResponse{first, second} {
+			if err := storage.Upsert(ctx, "123", "api1", resp); err != nil {
+				t.Fatalf("failed to upsert: %v", err)
+			}
+		}
+		if first.ID == 0 || second.ID != first.ID {
+			t.Fatalf("expected the same response to be updated, got ids %d and %d", first.ID, second.ID)
+		}
+		if !second.FirstFetchedAt.Equal(first.FirstFetchedAt) {
+			t.Fatalf("expected first fetched at of stored response, got %s", second.FirstFetchedAt)
+		}
+
+		changed := newResponse("123", "api1", 2000, "bar")
+		if err := storage.Upsert(ctx, "123", "api1", changed); err != nil {
+			t.Fatalf("failed to upsert: %v", err)
+		}
+		if changed.ID == first.ID {
+			t.Fatalf("expected changed response to be inserted")
+		}
+
+		latest, err := storage.GetLatest(ctx, "123", []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		if len(latest) != 1 {
+			t.Fatalf("expected 1 response, got %d", len(latest))
+		}
+		assertResponsesEqual(t, latest[0], changed)
+	})
+
+	t.Run("GetDueForRefresh", func(t *testing.T) {
+		storage := newStorage(t)
+		for _, resp := range []service.PostalApiResponse{
+			{TrackingNumber: "success-due", LastFetchedAt: time.Unix(1000, 0), Status: service.StatusSuccess},
+			{TrackingNumber: "success-fresh", LastFetchedAt: time.Unix(1000, 0), Status: service.StatusSuccess},
+			{TrackingNumber: "success-fresh", LastFetchedAt: time.Unix(5000, 0), Status: service.StatusSuccess},
+			{TrackingNumber: "success-final", LastFetchedAt: time.Unix(1000, 0), Status: service.StatusSuccess, IsFinal: true},
+			{TrackingNumber: "success-expired", LastFetchedAt: time.Unix(10, 0), Status: service.StatusSuccess},
+			{TrackingNumber: "not-found-due", LastFetchedAt: time.Unix(400, 0), Status: service.StatusNotFound},
+			{TrackingNumber: "not-found-fresh", LastFetchedAt: time.Unix(1000, 0), Status: service.StatusNotFound},
+			{TrackingNumber: "unknown-error-due", LastFetchedAt: time.Unix(2500, 0), Status: service.StatusUnknownError},
+			{TrackingNumber: "other-api-due", APIName: "api2", LastFetchedAt: time.Unix(900, 0), Status: service.StatusSuccess},
+			{TrackingNumber: "success-due", APIName: "api2", LastFetchedAt: time.Unix(1600, 0), Status: service.StatusSuccess},
+			{TrackingNumber: "unregistered-api", APIName: "api3", LastFetchedAt: time.Unix(1000, 0), Status: service.StatusSuccess},
+		} {
+			resp := resp
+			if resp.APIName == "" {
+				resp.APIName = "api1"
+			}
+			resp.ResponseBody = []byte("body")
+			insert(t, storage, &resp)
+		}
+
+		query := service.RefreshQuery{
+			APINames:                  []service.APIName{"api1", "api2"},
+			NotExpiredSince:           time.Unix(100, 0),
+			SuccessFetchedBefore:      time.Unix(2000, 0),
+			NotFoundFetchedBefore:     time.Unix(500, 0),
+			UnknownErrorFetchedBefore: time.Unix(3000, 0),
+			Limit:                     10,
+		}
+		dueTrackingNumbers := func(query service.RefreshQuery) []string {
+			t.Helper()
+			due, err := storage.GetDueForRefresh(ctx, query)
+			if err != nil {
+				t.Fatalf("failed to get due for refresh: %v", err)
+			}
+			var result []string
+			for _, resp := range due {
+				if resp.Status == "" || resp.LastFetchedAt.IsZero() {
+					t.Fatalf("expected status and last fetched at to be loaded, got %+v", resp)
+				}
+				result = append(result, resp.TrackingNumber)
+			}
+			return result
+		}
+
+		// expired ones are not due: we are not tracking them anymore.
+		// Overdue by 1100s, 1000s, 500s, 400s and 100s respectively
+		expected := []string{"other-api-due", "success-due", "unknown-error-due", "success-due", "not-found-due"}
+		if actual := dueTrackingNumbers(query); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("expected %v to be due, most overdue first, got %v", expected, actual)
+		}
+
+		query.Limit = 2
+		if actual := dueTrackingNumbers(query); !reflect.DeepEqual(actual, expected[:2]) {
+			t.Fatalf("expected only %v to be returned, got %v", expected[:2], actual)
+		}
+
+		count, err := storage.CountDueForRefresh(ctx, query)
+		if err != nil {
+			t.Fatalf("failed to count due for refresh: %v", err)
+		}
+		// success-due is due for both APIs, but it's still one parcel
+		if count != 4 {
+			t.Fatalf("expected 4 parcels to be due regardless of limit, got %d", count)
+		}
+	})
+
+	t.Run("GetHistory", func(t *testing.T) {
+		storage := newStorage(t)
+		first := newResponse("123", "api1", 1000, "first")
+		latest := newResponse("123", "api1", 3000, "latest")
+		// inserted out of order, but should be returned in order it was first fetched
+		second := newResponse("123", "api1", 2000, "second")
+		insert(t, storage,
+			first,
+			latest,
+			second,
+			newResponse("123", "api2", 1500, "other api"),
+			newResponse("456", "api1", 1500, "other tracking number"),
+		)
+
+		history, err := storage.GetHistory(ctx, "123", "api1")
+		if err != nil {
+			t.Fatalf("failed to get history: %v", err)
+		}
+		if len(history) != 3 {
+			t.Fatalf("expected 3 responses, got %d", len(history))
+		}
+		assertResponsesEqual(t, history[0], first)
+		assertResponsesEqual(t, history[1], second)
+		assertResponsesEqual(t, history[2], latest)
+
+		history, err = storage.GetHistory(ctx, "789", "api1")
+		if err != nil {
+			t.Fatalf("failed to get history of unknown tracking number: %v", err)
+		}
+		if len(history) != 0 {
+			t.Fatalf("expected no history, got %d responses", len(history))
+		}
+	})
+
+	t.Run("GetResponsesAfter", func(t *testing.T) {
+		storage := newStorage(t)
+		responses := []*service.PostalApiResponse{
+			newResponse("1", "api1", 1000, "old"),
+			newResponse("2", "api1", 1000, "body"),
+			newResponse("1", "api1", 2000, "new"),
+		}
+		insert(t, storage, responses[0], newResponse("1", "api2", 1000, "other api"), responses[1], responses[2])
+
+		var walked []*service.PostalApiResponse
+		var afterID int64
+		for page := 0; ; page++ {
+			if page > len(responses) {
+				t.Fatalf("expected pagination to stop, got %d pages", page)
+			}
+			batch, err := storage.GetResponsesAfter(ctx, "api1", afterID, 2)
+			if err != nil {
+				t.Fatalf("failed to get responses: %v", err)
+			}
+			if len(batch) == 0 {
+				break
+			}
+			walked = append(walked, batch...)
+			afterID = batch[len(batch)-1].ID
+		}
+
+		if len(walked) != len(responses) {
+			t.Fatalf("expected %d responses, got %d", len(responses), len(walked))
+		}
+		for i := range responses {
+			assertResponsesEqual(t, walked[i], responses[i])
+		}
+	})
+
+	t.Run("GetFinalResponsesAfter", func(t *testing.T) {
+		storage := newStorage(t)
+		final := func(resp *service.PostalApiResponse) *service.PostalApiResponse {
+			resp.IsFinal = true
+			return resp
+		}
+		notFound := final(newResponse("not-found", "api1", 1000, "not found"))
+		notFound.Status = service.StatusNotFound
+		expected := []*service.PostalApiResponse{
+			final(newResponse("delivered", "api1", 1000, "delivered")),
+			final(newResponse("returned", "api1", 1000, "returned")),
+		}
+		insert(t, storage,
+			expected[0],
+			newResponse("in-transit", "api1", 1000, "in transit"),
+			final(newResponse("superseded", "api1", 1000, "old")),
+			newResponse("superseded", "api1", 2000, "new"),
+			notFound,
+			final(newResponse("other-api", "api2", 1000, "other api")),
+			expected[1],
+		)
+
+		var walked []*service.PostalApiResponse
+		var afterID int64
+		for page := 0; ; page++ {
+			if page > len(expected) {
+				t.Fatalf("expected pagination to stop, got %d pages", page)
+			}
+			batch, err := storage.GetFinalResponsesAfter(ctx, "api1", afterID, 1)
+			if err != nil {
+				t.Fatalf("failed to get final responses: %v", err)
+			}
+			if len(batch) == 0 {
+				break
+			}
+			walked = append(walked, batch...)
+			afterID = batch[len(batch)-1].ID
+		}
+
+		if len(walked) != len(expected) {
+			t.Fatalf("expected %d responses, got %d", len(expected), len(walked))
+		}
+		for i := range expected {
+			assertResponsesEqual(t, walked[i], expected[i])
+		}
+	})
+
+	t.Run("InsertLinks and GetLinks", func(t *testing.T) {
+		storage := newStorage(t)
+
+		if err := storage.InsertLinks(ctx, "123", []string{"456", "789"}); err != nil {
+			t.Fatalf("failed to insert links: %v", err)
+		}
+		if err := storage.InsertLinks(ctx, "123", []string{"456"}); err != nil {
+			t.Fatalf("failed to insert already known links: %v", err)
+		}
+
+		links, err := storage.GetLinks(ctx, "123")
+		if err != nil {
+			t.Fatalf("failed to get links: %v", err)
+		}
+		if !reflect.DeepEqual(links, []string{"456", "789"}) {
+			t.Fatalf("expected links [456 789], got %v", links)
+		}
+
+		links, err = storage.GetLinks(ctx, "456")
+		if err != nil {
+			t.Fatalf("failed to get links: %v", err)
+		}
+		if len(links) != 0 {
+			t.Fatalf("expected links to be directional, got %v", links)
+		}
+	})
+
+	t.Run("UpsertLocalized and GetLocalized", func(t *testing.T) {
+		storage := newStorage(t)
+
+		localized, err := storage.GetLocalized(ctx, "123", "api1", "ru")
+		if err != nil {
+			t.Fatalf("failed to get localized: %v", err)
+		}
+		if localized != nil {
+			t.Fatalf("expected no localized response, got %+v", localized)
+		}
+
+		for _, resp := range []*service.PostalApiResponse{
+			newResponse("123", "api1", 1000, "ru-old"),
+			newResponse("123", "api1", 2000, "ru-new"),
+		} {
+			resp.Language = "ru"
+			if err := storage.UpsertLocalized(ctx, resp); err != nil {
+				t.Fatalf("failed to upsert localized: %v", err)
+			}
+		}
+		other := newResponse("123", "api1", 3000, "he")
+		other.Language = "he"
+		if err := storage.UpsertLocalized(ctx, other); err != nil {
+			t.Fatalf("failed to upsert localized: %v", err)
+		}
+
+		localized, err = storage.GetLocalized(ctx, "123", "api1", "ru")
+		if err != nil {
+			t.Fatalf("failed to get localized: %v", err)
+		}
+		if localized == nil ||
+			string(localized.ResponseBody) != "ru-new" ||
+			localized.Language != "ru" ||
+			localized.Status != service.StatusSuccess ||
+			!localized.LastFetchedAt.Equal(time.Unix(2000, 0)) {
+			t.Fatalf("expected the latest localized response in ru, got %+v", localized)
+		}
+
+		latest, err := storage.GetLatest(ctx, "123", []service.APIName{"api1"})
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		if len(latest) != 0 {
+			t.Fatalf("expected localized responses not to be regular ones, got %d", len(latest))
+		}
+	})
+}
+
+// TestSubscriptionsStorage runs the suite against subscriptions storages created by newStorage
+// TestRetentionStorage runs the suite against retention storages created by newStorages,
+// service.Storage sharing the same database is used to populate it
+func TestRetentionStorage(t *testing.T, newStorages func(t *testing.T) (service.Storage, retention.Storage)) {
+	ctx := context.Background()
+
+	t.Run("Prune", func(t *testing.T) {
+		storage, retentionStorage := newStorages(t)
+
+		respond := func(trackingNumber string, apiName service.APIName, lastFetchedAt int64, isFinal bool) {
+			t.Helper()
+			resp := &service.PostalApiResponse{
+				FirstFetchedAt: time.Unix(lastFetchedAt, 0),
+				LastFetchedAt:  time.Unix(lastFetchedAt, 0),
+				ResponseBody:   []byte(fmt.Sprintf("%s-%d", trackingNumber, lastFetchedAt)),
+				Status:         service.StatusSuccess,
+				IsFinal:        isFinal,
+			}
+			if err := storage.Insert(ctx, trackingNumber, apiName, resp); err != nil {
+				t.Fatalf("failed to insert: %v", err)
+			}
+		}
+		// not fetched for so long that it's expired
+		respond("expired", "api1", 100, false)
+		respond("expired", "api1", 200, false)
+		// one of the APIs is not fetched for long, but the parcel is still tracked by the other one
+		respond("tracked", "api1", 200, false)
+		respond("tracked", "api2", 5000, false)
+		// delivered long ago, only first and latest responses are worth keeping
+		respond("delivered", "api1", 1100, false)
+		respond("delivered", "api1", 1200, false)
+		respond("delivered", "api1", 1300, false)
+		respond("delivered", "api1", 1400, true)
+		// delivered recently, nothing to prune yet
+		respond("delivered-recently", "api1", 3000, false)
+		respond("delivered-recently", "api1", 5000, false)
+		respond("delivered-recently", "api1", 9000, true)
+		// still in transit, only history older than retention period is pruned
+		respond("in-transit", "api1", 500, false)
+		respond("in-transit", "api1", 600, false)
+		respond("in-transit", "api1", 1500, false)
+		respond("in-transit", "api1", 2000, false)
+		if err := storage.InsertLinks(ctx, "expired", []string{"linked"}); err != nil {
+			t.Fatalf("failed to insert links: %v", err)
+		}
+		for _, trackingNumber := range []string{"expired", "tracked"} {
+			localized := &service.PostalApiResponse{
+				TrackingNumber: trackingNumber,
+				APIName:        "api1",
+				LastFetchedAt:  time.Unix(200, 0),
+				ResponseBody:   []byte(trackingNumber + "-ru"),
+				Status:         service.StatusSuccess,
+				Language:       "ru",
+			}
+			if err := storage.UpsertLocalized(ctx, localized); err != nil {
+				t.Fatalf("failed to upsert localized: %v", err)
+			}
+		}
+
+		query := retention.Query{
+			ExpiredFetchedBefore:   time.Unix(1000, 0),
+			DeliveredFetchedBefore: time.Unix(2000, 0),
+			HistoryFetchedBefore:   time.Unix(1000, 0),
+		}
+		expected := retention.Report{Expired: 2, Delivered: 2, History: 1}
+
+		report, err := retentionStorage.Prune(ctx, query, true)
+		if err != nil {
+			t.Fatalf("failed to dry-run prune: %v", err)
+		}
+		if report != expected {
+			t.Fatalf("expected dry run to report %+v, got %+v", expected, report)
+		}
+
+		// dry run did not delete anything, so the real run reports the same
+		report, err = retentionStorage.Prune(ctx, query, false)
+		if err != nil {
+			t.Fatalf("failed to prune: %v", err)
+		}
+		if report != expected {
+			t.Fatalf("expected prune to report %+v, got %+v", expected, report)
+		}
+
+		report, err = retentionStorage.Prune(ctx, query, false)
+		if err != nil {
+			t.Fatalf("failed to prune again: %v", err)
+		}
+		if report != (retention.Report{}) {
+			t.Fatalf("expected nothing left to prune, got %+v", report)
+		}
+
+		latest, err := storage.GetLatestBatch(
+			ctx,
+			[]string{"expired", "tracked", "delivered", "delivered-recently", "in-transit"},
+			[]service.APIName{"api1", "api2"},
+		)
+		if err != nil {
+			t.Fatalf("failed to get latest: %v", err)
+		}
+		sortResponses(latest)
+		var latestFetchedAt []int64
+		for _, resp := range latest {
+			latestFetchedAt = append(latestFetchedAt, resp.LastFetchedAt.Unix())
+		}
+		if expected := []int64{1400, 9000, 2000, 200, 5000}; !reflect.DeepEqual(latestFetchedAt, expected) {
+			t.Fatalf("expected latest responses fetched at %v to survive, got %v", expected, latestFetchedAt)
+		}
+
+		links, err := storage.GetLinks(ctx, "expired")
+		if err != nil {
+			t.Fatalf("failed to get links: %v", err)
+		}
+		if len(links) != 0 {
+			t.Fatalf("expected links of expired tracking number to be deleted, got %v", links)
+		}
+
+		for trackingNumber, shouldSurvive := range map[string]bool{"expired": false, "tracked": true} {
+			localized, err := storage.GetLocalized(ctx, trackingNumber, "api1", "ru")
+			if err != nil {
+				t.Fatalf("failed to get localized: %v", err)
+			}
+			if (localized != nil) != shouldSurvive {
+				t.Fatalf("expected localized response of %s to survive: %v, got %+v", trackingNumber, shouldSurvive, localized)
+			}
+		}
+
+		// the first responses survived as well: only those in between would be pruned with no retention period at all
+		report, err = retentionStorage.Prune(ctx, retention.Query{HistoryFetchedBefore: time.Unix(100000, 0)}, true)
+		if err != nil {
+			t.Fatalf("failed to dry-run prune: %v", err)
+		}
+		if report.History != 2 {
+			t.Fatalf("expected 2 responses in between first and latest to be left, got %+v", report)
+		}
+	})
+}
+
+func TestSubscriptionsStorage(t *testing.T, newStorage func(t *testing.T) subscriptions.Storage) {
+	ctx := context.Background()
+
+	t.Run("subscriptions and deliveries", func(t *testing.T) {
+		storage := newStorage(t)
+
+		sub := &subscriptions.Subscription{
+			TrackingNumber: "123",
+			CallbackURL:    "http://example.com/hook",
+			Secret:         "secret",
+			CreatedAt:      time.Unix(1000, 0),
+		}
+		if err := storage.CreateSubscription(ctx, sub); err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+		if sub.ID == 0 {
+			t.Fatalf("expected subscription ID to be set")
+		}
+
+		subs, err := storage.GetSubscriptions(ctx, "123")
+		if err != nil {
+			t.Fatalf("failed to get subscriptions: %v", err)
+		}
+		if len(subs) != 1 || !reflect.DeepEqual(subs[0], sub) {
+			t.Fatalf("expected %+v, got %+v", sub, subs)
+		}
+
+		due, notYetDue := &subscriptions.Delivery{
+			SubscriptionID: sub.ID,
+			Payload:        []byte(`{"event":"due"}`),
+			Status:         subscriptions.DeliveryStatusPending,
+			NextAttemptAt:  time.Unix(2000, 0),
+			CreatedAt:      time.Unix(2000, 0),
+		}, &subscriptions.Delivery{
+			SubscriptionID: sub.ID,
+			Payload:        []byte(`{"event":"not yet due"}`),
+			Status:         subscriptions.DeliveryStatusPending,
+			NextAttemptAt:  time.Unix(4000, 0),
+			CreatedAt:      time.Unix(2000, 0),
+		}
+		if err := storage.InsertDeliveries(ctx, []*subscriptions.Delivery{due, notYetDue}); err != nil {
+			t.Fatalf("failed to insert deliveries: %v", err)
+		}
+
+		dueDeliveries, err := storage.ClaimDueDeliveries(ctx, time.Unix(3000, 0), time.Unix(4500, 0), 10)
+		if err != nil {
+			t.Fatalf("failed to get due deliveries: %v", err)
+		}
+		if len(dueDeliveries) != 1 || dueDeliveries[0].ID != due.ID {
+			t.Fatalf("expected only due delivery, got %+v", dueDeliveries)
+		}
+		if dueDeliveries[0].CallbackURL != sub.CallbackURL || dueDeliveries[0].Secret != sub.Secret {
+			t.Fatalf("expected delivery to carry subscription details, got %+v", dueDeliveries[0])
+		}
+		if !bytes.Equal(dueDeliveries[0].Payload, due.Payload) {
+			t.Fatalf("expected payload %s, got %s", due.Payload, dueDeliveries[0].Payload)
+		}
+		// another replica polling at the same time should not send it too
+		if claimedAgain, err := storage.ClaimDueDeliveries(ctx, time.Unix(3000, 0), time.Unix(4500, 0), 10); err != nil {
+			t.Fatalf("failed to get due deliveries: %v", err)
+		} else if len(claimedAgain) != 0 {
+			t.Fatalf("expected claimed delivery not to be claimed again, got %+v", claimedAgain)
+		}
+
+		due.Status = subscriptions.DeliveryStatusDelivered
+		due.Attempts = 1
+		if err := storage.UpdateDelivery(ctx, due); err != nil {
+			t.Fatalf("failed to update delivery: %v", err)
+		}
+		dueDeliveries, err = storage.ClaimDueDeliveries(ctx, time.Unix(5000, 0), time.Unix(6000, 0), 10)
+		if err != nil {
+			t.Fatalf("failed to get due deliveries: %v", err)
+		}
+		if len(dueDeliveries) != 1 || dueDeliveries[0].ID != notYetDue.ID {
+			t.Fatalf("expected only pending delivery, got %+v", dueDeliveries)
+		}
+
+		// replica that claimed it died before sending it, so it's due again once the lease is over
+		dueDeliveries, err = storage.ClaimDueDeliveries(ctx, time.Unix(6000, 0), time.Unix(7000, 0), 10)
+		if err != nil {
+			t.Fatalf("failed to get due deliveries: %v", err)
+		}
+		if len(dueDeliveries) != 1 || dueDeliveries[0].ID != notYetDue.ID {
+			t.Fatalf("expected delivery to be claimed again after lease, got %+v", dueDeliveries)
+		}
+	})
+}
+
+func assertResponsesEqual(t *testing.T, actual, expected *service.PostalApiResponse) {
+	t.Helper()
+	if actual.ID != expected.ID ||
+		actual.TrackingNumber != expected.TrackingNumber ||
+		actual.APIName != expected.APIName ||
+		!actual.FirstFetchedAt.Equal(expected.FirstFetchedAt) ||
+		!actual.LastFetchedAt.Equal(expected.LastFetchedAt) ||
+		!bytes.Equal(actual.ResponseBody, expected.ResponseBody) ||
+		actual.Status != expected.Status ||
+		actual.IsFinal != expected.IsFinal {
+		t.Fatalf("expected response %+v, got %+v", expected, actual)
+	}
+	if actual.ResponseHash != service.HashResponseBody(expected.ResponseBody) {
+		t.Fatalf("expected response hash to be computed from body, got %q", actual.ResponseHash)
+	}
+}
+
+func sortResponses(responses []*service.PostalApiResponse) {
+	sort.Slice(responses, func(i, j int) bool {
+		if responses[i].TrackingNumber != responses[j].TrackingNumber {
+			return responses[i].TrackingNumber < responses[j].TrackingNumber
+		}
+		return responses[i].APIName < responses[j].APIName
+	})
+}
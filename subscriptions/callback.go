@@ -0,0 +1,69 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress means callback URL points to an address webhooks are not sent to,
+// since otherwise anyone could make us poke our own internal network
+var ErrForbiddenAddress = errors.New("callback URL should point to a public address")
+
+// IsPublicIP tells whether webhooks can be sent to ip: loopback, private, link-local,
+// unspecified and multicast addresses are all considered ours or nobody's
+func IsPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
+
+// CheckCallbackURL resolves the host of callback URL and returns ErrForbiddenAddress if any of its addresses is not public.
+// This only spares subscribers a webhook that would never be delivered:
+// host may resolve differently by the time webhook is sent, which is why NewWebhookClient checks again
+func CheckCallbackURL(ctx context.Context, callbackURL string) error {
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return err
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		if !IsPublicIP(ip.IP) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// NewWebhookClient creates HTTP client that refuses to connect to addresses that are not public.
+// The address is checked after host is resolved, right before connecting, so neither DNS tricks nor redirects get around it
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+				return fmt.Errorf("%w, got %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// proxy would connect on our behalf, where we can't check the address
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
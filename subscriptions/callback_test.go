@@ -0,0 +1,68 @@
+package subscriptions_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/subscriptions"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	testCases := []struct {
+		ip       string
+		expected bool
+	}{
+		{"8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.ip, func(t *testing.T) {
+			if actual := subscriptions.IsPublicIP(net.ParseIP(tc.ip)); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCheckCallbackURL(t *testing.T) {
+	for _, callbackURL := range []string{"http://127.0.0.1:8080/hook", "http://localhost/hook", "https://169.254.169.254/latest"} {
+		t.Run(callbackURL, func(t *testing.T) {
+			if err := subscriptions.CheckCallbackURL(context.Background(), callbackURL); !errors.Is(err, subscriptions.ErrForbiddenAddress) {
+				t.Fatalf("expected %v, got %v", subscriptions.ErrForbiddenAddress, err)
+			}
+		})
+	}
+}
+
+func TestWebhookClient(t *testing.T) {
+	hit := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	t.Cleanup(server.Close)
+
+	// callback URL could have resolved to a public address at registration, but not anymore
+	_, err := subscriptions.NewWebhookClient(time.Second).Post(server.URL, "application/json", nil)
+	if !errors.Is(err, subscriptions.ErrForbiddenAddress) {
+		t.Fatalf("expected %v, got %v", subscriptions.ErrForbiddenAddress, err)
+	}
+	if hit {
+		t.Fatalf("expected webhook not to be sent to loopback address")
+	}
+}
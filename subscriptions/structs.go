@@ -0,0 +1,54 @@
+package subscriptions
+
+import (
+	"time"
+)
+
+// Subscription is a request to be notified about changes of a parcel
+type Subscription struct {
+	ID             int64
+	TrackingNumber string
+	CallbackURL    string
+	// Secret is used to sign payloads, so that receiver can verify that notification came from us
+	Secret    string
+	CreatedAt time.Time
+}
+
+// Delivery is a single notification to be sent to a subscriber.
+// Deliveries are stored in an outbox first, and are sent (and retried) by the dispatcher later.
+type Delivery struct {
+	ID             int64
+	SubscriptionID int64
+	// CallbackURL and Secret are copied from the subscription for dispatcher's convenience
+	CallbackURL   string
+	Secret        string
+	Payload       []byte
+	Status        DeliveryStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	// DeliveryStatusDead means we gave up after too many attempts
+	DeliveryStatusDead DeliveryStatus = "dead"
+)
+
+// Payload is what subscriber receives in the body of a notification
+type Payload struct {
+	Event          string         `json:"event"`
+	TrackingNumber string         `json:"tracking_number"`
+	ApiName        string         `json:"api_name"`
+	Events         []PayloadEvent `json:"events"`
+}
+
+type PayloadEvent struct {
+	Time        string `json:"time"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+}
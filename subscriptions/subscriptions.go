@@ -0,0 +1,257 @@
+package subscriptions
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"github.com/hori-ryota/zaperr"
+	"go.uber.org/zap"
+)
+
+// SignatureHeader contains hex-encoded HMAC-SHA256 of the request body, keyed with subscription secret
+const SignatureHeader = "X-Parcels-Signature"
+
+// EventTrackingInfoChanged is sent whenever a parcel gets new events
+const EventTrackingInfoChanged = "tracking_info.changed"
+
+type Service interface {
+	Subscribe(ctx context.Context, trackingNumber string, callbackURL string) (*Subscription, error)
+}
+
+// Storage contains subscriptions and the outbox of their deliveries
+type Storage interface {
+	CreateSubscription(ctx context.Context, subscription *Subscription) error
+	GetSubscriptions(ctx context.Context, trackingNumber string) ([]*Subscription, error)
+	InsertDeliveries(ctx context.Context, deliveries []*Delivery) error
+	// ClaimDueDeliveries returns pending deliveries with NextAttemptAt not after now, oldest first,
+	// and postpones their next attempt until leaseUntil, so that other replicas don't send them too.
+	// Should the replica die before updating them, they are due again once the lease is over
+	ClaimDueDeliveries(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery *Delivery) error
+}
+
+// Metrics describes what custom metrics subscriptions should report on
+type Metrics interface {
+	WebhookDelivered()
+	WebhookFailed()
+	WebhookDead()
+}
+
+func NewService(
+	storage Storage,
+	metrics Metrics,
+	httpClient *http.Client,
+	pollInterval time.Duration,
+	baseBackoff time.Duration,
+	maxAttempts int,
+	logger *zap.Logger,
+	now func() time.Time,
+) *Impl {
+	s := &Impl{
+		storage:      storage,
+		metrics:      metrics,
+		httpClient:   httpClient,
+		pollInterval: pollInterval,
+		baseBackoff:  baseBackoff,
+		maxAttempts:  maxAttempts,
+		log:          logger,
+		now:          now,
+	}
+	var _ Service = s
+	var _ service.ChangeListener = s
+	return s
+}
+
+type Impl struct {
+	storage      Storage
+	metrics      Metrics
+	httpClient   *http.Client
+	pollInterval time.Duration
+	baseBackoff  time.Duration
+	maxAttempts  int
+	log          *zap.Logger
+	now          func() time.Time
+}
+
+// dispatchBatchSize is how many deliveries are sent per dispatcher iteration
+const dispatchBatchSize = 100
+
+// defaultDeliveryLease is how long claimed deliveries are left to the dispatcher that claimed them,
+// when HTTP client has no timeout to tell how long sending a batch could take
+const defaultDeliveryLease = 10 * time.Minute
+
+func (s *Impl) Subscribe(ctx context.Context, trackingNumber string, callbackURL string) (*Subscription, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, zaperr.Wrap(err, "failed to generate secret")
+	}
+
+	subscription := &Subscription{
+		TrackingNumber: trackingNumber,
+		CallbackURL:    callbackURL,
+		Secret:         hex.EncodeToString(secret),
+		CreatedAt:      s.now(),
+	}
+	if err := s.storage.CreateSubscription(ctx, subscription); err != nil {
+		return nil, zaperr.Wrap(err, "failed to create subscription", zap.String("trackingNumber", trackingNumber))
+	}
+	return subscription, nil
+}
+
+// TrackingInfoChanged puts a delivery for every subscriber of the parcel into the outbox
+func (s *Impl) TrackingInfoChanged(ctx context.Context, trackingInfo *service.TrackingInfo) {
+	logger := s.log.With(
+		zap.String("trackingNumber", trackingInfo.TrackingNumber),
+		zap.String("apiName", string(trackingInfo.APIName)),
+	)
+
+	subs, err := s.storage.GetSubscriptions(ctx, trackingInfo.TrackingNumber)
+	if err != nil {
+		logger.Error("failed to get subscriptions", zaperr.ToField(err))
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(Payload{}.fromBusinessStruct(trackingInfo))
+	if err != nil {
+		logger.Error("failed to marshal payload", zap.Error(err))
+		return
+	}
+
+	now := s.now()
+	deliveries := make([]*Delivery, 0, len(subs))
+	for _, sub := range subs {
+		deliveries = append(deliveries, &Delivery{
+			SubscriptionID: sub.ID,
+			Payload:        payload,
+			Status:         DeliveryStatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+	if err := s.storage.InsertDeliveries(ctx, deliveries); err != nil {
+		logger.Error("failed to insert deliveries", zaperr.ToField(err))
+		return
+	}
+	logger.Info("enqueued webhook deliveries", zap.Int("count", len(deliveries)))
+}
+
+// RunDispatcher sends due deliveries every pollInterval until ctx is done
+func (s *Impl) RunDispatcher(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.DispatchDue(ctx); err != nil {
+			s.log.Error("failed to dispatch deliveries", zaperr.ToField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			s.log.Info("dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue sends deliveries that are due now.
+// Failed deliveries are retried with exponential backoff, until maxAttempts is reached.
+func (s *Impl) DispatchDue(ctx context.Context) error {
+	now := s.now()
+	deliveries, err := s.storage.ClaimDueDeliveries(ctx, now, now.Add(s.deliveryLease()), dispatchBatchSize)
+	if err != nil {
+		return zaperr.Wrap(err, "failed to get due deliveries")
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		delivery.Attempts++
+		if err := s.send(ctx, delivery); err == nil {
+			delivery.Status = DeliveryStatusDelivered
+			delivery.LastError = ""
+			s.metrics.WebhookDelivered()
+		} else if delivery.Attempts >= s.maxAttempts {
+			delivery.Status = DeliveryStatusDead
+			delivery.LastError = err.Error()
+			s.metrics.WebhookDead()
+			s.log.Error("webhook delivery is dead", zap.Int64("deliveryID", delivery.ID), zap.Error(err))
+		} else {
+			delivery.LastError = err.Error()
+			delivery.NextAttemptAt = s.now().Add(s.baseBackoff * time.Duration(1<<(delivery.Attempts-1)))
+			s.metrics.WebhookFailed()
+			s.log.Warn("webhook delivery failed", zap.Int64("deliveryID", delivery.ID), zap.Error(err))
+		}
+
+		if err := s.storage.UpdateDelivery(ctx, delivery); err != nil {
+			return zaperr.Wrap(err, "failed to update delivery", zap.Int64("deliveryID", delivery.ID))
+		}
+	}
+	return nil
+}
+
+// deliveryLease is long enough to send a whole batch, even if every subscriber takes as long as it's allowed to
+func (s *Impl) deliveryLease() time.Duration {
+	if s.httpClient.Timeout == 0 {
+		return defaultDeliveryLease
+	}
+	return s.httpClient.Timeout*dispatchBatchSize + time.Minute
+}
+
+func (s *Impl) send(ctx context.Context, delivery *Delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.CallbackURL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, "sha256="+Sign(delivery.Payload, delivery.Secret))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns hex-encoded HMAC-SHA256 of payload
+func Sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (p Payload) fromBusinessStruct(t *service.TrackingInfo) Payload {
+	p.Event = EventTrackingInfoChanged
+	p.TrackingNumber = t.TrackingNumber
+	p.ApiName = string(t.APIName)
+	p.Events = []PayloadEvent{}
+	for _, e := range t.Events {
+		p.Events = append(p.Events, PayloadEvent{
+			Time:        e.Time.Format(time.RFC3339),
+			Description: e.Description,
+			Status:      string(e.Status),
+		})
+	}
+	return p
+}
@@ -0,0 +1,204 @@
+package subscriptions_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dir01/parcels/service"
+	"github.com/dir01/parcels/sqlite_storage"
+	"github.com/dir01/parcels/subscriptions"
+	"github.com/jmoiron/sqlx"
+	"github.com/rubenv/sql-migrate"
+	"go.uber.org/zap"
+)
+
+type fakeMetrics struct {
+	delivered, failed, dead int
+}
+
+func (f *fakeMetrics) WebhookDelivered() { f.delivered++ }
+func (f *fakeMetrics) WebhookFailed()    { f.failed++ }
+func (f *fakeMetrics) WebhookDead()      { f.dead++ }
+
+// receiver is a local webhook receiver that responds with the given status codes in order
+type receiver struct {
+	mu          sync.Mutex
+	statusCodes []int
+	requests    []*http.Request
+	bodies      [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	statusCode := http.StatusOK
+	if len(rc.statusCodes) > 0 {
+		statusCode = rc.statusCodes[0]
+		rc.statusCodes = rc.statusCodes[1:]
+	}
+	w.WriteHeader(statusCode)
+}
+
+func TestSubscriptions(t *testing.T) {
+	prepareTestSubjects := func(statusCodes ...int) (
+		svc *subscriptions.Impl,
+		sub *subscriptions.Subscription,
+		rc *receiver,
+		metrics *fakeMetrics,
+		setNow func(time.Time),
+	) {
+		db := sqlx.MustConnect("sqlite3", ":memory:")
+		db.SetMaxOpenConns(1)
+		migrations := &migrate.FileMigrationSource{
+			Dir: "../db/migrations",
+		}
+		if _, err := migrate.Exec(db.DB, "sqlite3", migrations, migrate.Up); err != nil {
+			t.Fatalf("failed to apply migrations: %v", err)
+		}
+
+		rc = &receiver{statusCodes: statusCodes}
+		server := httptest.NewServer(rc)
+		t.Cleanup(server.Close)
+
+		now := time.Unix(10000, 0)
+		setNow = func(t time.Time) {
+			now = t
+		}
+		metrics = &fakeMetrics{}
+		svc = subscriptions.NewService(
+			sqlite_storage.NewSubscriptionsStorage(db),
+			metrics,
+			server.Client(),
+			time.Second,
+			time.Minute,
+			3,
+			zap.NewNop(),
+			func() time.Time { return now },
+		)
+
+		sub, err := svc.Subscribe(context.Background(), "123", server.URL+"/hook")
+		if err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+
+		return svc, sub, rc, metrics, setNow
+	}
+
+	trackingInfo := &service.TrackingInfo{
+		TrackingNumber: "123",
+		APIName:        "api1",
+		Events: []service.TrackingEvent{
+			{Time: time.Unix(5000, 0), Description: "Delivered", Status: service.TrackingStatusDelivered},
+		},
+	}
+
+	t.Run("delivers signed payload", func(t *testing.T) {
+		ctx := context.Background()
+		svc, _, rc, metrics, _ := prepareTestSubjects()
+
+		svc.TrackingInfoChanged(ctx, trackingInfo)
+		if err := svc.DispatchDue(ctx); err != nil {
+			t.Fatalf("failed to dispatch: %v", err)
+		}
+
+		if len(rc.requests) != 1 {
+			t.Fatalf("expected 1 request, got %d", len(rc.requests))
+		}
+		var payload subscriptions.Payload
+		if err := json.Unmarshal(rc.bodies[0], &payload); err != nil {
+			t.Fatalf("failed to unmarshal payload: %v", err)
+		}
+		if payload.TrackingNumber != "123" || payload.Event != subscriptions.EventTrackingInfoChanged || len(payload.Events) != 1 {
+			t.Fatalf("unexpected payload: %+v", payload)
+		}
+		if metrics.delivered != 1 {
+			t.Fatalf("expected 1 delivered, got %d", metrics.delivered)
+		}
+
+		// dispatching again should not resend
+		if err := svc.DispatchDue(ctx); err != nil {
+			t.Fatalf("failed to dispatch: %v", err)
+		}
+		if len(rc.requests) != 1 {
+			t.Fatalf("expected delivered notification not to be resent, got %d requests", len(rc.requests))
+		}
+	})
+
+	t.Run("signature can be verified with subscription secret", func(t *testing.T) {
+		ctx := context.Background()
+		svc, sub, rc, _, _ := prepareTestSubjects()
+		if sub.Secret == "" {
+			t.Fatalf("expected secret to be generated")
+		}
+
+		svc.TrackingInfoChanged(ctx, trackingInfo)
+		if err := svc.DispatchDue(ctx); err != nil {
+			t.Fatalf("failed to dispatch: %v", err)
+		}
+
+		signature := rc.requests[0].Header.Get(subscriptions.SignatureHeader)
+		if expected := "sha256=" + subscriptions.Sign(rc.bodies[0], sub.Secret); signature != expected {
+			t.Fatalf("expected signature %s, got %s", expected, signature)
+		}
+		if signature == "sha256="+subscriptions.Sign(rc.bodies[0], "another secret") {
+			t.Fatalf("expected signature to depend on secret")
+		}
+	})
+
+	t.Run("retries with exponential backoff and gives up", func(t *testing.T) {
+		ctx := context.Background()
+		svc, _, rc, metrics, setNow := prepareTestSubjects(
+			http.StatusInternalServerError,
+			http.StatusInternalServerError,
+			http.StatusInternalServerError,
+		)
+
+		svc.TrackingInfoChanged(ctx, trackingInfo)
+
+		dispatchAt := func(at time.Time) {
+			setNow(at)
+			if err := svc.DispatchDue(ctx); err != nil {
+				t.Fatalf("failed to dispatch: %v", err)
+			}
+		}
+
+		dispatchAt(time.Unix(10000, 0))
+		if len(rc.requests) != 1 || metrics.failed != 1 {
+			t.Fatalf("expected first attempt to fail, got %d requests", len(rc.requests))
+		}
+
+		dispatchAt(time.Unix(10000, 0).Add(59 * time.Second))
+		if len(rc.requests) != 1 {
+			t.Fatalf("expected no retry before backoff, got %d requests", len(rc.requests))
+		}
+
+		dispatchAt(time.Unix(10000, 0).Add(time.Minute))
+		if len(rc.requests) != 2 || metrics.failed != 2 {
+			t.Fatalf("expected second attempt after 1 minute, got %d requests", len(rc.requests))
+		}
+
+		dispatchAt(time.Unix(10000, 0).Add(2*time.Minute + 59*time.Second))
+		if len(rc.requests) != 2 {
+			t.Fatalf("expected backoff to double, got %d requests", len(rc.requests))
+		}
+
+		dispatchAt(time.Unix(10000, 0).Add(3 * time.Minute))
+		if len(rc.requests) != 3 || metrics.dead != 1 {
+			t.Fatalf("expected third attempt to be the last one, got %d requests, %d dead", len(rc.requests), metrics.dead)
+		}
+
+		dispatchAt(time.Unix(10000, 0).Add(24 * time.Hour))
+		if len(rc.requests) != 3 {
+			t.Fatalf("expected dead delivery not to be retried, got %d requests", len(rc.requests))
+		}
+	})
+}